@@ -5,15 +5,31 @@
 package main
 
 import (
+	"context"
 	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	// Users' timezones must resolve in images without system tz database
+	_ "time/tzdata"
 
 	_ "github.com/limbo/discipline/docs"
 
+	"github.com/google/uuid"
 	"github.com/limbo/discipline/internal/api"
+	"github.com/limbo/discipline/internal/metrics"
 	"github.com/limbo/discipline/internal/repository"
 	"github.com/limbo/discipline/internal/service"
+	"github.com/limbo/discipline/pkg/cache"
+	"github.com/limbo/discipline/pkg/cleanup"
 	"github.com/limbo/discipline/pkg/config"
+	"github.com/limbo/discipline/pkg/entity"
 	jwtservice "github.com/limbo/discipline/pkg/jwt_service"
+	"github.com/limbo/discipline/pkg/logging"
+	"github.com/limbo/discipline/pkg/reqid"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func init() {
@@ -22,21 +38,186 @@ func init() {
 
 func main() {
 	cfg := config.New()
+	// E.g. LOG_FORMAT=json in production for structured logs, text in development
+	logger := logging.New(os.Stdout, cfg.GetString("LOG_LEVEL"), cfg.GetString("LOG_FORMAT"))
 	dbCfg := repository.PGCfg{
 		Address:  cfg.GetString("POSTGRES_DB_ADDRESS"),
 		Username: cfg.GetString("POSTGRES_USER"),
 		Password: cfg.GetString("POSTGRES_PASSWORD"),
 		DB:       cfg.GetString("POSTGRES_DB"),
 	}
-	userService := service.NewUserService(repository.NewUsersRepo(&dbCfg))
-	habitService := service.NewHabitsService(repository.NewHabitsRepo(&dbCfg))
+	if migrate, _ := strconv.ParseBool(cfg.GetString("MIGRATE_ON_START")); migrate {
+		if err := repository.Migrate(context.Background(), &dbCfg); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// Reads are offloaded to replica only if its address is provided
+	var replicaCfg repository.DBConfig
+	if replicaAddr := cfg.GetString("POSTGRES_REPLICA_ADDRESS"); replicaAddr != "" {
+		replicaCfg = &repository.PGCfg{
+			Address:  replicaAddr,
+			Username: dbCfg.Username,
+			Password: dbCfg.Password,
+			DB:       dbCfg.DB,
+		}
+	}
+	// Caches users looked up on each authorized request, 0 turns caching off
+	userCacheTTL, err := time.ParseDuration(cfg.GetString("USER_CACHE_TTL"))
+	if err != nil {
+		userCacheTTL = time.Minute
+	}
+	var userCache cache.Cache[uuid.UUID, entity.User]
+	if userCacheTTL > 0 {
+		userCache = cache.NewMemory[uuid.UUID, entity.User](userCacheTTL)
+	}
+	// Can only be lowered below service.MaxUsernameLength
+	if maxLen, err := strconv.Atoi(cfg.GetString("USERNAME_MAX_LENGTH")); err == nil {
+		service.SetUsernameMaxLength(maxLen)
+	}
+	usersRepo := repository.NewUsersRepoWithReplica(&dbCfg, replicaCfg)
+	userService := service.NewUserServiceWithCache(usersRepo, userCache)
+	// Raising BCRYPT_COST upgrades stored hashes on users' next login
+	if cost, err := strconv.Atoi(cfg.GetString("BCRYPT_COST")); err == nil {
+		userService.SetHashCost(cost)
+	}
+	// Comma-separated names users can't take, replaces default list if set
+	if reserved := cfg.GetString("RESERVED_NAMES"); reserved != "" {
+		userService.SetReservedNames(strings.Split(reserved, ",")...)
+	}
+	habitsRepo := repository.NewHabitsRepoWithReplica(&dbCfg, replicaCfg)
+	// Deleted habits can be restored for HABIT_RESTORE_DAYS (7 by default), then they are purged
+	restoreDays, _ := strconv.Atoi(cfg.GetString("HABIT_RESTORE_DAYS"))
+	restoreWindow := time.Duration(restoreDays) * 24 * time.Hour
+	if restoreWindow <= 0 {
+		restoreWindow = service.DefaultRestoreWindow
+	}
+	habitService := service.NewHabitsServiceWithRestoreWindow(habitsRepo, restoreWindow)
+	schedulePurge(habitService, restoreWindow, logger)
+	checksRepo := repository.NewHabitChecksRepoWithReplica(&dbCfg, replicaCfg)
+	// Queries running longer than SLOW_QUERY_THRESHOLD (e.g. 200ms) are logged as warnings, off if unset
+	slowQueryThreshold, _ := time.ParseDuration(cfg.GetString("SLOW_QUERY_THRESHOLD"))
+	usersRepo.SetSlowQueryLogging(logger, slowQueryThreshold)
+	habitsRepo.SetSlowQueryLogging(logger, slowQueryThreshold)
+	checksRepo.SetSlowQueryLogging(logger, slowQueryThreshold)
+	checksService := service.NewHabitChecksServiceWithUsers(habitsRepo, checksRepo, usersRepo)
+	// Stats are kept in habit_stats, updated in one transaction with checks
+	checksService.SetTxRunner(repository.NewTxManager(&dbCfg))
+	habitService.SetChecksService(checksService)
+	// Pools stats are exposed on /metrics, replica ones only if replica is set
+	metrics.NewPoolCollector(prometheus.DefaultRegisterer, map[string]metrics.PoolStatsSource{
+		"users":                usersRepo,
+		"habits":               habitsRepo,
+		"habit_checks":         checksRepo,
+		"users_replica":        metrics.PoolStatsFunc(usersRepo.ReplicaPoolStats),
+		"habits_replica":       metrics.PoolStatsFunc(habitsRepo.ReplicaPoolStats),
+		"habit_checks_replica": metrics.PoolStatsFunc(checksRepo.ReplicaPoolStats),
+	}).Start(15 * time.Second)
+	debugErrors, _ := strconv.ParseBool(cfg.GetString("DEBUG_ERRORS"))
+	rejectGetBodies, _ := strconv.ParseBool(cfg.GetString("REJECT_GET_BODIES"))
+	// Bodies are logged at debug level, so LOG_LEVEL must be debug too
+	logBodies, _ := strconv.ParseBool(cfg.GetString("LOG_BODIES"))
+	// Auth failures look like unknown paths (404) if set
+	hideAuthFailures, _ := strconv.ParseBool(cfg.GetString("HIDE_AUTH_FAILURES"))
+	// Comma-separated origins allowed to cross-origin requests ("*" for any), CORS is off if unset.
+	// Zero CORS_MAX_AGE (unset or invalid) leaves default preflight cache duration
+	var corsOrigins []string
+	for _, origin := range strings.Split(cfg.GetString("CORS_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			corsOrigins = append(corsOrigins, origin)
+		}
+	}
+	corsMaxAge, _ := time.ParseDuration(cfg.GetString("CORS_MAX_AGE"))
+	// For TLS terminated at app or proxy: HTTPS_REDIRECT redirects plain HTTP requests,
+	// HSTS_MAX_AGE (e.g. 8760h) enables Strict-Transport-Security, both are off if unset
+	httpsRedirect, _ := strconv.ParseBool(cfg.GetString("HTTPS_REDIRECT"))
+	hstsMaxAge, _ := time.ParseDuration(cfg.GetString("HSTS_MAX_AGE"))
+	// Zero values (unset or invalid) leave default limits
+	defaultPageLimit, _ := strconv.Atoi(cfg.GetString("DEFAULT_PAGE_LIMIT"))
+	maxPageLimit, _ := strconv.Atoi(cfg.GetString("MAX_PAGE_LIMIT"))
+	// Zero (unset or invalid) leaves default request timeout
+	requestTimeout, _ := time.ParseDuration(cfg.GetString("REQUEST_TIMEOUT"))
+	// Requests served at once are limited by MAX_IN_FLIGHT, unlimited if unset
+	maxInFlight, _ := strconv.Atoi(cfg.GetString("MAX_IN_FLIGHT"))
+	// Writes per user: USER_RATE_LIMIT per second with USER_RATE_BURST burst, defaults are 5 and 10
+	userRate, err := strconv.ParseFloat(cfg.GetString("USER_RATE_LIMIT"), 64)
+	if err != nil {
+		userRate = 5
+	}
+	userBurst, err := strconv.Atoi(cfg.GetString("USER_RATE_BURST"))
+	if err != nil {
+		userBurst = 10
+	}
+	// Comma-separated ids of users allowed to admin endpoints
+	var admins []uuid.UUID
+	for _, rawID := range strings.Split(cfg.GetString("ADMIN_USER_IDS"), ",") {
+		if rawID = strings.TrimSpace(rawID); rawID == "" {
+			continue
+		}
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			log.Fatal("invalid id in ADMIN_USER_IDS: " + rawID)
+		}
+		admins = append(admins, id)
+	}
+	// One of "uuid" (default), "ulid" or "counter"
+	requestIDs, err := reqid.New(cfg.GetString("REQUEST_ID_FORMAT"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	inheritRequestID, _ := strconv.ParseBool(cfg.GetString("REQUEST_ID_INHERIT"))
+	// /metrics is served on METRICS_ADDRESS (e.g. internal interface) instead of API_ADDRESS if set
+	metricsAddress := cfg.GetString("METRICS_ADDRESS")
+	jwtSecret := cfg.GetString("JWT_SECRET")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET is empty")
+	}
 	serv := api.New(&api.ServicesList{
 		UserService:   userService,
 		HabitsService: habitService,
-		JwtService:    jwtservice.New(cfg.GetString("JWT_SECRET")),
-	})
-	err := serv.Run(cfg.GetString("API_ADDRESS"))
+		ChecksService: checksService,
+		// Tokens signed with JWT_PREVIOUS_SECRETS (comma-separated) stay valid during rotation
+		JwtService: jwtservice.NewWithRotation(jwtSecret, strings.Split(cfg.GetString("JWT_PREVIOUS_SECRETS"), ",")),
+	}, api.WithDebugErrors(debugErrors), api.WithPageLimits(defaultPageLimit, maxPageLimit),
+		api.WithRequestTimeout(requestTimeout), api.WithLogger(logger), api.WithUserRateLimit(userRate, userBurst),
+		api.WithAdmins(admins...), api.WithRejectGetBodies(rejectGetBodies), api.WithBodyLogging(logBodies),
+		api.WithHiddenAuthFailures(hideAuthFailures), api.WithSchemaVersionSource(repository.NewSchemaInspector(&dbCfg)),
+		api.WithCORSOrigins(corsOrigins...), api.WithCORSMaxAge(corsMaxAge), api.WithMaxInFlight(maxInFlight),
+		api.WithHTTPSRedirect(httpsRedirect), api.WithHSTS(hstsMaxAge),
+		api.WithRequestIDGenerator(requestIDs), api.WithInheritedRequestID(inheritRequestID), api.WithMetricsAddress(metricsAddress))
+	err = serv.Run(cfg.GetString("API_ADDRESS"))
 	if err != nil {
-		log.Println("Server error: " + err.Error())
+		logger.Error("server stopped with error", slog.String("error", err.Error()))
+		os.Exit(1)
 	}
 }
+
+// Purges habits deleted longer than window ago every hour until cleanup.
+func schedulePurge(habits service.HabitsServiceI, window time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				purged, err := habits.PurgeDeleted(ctx, window)
+				cancel()
+				if err != nil {
+					logger.Error("purging deleted habits error", slog.String("error", err.Error()))
+					continue
+				}
+				logger.Info("deleted habits purged", slog.Int64("count", purged))
+			}
+		}
+	}()
+	cleanup.Register(&cleanup.Job{
+		Name: "stopping deleted habits purge",
+		F: func() error {
+			ticker.Stop()
+			close(done)
+			return nil
+		},
+	})
+}
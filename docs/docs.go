@@ -15,79 +15,158 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Recieves user's credentials and on success returns user ID and auth token.\nGives back error if user doesn't exist or password is wrong, etc.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/users": {
+            "get": {
+                "description": "Admin only. Lists users whose name contains q (case-insensitive) with pagination and sorting.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Authentication with providing token",
+                "summary": "Provides list of users for operators",
                 "parameters": [
                     {
-                        "description": "User's credentials",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of user name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "default": "name",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit of users by page, clamped to configured max (50 by default)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Response with user ID and auth token",
+                        "description": "Page of users with total count",
                         "schema": {
-                            "$ref": "#/definitions/api.UIDResponse"
+                            "$ref": "#/definitions/api.ListUsersResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Unknown sort order",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "Wrong credentials",
+                        "description": "User is not admin",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/disable": {
+            "post": {
+                "description": "Admin only. Suspends account without deleting its data: user can't log in and already issued tokens are rejected with 403.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Disables user's account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Disabled"
+                    },
+                    "400": {
+                        "description": "Invalid user id",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not admin",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "User doesn't exist",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Something went wrong internally (in services, repos etc.)",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "Recieves username and password, registers new user\nand saves in DB.",
+        "/auth/account": {
+            "delete": {
+                "description": "Recieves user's password for confirmation and deletes account. With erase=true all habits and checks\nare removed explicitly in single transaction and summary of removed rows is returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -97,65 +176,78 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Register a new user",
+                "summary": "Deletes authorized user's account",
                 "parameters": [
                     {
-                        "description": "User's credentials",
-                        "name": "credentials",
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Erase all user's data and return summary",
+                        "name": "erase",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Password confirmation",
+                        "name": "password",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.RegisterRequest"
+                            "$ref": "#/definitions/api.DeleteAccountRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Response with user ID",
+                    "200": {
+                        "description": "Data erased",
                         "schema": {
-                            "$ref": "#/definitions/api.UIDResponse"
+                            "$ref": "#/definitions/entity.ErasureSummary"
                         }
                     },
+                    "204": {
+                        "description": "Account deleted"
+                    },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid request body or erase param",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Registering already existed user",
+                    "401": {
+                        "description": "Authorization failed or wrong password",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Something went wrong internally (in services, repos etc.)",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/habits": {
+        "/auth/account/summary": {
             "get": {
-                "description": "Provides list of user's habits with pagination in query params (page, limit).",
+                "description": "Returns counts of rows account deletion would remove: user, all habits (including deleted ones) and their checks.\nNothing is removed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Habits"
+                    "Users"
                 ],
-                "summary": "Provides list of habits",
+                "summary": "Previews authorized user's account deletion",
                 "parameters": [
                     {
                         "type": "string",
@@ -163,61 +255,46 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Limit of habits by page",
-                        "name": "limit",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Response with md (uid, page, limit) and habits list",
+                        "description": "Data to be removed",
                         "schema": {
-                            "$ref": "#/definitions/api.GetHabitsResponse"
+                            "$ref": "#/definitions/entity.ErasureSummary"
                         }
                     },
                     "401": {
                         "description": "Authorization failed",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Something went wrong internally (in services, repos etc.)",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Recieves habits' title and description, create new one\nand returns its ID.",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/auth/export": {
+            "get": {
+                "description": "Returns single JSON document with user's profile, all habits and all their checks and skips, for download.\nDocument is streamed as it's read from database. If reading fails midway, connection is aborted, so incomplete document is never taken for whole one.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Habits"
+                    "Users"
                 ],
-                "summary": "Creates new user's habit",
+                "summary": "Exports authorized user's data",
                 "parameters": [
                     {
                         "type": "string",
@@ -225,85 +302,110 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User's data",
+                        "schema": {
+                            "$ref": "#/definitions/api.AccountExport"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "Makes document downloaded as discipline-export.json"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Recieves user's credentials and on success returns user ID and auth token.\nGives back error if user doesn't exist or password is wrong, etc.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Authentication with providing token",
+                "parameters": [
                     {
-                        "description": "Habit title and description",
-                        "name": "Habit",
+                        "description": "User's credentials",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.CreateHabitRequest"
+                            "$ref": "#/definitions/api.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Response with habit_id",
+                    "200": {
+                        "description": "Response with user ID and auth token",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.UIDResponse"
                         }
                     },
                     "400": {
                         "description": "Invalid request body",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Authorization failed",
+                    "403": {
+                        "description": "Wrong credentials or account is disabled",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Owner (user) doesn't exist",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "409": {
-                        "description": "Habit with such title already exists",
+                        "description": "User doesn't exist",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Something went wrong internally (in services, repos etc.)",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/habits/{id}": {
-            "delete": {
-                "description": "Recieves habit ID in path, deletes it if user is owner.",
+        "/auth/profile": {
+            "get": {
+                "description": "Returns user ID, name and time of last login.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Habits"
+                    "Users"
                 ],
-                "summary": "Deletes habit",
+                "summary": "Provides authorized user's profile",
                 "parameters": [
                     {
                         "type": "string",
@@ -311,89 +413,2866 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Habit ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Invalid id param in path",
+                        "description": "User's profile",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.ProfileResponse"
                         }
                     },
                     "401": {
                         "description": "Authorization failed",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "description": "User doesn't exist",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Something went wrong internally (in services, repos etc.)",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/httputil.ErrorResponse"
                         }
                     }
                 }
             }
-        }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Recieves username and password, registers new user\nand saves in DB.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User's credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Response with user ID",
+                        "schema": {
+                            "$ref": "#/definitions/api.UIDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or credentials don't meet requirements",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Registering already existed user, error_code tells taken field (name_taken)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/revoke-all": {
+            "post": {
+                "description": "Makes every token issued to user so far (including one of request) rejected, user has to log in again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Revokes all authorized user's tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Revoked"
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/timezone": {
+            "put": {
+                "description": "Recieves IANA timezone name, in which user's days (e.g. \"today\" for checks) are counted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Sets authorized user's timezone",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Timezone",
+                        "name": "timezone",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SetTimezoneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Timezone set"
+                    },
+                    "400": {
+                        "description": "Invalid request body or unknown timezone",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/username": {
+            "put": {
+                "description": "Recieves new name, validates it with the same rules as on registration and renames user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Renames authorized user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "New name",
+                        "name": "name",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ChangeUsernameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Renamed"
+                    },
+                    "400": {
+                        "description": "Invalid request body or name",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Name is already taken",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/checks": {
+            "get": {
+                "description": "Returns checks and skips of all user's habits on date, sorted by habit title.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Provides user's checks on date",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date (YYYY-MM-DD), today in user's timezone if empty",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Checks on date with titles of their habits",
+                        "schema": {
+                            "$ref": "#/definitions/api.UserChecksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/checks/batch": {
+            "post": {
+                "description": "Recieves list of checks, possibly of different habits, and creates valid ones in single transaction.\nResult of each item is reported separately: others' habits, disallowed dates and already checked days don't prevent other checks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Checks several habits at once",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Checks to create",
+                        "name": "Checks",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.CheckBatchItem"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "All checks created, result for each one in request order",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.BatchResponse-httputil_BatchItemResult"
+                        }
+                    },
+                    "207": {
+                        "description": "Some checks failed, result for each one in request order",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.BatchResponse-httputil_BatchItemResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, empty or too big batch",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/errors": {
+            "get": {
+                "description": "Returns machine-readable codes error responses may carry in error_code, with their statuses and messages.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service"
+                ],
+                "summary": "Provides catalog of error codes",
+                "responses": {
+                    "200": {
+                        "description": "Known error codes",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorsCatalogResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/habits": {
+            "get": {
+                "description": "Returns combined list of habits owned by members of group, ordered by creation time.\nUntil groups are introduced, every user has own group with id equal to user's id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Groups"
+                ],
+                "summary": "Provides habits of group members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit of habits in page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of group habits with total count",
+                        "schema": {
+                            "$ref": "#/definitions/api.GroupHabitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Group doesn't exist or authorizated user is not its member",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits": {
+            "get": {
+                "description": "Provides list of user's habits with pagination in query params (page, limit) and optional fields projection.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Provides list of habits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit of habits by page, clamped to configured max (50 by default)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated habit fields to provide (id, uid, title, desc, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags), unknown ones are ignored",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Switches to cursor pagination (page is ignored): next_cursor of previous response, empty for the first page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Adds checked_today field to each habit (today is in user's timezone)",
+                        "name": "with_today",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "position"
+                        ],
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "Order of habits: by creation or arranged by user (see PUT /habits/order), offset pagination only",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time of list client has, in HTTP date format",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of habits with md (uid, total, total_pages; cursor pages have no page and totals), habits have only requested fields if projection is set and checked_today field if with_today is set (see HabitsWithTodayResponse)",
+                        "schema": {
+                            "$ref": "#/definitions/api.GetHabitsResponse"
+                        },
+                        "headers": {
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time habits list last changed"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Habits list hasn't changed since If-Modified-Since, never responded with with_today",
+                        "headers": {
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time habits list last changed"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor, unknown sort or sort is used with cursor",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Recieves habits' title and description, create new one\nand returns it with its ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Creates new user's habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Habit title, description, color (#RRGGBB), icon and tags",
+                        "name": "Habit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateHabitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created habit with habit_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateHabitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, habit color or tags",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Owner (user) doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Habit with such title already exists",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many write requests from user",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/batch": {
+            "post": {
+                "description": "Recieves list of habits and creates them in single transaction.\nResult of each item is reported separately: duplicates and invalid items don't prevent others from creation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Creates several habits at once",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Habits to create",
+                        "name": "Habits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateHabitsBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "All habits created, result for each one in request order",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.BatchResponse-api_BatchItemResult"
+                        }
+                    },
+                    "207": {
+                        "description": "Some habits failed, result for each one in request order",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.BatchResponse-api_BatchItemResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, empty or too big batch",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Owner (user) doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/order": {
+            "put": {
+                "description": "Recieves user's habits IDs in desired order, it's used by habits list with sort=position.\nPositions are changed all at once, habits not listed go after listed ones in their previous order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Arranges user's habits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Habits in desired order",
+                        "name": "Order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ReorderHabitsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Reordered"
+                    },
+                    "400": {
+                        "description": "Invalid request body or habit id, empty list, too many or duplicated habits",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Any of habits doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/tags": {
+            "get": {
+                "description": "Returns sorted distinct tags of authorizated user's habits, empty list if there are none.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Provides tags of user's habits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User's tags",
+                        "schema": {
+                            "$ref": "#/definitions/api.TagsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}": {
+            "get": {
+                "description": "Recieves habit ID in path. With include=stats habit is returned along with its checks stats.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Provides habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "stats"
+                        ],
+                        "type": "string",
+                        "description": "Set to stats to include checks stats",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Habit, stats are present only if requested",
+                        "schema": {
+                            "$ref": "#/definitions/api.HabitDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path or include value",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Recieves habit ID in path, deletes it if user is owner. Deleted habit can be restored for a while.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Deletes habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Invalid id param in path",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "description": "Recieves habit ID in path. Responds same status as GET does, but without body.",
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Checks habit existence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Habit exists and is owned by authorizated user"
+                    },
+                    "400": {
+                        "description": "Invalid id param in path"
+                    },
+                    "401": {
+                        "description": "Authorization failed"
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner"
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Recieves habit ID in path and fields to update in body.\nOnly provided fields are updated, absent ones stay untouched. Returns updated habit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Partially updates habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Habit fields to update",
+                        "name": "Habit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PatchHabitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated habit",
+                        "schema": {
+                            "$ref": "#/definitions/entity.Habit"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path, request body, habit color or tags",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Habit with such title already exists",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/adherence": {
+            "get": {
+                "description": "Returns count of checked days among last N days (today in user's timezone included).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Provides how many of last days habit was checked",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 30,
+                        "description": "Window size in days, 1..365",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Checked days and window size",
+                        "schema": {
+                            "$ref": "#/definitions/api.AdherenceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path or days",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/checks": {
+            "get": {
+                "description": "Returns checks and skips of habit between from and to (both included), sorted by date.\nPeriod is last 30 days (today in user's timezone included) by default, up to 366 days are allowed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Provides habit's checks for period",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date of period (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date of period (YYYY-MM-DD), today if empty",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Order by date",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "checked",
+                            "skipped"
+                        ],
+                        "type": "string",
+                        "description": "Provides only completions or only skips (rest days), all marks if empty",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Marks for period",
+                        "schema": {
+                            "$ref": "#/definitions/api.HabitChecksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path, dates, period or status",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Marks habit as done on given date (today by default) with optional note.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Checks habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Check date and note",
+                        "name": "Check",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CheckHabitRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Replace note of existing check instead of failing with 409",
+                        "name": "upsert",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated check (upsert only)",
+                        "schema": {
+                            "$ref": "#/definitions/api.CheckHabitResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created check",
+                        "schema": {
+                            "$ref": "#/definitions/api.CheckHabitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path, request body or date in the future or before habit start",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Habit already checked on this date",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many write requests from user",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/checks/by-month": {
+            "get": {
+                "description": "Returns days habit was checked on in year, grouped by month number. Skipped days aren't included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Provides habit's check history by month",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year of history, current one in user's timezone if empty",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Checked days by month",
+                        "schema": {
+                            "$ref": "#/definitions/api.MonthlyChecksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path or year",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/checks/can": {
+            "get": {
+                "description": "Runs same validations as checking habit (date allowed, not checked yet) without creating check.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Tells if habit can be checked",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date to check (YYYY-MM-DD), today in user's timezone if empty",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If check is allowed and reason if it isn't",
+                        "schema": {
+                            "$ref": "#/definitions/api.CanCheckResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path or date",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/checks/count": {
+            "get": {
+                "description": "Returns count of checks (skips excluded) on habit between from and to, both included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Provides count of habit's checks in period",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period start (YYYY-MM-DD), unbounded if empty",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period end (YYYY-MM-DD), today in user's timezone if empty",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Count of checks",
+                        "schema": {
+                            "$ref": "#/definitions/api.CountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path or period",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/checks/latest": {
+            "get": {
+                "description": "Returns date of the most recent check (skips excluded), 204 if habit has no checks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Provides date of habit's last check",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Last check date",
+                        "schema": {
+                            "$ref": "#/definitions/api.LastCheckResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "Habit has no checks"
+                    },
+                    "400": {
+                        "description": "Invalid id param in path",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/checks/{date}": {
+            "delete": {
+                "description": "Deletes check of habit on date given in path.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Unchecks habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Check date (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid id or date param in path",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit or check doesn't exist, or authorizated user is not habit's owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many write requests from user",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/merge": {
+            "post": {
+                "description": "Recieves target habit ID in path and source one in body. Checks and skips of source are moved to target,\non days both habits are marked target's marks are kept. Source is deleted permanently after that.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Merges duplicate habit into another one",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Source habit",
+                        "name": "Merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.MergeHabitsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Merged"
+                    },
+                    "400": {
+                        "description": "Invalid id param in path, request body or source_id, source is target itself",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Any of habits doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/pause": {
+            "post": {
+                "description": "Pauses habit since today (in user's timezone): paused days break neither streak nor completion rate.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Pauses habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Habit paused"
+                    },
+                    "400": {
+                        "description": "Invalid id param in path",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Habit is already paused",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/restore": {
+            "post": {
+                "description": "Recieves habit ID in path, brings it back with its checks if it was deleted recently and user is owner.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Habits"
+                ],
+                "summary": "Restores deleted habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Restored"
+                    },
+                    "400": {
+                        "description": "Invalid id param in path",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No recently deleted habit or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Habit's title is used by another habit",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/resume": {
+            "post": {
+                "description": "Ends habit's pause, today (in user's timezone) is tracked again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Resumes paused habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Habit resumed"
+                    },
+                    "400": {
+                        "description": "Invalid id param in path",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Habit isn't paused",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/skip": {
+            "post": {
+                "description": "Marks given date (today by default) as skipped: it neither breaks nor extends habit's streak.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Skips habit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Skip date",
+                        "name": "Skip",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SkipHabitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created skip",
+                        "schema": {
+                            "$ref": "#/definitions/api.SkipHabitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path, request body or date in the future or before habit start",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Habit already checked or skipped on this date",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/habits/{id}/stats/weekdays": {
+            "get": {
+                "description": "Returns count of checks on habit for each day of week, starting from Sunday.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Provides habit's checks by weekday",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Habit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Checks by weekday",
+                        "schema": {
+                            "$ref": "#/definitions/api.WeekdayDistributionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id param in path",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Habit doesn't exist or authorizated user is not its owner",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reminders/at-risk": {
+            "get": {
+                "description": "Returns habits marked yesterday but not today yet (in user's timezone), so user can be reminded before the day ends. Paused habits aren't included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminders"
+                ],
+                "summary": "Provides habits whose streak is at risk",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Habits to check today to keep streak",
+                        "schema": {
+                            "$ref": "#/definitions/api.AtRiskHabitsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/server": {
+            "get": {
+                "description": "Admin only. Returns in-memory counters of requests served by this instance since its start:\ntotal, by status class and average latency. Counters are reset on restart.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Provides request counters of server",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Counters snapshot",
+                        "schema": {
+                            "$ref": "#/definitions/api.ServerStatsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not admin",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/summary": {
+            "get": {
+                "description": "Returns count of habits and checks, and the longest max streak among all user's habits.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Checks"
+                ],
+                "summary": "Provides stats over all user's habits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Aggregated stats",
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserSummary"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization failed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Returns version, git commit and build time of deployed service.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service"
+                ],
+                "summary": "Provides build info",
+                "responses": {
+                    "200": {
+                        "description": "Build info",
+                        "schema": {
+                            "$ref": "#/definitions/api.VersionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/version/schema": {
+            "get": {
+                "description": "Returns version of latest migration applied to database, to check schema matches deployed service.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service"
+                ],
+                "summary": "Provides database schema version",
+                "responses": {
+                    "200": {
+                        "description": "Applied schema version",
+                        "schema": {
+                            "$ref": "#/definitions/api.SchemaVersionResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong internally (in services, repos etc.)",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Migrations were never applied or version isn't provided",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "api.AccountExport": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.HabitCheck"
+                    }
+                },
+                "habits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Habit"
+                    }
+                },
+                "profile": {
+                    "$ref": "#/definitions/api.ProfileResponse"
+                }
+            }
+        },
+        "api.AdherenceResponse": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "type": "integer",
+                    "example": 21
+                },
+                "habit_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 30
+                }
+            }
+        },
+        "api.AdminUser": {
+            "type": "object",
+            "properties": {
+                "last_login_at": {
+                    "type": "string",
+                    "example": "2025-01-01T12:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "arch_linux_user"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                },
+                "uid": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "api.AtRiskHabitsResponse": {
+            "type": "object",
+            "properties": {
+                "habits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Habit"
+                    }
+                }
+            }
+        },
+        "api.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "habit already exists"
+                },
+                "error_code": {
+                    "description": "Machine-readable code of item's error, see AppError. Absent for succeeded items and internal errors",
+                    "type": "string",
+                    "example": "habit_exists"
+                },
+                "habit": {
+                    "$ref": "#/definitions/entity.Habit"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "api.CanCheckResponse": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "reason": {
+                    "description": "Why check would be rejected (check_date_not_allowed, check_exists), empty if it's allowed",
+                    "type": "string",
+                    "example": "check_exists"
+                }
+            }
+        },
+        "api.ChangeUsernameRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "gentoo_user"
+                }
+            }
+        },
+        "api.CheckBatchItem": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Date in YYYY-MM-DD format",
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "habit_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "api.CheckHabitRequest": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Date in YYYY-MM-DD format, today in user's timezone if empty",
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "felt great"
+                }
+            }
+        },
+        "api.CheckHabitResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "habit_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "felt great"
+                }
+            }
+        },
+        "api.CountResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
         "api.CreateHabitRequest": {
             "type": "object",
             "properties": {
+                "allow_multiple_per_day": {
+                    "description": "Habit may be checked several times a day, can't be changed later",
+                    "type": "boolean",
+                    "example": false
+                },
+                "color": {
+                    "type": "string",
+                    "example": "#00ff00"
+                },
+                "desc": {
+                    "type": "string",
+                    "example": "hit my legs very hard"
+                },
+                "icon": {
+                    "type": "string",
+                    "example": "dumbbell"
+                },
+                "start_date": {
+                    "description": "YYYY-MM-DD since which habit is tracked, creation day if empty",
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "health",
+                        "morning"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "LEG DAY"
+                }
+            }
+        },
+        "api.CreateHabitResponse": {
+            "type": "object",
+            "properties": {
+                "allow_multiple_per_day": {
+                    "description": "Habit may be checked several times a day, then its checks are counted, not just days",
+                    "type": "boolean"
+                },
+                "color": {
+                    "description": "Hex code as #RRGGBB, empty if not set",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "Set when habit is deleted, it can be restored until purged. Nil for active habits",
+                    "type": "string"
+                },
+                "desc": {
+                    "type": "string"
+                },
+                "habit_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Calendar day (UTC midnight) since which habit is tracked, creation day by default",
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Labels user groups habits by, empty if there are none",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateHabitsBatchRequest": {
+            "type": "object",
+            "properties": {
+                "habits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CreateHabitRequest"
+                    }
+                }
+            }
+        },
+        "api.DeleteAccountRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "secret_passw0rd"
+                }
+            }
+        },
+        "api.ErrorCodeInfo": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "habit_not_found"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "habit doesn't exists"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                }
+            }
+        },
+        "api.ErrorsCatalogResponse": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ErrorCodeInfo"
+                    }
+                }
+            }
+        },
+        "api.GetHabitsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Habit"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "next_cursor": {
+                    "description": "Cursor of next page, set only with cursor pagination while there may be more habits",
+                    "type": "string",
+                    "example": "MjAyNS0wMS0wMVQxMjowMDowMFp8NTUwZTg0MDAtZTI5Yi00MWQ0LWE3MTYtNDQ2NjU1NDQwMDAw"
+                },
+                "page": {
+                    "description": "Absent in cursor pages",
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "description": "Count of items on all pages, absent in cursor pages since they aren't counted",
+                    "type": "integer",
+                    "example": 42
+                },
+                "total_pages": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "uid": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "api.GroupHabitsResponse": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Habit"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "page": {
+                    "description": "Absent in cursor pages",
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "description": "Count of items on all pages, absent in cursor pages since they aren't counted",
+                    "type": "integer",
+                    "example": 42
+                },
+                "total_pages": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "api.HabitCheckResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-01T12:00:00Z"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "felt great"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.CheckStatus"
+                        }
+                    ],
+                    "example": "checked"
+                }
+            }
+        },
+        "api.HabitChecksResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.HabitCheckResponse"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "habit_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-01-30"
+                }
+            }
+        },
+        "api.HabitDetailResponse": {
+            "type": "object",
+            "properties": {
+                "allow_multiple_per_day": {
+                    "description": "Habit may be checked several times a day, then its checks are counted, not just days",
+                    "type": "boolean"
+                },
+                "color": {
+                    "description": "Hex code as #RRGGBB, empty if not set",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "Set when habit is deleted, it can be restored until purged. Nil for active habits",
+                    "type": "string"
+                },
+                "desc": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Calendar day (UTC midnight) since which habit is tracked, creation day by default",
+                    "type": "string"
+                },
+                "stats": {
+                    "$ref": "#/definitions/entity.HabitStats"
+                },
+                "tags": {
+                    "description": "Labels user groups habits by, empty if there are none",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.LastCheckResponse": {
+            "type": "object",
+            "properties": {
+                "last_check": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                }
+            }
+        },
+        "api.ListUsersResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AdminUser"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "page": {
+                    "description": "Absent in cursor pages",
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "description": "Count of items on all pages, absent in cursor pages since they aren't counted",
+                    "type": "integer",
+                    "example": 42
+                },
+                "total_pages": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "api.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "arch_linux_user"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "secret_passw0rd"
+                }
+            }
+        },
+        "api.MergeHabitsRequest": {
+            "type": "object",
+            "properties": {
+                "source_id": {
+                    "description": "Habit merged into one in path, it's deleted after merge",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "api.MonthlyChecksResponse": {
+            "type": "object",
+            "properties": {
+                "habit_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "months": {
+                    "description": "Checked days of month keyed by month number (1-12), months without checks are absent",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2024
+                }
+            }
+        },
+        "api.PatchHabitRequest": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string",
+                    "example": "#ff0000"
+                },
                 "desc": {
                     "type": "string",
-                    "example": "hit my legs very hard"
+                    "example": "hit my arms very hard"
+                },
+                "icon": {
+                    "type": "string",
+                    "example": "biceps"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "tags": {
+                    "description": "Replaces all habit's tags, empty list removes them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "health",
+                        "evening"
+                    ]
                 },
                 "title": {
                     "type": "string",
-                    "example": "LEG DAY"
+                    "example": "ARM DAY"
                 }
             }
         },
-        "api.GetHabitsResponse": {
+        "api.ProfileResponse": {
             "type": "object",
             "properties": {
-                "habits": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/entity.Habit"
-                    }
+                "last_login_at": {
+                    "type": "string",
+                    "example": "2025-01-01T12:00:00Z"
                 },
-                "limit": {
-                    "type": "integer",
-                    "example": 10
+                "name": {
+                    "type": "string",
+                    "example": "arch_linux_user"
                 },
-                "page": {
-                    "type": "integer",
-                    "example": 1
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Moscow"
                 },
                 "uid": {
                     "type": "string",
@@ -401,7 +3280,7 @@ const docTemplate = `{
                 }
             }
         },
-        "api.LoginRequest": {
+        "api.RegisterRequest": {
             "type": "object",
             "properties": {
                 "name": {
@@ -410,20 +3289,109 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string",
-                    "example": "secret_password"
+                    "example": "secret_passw0rd"
                 }
             }
         },
-        "api.RegisterRequest": {
+        "api.ReorderHabitsRequest": {
             "type": "object",
             "properties": {
-                "name": {
+                "habit_ids": {
+                    "description": "User's habits in desired order, ones not listed go after them in their previous order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "550e8400-e29b-41d4-a716-446655440000"
+                    ]
+                }
+            }
+        },
+        "api.SchemaVersionResponse": {
+            "type": "object",
+            "properties": {
+                "version": {
+                    "type": "integer",
+                    "example": 14
+                }
+            }
+        },
+        "api.ServerStatsResponse": {
+            "type": "object",
+            "properties": {
+                "average_latency_ms": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "by_status_class": {
+                    "description": "Requests by status class (1xx-5xx), classes without requests are absent",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "since": {
+                    "description": "Requests are counted since server start",
                     "type": "string",
-                    "example": "arch_linux_user"
+                    "example": "2025-01-01T12:00:00Z"
                 },
-                "password": {
+                "total_requests": {
+                    "type": "integer",
+                    "example": 1024
+                }
+            }
+        },
+        "api.SetTimezoneRequest": {
+            "type": "object",
+            "properties": {
+                "timezone": {
+                    "description": "IANA timezone name",
+                    "type": "string",
+                    "example": "Asia/Vladivostok"
+                }
+            }
+        },
+        "api.SkipHabitRequest": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Date in YYYY-MM-DD format, today in user's timezone if empty",
+                    "type": "string",
+                    "example": "2025-01-01"
+                }
+            }
+        },
+        "api.SkipHabitResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "habit_id": {
                     "type": "string",
-                    "example": "secret_password"
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "skipped"
+                }
+            }
+        },
+        "api.TagsResponse": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "health",
+                        "morning"
+                    ]
                 }
             }
         },
@@ -440,18 +3408,155 @@ const docTemplate = `{
                 }
             }
         },
+        "api.UserCheckResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-01T12:00:00Z"
+                },
+                "habit_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "habit_title": {
+                    "type": "string",
+                    "example": "Morning run"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "felt great"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.CheckStatus"
+                        }
+                    ],
+                    "example": "checked"
+                }
+            }
+        },
+        "api.UserChecksResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.UserCheckResponse"
+                    }
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                }
+            }
+        },
+        "api.VersionResponse": {
+            "type": "object",
+            "properties": {
+                "build_time": {
+                    "type": "string",
+                    "example": "2025-01-01T12:00:00Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "7ea22e3"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v1.0.0"
+                }
+            }
+        },
+        "api.WeekdayDistributionResponse": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "description": "Checks count by day of week, starting from Sunday",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        0,
+                        4,
+                        2,
+                        0,
+                        1,
+                        0,
+                        0
+                    ]
+                },
+                "habit_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "entity.CheckStatus": {
+            "type": "string",
+            "enum": [
+                "checked",
+                "skipped"
+            ],
+            "x-enum-varnames": [
+                "CheckStatusChecked",
+                "CheckStatusSkipped"
+            ]
+        },
+        "entity.ErasureSummary": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "integer"
+                },
+                "habits": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
         "entity.Habit": {
             "type": "object",
             "properties": {
+                "allow_multiple_per_day": {
+                    "description": "Habit may be checked several times a day, then its checks are counted, not just days",
+                    "type": "boolean"
+                },
+                "color": {
+                    "description": "Hex code as #RRGGBB, empty if not set",
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "Set when habit is deleted, it can be restored until purged. Nil for active habits",
+                    "type": "string"
+                },
                 "desc": {
                     "type": "string"
                 },
+                "icon": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
+                "start_date": {
+                    "description": "Calendar day (UTC midnight) since which habit is tracked, creation day by default",
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Labels user groups habits by, empty if there are none",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
                 },
@@ -462,6 +3567,146 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "entity.HabitCheck": {
+            "type": "object",
+            "properties": {
+                "checkDate": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "habitID": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "User's annotation, empty if not set",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/entity.CheckStatus"
+                }
+            }
+        },
+        "entity.HabitStats": {
+            "type": "object",
+            "properties": {
+                "completion_rate": {
+                    "type": "number",
+                    "example": 0.75
+                },
+                "current_streak": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "habit_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "last_check": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "max_streak": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "paused": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "total_checks": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "entity.UserSummary": {
+            "type": "object",
+            "properties": {
+                "habits": {
+                    "type": "integer"
+                },
+                "longest_streak": {
+                    "description": "The longest max streak among user's habits",
+                    "type": "integer"
+                },
+                "total_checks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "httputil.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "habit already exists"
+                },
+                "error_code": {
+                    "description": "Machine-readable code of item's error, see AppError. Absent for succeeded items and internal errors",
+                    "type": "string",
+                    "example": "habit_exists"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "httputil.BatchResponse-api_BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BatchItemResult"
+                    }
+                }
+            }
+        },
+        "httputil.BatchResponse-httputil_BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httputil.BatchItemResult"
+                    }
+                }
+            }
+        },
+        "httputil.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Duplicates HTTP status code",
+                    "type": "integer",
+                    "example": 404
+                },
+                "details": {
+                    "description": "Underlying error, provided if debug errors are on or it clarifies client's error (e.g. unmet validation rules)",
+                    "type": "string",
+                    "example": "habit not found"
+                },
+                "error_code": {
+                    "description": "Machine-readable error code, see AppError",
+                    "type": "string",
+                    "example": "habit_not_found"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "habit doesn't exist"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	errorvalues "github.com/limbo/discipline/internal/error_values"
+	"github.com/limbo/discipline/pkg/httputil"
+)
+
+// Statuses and codes services' errors are responded with.
+// New sentinel needs just a line here to be handled by every handler.
+func init() {
+	register := func(sentinel error, status int, code string) {
+		httputil.RegisterError(sentinel, httputil.AppError{Status: status, Code: code})
+	}
+	// Validation details tell client which fields are wrong
+	httputil.RegisterError(errorvalues.ErrValidation, httputil.AppError{
+		Status:      http.StatusBadRequest,
+		Code:        "validation_failed",
+		ShowDetails: true,
+	})
+	// Specific conflicts go first to win over ErrUserExists they wrap
+	register(errorvalues.ErrNameTaken, http.StatusConflict, "name_taken")
+	register(errorvalues.ErrUserExists, http.StatusConflict, "user_exists")
+	register(errorvalues.ErrUserNotFound, http.StatusNotFound, "user_not_found")
+	register(errorvalues.ErrOwnerNotFound, http.StatusNotFound, "user_not_found")
+	register(errorvalues.ErrWrongCredentials, http.StatusForbidden, "wrong_credentials")
+	register(errorvalues.ErrAccountDisabled, http.StatusForbidden, "account_disabled")
+	register(errorvalues.ErrInvalidToken, http.StatusUnauthorized, "invalid_token")
+	register(errorvalues.ErrUserHasHabit, http.StatusConflict, "habit_exists")
+	register(errorvalues.ErrHabitNotFound, http.StatusNotFound, "habit_not_found")
+	// Others' habits are indistinguishable from unexist ones
+	httputil.RegisterError(errorvalues.ErrWrongOwner, httputil.AppError{
+		Err:    errorvalues.ErrHabitNotFound,
+		Status: http.StatusNotFound,
+		Code:   "habit_not_found",
+	})
+	register(errorvalues.ErrCheckExist, http.StatusConflict, "check_exists")
+	register(errorvalues.ErrCheckNotFound, http.StatusNotFound, "check_not_found")
+	register(errorvalues.ErrCheckDateNotAllowed, http.StatusBadRequest, "check_date_not_allowed")
+	register(errorvalues.ErrGroupNotFound, http.StatusNotFound, "group_not_found")
+	register(errorvalues.ErrHabitPaused, http.StatusConflict, "habit_paused")
+	register(errorvalues.ErrHabitNotPaused, http.StatusConflict, "habit_not_paused")
+	register(errorvalues.ErrSchemaNotMigrated, http.StatusServiceUnavailable, "schema_not_migrated")
+}
+
+// Writes err by httputil.WriteAppError. Unknown errors are responded with 500,
+// details of them and of known ones not meant for client are provided only if debug errors are on.
+func (s *Server) writeAppError(w http.ResponseWriter, err error) {
+	if _, known := httputil.LookupAppError(err); !known {
+		s.writeError(w, http.StatusInternalServerError, "internal error", err)
+		return
+	}
+	if s.debugErrors {
+		httputil.WriteAppErrorWithDetails(w, err)
+		return
+	}
+	httputil.WriteAppError(w, err)
+}
@@ -1,43 +1,365 @@
 package api
 
 import (
-	"context"
+	"bufio"
+	"encoding/base64"
 	"errors"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/google/uuid"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
 	"github.com/limbo/discipline/internal/service"
+	"github.com/limbo/discipline/pkg/buildinfo"
 	"github.com/limbo/discipline/pkg/entity"
 	"github.com/limbo/discipline/pkg/httputil"
 )
 
 type RegisterRequest struct {
 	Name     string `json:"name" example:"arch_linux_user"`
-	Password string `json:"password" example:"secret_password"`
+	Password string `json:"password" example:"secret_passw0rd"`
 }
 
 type LoginRequest struct {
 	Name     string `json:"name" example:"arch_linux_user"`
-	Password string `json:"password" example:"secret_password"`
+	Password string `json:"password" example:"secret_passw0rd"`
 }
 
 type CreateHabitRequest struct {
 	Title       string `json:"title" example:"LEG DAY"`
 	Description string `json:"desc" example:"hit my legs very hard"`
+	Color       string `json:"color,omitempty" example:"#00ff00"`
+	Icon        string `json:"icon,omitempty" example:"dumbbell"`
+	// YYYY-MM-DD since which habit is tracked, creation day if empty
+	StartDate string `json:"start_date,omitempty" example:"2025-01-01"`
+	// Habit may be checked several times a day, can't be changed later
+	AllowMultiplePerDay bool     `json:"allow_multiple_per_day,omitempty" example:"false"`
+	Tags                []string `json:"tags,omitempty" example:"health,morning"`
+}
+
+// Fields absent in body stay untouched
+type PatchHabitRequest struct {
+	Title       *string `json:"title,omitempty" example:"ARM DAY"`
+	Description *string `json:"desc,omitempty" example:"hit my arms very hard"`
+	Color       *string `json:"color,omitempty" example:"#ff0000"`
+	Icon        *string `json:"icon,omitempty" example:"biceps"`
+	StartDate   *string `json:"start_date,omitempty" example:"2025-01-01"`
+	// Replaces all habit's tags, empty list removes them
+	Tags *[]string `json:"tags,omitempty" example:"health,evening"`
+}
+
+// Limit of habits created by one batch request
+const maxHabitsBatchSize = 100
+
+// Limit of checks created by one batch request
+const maxChecksBatchSize = 100
+
+// Limit of habits arranged by one reorder request
+const maxHabitsOrderSize = 1000
+
+// Longest period of habit's checks listed at once and default one
+const (
+	maxChecksPeriodDays     = 366
+	defaultChecksPeriodDays = 30
+)
+
+type CheckBatchItem struct {
+	HabitID string `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Date in YYYY-MM-DD format
+	Date string `json:"date" example:"2025-01-01"`
+}
+
+type CreateHabitsBatchRequest struct {
+	Habits []CreateHabitRequest `json:"habits"`
+}
+
+// Outcome of batch item along with created habit, if any
+type BatchItemResult struct {
+	httputil.BatchItemResult
+	Habit *entity.Habit `json:"habit,omitempty"`
+}
+
+// Habit with its id duplicated in habit_id, kept for clients relying on it
+type CreateHabitResponse struct {
+	HabitID string `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	entity.Habit
+}
+
+func (r CreateHabitResponse) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(struct {
+		HabitID string `json:"habit_id"`
+		entity.HabitJSON
+	}{r.HabitID, r.Habit.JSON()})
 }
 
 type GetHabitsResponse struct {
-	UserID string          `json:"uid" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Page   int             `json:"page" example:"1"`
-	Limit  int             `json:"limit" example:"10"`
+	UserID string `json:"uid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	httputil.Page[*entity.Habit]
+	// Cursor of next page, set only with cursor pagination while there may be more habits
+	NextCursor string `json:"next_cursor,omitempty" example:"MjAyNS0wMS0wMVQxMjowMDowMFp8NTUwZTg0MDAtZTI5Yi00MWQ0LWE3MTYtNDQ2NjU1NDQwMDAw"`
+}
+
+type GroupHabitsResponse struct {
+	GroupID string `json:"group_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	httputil.Page[*entity.Habit]
+}
+
+type TagsResponse struct {
+	Tags []string `json:"tags" example:"health,morning"`
+}
+
+type AdminUser struct {
+	UserID      string            `json:"uid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string            `json:"name" example:"arch_linux_user"`
+	LastLoginAt *entity.Timestamp `json:"last_login_at,omitempty" example:"2025-01-01T12:00:00Z"`
+	Timezone    string            `json:"timezone" example:"Europe/Moscow"`
+}
+
+// Page of users matching query, total counts them on all pages
+type ListUsersResponse struct {
+	httputil.Page[AdminUser]
+}
+
+// Same as GetHabitsResponse, but each habit tells if it's checked today (with_today param)
+type HabitsWithTodayResponse struct {
+	UserID string `json:"uid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	httputil.Page[*entity.HabitWithStatus]
+	// Same as in GetHabitsResponse
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Same as GetHabitsResponse, but habits have only fields requested in projection
+type ProjectedHabitsResponse struct {
+	UserID string `json:"uid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	httputil.Page[map[string]any]
+	// Same as in GetHabitsResponse
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Habit fields allowed in projection, keyed by their json names
+var habitFields = map[string]func(h *entity.Habit) any{
+	"id":                     func(h *entity.Habit) any { return h.ID },
+	"uid":                    func(h *entity.Habit) any { return h.UserID },
+	"title":                  func(h *entity.Habit) any { return h.Title },
+	"desc":                   func(h *entity.Habit) any { return h.Description },
+	"color":                  func(h *entity.Habit) any { return h.Color },
+	"icon":                   func(h *entity.Habit) any { return h.Icon },
+	"start_date":             func(h *entity.Habit) any { return entity.Timestamp(h.StartDate) },
+	"created_at":             func(h *entity.Habit) any { return entity.Timestamp(h.CreatedAt) },
+	"updated_at":             func(h *entity.Habit) any { return entity.Timestamp(h.UpdatedAt) },
+	"allow_multiple_per_day": func(h *entity.Habit) any { return h.AllowMultiplePerDay },
+	"tags":                   func(h *entity.Habit) any { return h.Tags },
+}
+
+// Parses comma-separated list of habit fields, unknown and repeated ones are dropped.
+func parseHabitFields(raw string) []string {
+	fields := make([]string, 0)
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if _, ok := habitFields[field]; ok && !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func projectHabits(habits []*entity.Habit, fields []string) []map[string]any {
+	projected := make([]map[string]any, 0, len(habits))
+	for _, h := range habits {
+		item := make(map[string]any, len(fields))
+		for _, field := range fields {
+			item[field] = habitFields[field](h)
+		}
+		projected = append(projected, item)
+	}
+	return projected
+}
+
+// Habit with stats of its checks, for habit detail view
+type HabitDetailResponse struct {
+	*entity.Habit
+	Stats *entity.HabitStats `json:"stats,omitempty"`
+}
+
+func (r HabitDetailResponse) MarshalJSON() ([]byte, error) {
+	var habit entity.HabitJSON
+	if r.Habit != nil {
+		habit = r.Habit.JSON()
+	}
+	return sonic.Marshal(struct {
+		entity.HabitJSON
+		Stats *entity.HabitStats `json:"stats,omitempty"`
+	}{habit, r.Stats})
+}
+
+type CheckHabitRequest struct {
+	// Date in YYYY-MM-DD format, today in user's timezone if empty
+	Date string `json:"date,omitempty" example:"2025-01-01"`
+	Note string `json:"note,omitempty" example:"felt great"`
+}
+
+type CheckHabitResponse struct {
+	HabitID string `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Date    string `json:"date" example:"2025-01-01"`
+	Note    string `json:"note,omitempty" example:"felt great"`
+}
+
+// Ids in body are kept as strings and parsed after decoding,
+// so malformed one is reported by its field name rather than as generic decoding error
+type ReorderHabitsRequest struct {
+	// User's habits in desired order, ones not listed go after them in their previous order
+	HabitIDs []string `json:"habit_ids" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// Same as ReorderHabitsRequest, id is parsed after decoding
+type MergeHabitsRequest struct {
+	// Habit merged into one in path, it's deleted after merge
+	SourceID string `json:"source_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+type SkipHabitRequest struct {
+	// Date in YYYY-MM-DD format, today in user's timezone if empty
+	Date string `json:"date,omitempty" example:"2025-01-01"`
+}
+
+type SkipHabitResponse struct {
+	HabitID string `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Date    string `json:"date" example:"2025-01-01"`
+	Status  string `json:"status" example:"skipped"`
+}
+
+type ChangeUsernameRequest struct {
+	Name string `json:"name" example:"gentoo_user"`
+}
+
+type CanCheckResponse struct {
+	Allowed bool `json:"allowed" example:"false"`
+	// Why check would be rejected (check_date_not_allowed, check_exists), empty if it's allowed
+	Reason string `json:"reason,omitempty" example:"check_exists"`
+}
+
+type CountResponse struct {
+	Count int `json:"count" example:"12"`
+}
+
+type LastCheckResponse struct {
+	LastCheck string `json:"last_check" example:"2025-01-01"`
+}
+
+type UserCheckResponse struct {
+	HabitID    string             `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	HabitTitle string             `json:"habit_title" example:"Morning run"`
+	Status     entity.CheckStatus `json:"status" example:"checked"`
+	Note       string             `json:"note,omitempty" example:"felt great"`
+	CreatedAt  entity.Timestamp   `json:"created_at" example:"2025-01-01T12:00:00Z"`
+}
+
+type UserChecksResponse struct {
+	Date   string              `json:"date" example:"2025-01-01"`
+	Checks []UserCheckResponse `json:"checks"`
+}
+
+type AdherenceResponse struct {
+	HabitID string `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Checked int    `json:"checked" example:"21"`
+	Total   int    `json:"total" example:"30"`
+}
+
+type WeekdayDistributionResponse struct {
+	HabitID string `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Checks count by day of week, starting from Sunday
+	Counts [7]int `json:"counts" example:"0,4,2,0,1,0,0"`
+}
+
+type AtRiskHabitsResponse struct {
 	Habits []*entity.Habit `json:"habits"`
 }
 
+type HabitCheckResponse struct {
+	Date      string             `json:"date" example:"2025-01-01"`
+	Status    entity.CheckStatus `json:"status" example:"checked"`
+	Note      string             `json:"note,omitempty" example:"felt great"`
+	CreatedAt entity.Timestamp   `json:"created_at" example:"2025-01-01T12:00:00Z"`
+}
+
+type HabitChecksResponse struct {
+	HabitID string               `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	From    string               `json:"from" example:"2025-01-01"`
+	To      string               `json:"to" example:"2025-01-30"`
+	Checks  []HabitCheckResponse `json:"checks"`
+}
+
+type MonthlyChecksResponse struct {
+	HabitID string `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Year    int    `json:"year" example:"2024"`
+	// Checked days of month keyed by month number (1-12), months without checks are absent
+	Months map[int][]int `json:"months"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" example:"secret_passw0rd"`
+}
+
+type SetTimezoneRequest struct {
+	// IANA timezone name
+	Timezone string `json:"timezone" example:"Asia/Vladivostok"`
+}
+
+type ProfileResponse struct {
+	UserID      string            `json:"uid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string            `json:"name" example:"arch_linux_user"`
+	LastLoginAt *entity.Timestamp `json:"last_login_at,omitempty" example:"2025-01-01T12:00:00Z"`
+	Timezone    string            `json:"timezone" example:"Europe/Moscow"`
+}
+
+// Account data export. Document is streamed, so it never exists as this struct on server side
+type AccountExport struct {
+	Profile ProfileResponse      `json:"profile"`
+	Habits  []*entity.Habit      `json:"habits"`
+	Checks  []*entity.HabitCheck `json:"checks"`
+}
+
+// Name export is suggested to be saved with
+const exportFilename = "discipline-export.json"
+
+type VersionResponse struct {
+	Version   string `json:"version" example:"v1.0.0"`
+	Commit    string `json:"commit" example:"7ea22e3"`
+	BuildTime string `json:"build_time" example:"2025-01-01T12:00:00Z"`
+}
+
+type ErrorCodeInfo struct {
+	Code    string `json:"code" example:"habit_not_found"`
+	Status  int    `json:"status" example:"404"`
+	Message string `json:"message" example:"habit doesn't exists"`
+}
+
+type ErrorsCatalogResponse struct {
+	Errors []ErrorCodeInfo `json:"errors"`
+}
+
+type ServerStatsResponse struct {
+	TotalRequests int64 `json:"total_requests" example:"1024"`
+	// Requests by status class (1xx-5xx), classes without requests are absent
+	ByStatusClass    map[string]int64 `json:"by_status_class"`
+	AverageLatencyMs float64          `json:"average_latency_ms" example:"12.5"`
+	// Requests are counted since server start
+	Since entity.Timestamp `json:"since" example:"2025-01-01T12:00:00Z"`
+}
+
+type ReadinessResponse struct {
+	Status string `json:"status" example:"ready"`
+}
+
+type SchemaVersionResponse struct {
+	Version int64 `json:"version" example:"14"`
+}
+
 type UIDResponse struct {
 	UserID string `json:"uid" example:"550e8400-e29b-41d4-a716-446655440000"`
 	Token  string `json:"token,omitempty" example:"xxxx.yyyy.zzzz"`
@@ -52,9 +374,9 @@ type UIDResponse struct {
 // @Produce json
 // @Param credentials body RegisterRequest true "User's credentials"
 // @Success 201 {object} UIDResponse "Response with user ID"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 409 {object} map[string]string "Registering already existed user"
-// @Failure 500 {object} map[string]string "Something went wrong internally (in services, repos etc.)"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid request body or credentials don't meet requirements"
+// @Failure 409 {object} httputil.ErrorResponse "Registering already existed user, error_code tells taken field (name_taken)"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
 // @Router /auth/register [post]
 func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 	logger := GetLoggerFromCtx(r.Context())
@@ -63,23 +385,18 @@ func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 	err := sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		logger.Error("registering error: invalid body")
-		httputil.WriteErrorResponse(w, http.StatusBadRequest, "invalid request body", nil)
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
+	ctx := r.Context()
 	user, err := s.userService.Register(ctx, &service.RegisterRequest{
 		Name:     req.Name,
 		Password: req.Password,
 	})
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrUserExists) {
-			logger.Error("registering error: existed user")
-			httputil.WriteErrorResponse(w, http.StatusConflict, "user with such name already exists", nil)
-			return
-		}
-		logger.Error("registering error: service error", slog.String("error", err.Error()))
-		httputil.WriteErrorResponse(w, http.StatusInternalServerError, "internal error during registration", nil)
+		logger.Error("registering error", slog.String("error", err.Error()))
+		// Client gets to know which validation requirement is unmet from details
+		s.writeAppError(w, err)
 		return
 	}
 	httputil.WriteJSONResponse(w, http.StatusCreated, UIDResponse{
@@ -97,10 +414,10 @@ func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param credentials body LoginRequest true "User's credentials"
 // @Success 200 {object} UIDResponse "Response with user ID and auth token"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 404 {object} map[string]string "User doesn't exist"
-// @Failure 403 {object} map[string]string "Wrong credentials"
-// @Failure 500 {object} map[string]string "Something went wrong internally (in services, repos etc.)"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid request body"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 403 {object} httputil.ErrorResponse "Wrong credentials or account is disabled"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
 // @Router /auth/login [post]
 func (s *Server) Login(w http.ResponseWriter, r *http.Request) {
 	logger := GetLoggerFromCtx(r.Context())
@@ -109,32 +426,20 @@ func (s *Server) Login(w http.ResponseWriter, r *http.Request) {
 	err := sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		logger.Error("login error: invalid body")
-		httputil.WriteErrorResponse(w, http.StatusBadRequest, "invalid request body", nil)
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
+	ctx := r.Context()
 	user, err := s.userService.Login(ctx, req.Name, req.Password)
 	if err != nil {
-		switch {
-		case errors.Is(err, errorvalues.ErrUserNotFound):
-			logger.Error("login error: unexist user")
-			httputil.WriteErrorResponse(w, http.StatusNotFound, "user with such name doesn't exist", nil)
-			return
-		case errors.Is(err, errorvalues.ErrWrongCredentials):
-			logger.Error("login error: wrong password")
-			httputil.WriteErrorResponse(w, http.StatusForbidden, "invalid username or password", nil)
-			return
-		default:
-			logger.Error("login error: service error", slog.String("error", err.Error()))
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, "internal error during login", nil)
-			return
-		}
+		logger.Error("login error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
 	}
 	token, err := s.jwtService.GenerateToken(user)
 	if err != nil {
 		logger.Error("login error: generating token error", slog.String("error", err.Error()))
-		httputil.WriteErrorResponse(w, http.StatusInternalServerError, "error creating token", nil)
+		s.writeError(w, http.StatusInternalServerError, "error creating token", err)
 		return
 	}
 	httputil.WriteJSONResponse(w, http.StatusOK, UIDResponse{
@@ -144,153 +449,2191 @@ func (s *Server) Login(w http.ResponseWriter, r *http.Request) {
 	logger.Info("successful login")
 }
 
-// CreateHabit godoc
-// @Summary Creates new user's habit
-// @Description Recieves habits' title and description, create new one
-// @Description and returns its ID.
-// @Tags Habits
+// GetProfile godoc
+// @Summary Provides authorized user's profile
+// @Description Returns user ID, name and time of last login.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} ProfileResponse "User's profile"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /auth/profile [get]
+func (s *Server) GetProfile(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("get profile error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	ctx := r.Context()
+	user, err := s.userService.GetByID(ctx, uid)
+	if err != nil {
+		logger.Error("get profile error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, ProfileResponse{
+		UserID:      user.ID.String(),
+		Name:        user.Name,
+		LastLoginAt: entity.OptionalTimestamp(user.LastLoginAt),
+		Timezone:    user.Timezone,
+	})
+	logger.Info("profile provided")
+}
+
+// RevokeSessions godoc
+// @Summary Revokes all authorized user's tokens
+// @Description Makes every token issued to user so far (including one of request) rejected, user has to log in again.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 204 "Revoked"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /auth/sessions/revoke-all [post]
+func (s *Server) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("revoke sessions error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	err = s.userService.RevokeSessions(r.Context(), uid)
+	if err != nil {
+		logger.Error("revoke sessions error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("sessions revoked")
+}
+
+// ChangeUsername godoc
+// @Summary Renames authorized user
+// @Description Recieves new name, validates it with the same rules as on registration and renames user.
+// @Tags Users
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Access token"
-// @Param Habit body CreateHabitRequest true "Habit title and description"
-// @Success 201 {object} map[string]string "Response with habit_id"
-// @Failure 401 {object} map[string]string "Authorization failed"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 409 {object} map[string]string "Habit with such title already exists"
-// @Failure 404 {object} map[string]string "Owner (user) doesn't exist"
-// @Failure 500 {object} map[string]string "Something went wrong internally (in services, repos etc.)"
-// @Router /habits [post]
-func (s *Server) CreateHabit(w http.ResponseWriter, r *http.Request) {
+// @Param name body ChangeUsernameRequest true "New name"
+// @Success 204 "Renamed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid request body or name"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 409 {object} httputil.ErrorResponse "Name is already taken"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /auth/username [put]
+func (s *Server) ChangeUsername(w http.ResponseWriter, r *http.Request) {
 	logger := GetLoggerFromCtx(r.Context())
 	uid, err := GetUIDFromContext(r)
 	if err != nil {
-		logger.Error("create habit error: unauthorized")
-		httputil.WriteErrorResponse(w, http.StatusUnauthorized, "no authorization", nil)
+		logger.Error("change username error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
 		return
 	}
-	var req CreateHabitRequest
+	var req ChangeUsernameRequest
 	defer r.Body.Close()
 	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		logger.Error("create habit error: invalid request body")
-		httputil.WriteErrorResponse(w, http.StatusBadRequest, "invalid request body", nil)
+		logger.Error("change username error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
-	habit, err := s.habitService.CreateHabit(ctx, uid, service.CreateHabitRequest{
-		Title:       req.Title,
-		Description: req.Description,
-	})
+	ctx := r.Context()
+	err = s.userService.ChangeUsername(ctx, uid, req.Name)
 	if err != nil {
-		switch {
-		case errors.Is(err, errorvalues.ErrUserHasHabit):
-			logger.Error("create habit error: attempt to create existed habit")
-			httputil.WriteErrorResponse(w, http.StatusConflict, "habit already exists", nil)
-		case errors.Is(err, errorvalues.ErrUserNotFound):
-			logger.Error("create habit error: unexist user")
-			httputil.WriteErrorResponse(w, http.StatusNotFound, "couldn't create habit: user doesn't exists", nil)
-		default:
-			logger.Error("create habit error: service error", slog.String("error", err.Error()))
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, "internal error while creating habit", nil)
-		}
+		logger.Error("change username error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
 		return
 	}
-	httputil.WriteJSONResponse(w, http.StatusCreated, map[string]any{"habit_id": habit.ID.String()})
-	logger.Info("habit created")
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("username changed")
 }
 
-// GetHabits godoc
-// @Summary Provides list of habits
-// @Description Provides list of user's habits with pagination in query params (page, limit).
-// @Tags Habits
+// SetTimezone godoc
+// @Summary Sets authorized user's timezone
+// @Description Recieves IANA timezone name, in which user's days (e.g. "today" for checks) are counted.
+// @Tags Users
+// @Accept json
 // @Produce json
 // @Param Authorization header string true "Access token"
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Limit of habits by page" default(10)
-// @Success 200 {object} GetHabitsResponse "Response with md (uid, page, limit) and habits list"
-// @Failure 401 {object} map[string]string "Authorization failed"
-// @Failure 500 {object} map[string]string "Something went wrong internally (in services, repos etc.)"
-// @Router /habits [get]
-func (s *Server) GetHabits(w http.ResponseWriter, r *http.Request) {
+// @Param timezone body SetTimezoneRequest true "Timezone"
+// @Success 204 "Timezone set"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid request body or unknown timezone"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /auth/timezone [put]
+func (s *Server) SetTimezone(w http.ResponseWriter, r *http.Request) {
 	logger := GetLoggerFromCtx(r.Context())
 	uid, err := GetUIDFromContext(r)
 	if err != nil {
-		logger.Error("get habits error: unauthorized")
-		httputil.WriteErrorResponse(w, http.StatusUnauthorized, "no authorization", nil)
+		logger.Error("set timezone error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
 		return
 	}
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-	if err != nil || limit < 1 || limit > 50 {
-		limit = 10
+	var req SetTimezoneRequest
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Error("set timezone error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		return
 	}
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page < 1 {
-		page = 1
+	ctx := r.Context()
+	err = s.userService.SetTimezone(ctx, uid, req.Timezone)
+	if err != nil {
+		logger.Error("set timezone error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
 	}
-	offset := (page - 1) * limit
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
-	defer cancel()
-	habits, err := s.habitService.GetUserHabits(ctx, uid, service.PaginationOpts{
-		Limit:  limit,
-		Offset: offset,
-	})
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("timezone set")
+}
+
+// ExportAccount godoc
+// @Summary Exports authorized user's data
+// @Description Returns single JSON document with user's profile, all habits and all their checks and skips, for download.
+// @Description Document is streamed as it's read from database. If reading fails midway, connection is aborted, so incomplete document is never taken for whole one.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} AccountExport "User's data"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Header 200 {string} Content-Disposition "Makes document downloaded as discipline-export.json"
+// @Router /auth/export [get]
+func (s *Server) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
 	if err != nil {
-		logger.Error("getting habits list error", slog.String("error", err.Error()))
-		httputil.WriteErrorResponse(w, http.StatusInternalServerError, "error while getting habits list", nil)
+		logger.Error("account export error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
 		return
 	}
-	httputil.WriteJSONResponse(w, http.StatusOK, GetHabitsResponse{
-		UserID: uid.String(),
-		Page:   page,
-		Limit:  limit,
-		Habits: habits,
+	ctx := r.Context()
+	user, err := s.userService.GetByID(ctx, uid)
+	if err != nil {
+		logger.Error("account export error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+exportFilename+`"`)
+	w.WriteHeader(http.StatusOK)
+	out := bufio.NewWriter(w)
+	// Status is sent already, so failure can only be reported by breaking the connection
+	abort := func(err error) {
+		logger.Error("account export error: aborting", slog.String("error", err.Error()))
+		panic(http.ErrAbortHandler)
+	}
+	out.WriteString(`{"profile":`)
+	err = writeJSONValue(out, ProfileResponse{
+		UserID:      user.ID.String(),
+		Name:        user.Name,
+		LastLoginAt: entity.OptionalTimestamp(user.LastLoginAt),
+		Timezone:    user.Timezone,
 	})
-	logger.Info("habits provided")
+	if err != nil {
+		abort(err)
+	}
+	var habits, checks int
+	out.WriteString(`,"habits":[`)
+	err = s.habitService.ExportHabits(ctx, uid, func(habit *entity.Habit) error {
+		habits++
+		return writeArrayItem(out, habits == 1, habit)
+	})
+	if err != nil {
+		abort(err)
+	}
+	out.WriteString(`],"checks":[`)
+	err = s.checkService.ExportChecks(ctx, uid, func(check *entity.HabitCheck) error {
+		checks++
+		return writeArrayItem(out, checks == 1, check)
+	})
+	if err != nil {
+		abort(err)
+	}
+	out.WriteString(`]}`)
+	if err = out.Flush(); err != nil {
+		abort(err)
+	}
+	logger.Info("account exported", slog.Int("habits", habits), slog.Int("checks", checks))
 }
 
-// DeleteHabit godoc
-// @Summary Deletes habit
-// @Description Recieves habit ID in path, deletes it if user is owner.
-// @Tags Habits
+func writeJSONValue(out *bufio.Writer, v any) error {
+	data, err := sonic.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// Writes element of JSON array being streamed, elements except the first one are preceded with comma.
+func writeArrayItem(out *bufio.Writer, first bool, v any) error {
+	if !first {
+		if err := out.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	return writeJSONValue(out, v)
+}
+
+// DeleteAccount godoc
+// @Summary Deletes authorized user's account
+// @Description Recieves user's password for confirmation and deletes account. With erase=true all habits and checks
+// @Description are removed explicitly in single transaction and summary of removed rows is returned.
+// @Tags Users
+// @Accept json
 // @Produce json
 // @Param Authorization header string true "Access token"
-// @Param id path string true "Habit ID"
-// @Success 200
-// @Failure 401 {object} map[string]string "Authorization failed"
-// @Failure 400 {object} map[string]string "Invalid id param in path"
-// @Failure 404 {object} map[string]string "Habit doesn't exist or authorizated user is not its owner"
-// @Failure 500 {object} map[string]string "Something went wrong internally (in services, repos etc.)"
-// @Router /habits/{id} [delete]
-func (s *Server) DeleteHabit(w http.ResponseWriter, r *http.Request) {
+// @Param erase query bool false "Erase all user's data and return summary"
+// @Param password body DeleteAccountRequest true "Password confirmation"
+// @Success 200 {object} entity.ErasureSummary "Data erased"
+// @Success 204 "Account deleted"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid request body or erase param"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed or wrong password"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /auth/account [delete]
+func (s *Server) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 	logger := GetLoggerFromCtx(r.Context())
 	uid, err := GetUIDFromContext(r)
 	if err != nil {
-		logger.Error("habit deletion error: unauthorized")
-		httputil.WriteErrorResponse(w, http.StatusUnauthorized, "no authorization", nil)
+		logger.Error("account deletion error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
 		return
 	}
-	id, err := uuid.Parse(r.PathValue("id"))
+	erase := false
+	if param := r.URL.Query().Get("erase"); param != "" {
+		erase, err = strconv.ParseBool(param)
+		if err != nil {
+			logger.Error("account deletion error: invalid erase param")
+			s.writeError(w, http.StatusBadRequest, "invalid erase param", err)
+			return
+		}
+	}
+	var req DeleteAccountRequest
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		logger.Error("habit deletion error: invalid id in path value")
-		httputil.WriteErrorResponse(w, http.StatusBadRequest, "invalid habit id in path value", nil)
+		logger.Error("account deletion error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
-	err = s.habitService.DeleteHabit(ctx, id, uid)
+	ctx := r.Context()
+	var summary *entity.ErasureSummary
+	if erase {
+		summary, err = s.userService.EraseAccount(ctx, uid, req.Password)
+	} else {
+		err = s.userService.DeleteAccount(ctx, uid, req.Password)
+	}
 	if err != nil {
-		switch {
-		case errors.Is(err, errorvalues.ErrHabitNotFound):
-			logger.Error("habit deletion error: unexist habit")
-			httputil.WriteErrorResponse(w, http.StatusNotFound, "habit doesn't exist", nil)
-		case errors.Is(err, errorvalues.ErrWrongOwner):
-			logger.Error("habit deletion error: habit has different owner")
-			httputil.WriteErrorResponse(w, http.StatusNotFound, "habit doesn't exist", nil)
-		default:
-			logger.Error("habit deletion error: service error")
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, "internal error while deleting habit", nil)
+		logger.Error("account deletion error", slog.String("error", err.Error()))
+		// User is authorized already, so wrong password isn't forbidden login attempt
+		if errors.Is(err, errorvalues.ErrWrongCredentials) {
+			err = &httputil.AppError{Err: err, Status: http.StatusUnauthorized, Code: "wrong_credentials"}
 		}
+		s.writeAppError(w, err)
+		return
+	}
+	if summary != nil {
+		httputil.WriteJSONResponse(w, http.StatusOK, summary)
+		logger.Info("account erased", slog.Int64("habits", summary.Habits), slog.Int64("checks", summary.Checks))
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("account deleted")
+}
+
+// GetAccountSummary godoc
+// @Summary Previews authorized user's account deletion
+// @Description Returns counts of rows account deletion would remove: user, all habits (including deleted ones) and their checks.
+// @Description Nothing is removed.
+// @Tags Users
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} entity.ErasureSummary "Data to be removed"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /auth/account/summary [get]
+func (s *Server) GetAccountSummary(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("account summary error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	summary, err := s.userService.PreviewDeletion(r.Context(), uid)
+	if err != nil {
+		logger.Error("account summary error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, summary)
+	logger.Info("account summary provided")
+}
+
+// Version godoc
+// @Summary Provides build info
+// @Description Returns version, git commit and build time of deployed service.
+// @Tags Service
+// @Produce json
+// @Success 200 {object} VersionResponse "Build info"
+// @Router /version [get]
+func (s *Server) Version(w http.ResponseWriter, r *http.Request) {
+	httputil.WriteJSONResponse(w, http.StatusOK, VersionResponse{
+		Version:   buildinfo.Version,
+		Commit:    buildinfo.Commit,
+		BuildTime: buildinfo.BuildTime,
+	})
+}
+
+// ListErrorCodes godoc
+// @Summary Provides catalog of error codes
+// @Description Returns machine-readable codes error responses may carry in error_code, with their statuses and messages.
+// @Tags Service
+// @Produce json
+// @Success 200 {object} ErrorsCatalogResponse "Known error codes"
+// @Router /errors [get]
+func (s *Server) ListErrorCodes(w http.ResponseWriter, r *http.Request) {
+	known := httputil.RegisteredErrors()
+	resp := ErrorsCatalogResponse{Errors: make([]ErrorCodeInfo, 0, len(known))}
+	for _, appErr := range known {
+		resp.Errors = append(resp.Errors, ErrorCodeInfo{
+			Code:    appErr.Code,
+			Status:  appErr.Status,
+			Message: appErr.Error(),
+		})
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, resp)
+}
+
+// SchemaVersion godoc
+// @Summary Provides database schema version
+// @Description Returns version of latest migration applied to database, to check schema matches deployed service.
+// @Tags Service
+// @Produce json
+// @Success 200 {object} SchemaVersionResponse "Applied schema version"
+// @Failure 503 {object} httputil.ErrorResponse "Migrations were never applied or version isn't provided"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /version/schema [get]
+func (s *Server) SchemaVersion(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	if s.schemaVersion == nil {
+		logger.Error("schema version error: source isn't set")
+		httputil.WriteErrorResponse(w, http.StatusServiceUnavailable, "schema version unavailable", nil)
+		return
+	}
+	version, err := s.schemaVersion.SchemaVersion(r.Context())
+	if err != nil {
+		logger.Error("schema version error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, SchemaVersionResponse{Version: version})
+}
+
+// Readiness probe, mounted out of API base path as /metrics is, so it isn't in swagger docs.
+// Responds 200 if database is reachable (checked if schema version source is set) and JWT service
+// signs and parses back token of dummy user, so misconfigured secret fails deploy. Otherwise responds 503.
+func (s *Server) Readiness(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	if s.schemaVersion != nil {
+		if _, err := s.schemaVersion.SchemaVersion(r.Context()); err != nil {
+			logger.Error("readiness error: database check failed", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusServiceUnavailable, "not ready: database check failed", err)
+			return
+		}
+	}
+	if err := s.jwtSelfTest(); err != nil {
+		logger.Error("readiness error: jwt self-test failed", slog.String("error", err.Error()))
+		s.writeError(w, http.StatusServiceUnavailable, "not ready: jwt self-test failed", err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, ReadinessResponse{Status: "ready"})
+}
+
+// Dummy user readiness self-test token is issued for
+var readinessProbeUser = entity.User{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Name: "readyz"}
+
+// Signs token for dummy user and parses it back with configured JWT service.
+func (s *Server) jwtSelfTest() error {
+	if s.jwtService == nil {
+		return errors.New("jwt service isn't set")
+	}
+	token, err := s.jwtService.GenerateToken(&readinessProbeUser)
+	if err != nil {
+		return errors.New("signing token error: " + err.Error())
+	}
+	claims, err := s.jwtService.ParseToken(token)
+	if err != nil {
+		return errors.New("parsing token error: " + err.Error())
+	}
+	if claims.UserID != readinessProbeUser.ID.String() {
+		return errors.New("parsed token carries uid " + claims.UserID)
+	}
+	return nil
+}
+
+// Responds to requests for unknown paths
+func (s *Server) NotFound(w http.ResponseWriter, r *http.Request) {
+	GetLoggerFromCtx(r.Context()).Error("unknown path requested", slog.String("path", r.URL.Path))
+	httputil.WriteErrorResponse(w, http.StatusNotFound, "resource not found", nil)
+}
+
+// Responds to requests with method not supported by path
+func (s *Server) MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	GetLoggerFromCtx(r.Context()).Error("method not allowed", slog.String("method", r.Method), slog.String("path", r.URL.Path))
+	httputil.WriteErrorResponse(w, http.StatusMethodNotAllowed, "method not allowed", nil)
+}
+
+// CreateHabit godoc
+// @Summary Creates new user's habit
+// @Description Recieves habits' title and description, create new one
+// @Description and returns it with its ID.
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param Habit body CreateHabitRequest true "Habit title, description, color (#RRGGBB), icon and tags"
+// @Success 201 {object} CreateHabitResponse "Created habit with habit_id"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid request body, habit color or tags"
+// @Failure 409 {object} httputil.ErrorResponse "Habit with such title already exists"
+// @Failure 404 {object} httputil.ErrorResponse "Owner (user) doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Failure 429 {object} httputil.ErrorResponse "Too many write requests from user"
+// @Router /habits [post]
+func (s *Server) CreateHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("create habit error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	var req CreateHabitRequest
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Error("create habit error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	ctx := r.Context()
+	habit, err := s.habitService.CreateHabit(ctx, uid, service.CreateHabitRequest{
+		Title:               req.Title,
+		Description:         req.Description,
+		Color:               req.Color,
+		Icon:                req.Icon,
+		StartDate:           req.StartDate,
+		AllowMultiplePerDay: req.AllowMultiplePerDay,
+		Tags:                req.Tags,
+	})
+	if err != nil {
+		logger.Error("create habit error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusCreated, CreateHabitResponse{
+		HabitID: habit.ID.String(),
+		Habit:   *habit,
+	})
+	logger.Info("habit created")
+}
+
+// CreateHabitsBatch godoc
+// @Summary Creates several habits at once
+// @Description Recieves list of habits and creates them in single transaction.
+// @Description Result of each item is reported separately: duplicates and invalid items don't prevent others from creation.
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param Habits body CreateHabitsBatchRequest true "Habits to create"
+// @Success 201 {object} httputil.BatchResponse[BatchItemResult] "All habits created, result for each one in request order"
+// @Success 207 {object} httputil.BatchResponse[BatchItemResult] "Some habits failed, result for each one in request order"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid request body, empty or too big batch"
+// @Failure 404 {object} httputil.ErrorResponse "Owner (user) doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/batch [post]
+func (s *Server) CreateHabitsBatch(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("create habits batch error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	var req CreateHabitsBatchRequest
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Error("create habits batch error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if len(req.Habits) == 0 || len(req.Habits) > maxHabitsBatchSize {
+		logger.Error("create habits batch error: invalid batch size", slog.Int("size", len(req.Habits)))
+		s.writeError(w, http.StatusBadRequest, "batch must contain from 1 to "+strconv.Itoa(maxHabitsBatchSize)+" habits", nil)
+		return
+	}
+	reqs := make([]service.CreateHabitRequest, 0, len(req.Habits))
+	for _, h := range req.Habits {
+		reqs = append(reqs, service.CreateHabitRequest{
+			Title:               h.Title,
+			Description:         h.Description,
+			Color:               h.Color,
+			Icon:                h.Icon,
+			StartDate:           h.StartDate,
+			AllowMultiplePerDay: h.AllowMultiplePerDay,
+			Tags:                h.Tags,
+		})
+	}
+	ctx := r.Context()
+	habits, failures, err := s.habitService.CreateHabits(ctx, uid, reqs)
+	if err != nil {
+		logger.Error("create habits batch error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	results := make([]BatchItemResult, 0, len(reqs))
+	for i := range reqs {
+		if len(failures) != 0 && failures[0].Index == i {
+			results = append(results, batchFailure(i, failures[0].Err, "internal error while creating habit"))
+			failures = failures[1:]
+			continue
+		}
+		results = append(results, BatchItemResult{
+			BatchItemResult: httputil.BatchItemResult{Index: i, Status: http.StatusCreated},
+			Habit:           habits[0],
+		})
+		habits = habits[1:]
+	}
+	httputil.WriteBatchResponse(w, http.StatusCreated, results)
+	logger.Info("habits batch created")
+}
+
+// Result of failed batch item, with status, code and message err would be responded with alone.
+// Errors not known to httputil get 500 with internalMessage.
+func batchFailure(index int, err error, internalMessage string) BatchItemResult {
+	item := BatchItemResult{BatchItemResult: httputil.BatchItemResult{Index: index}}
+	appErr, ok := httputil.LookupAppError(err)
+	if !ok {
+		item.Status, item.Error = http.StatusInternalServerError, internalMessage
+		return item
+	}
+	item.Status, item.ErrorCode, item.Error = appErr.Status, appErr.Code, appErr.Error()
+	// Validation details tell what's wrong with exactly this item
+	if errors.Is(err, errorvalues.ErrValidation) {
+		item.Error = err.Error()
+	}
+	return item
+}
+
+// CheckHabitsBatch godoc
+// @Summary Checks several habits at once
+// @Description Recieves list of checks, possibly of different habits, and creates valid ones in single transaction.
+// @Description Result of each item is reported separately: others' habits, disallowed dates and already checked days don't prevent other checks.
+// @Tags Checks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param Checks body []CheckBatchItem true "Checks to create"
+// @Success 201 {object} httputil.BatchResponse[httputil.BatchItemResult] "All checks created, result for each one in request order"
+// @Success 207 {object} httputil.BatchResponse[httputil.BatchItemResult] "Some checks failed, result for each one in request order"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid request body, empty or too big batch"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /checks/batch [post]
+func (s *Server) CheckHabitsBatch(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("check habits batch error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	var items []CheckBatchItem
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&items)
+	if err != nil {
+		logger.Error("check habits batch error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if len(items) == 0 || len(items) > maxChecksBatchSize {
+		logger.Error("check habits batch error: invalid batch size", slog.Int("size", len(items)))
+		s.writeError(w, http.StatusBadRequest, "batch must contain from 1 to "+strconv.Itoa(maxChecksBatchSize)+" checks", nil)
+		return
+	}
+	reqs := make([]service.CheckRequest, 0, len(items))
+	for i, item := range items {
+		habitID, err := uuid.Parse(item.HabitID)
+		if err != nil {
+			logger.Error("check habits batch error: invalid habit id", slog.Int("index", i))
+			s.writeError(w, http.StatusBadRequest, "invalid habit id in item "+strconv.Itoa(i), err)
+			return
+		}
+		date, err := httputil.ParseDate(item.Date)
+		if err != nil {
+			logger.Error("check habits batch error: invalid date", slog.Int("index", i))
+			s.writeError(w, http.StatusBadRequest, "invalid date in item "+strconv.Itoa(i)+": "+httputil.ErrInvalidDate.Error(), err)
+			return
+		}
+		reqs = append(reqs, service.CheckRequest{HabitID: habitID, Date: date})
+	}
+	failures, err := s.checkService.CheckMany(r.Context(), uid, reqs)
+	if err != nil {
+		logger.Error("check habits batch error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	results := make([]httputil.BatchItemResult, 0, len(reqs))
+	for i := range reqs {
+		if len(failures) != 0 && failures[0].Index == i {
+			results = append(results, batchFailure(i, failures[0].Err, "internal error while checking habit").BatchItemResult)
+			failures = failures[1:]
+			continue
+		}
+		results = append(results, httputil.BatchItemResult{Index: i, Status: http.StatusCreated})
+	}
+	httputil.WriteBatchResponse(w, http.StatusCreated, results)
+	logger.Info("checks batch created", slog.Int("size", len(reqs)))
+}
+
+// GetHabits godoc
+// @Summary Provides list of habits
+// @Description Provides list of user's habits with pagination in query params (page, limit) and optional fields projection.
+// @Tags Habits
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Limit of habits by page, clamped to configured max (50 by default)" default(10)
+// @Param fields query string false "Comma-separated habit fields to provide (id, uid, title, desc, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags), unknown ones are ignored"
+// @Param cursor query string false "Switches to cursor pagination (page is ignored): next_cursor of previous response, empty for the first page"
+// @Param with_today query bool false "Adds checked_today field to each habit (today is in user's timezone)" default(false)
+// @Param sort query string false "Order of habits: by creation or arranged by user (see PUT /habits/order), offset pagination only" Enums(created_at, position) default(created_at)
+// @Param If-Modified-Since header string false "Time of list client has, in HTTP date format"
+// @Success 200 {object} GetHabitsResponse "Page of habits with md (uid, total, total_pages; cursor pages have no page and totals), habits have only requested fields if projection is set and checked_today field if with_today is set (see HabitsWithTodayResponse)"
+// @Success 304 "Habits list hasn't changed since If-Modified-Since, never responded with with_today"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid cursor, unknown sort or sort is used with cursor"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Header 200,304 {string} Last-Modified "Time habits list last changed"
+// @Router /habits [get]
+func (s *Server) GetHabits(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("get habits error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	page, limit := s.pageParams(r)
+	// Offset pagination stays default for compatibility, cursor one is used if cursor param is present
+	rawCursor, byCursor := r.URL.Query()["cursor"]
+	var cursor *entity.HabitCursor
+	if byCursor && rawCursor[0] != "" {
+		cursor, err = decodeHabitCursor(rawCursor[0])
+		if err != nil {
+			logger.Error("get habits error: invalid cursor", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusBadRequest, "invalid cursor", err)
+			return
+		}
+	}
+	sort := entity.HabitSort(r.URL.Query().Get("sort"))
+	// Cursor is made of creation time, so it can't page other orders
+	if byCursor && sort != "" && sort != entity.HabitSortCreatedAt {
+		logger.Error("get habits error: sort with cursor", slog.String("sort", string(sort)))
+		s.writeError(w, http.StatusBadRequest, "sort is not supported with cursor pagination", nil)
+		return
+	}
+	withToday, _ := strconv.ParseBool(r.URL.Query().Get("with_today"))
+	ctx := r.Context()
+	// Checks don't change habits list modification time, so list with their status is always provided whole
+	if !withToday {
+		lastModified, err := s.habitService.LastModified(ctx, uid)
+		if err != nil {
+			// List is still provided, just without conditional support
+			logger.Warn("getting habits last modified time error", slog.String("error", err.Error()))
+		} else if notModified(w, r, lastModified) {
+			logger.Info("habits not modified")
+			return
+		}
+	}
+	var habits []*entity.Habit
+	if byCursor {
+		habits, err = s.habitService.GetUserHabitsAfter(ctx, uid, cursor, limit)
+	} else {
+		habits, err = s.habitService.GetUserHabits(ctx, uid, sort, service.PaginationOpts{
+			Limit:  limit,
+			Offset: (page - 1) * limit,
+		})
+	}
+	if errors.Is(err, errorvalues.ErrValidation) {
+		logger.Error("get habits error: invalid sort", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	if err != nil {
+		logger.Error("getting habits list error", slog.String("error", err.Error()))
+		s.writeError(w, http.StatusInternalServerError, "error while getting habits list", err)
+		return
+	}
+	var nextCursor string
+	// Short page is the last one
+	if byCursor && len(habits) == limit {
+		last := habits[len(habits)-1]
+		nextCursor = encodeHabitCursor(entity.HabitCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	// Cursor pages aren't counted, that's what makes them cheaper than offset ones
+	var total int
+	if !byCursor {
+		total, err = s.habitService.CountUserHabits(ctx, uid)
+		if err != nil {
+			logger.Error("counting habits error", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusInternalServerError, "error while getting habits list", err)
+			return
+		}
+	}
+	var checkedToday map[uuid.UUID]bool
+	if withToday {
+		ids := make([]uuid.UUID, 0, len(habits))
+		for _, h := range habits {
+			ids = append(ids, h.ID)
+		}
+		checkedToday, err = s.checkService.CheckedToday(ctx, uid, ids)
+		if err != nil {
+			logger.Error("getting habits checked today error", slog.String("error", err.Error()))
+			s.writeAppError(w, err)
+			return
+		}
+	}
+	// Without known fields requested habits are provided whole
+	if fields := parseHabitFields(r.URL.Query().Get("fields")); len(fields) != 0 {
+		projected := projectHabits(habits, fields)
+		if withToday {
+			for i, h := range habits {
+				projected[i]["checked_today"] = checkedToday[h.ID]
+			}
+		}
+		httputil.WriteJSONResponse(w, http.StatusOK, ProjectedHabitsResponse{
+			UserID:     uid.String(),
+			Page:       habitsPage(projected, byCursor, page, limit, total),
+			NextCursor: nextCursor,
+		})
+		logger.Info("habits provided", slog.Any("fields", fields))
+		return
+	}
+	if withToday {
+		withStatus := make([]*entity.HabitWithStatus, 0, len(habits))
+		for _, h := range habits {
+			withStatus = append(withStatus, &entity.HabitWithStatus{Habit: *h, CheckedToday: checkedToday[h.ID]})
+		}
+		httputil.WriteJSONResponse(w, http.StatusOK, HabitsWithTodayResponse{
+			UserID:     uid.String(),
+			Page:       habitsPage(withStatus, byCursor, page, limit, total),
+			NextCursor: nextCursor,
+		})
+		logger.Info("habits provided with today status")
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, GetHabitsResponse{
+		UserID:     uid.String(),
+		Page:       habitsPage(habits, byCursor, page, limit, total),
+		NextCursor: nextCursor,
+	})
+	logger.Info("habits provided")
+}
+
+// Makes page of habits list, cursor one has neither number nor total
+func habitsPage[T any](items []T, byCursor bool, page, limit, total int) httputil.Page[T] {
+	if byCursor {
+		return httputil.NewCursorPage(items, limit)
+	}
+	return httputil.NewPage(items, page, limit, total)
+}
+
+// GetGroupHabits godoc
+// @Summary Provides habits of group members
+// @Description Returns combined list of habits owned by members of group, ordered by creation time.
+// @Description Until groups are introduced, every user has own group with id equal to user's id.
+// @Tags Groups
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Group ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Limit of habits in page" default(10)
+// @Success 200 {object} GroupHabitsResponse "Page of group habits with total count"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path"
+// @Failure 404 {object} httputil.ErrorResponse "Group doesn't exist or authorizated user is not its member"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /groups/{id}/habits [get]
+func (s *Server) GetGroupHabits(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("get group habits error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	groupID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("get group habits error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid group id in path value", err)
+		return
+	}
+	page, limit := s.pageParams(r)
+	ctx := r.Context()
+	habits, total, err := s.habitService.GetGroupHabits(ctx, groupID, uid, service.PaginationOpts{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		logger.Error("get group habits error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, GroupHabitsResponse{
+		GroupID: groupID.String(),
+		Page:    httputil.NewPage(habits, page, limit, total),
+	})
+	logger.Info("group habits provided")
+}
+
+// ListHabitTags godoc
+// @Summary Provides tags of user's habits
+// @Description Returns sorted distinct tags of authorizated user's habits, empty list if there are none.
+// @Tags Habits
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} TagsResponse "User's tags"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/tags [get]
+func (s *Server) ListHabitTags(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("listing tags error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	ctx := r.Context()
+	tags, err := s.habitService.ListTags(ctx, uid)
+	if err != nil {
+		logger.Error("listing tags error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, TagsResponse{Tags: tags})
+	logger.Info("tags provided")
+}
+
+// DeleteHabit godoc
+// @Summary Deletes habit
+// @Description Recieves habit ID in path, deletes it if user is owner. Deleted habit can be restored for a while.
+// @Tags Habits
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Success 200
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id} [delete]
+func (s *Server) DeleteHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit deletion error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit deletion error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	err = s.habitService.DeleteHabit(ctx, id, uid)
+	if err != nil {
+		logger.Error("habit deletion error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+}
+
+// RestoreHabit godoc
+// @Summary Restores deleted habit
+// @Description Recieves habit ID in path, brings it back with its checks if it was deleted recently and user is owner.
+// @Tags Habits
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Success 204 "Restored"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path"
+// @Failure 404 {object} httputil.ErrorResponse "No recently deleted habit or authorizated user is not its owner"
+// @Failure 409 {object} httputil.ErrorResponse "Habit's title is used by another habit"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/restore [post]
+func (s *Server) RestoreHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit restoring error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit restoring error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	err = s.habitService.RestoreHabit(ctx, id, uid)
+	if err != nil {
+		logger.Error("habit restoring error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("habit restored")
+}
+
+// MergeHabits godoc
+// @Summary Merges duplicate habit into another one
+// @Description Recieves target habit ID in path and source one in body. Checks and skips of source are moved to target,
+// @Description on days both habits are marked target's marks are kept. Source is deleted permanently after that.
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Target habit ID"
+// @Param Merge body MergeHabitsRequest true "Source habit"
+// @Success 204 "Merged"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path, request body or source_id, source is target itself"
+// @Failure 404 {object} httputil.ErrorResponse "Any of habits doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/merge [post]
+func (s *Server) MergeHabits(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habits merge error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	targetID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habits merge error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	var req MergeHabitsRequest
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Error("habits merge error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	sourceID, err := uuid.Parse(req.SourceID)
+	if err != nil {
+		logger.Error("habits merge error: invalid source_id")
+		s.writeError(w, http.StatusBadRequest, "invalid source_id", err)
+		return
+	}
+	ctx := r.Context()
+	err = s.habitService.MergeHabits(ctx, sourceID, targetID, uid)
+	if err != nil {
+		logger.Error("habits merge error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("habits merged", slog.String("source_id", sourceID.String()), slog.String("target_id", targetID.String()))
+}
+
+// ReorderHabits godoc
+// @Summary Arranges user's habits
+// @Description Recieves user's habits IDs in desired order, it's used by habits list with sort=position.
+// @Description Positions are changed all at once, habits not listed go after listed ones in their previous order.
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param Order body ReorderHabitsRequest true "Habits in desired order"
+// @Success 204 "Reordered"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid request body or habit id, empty list, too many or duplicated habits"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 404 {object} httputil.ErrorResponse "Any of habits doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/order [put]
+func (s *Server) ReorderHabits(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habits reorder error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	var req ReorderHabitsRequest
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Error("habits reorder error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if len(req.HabitIDs) > maxHabitsOrderSize {
+		logger.Error("habits reorder error: too many habits", slog.Int("size", len(req.HabitIDs)))
+		s.writeError(w, http.StatusBadRequest, "order must contain at most "+strconv.Itoa(maxHabitsOrderSize)+" habits", nil)
+		return
+	}
+	ids := make([]uuid.UUID, 0, len(req.HabitIDs))
+	for _, raw := range req.HabitIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			logger.Error("habits reorder error: invalid habit id", slog.String("habit_id", raw))
+			s.writeError(w, http.StatusBadRequest, "invalid habit id in habit_ids", err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	ctx := r.Context()
+	err = s.habitService.ReorderHabits(ctx, uid, ids)
+	if err != nil {
+		logger.Error("habits reorder error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("habits reordered", slog.Int("count", len(ids)))
+}
+
+// GetHabit godoc
+// @Summary Provides habit
+// @Description Recieves habit ID in path. With include=stats habit is returned along with its checks stats.
+// @Tags Habits
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param include query string false "Set to stats to include checks stats" Enums(stats)
+// @Success 200 {object} HabitDetailResponse "Habit, stats are present only if requested"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path or include value"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id} [get]
+func (s *Server) GetHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit providing error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit providing error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	include := r.URL.Query().Get("include")
+	if include != "" && include != "stats" {
+		logger.Error("habit providing error: invalid include value")
+		s.writeError(w, http.StatusBadRequest, "invalid include value, only stats supported", nil)
+		return
+	}
+	ctx := r.Context()
+	var resp HabitDetailResponse
+	if include == "stats" {
+		var detail *service.HabitDetail
+		detail, err = s.habitService.GetHabitWithStats(ctx, id, uid)
+		if detail != nil {
+			resp = HabitDetailResponse{Habit: detail.Habit, Stats: detail.Stats}
+		}
+	} else {
+		resp.Habit, err = s.habitService.GetHabit(ctx, id, uid)
+	}
+	if err != nil {
+		logger.Error("habit providing error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, resp)
+	logger.Info("habit provided")
+}
+
+// HeadHabit godoc
+// @Summary Checks habit existence
+// @Description Recieves habit ID in path. Responds same status as GET does, but without body.
+// @Tags Habits
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Success 200 "Habit exists and is owned by authorizated user"
+// @Failure 401 "Authorization failed"
+// @Failure 400 "Invalid id param in path"
+// @Failure 404 "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id} [head]
+func (s *Server) HeadHabit(w http.ResponseWriter, r *http.Request) {
+	// Stats aren't needed to tell if habit exists
+	r = r.Clone(r.Context())
+	r.URL.RawQuery = ""
+	s.GetHabit(headWriter{w}, r)
+}
+
+// Drops response body, keeping status and headers
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (hw headWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// PatchHabit godoc
+// @Summary Partially updates habit
+// @Description Recieves habit ID in path and fields to update in body.
+// @Description Only provided fields are updated, absent ones stay untouched. Returns updated habit.
+// @Tags Habits
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param Habit body PatchHabitRequest true "Habit fields to update"
+// @Success 200 {object} entity.Habit "Updated habit"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path, request body, habit color or tags"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 409 {object} httputil.ErrorResponse "Habit with such title already exists"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id} [patch]
+func (s *Server) PatchHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit patching error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit patching error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	var req PatchHabitRequest
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Error("habit patching error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	ctx := r.Context()
+	habit, err := s.habitService.UpdateHabit(ctx, id, uid, service.UpdateHabitRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		Color:       req.Color,
+		Icon:        req.Icon,
+		StartDate:   req.StartDate,
+		Tags:        req.Tags,
+	})
+	if err != nil {
+		logger.Error("habit patching error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, habit)
+	logger.Info("habit patched")
+}
+
+// CheckHabit godoc
+// @Summary Checks habit
+// @Description Marks habit as done on given date (today by default) with optional note.
+// @Tags Checks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param Check body CheckHabitRequest true "Check date and note"
+// @Param upsert query bool false "Replace note of existing check instead of failing with 409"
+// @Success 201 {object} CheckHabitResponse "Created check"
+// @Success 200 {object} CheckHabitResponse "Updated check (upsert only)"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path, request body or date in the future or before habit start"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 409 {object} httputil.ErrorResponse "Habit already checked on this date"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Failure 429 {object} httputil.ErrorResponse "Too many write requests from user"
+// @Router /habits/{id}/checks [post]
+func (s *Server) CheckHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit checking error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit checking error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	var req CheckHabitRequest
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Error("habit checking error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	upsert := false
+	if raw := r.URL.Query().Get("upsert"); raw != "" {
+		upsert, err = strconv.ParseBool(raw)
+		if err != nil {
+			logger.Error("habit checking error: invalid upsert param")
+			s.writeError(w, http.StatusBadRequest, "invalid upsert param, boolean expected", err)
+			return
+		}
+	}
+	ctx := r.Context()
+	var date time.Time
+	if req.Date != "" {
+		date, err = httputil.ParseDate(req.Date)
+		if err != nil {
+			logger.Error("habit checking error: invalid date")
+			s.writeError(w, http.StatusBadRequest, httputil.ErrInvalidDate.Error(), err)
+			return
+		}
+	} else {
+		// Today is resolved in user's timezone, not server's one
+		date, err = s.checkService.Today(ctx, uid)
+		if err != nil {
+			logger.Error("habit checking error: resolving today", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusInternalServerError, "internal error while resolving date", err)
+			return
+		}
+	}
+	created := true
+	if upsert {
+		created, err = s.checkService.UpsertCheck(ctx, id, uid, date, req.Note)
+	} else {
+		err = s.checkService.CheckHabit(ctx, id, uid, date, req.Note)
+	}
+	if err != nil {
+		logger.Error("habit checking error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	httputil.WriteJSONResponse(w, status, CheckHabitResponse{
+		HabitID: id.String(),
+		Date:    date.Format(time.DateOnly),
+		Note:    req.Note,
+	})
+	logger.Info("habit checked", slog.Bool("created", created))
+}
+
+// UncheckHabit godoc
+// @Summary Unchecks habit
+// @Description Deletes check of habit on date given in path.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param date path string true "Check date (YYYY-MM-DD)"
+// @Success 204
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id or date param in path"
+// @Failure 404 {object} httputil.ErrorResponse "Habit or check doesn't exist, or authorizated user is not habit's owner"
+// @Failure 429 {object} httputil.ErrorResponse "Too many write requests from user"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/checks/{date} [delete]
+func (s *Server) UncheckHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit unchecking error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit unchecking error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	date, err := httputil.ParseDate(r.PathValue("date"))
+	if err != nil {
+		logger.Error("habit unchecking error: invalid date in path value")
+		s.writeError(w, http.StatusBadRequest, httputil.ErrInvalidDate.Error(), err)
+		return
+	}
+	ctx := r.Context()
+	err = s.checkService.UncheckHabit(ctx, id, uid, date)
+	if err != nil {
+		logger.Error("habit unchecking error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("habit unchecked")
+}
+
+// GetUserChecks godoc
+// @Summary Provides user's checks on date
+// @Description Returns checks and skips of all user's habits on date, sorted by habit title.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param date query string false "Date (YYYY-MM-DD), today in user's timezone if empty"
+// @Success 200 {object} UserChecksResponse "Checks on date with titles of their habits"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid date"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /checks [get]
+func (s *Server) GetUserChecks(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("getting user checks error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	ctx := r.Context()
+	var date time.Time
+	if raw := r.URL.Query().Get("date"); raw != "" {
+		date, err = httputil.ParseDate(raw)
+		if err != nil {
+			logger.Error("getting user checks error: invalid date")
+			s.writeError(w, http.StatusBadRequest, httputil.ErrInvalidDate.Error(), err)
+			return
+		}
+	} else {
+		date, err = s.checkService.Today(ctx, uid)
+		if err != nil {
+			logger.Error("getting user checks error: resolving today", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusInternalServerError, "internal error while resolving date", err)
+			return
+		}
+	}
+	checks, err := s.checkService.GetUserChecksOn(ctx, uid, date)
+	if err != nil {
+		logger.Error("getting user checks error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	resp := UserChecksResponse{
+		Date:   date.Format(time.DateOnly),
+		Checks: make([]UserCheckResponse, 0, len(checks)),
+	}
+	for _, check := range checks {
+		resp.Checks = append(resp.Checks, UserCheckResponse{
+			HabitID:    check.HabitID.String(),
+			HabitTitle: check.HabitTitle,
+			Status:     check.Status,
+			Note:       check.Note,
+			CreatedAt:  entity.Timestamp(check.CreatedAt),
+		})
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, resp)
+	logger.Info("user checks provided", slog.Int("count", len(checks)))
+}
+
+// CanCheckHabit godoc
+// @Summary Tells if habit can be checked
+// @Description Runs same validations as checking habit (date allowed, not checked yet) without creating check.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param date query string false "Date to check (YYYY-MM-DD), today in user's timezone if empty"
+// @Success 200 {object} CanCheckResponse "If check is allowed and reason if it isn't"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path or date"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/checks/can [get]
+func (s *Server) CanCheckHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("check validation error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("check validation error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	var date time.Time
+	if raw := r.URL.Query().Get("date"); raw != "" {
+		date, err = httputil.ParseDate(raw)
+		if err != nil {
+			logger.Error("check validation error: invalid date")
+			s.writeError(w, http.StatusBadRequest, httputil.ErrInvalidDate.Error(), err)
+			return
+		}
+	} else {
+		date, err = s.checkService.Today(ctx, uid)
+		if err != nil {
+			logger.Error("check validation error: resolving today", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusInternalServerError, "internal error while resolving date", err)
+			return
+		}
+	}
+	allowed, reason, err := s.checkService.CanCheck(ctx, id, uid, date)
+	if err != nil {
+		logger.Error("check validation error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, CanCheckResponse{Allowed: allowed, Reason: reason})
+}
+
+// CountHabitChecks godoc
+// @Summary Provides count of habit's checks in period
+// @Description Returns count of checks (skips excluded) on habit between from and to, both included.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param from query string false "Period start (YYYY-MM-DD), unbounded if empty"
+// @Param to query string false "Period end (YYYY-MM-DD), today in user's timezone if empty"
+// @Success 200 {object} CountResponse "Count of checks"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path or period"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/checks/count [get]
+func (s *Server) CountHabitChecks(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("checks counting error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("checks counting error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	var from, to time.Time
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		from, err = httputil.ParseDate(raw)
+		if err != nil {
+			logger.Error("checks counting error: invalid from date")
+			s.writeError(w, http.StatusBadRequest, "from "+httputil.ErrInvalidDate.Error(), err)
+			return
+		}
+	}
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		to, err = httputil.ParseDate(raw)
+		if err != nil {
+			logger.Error("checks counting error: invalid to date")
+			s.writeError(w, http.StatusBadRequest, "to "+httputil.ErrInvalidDate.Error(), err)
+			return
+		}
+	} else {
+		to, err = s.checkService.Today(ctx, uid)
+		if err != nil {
+			logger.Error("checks counting error: resolving today", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusInternalServerError, "internal error while resolving date", err)
+			return
+		}
+	}
+	if from.After(to) {
+		logger.Error("checks counting error: from is after to")
+		s.writeError(w, http.StatusBadRequest, "from date must not be after to date", nil)
+		return
+	}
+	count, err := s.checkService.CountHabitChecks(ctx, id, uid, from, to)
+	if err != nil {
+		logger.Error("checks counting error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, CountResponse{Count: count})
+	logger.Info("checks count provided")
+}
+
+// GetLastCheck godoc
+// @Summary Provides date of habit's last check
+// @Description Returns date of the most recent check (skips excluded), 204 if habit has no checks.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Success 200 {object} LastCheckResponse "Last check date"
+// @Success 204 "Habit has no checks"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/checks/latest [get]
+func (s *Server) GetLastCheck(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("last check providing error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("last check providing error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	date, err := s.checkService.GetLastCheck(ctx, id, uid)
+	if err != nil {
+		logger.Error("last check providing error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	if date == nil {
+		httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+		logger.Info("no last check to provide")
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, LastCheckResponse{LastCheck: date.Format(time.DateOnly)})
+	logger.Info("last check provided")
+}
+
+// PauseHabit godoc
+// @Summary Pauses habit
+// @Description Pauses habit since today (in user's timezone): paused days break neither streak nor completion rate.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Success 204 "Habit paused"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 409 {object} httputil.ErrorResponse "Habit is already paused"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/pause [post]
+func (s *Server) PauseHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit pausing error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit pausing error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	err = s.checkService.PauseHabit(ctx, id, uid)
+	if err != nil {
+		logger.Error("habit pausing error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("habit paused")
+}
+
+// ResumeHabit godoc
+// @Summary Resumes paused habit
+// @Description Ends habit's pause, today (in user's timezone) is tracked again.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Success 204 "Habit resumed"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 409 {object} httputil.ErrorResponse "Habit isn't paused"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/resume [post]
+func (s *Server) ResumeHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit resuming error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit resuming error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	err = s.checkService.ResumeHabit(ctx, id, uid)
+	if err != nil {
+		logger.Error("habit resuming error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("habit resumed")
+}
+
+// GetHabitAdherence godoc
+// @Summary Provides how many of last days habit was checked
+// @Description Returns count of checked days among last N days (today in user's timezone included).
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param days query int false "Window size in days, 1..365" default(30)
+// @Success 200 {object} AdherenceResponse "Checked days and window size"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path or days"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/adherence [get]
+func (s *Server) GetHabitAdherence(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("adherence providing error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("adherence providing error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	days := 30
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil {
+			logger.Error("adherence providing error: invalid days")
+			s.writeError(w, http.StatusBadRequest, "invalid days, integer expected", err)
+			return
+		}
+	}
+	ctx := r.Context()
+	checked, total, err := s.checkService.RecentAdherence(ctx, id, uid, days)
+	if err != nil {
+		logger.Error("adherence providing error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, AdherenceResponse{
+		HabitID: id.String(),
+		Checked: checked,
+		Total:   total,
+	})
+	logger.Info("adherence provided")
+}
+
+// SkipHabit godoc
+// @Summary Skips habit
+// @Description Marks given date (today by default) as skipped: it neither breaks nor extends habit's streak.
+// @Tags Checks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param Skip body SkipHabitRequest true "Skip date"
+// @Success 201 {object} SkipHabitResponse "Created skip"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path, request body or date in the future or before habit start"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 409 {object} httputil.ErrorResponse "Habit already checked or skipped on this date"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/skip [post]
+func (s *Server) SkipHabit(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit skipping error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit skipping error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	var req SkipHabitRequest
+	defer r.Body.Close()
+	err = sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Error("habit skipping error: invalid request body")
+		s.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	ctx := r.Context()
+	var date time.Time
+	if req.Date != "" {
+		date, err = httputil.ParseDate(req.Date)
+		if err != nil {
+			logger.Error("habit skipping error: invalid date")
+			s.writeError(w, http.StatusBadRequest, httputil.ErrInvalidDate.Error(), err)
+			return
+		}
+	} else {
+		// Today is resolved in user's timezone, not server's one
+		date, err = s.checkService.Today(ctx, uid)
+		if err != nil {
+			logger.Error("habit skipping error: resolving today", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusInternalServerError, "internal error while resolving date", err)
+			return
+		}
+	}
+	err = s.checkService.SkipHabit(ctx, id, uid, date)
+	if err != nil {
+		logger.Error("habit skipping error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusCreated, SkipHabitResponse{
+		HabitID: id.String(),
+		Date:    date.Format(time.DateOnly),
+		Status:  string(entity.CheckStatusSkipped),
+	})
+	logger.Info("habit skipped")
+}
+
+// GetWeekdayDistribution godoc
+// @Summary Provides habit's checks by weekday
+// @Description Returns count of checks on habit for each day of week, starting from Sunday.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Success 200 {object} WeekdayDistributionResponse "Checks by weekday"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/stats/weekdays [get]
+func (s *Server) GetWeekdayDistribution(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("weekday stats error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("weekday stats error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	counts, err := s.checkService.GetWeekdayDistribution(ctx, id, uid)
+	if err != nil {
+		logger.Error("weekday stats error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, WeekdayDistributionResponse{
+		HabitID: id.String(),
+		Counts:  counts,
+	})
+	logger.Info("weekday stats provided")
+}
+
+// GetHabitChecks godoc
+// @Summary Provides habit's checks for period
+// @Description Returns checks and skips of habit between from and to (both included), sorted by date.
+// @Description Period is last 30 days (today in user's timezone included) by default, up to 366 days are allowed.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param from query string false "First date of period (YYYY-MM-DD)"
+// @Param to query string false "Last date of period (YYYY-MM-DD), today if empty"
+// @Param order query string false "Order by date" Enums(asc, desc) default(asc)
+// @Param status query string false "Provides only completions or only skips (rest days), all marks if empty" Enums(checked, skipped)
+// @Success 200 {object} HabitChecksResponse "Marks for period"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path, dates, period or status"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/checks [get]
+func (s *Server) GetHabitChecks(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("habit checks error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("habit checks error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	query := r.URL.Query()
+	var to time.Time
+	if raw := query.Get("to"); raw != "" {
+		to, err = httputil.ParseDate(raw)
+	} else {
+		to, err = s.checkService.Today(ctx, uid)
+		if err != nil {
+			logger.Error("habit checks error: resolving today", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusInternalServerError, "internal error while resolving date", err)
+			return
+		}
+	}
+	from := to.AddDate(0, 0, 1-defaultChecksPeriodDays)
+	if raw := query.Get("from"); raw != "" && err == nil {
+		from, err = httputil.ParseDate(raw)
+	}
+	if err != nil {
+		logger.Error("habit checks error: invalid date")
+		s.writeError(w, http.StatusBadRequest, httputil.ErrInvalidDate.Error(), err)
+		return
+	}
+	if from.After(to) || to.Sub(from) >= maxChecksPeriodDays*24*time.Hour {
+		logger.Error("habit checks error: invalid period")
+		s.writeError(w, http.StatusBadRequest, "from must not be after to, period must not exceed "+strconv.Itoa(maxChecksPeriodDays)+" days", nil)
+		return
+	}
+	checks, err := s.checkService.GetHabitChecks(ctx, id, uid, from, to,
+		entity.CheckOrder(query.Get("order")), entity.CheckStatus(query.Get("status")))
+	if err != nil {
+		logger.Error("habit checks error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	resp := HabitChecksResponse{
+		HabitID: id.String(),
+		From:    from.Format(time.DateOnly),
+		To:      to.Format(time.DateOnly),
+		Checks:  make([]HabitCheckResponse, 0, len(checks)),
+	}
+	for _, check := range checks {
+		resp.Checks = append(resp.Checks, HabitCheckResponse{
+			Date:      check.CheckDate.Format(time.DateOnly),
+			Status:    check.Status,
+			Note:      check.Note,
+			CreatedAt: entity.Timestamp(check.CreatedAt),
+		})
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, resp)
+	logger.Info("habit checks provided", slog.Int("count", len(checks)))
+}
+
+// GetMonthlyChecks godoc
+// @Summary Provides habit's check history by month
+// @Description Returns days habit was checked on in year, grouped by month number. Skipped days aren't included.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "Habit ID"
+// @Param year query int false "Year of history, current one in user's timezone if empty"
+// @Success 200 {object} MonthlyChecksResponse "Checked days by month"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid id param in path or year"
+// @Failure 404 {object} httputil.ErrorResponse "Habit doesn't exist or authorizated user is not its owner"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /habits/{id}/checks/by-month [get]
+func (s *Server) GetMonthlyChecks(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("monthly checks error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("monthly checks error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid habit id in path value", err)
+		return
+	}
+	ctx := r.Context()
+	var year int
+	if raw := r.URL.Query().Get("year"); raw != "" {
+		year, err = strconv.Atoi(raw)
+		if err != nil || year < 1 || year > 9999 {
+			logger.Error("monthly checks error: invalid year")
+			s.writeError(w, http.StatusBadRequest, "invalid year", err)
+			return
+		}
+	} else {
+		today, err := s.checkService.Today(ctx, uid)
+		if err != nil {
+			logger.Error("monthly checks error: resolving today", slog.String("error", err.Error()))
+			s.writeError(w, http.StatusInternalServerError, "internal error while resolving date", err)
+			return
+		}
+		year = today.Year()
+	}
+	months, err := s.checkService.GetMonthlyChecks(ctx, id, uid, year)
+	if err != nil {
+		logger.Error("monthly checks error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	resp := MonthlyChecksResponse{
+		HabitID: id.String(),
+		Year:    year,
+		Months:  make(map[int][]int, len(months)),
+	}
+	for month, days := range months {
+		resp.Months[int(month)] = days
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, resp)
+	logger.Info("monthly checks provided", slog.Int("year", year))
+}
+
+// GetAtRiskHabits godoc
+// @Summary Provides habits whose streak is at risk
+// @Description Returns habits marked yesterday but not today yet (in user's timezone), so user can be reminded before the day ends. Paused habits aren't included.
+// @Tags Reminders
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} AtRiskHabitsResponse "Habits to check today to keep streak"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /reminders/at-risk [get]
+func (s *Server) GetAtRiskHabits(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("at-risk habits error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	habits, err := s.checkService.GetAtRiskHabits(r.Context(), uid)
+	if err != nil {
+		logger.Error("at-risk habits error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, AtRiskHabitsResponse{Habits: habits})
+	logger.Info("at-risk habits provided", slog.Int("count", len(habits)))
+}
+
+// GetServerStats godoc
+// @Summary Provides request counters of server
+// @Description Admin only. Returns in-memory counters of requests served by this instance since its start:
+// @Description total, by status class and average latency. Counters are reset on restart.
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} ServerStatsResponse "Counters snapshot"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 403 {object} httputil.ErrorResponse "User is not admin"
+// @Router /stats/server [get]
+func (s *Server) GetServerStats(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	snap := s.requestStats.Snapshot()
+	httputil.WriteJSONResponse(w, http.StatusOK, ServerStatsResponse{
+		TotalRequests:    snap.Total,
+		ByStatusClass:    snap.ByStatusClass,
+		AverageLatencyMs: float64(snap.AverageLatency) / float64(time.Millisecond),
+		Since:            entity.Timestamp(s.startedAt),
+	})
+	logger.Info("server stats provided")
+}
+
+// GetStatsSummary godoc
+// @Summary Provides stats over all user's habits
+// @Description Returns count of habits and checks, and the longest max streak among all user's habits.
+// @Tags Checks
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} entity.UserSummary "Aggregated stats"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /stats/summary [get]
+func (s *Server) GetStatsSummary(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	uid, err := GetUIDFromContext(r)
+	if err != nil {
+		logger.Error("stats summary error: unauthorized")
+		s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+		return
+	}
+	ctx := r.Context()
+	summary, err := s.checkService.GetUserSummary(ctx, uid)
+	if err != nil {
+		logger.Error("stats summary error: service error", slog.String("error", err.Error()))
+		s.writeError(w, http.StatusInternalServerError, "internal error while getting stats", err)
+		return
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, summary)
+	logger.Info("stats summary provided")
+}
+
+// Sets Last-Modified header and, if resource hasn't changed since request's If-Modified-Since,
+// responds with 304 and reports true. Zero lastModified means it's unknown, then nothing is done.
+func notModified(w http.ResponseWriter, r *http.Request, lastModified time.Time) bool {
+	if lastModified.IsZero() {
+		return false
+	}
+	// HTTP dates have seconds precision
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified.After(since) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// Reads page (1 by default) and limit (clamped to configured bounds) query params.
+// Encodes cursor as opaque token: base64 of creation time and id, clients must pass it back as is.
+func encodeHabitCursor(cursor entity.HabitCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHabitCursor(token string) (*entity.HabitCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("decoding cursor error: " + err.Error())
+	}
+	rawTime, rawID, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, errors.New("malformed cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, rawTime)
+	if err != nil {
+		return nil, errors.New("parsing cursor time error: " + err.Error())
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, errors.New("parsing cursor id error: " + err.Error())
+	}
+	return &entity.HabitCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+func (s *Server) pageParams(r *http.Request) (page, limit int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	switch {
+	case err != nil || limit < 1:
+		limit = s.defaultPageLimit
+	case limit > s.maxPageLimit:
+		limit = s.maxPageLimit
+	}
+	page, err = strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	return page, limit
+}
+
+// ListUsers godoc
+// @Summary Provides list of users for operators
+// @Description Admin only. Lists users whose name contains q (case-insensitive) with pagination and sorting.
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param q query string false "Part of user name"
+// @Param sort query string false "Sort order" Enums(name, -name, created_at, -created_at) default(name)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Limit of users by page, clamped to configured max (50 by default)" default(10)
+// @Success 200 {object} ListUsersResponse "Page of users with total count"
+// @Failure 400 {object} httputil.ErrorResponse "Unknown sort order"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 403 {object} httputil.ErrorResponse "User is not admin"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /admin/users [get]
+func (s *Server) ListUsers(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	page, limit := s.pageParams(r)
+	ctx := r.Context()
+	users, total, err := s.userService.ListUsers(ctx, r.URL.Query().Get("q"), entity.UserSort(r.URL.Query().Get("sort")), service.PaginationOpts{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		logger.Error("listing users error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
+		return
+	}
+	items := make([]AdminUser, 0, len(users))
+	for _, user := range users {
+		items = append(items, AdminUser{
+			UserID:      user.ID.String(),
+			Name:        user.Name,
+			LastLoginAt: entity.OptionalTimestamp(user.LastLoginAt),
+			Timezone:    user.Timezone,
+		})
+	}
+	httputil.WriteJSONResponse(w, http.StatusOK, ListUsersResponse{
+		Page: httputil.NewPage(items, page, limit, total),
+	})
+	logger.Info("users list provided")
+}
+
+// DisableUser godoc
+// @Summary Disables user's account
+// @Description Admin only. Suspends account without deleting its data: user can't log in and already issued tokens are rejected with 403.
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path string true "User ID"
+// @Success 204 "Disabled"
+// @Failure 400 {object} httputil.ErrorResponse "Invalid user id"
+// @Failure 401 {object} httputil.ErrorResponse "Authorization failed"
+// @Failure 403 {object} httputil.ErrorResponse "User is not admin"
+// @Failure 404 {object} httputil.ErrorResponse "User doesn't exist"
+// @Failure 500 {object} httputil.ErrorResponse "Something went wrong internally (in services, repos etc.)"
+// @Router /admin/users/{id}/disable [post]
+func (s *Server) DisableUser(w http.ResponseWriter, r *http.Request) {
+	logger := GetLoggerFromCtx(r.Context())
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		logger.Error("user disabling error: invalid id in path value")
+		s.writeError(w, http.StatusBadRequest, "invalid user id in path value", err)
+		return
+	}
+	err = s.userService.SetActive(r.Context(), id, false)
+	if err != nil {
+		logger.Error("user disabling error", slog.String("error", err.Error()))
+		s.writeAppError(w, err)
 		return
 	}
+	httputil.WriteJSONResponse(w, http.StatusNoContent, nil)
+	logger.Info("user disabled", slog.String("disabled_uid", id.String()))
 }
@@ -7,9 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,8 +25,11 @@ import (
 	"github.com/limbo/discipline/internal/repository"
 	"github.com/limbo/discipline/internal/service"
 	"github.com/limbo/discipline/internal/service/mocks"
+	"github.com/limbo/discipline/pkg/cleanup"
 	"github.com/limbo/discipline/pkg/entity"
+	"github.com/limbo/discipline/pkg/httputil"
 	jwtservice "github.com/limbo/discipline/pkg/jwt_service"
+	"github.com/limbo/discipline/pkg/logging"
 	"github.com/pressly/goose"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -88,18 +94,62 @@ func (usmock *UserServiceMock) GetByName(ctx context.Context, name string) (*ent
 	}
 	return nil, errors.New("mocked error")
 }
+func (usmock *UserServiceMock) ChangeUsername(ctx context.Context, id uuid.UUID, newName string) error {
+	if usmock.success {
+		return nil
+	}
+	return errors.New("mocked error")
+}
+func (usmock *UserServiceMock) SetTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	if usmock.success {
+		return nil
+	}
+	return errors.New("mocked error")
+}
+func (usmock *UserServiceMock) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	if usmock.success {
+		return nil
+	}
+	return errorvalues.ErrUserNotFound
+}
+func (usmock *UserServiceMock) RevokeSessions(ctx context.Context, id uuid.UUID) error {
+	if usmock.success {
+		return nil
+	}
+	return errorvalues.ErrUserNotFound
+}
 func (usmock *UserServiceMock) DeleteAccount(ctx context.Context, id uuid.UUID, password string) error {
 	if usmock.success {
 		return nil
 	}
 	return errors.New("mocked error")
 }
+func (usmock *UserServiceMock) EraseAccount(ctx context.Context, id uuid.UUID, password string) (*entity.ErasureSummary, error) {
+	if usmock.success {
+		return &entity.ErasureSummary{Users: 1, Habits: 2, Checks: 5}, nil
+	}
+	return nil, errors.New("mocked error")
+}
+func (usmock *UserServiceMock) PreviewDeletion(ctx context.Context, id uuid.UUID) (*entity.ErasureSummary, error) {
+	if usmock.success {
+		return &entity.ErasureSummary{Users: 1, Habits: 2, Checks: 5}, nil
+	}
+	return nil, errorvalues.ErrUserNotFound
+}
+func (usmock *UserServiceMock) ListUsers(ctx context.Context, query string, sort entity.UserSort, pagination service.PaginationOpts) ([]*entity.User, int, error) {
+	if usmock.success {
+		return []*entity.User{{ID: uid, Name: username, Timezone: "UTC", LastLoginAt: &lastLoginAt}}, 1, nil
+	}
+	return nil, 0, errors.New("mocked error")
+}
 
 var (
 	username        = "test_name"
-	password        = "test_password"
+	password        = "test_password1"
 	passwordHash, _ = bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	uid             = uuid.New()
+	// Stored with sub-second precision and not in UTC
+	lastLoginAt = time.Date(2025, 1, 1, 12, 0, 0, 123456789, time.FixedZone("MSK", 3*60*60))
 )
 
 func TestRegister(t *testing.T) {
@@ -176,6 +226,45 @@ func TestLogin(t *testing.T) {
 	})
 }
 
+func TestDeleteAccount(t *testing.T) {
+	mock := UserServiceMock{}
+	serv := api.New(&api.ServicesList{
+		UserService: &mock,
+	})
+	body := `{"password": "test_password1"}`
+	newRequest := func(target string) *http.Request {
+		r := httptest.NewRequest(http.MethodDelete, target, strings.NewReader(body))
+		return r.WithContext(context.WithValue(r.Context(), "User-ID", uid))
+	}
+	t.Run("deleted", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mock.ChangeState(true)
+		serv.DeleteAccount(rr, newRequest("/auth/account"))
+		assert.Equal(t, http.StatusNoContent, rr.Result().StatusCode)
+	})
+	t.Run("erased with summary", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mock.ChangeState(true)
+		serv.DeleteAccount(rr, newRequest("/auth/account?erase=true"))
+		assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		var summary entity.ErasureSummary
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&summary))
+		assert.Equal(t, entity.ErasureSummary{Users: 1, Habits: 2, Checks: 5}, summary)
+	})
+	t.Run("invalid erase param", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mock.ChangeState(true)
+		serv.DeleteAccount(rr, newRequest("/auth/account?erase=maybe"))
+		assert.Equal(t, http.StatusBadRequest, rr.Result().StatusCode)
+	})
+	t.Run("service error", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mock.ChangeState(false)
+		serv.DeleteAccount(rr, newRequest("/auth/account?erase=true"))
+		assert.Equal(t, http.StatusInternalServerError, rr.Result().StatusCode)
+	})
+}
+
 func testHandler(w http.ResponseWriter, r *http.Request) {
 	uid, err := api.GetUIDFromContext(r)
 	if err != nil {
@@ -243,6 +332,125 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 }
 
+func TestHiddenAuthFailures(t *testing.T) {
+	usMock := &UserServiceMock{}
+	usMock.ChangeState(true)
+	jwtService := jwtservice.New("secret")
+	token, err := jwtService.GenerateToken(&entity.User{ID: uid, Name: username})
+	require.NoError(t, err)
+	testCases := []struct {
+		Desc         string
+		Hidden       bool
+		Header       string
+		ExpectedCode int
+	}{
+		{Desc: "no token", Header: "", ExpectedCode: http.StatusUnauthorized},
+		{Desc: "invalid token", Header: "Bearer invalid", ExpectedCode: http.StatusUnauthorized},
+		{Desc: "valid token", Header: "Bearer " + token, ExpectedCode: http.StatusOK},
+		{Desc: "hidden no token", Hidden: true, Header: "", ExpectedCode: http.StatusNotFound},
+		{Desc: "hidden invalid token", Hidden: true, Header: "Bearer invalid", ExpectedCode: http.StatusNotFound},
+		{Desc: "hidden valid token", Hidden: true, Header: "Bearer " + token, ExpectedCode: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			serv := api.New(&api.ServicesList{
+				UserService: usMock,
+				JwtService:  jwtService,
+			}, api.WithHiddenAuthFailures(tc.Hidden))
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/habits", nil)
+			if tc.Header != "" {
+				req.Header.Set("Authorization", tc.Header)
+			}
+			serv.AuthMiddleware(http.HandlerFunc(testHandler)).ServeHTTP(rr, req)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			if tc.Hidden && tc.ExpectedCode == http.StatusNotFound {
+				var resp httputil.ErrorResponse
+				require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+				// Same as for unknown path
+				assert.Equal(t, "resource not found", resp.Message)
+			}
+		})
+	}
+}
+
+// Keeps user's token version, bumping it on revoking sessions
+type revokingUserService struct {
+	UserServiceMock
+	version int
+}
+
+func (us *revokingUserService) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	return &entity.User{ID: id, Name: username, TokenVersion: us.version}, nil
+}
+
+func (us *revokingUserService) RevokeSessions(ctx context.Context, id uuid.UUID) error {
+	us.version++
+	return nil
+}
+
+func TestRevokeSessions(t *testing.T) {
+	userService := &revokingUserService{}
+	jwtService := jwtservice.New("secret")
+	serv := api.New(&api.ServicesList{
+		UserService: userService,
+		JwtService:  jwtService,
+	})
+	request := func(method, path, token string) int {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		serv.Handler().ServeHTTP(rr, req)
+		return rr.Result().StatusCode
+	}
+	oldToken, err := jwtService.GenerateToken(&entity.User{ID: uid, Name: username})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/auth/profile", oldToken))
+	assert.Equal(t, http.StatusNoContent, request(http.MethodPost, "/api/v1/auth/sessions/revoke-all", oldToken))
+	assert.Equal(t, 1, userService.version)
+	t.Run("old token rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/auth/profile", oldToken))
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "/api/v1/auth/sessions/revoke-all", oldToken))
+		assert.Equal(t, 1, userService.version)
+	})
+	t.Run("new token accepted", func(t *testing.T) {
+		newToken, err := jwtService.GenerateToken(&entity.User{ID: uid, Name: username, TokenVersion: userService.version})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/auth/profile", newToken))
+	})
+}
+
+func TestGetAccountSummary(t *testing.T) {
+	mock := UserServiceMock{}
+	serv := api.New(&api.ServicesList{
+		UserService: &mock,
+	})
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/auth/account/summary", nil)
+		return r.WithContext(context.WithValue(r.Context(), "User-ID", uid))
+	}
+	t.Run("provided", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mock.ChangeState(true)
+		serv.GetAccountSummary(rr, newRequest())
+		assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		var summary entity.ErasureSummary
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&summary))
+		assert.Equal(t, entity.ErasureSummary{Users: 1, Habits: 2, Checks: 5}, summary)
+	})
+	t.Run("not found", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mock.ChangeState(false)
+		serv.GetAccountSummary(rr, newRequest())
+		assert.Equal(t, http.StatusNotFound, rr.Result().StatusCode)
+	})
+	t.Run("unauthorized", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		serv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/auth/account/summary", nil))
+		assert.Equal(t, http.StatusUnauthorized, rr.Result().StatusCode)
+	})
+}
+
 func TestUsersHandlersIntegrational(t *testing.T) {
 	cfg := setupUsersTestDB(t)
 	repo := repository.NewUsersRepo(cfg)
@@ -376,6 +584,25 @@ func TestCreateHabit(t *testing.T) {
 			},
 			Body: bytes.NewReader(body),
 		},
+		{
+			ExpectedCode: http.StatusCreated,
+			MockPrepFunc: func() {
+				hService.EXPECT().CreateHabit(gomock.Any(), userID, service.CreateHabitRequest{
+					Title:       habit.Title,
+					Description: habit.Description,
+					Tags:        []string{"health", "morning"},
+				}).Return(&entity.Habit{
+					ID:          habitID,
+					UserID:      uid,
+					Title:       habit.Title,
+					Description: habit.Description,
+					CreatedAt:   time.Now(),
+					UpdatedAt:   time.Now(),
+					Tags:        []string{"health", "morning"},
+				}, nil)
+			},
+			Body: strings.NewReader(`{"title": "test_habit", "desc": "test_habit_description", "tags": ["health", "morning"]}`),
+		},
 		{
 			ExpectedCode: http.StatusConflict,
 			MockPrepFunc: func() {
@@ -420,11 +647,147 @@ func TestCreateHabit(t *testing.T) {
 		serv.CreateHabit(rr, r)
 		assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
 		if tc.ExpectedCode == http.StatusCreated {
-			resp, _ := io.ReadAll(rr.Result().Body)
-			fmt.Println(string(resp))
+			var resp api.CreateHabitResponse
+			require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+			assert.Equal(t, habitID.String(), resp.HabitID)
+			assert.Equal(t, habitID, resp.ID)
+			assert.Equal(t, habit.Title, resp.Title)
+			assert.Equal(t, habit.Description, resp.Description)
+			assert.False(t, resp.CreatedAt.IsZero())
+		}
+	}
+}
+func TestCreateHabitsBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	body := `{"habits": [{"title": "run"}, {"title": "read"}]}`
+	created := func(titles ...string) []*entity.Habit {
+		habits := make([]*entity.Habit, 0, len(titles))
+		for _, title := range titles {
+			habits = append(habits, &entity.Habit{ID: uuid.New(), UserID: userID, Title: title})
 		}
+		return habits
+	}
+	testCases := []struct {
+		Desc           string
+		ExpectedCode   int
+		ExpectedStatus []int
+		ExpectedCodes  []string
+		MockPrepFunc   func()
+	}{
+		{
+			Desc:           "all created",
+			ExpectedCode:   http.StatusCreated,
+			ExpectedStatus: []int{http.StatusCreated, http.StatusCreated},
+			ExpectedCodes:  []string{"", ""},
+			MockPrepFunc: func() {
+				hService.EXPECT().CreateHabits(gomock.Any(), userID, gomock.Any()).Return(created("run", "read"), nil, nil)
+			},
+		},
+		{
+			Desc:           "mixed",
+			ExpectedCode:   http.StatusMultiStatus,
+			ExpectedStatus: []int{http.StatusConflict, http.StatusCreated},
+			ExpectedCodes:  []string{"habit_exists", ""},
+			MockPrepFunc: func() {
+				hService.EXPECT().CreateHabits(gomock.Any(), userID, gomock.Any()).Return(created("read"), []service.BatchError{
+					{Index: 0, Err: errorvalues.ErrUserHasHabit},
+				}, nil)
+			},
+		},
+		{
+			Desc:           "all failed",
+			ExpectedCode:   http.StatusMultiStatus,
+			ExpectedStatus: []int{http.StatusBadRequest, http.StatusInternalServerError},
+			ExpectedCodes:  []string{"validation_failed", ""},
+			MockPrepFunc: func() {
+				hService.EXPECT().CreateHabits(gomock.Any(), userID, gomock.Any()).Return(nil, []service.BatchError{
+					{Index: 0, Err: errors.Join(errorvalues.ErrValidation, errors.New("invalid color"))},
+					{Index: 1, Err: errors.New("db error")},
+				}, nil)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/habits/batch", strings.NewReader(body))
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			serv.CreateHabitsBatch(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			var resp httputil.BatchResponse[api.BatchItemResult]
+			require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+			require.Len(t, resp.Results, len(tc.ExpectedStatus))
+			for i, res := range resp.Results {
+				assert.Equal(t, i, res.Index)
+				assert.Equal(t, tc.ExpectedStatus[i], res.Status)
+				assert.Equal(t, tc.ExpectedCodes[i], res.ErrorCode)
+				assert.Equal(t, res.Status == http.StatusCreated, res.Habit != nil)
+			}
+		})
+	}
+}
+
+func TestCheckHabitsBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cService := mocks.NewMockHabitChecksServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		ChecksService: cService,
+	})
+	body := `[{"habit_id": "` + uuid.NewString() + `", "date": "2025-01-01"}, {"habit_id": "` + uuid.NewString() + `", "date": "2025-01-01"}]`
+	testCases := []struct {
+		Desc           string
+		ExpectedCode   int
+		ExpectedStatus []int
+		Failures       []service.BatchError
+	}{
+		{
+			Desc:           "all checked",
+			ExpectedCode:   http.StatusCreated,
+			ExpectedStatus: []int{http.StatusCreated, http.StatusCreated},
+		},
+		{
+			Desc:           "mixed",
+			ExpectedCode:   http.StatusMultiStatus,
+			ExpectedStatus: []int{http.StatusCreated, http.StatusConflict},
+			Failures:       []service.BatchError{{Index: 1, Err: errorvalues.ErrCheckExist}},
+		},
+		{
+			Desc:           "all failed",
+			ExpectedCode:   http.StatusMultiStatus,
+			ExpectedStatus: []int{http.StatusNotFound, http.StatusNotFound},
+			Failures: []service.BatchError{
+				{Index: 0, Err: errorvalues.ErrWrongOwner},
+				{Index: 1, Err: errorvalues.ErrHabitNotFound},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			cService.EXPECT().CheckMany(gomock.Any(), userID, gomock.Any()).Return(tc.Failures, nil)
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/checks/batch", strings.NewReader(body))
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			serv.CheckHabitsBatch(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			var resp httputil.BatchResponse[httputil.BatchItemResult]
+			require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+			require.Len(t, resp.Results, len(tc.ExpectedStatus))
+			for i, res := range resp.Results {
+				assert.Equal(t, tc.ExpectedStatus[i], res.Status)
+				// Others' habits look like unexist ones
+				if res.Status == http.StatusNotFound {
+					assert.Equal(t, "habit_not_found", res.ErrorCode)
+				}
+			}
+		})
 	}
 }
+
 func TestGetHabits(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	hService := mocks.NewMockHabitsServiceI(ctrl)
@@ -448,35 +811,40 @@ func TestGetHabits(t *testing.T) {
 		Limit               int
 		Page                int
 		ExpectedHabitsCount int
+		ExpectedTotalPages  int
 	}{
 		{
 			ExpectedCode: http.StatusOK,
 			MockPrepFunc: func() {
-				hService.EXPECT().GetUserHabits(gomock.Any(), userID, service.PaginationOpts{
+				hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), service.PaginationOpts{
 					Limit:  10,
 					Offset: 0,
 				}).Return(habits, nil)
+				hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(10, nil)
 			},
 			Page:                1,
 			Limit:               10,
 			ExpectedHabitsCount: 10,
+			ExpectedTotalPages:  1,
 		},
 		{
 			ExpectedCode: http.StatusOK,
 			MockPrepFunc: func() {
-				hService.EXPECT().GetUserHabits(gomock.Any(), userID, service.PaginationOpts{
+				hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), service.PaginationOpts{
 					Limit:  4,
 					Offset: 4,
 				}).Return(habits[2:6], nil)
+				hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(10, nil)
 			},
 			Page:                2,
 			Limit:               4,
 			ExpectedHabitsCount: 4,
+			ExpectedTotalPages:  3,
 		},
 		{
 			ExpectedCode: http.StatusInternalServerError,
 			MockPrepFunc: func() {
-				hService.EXPECT().GetUserHabits(gomock.Any(), userID, service.PaginationOpts{
+				hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), service.PaginationOpts{
 					Limit:  10,
 					Offset: 0,
 				}).Return(nil, errors.New("service error"))
@@ -485,7 +853,17 @@ func TestGetHabits(t *testing.T) {
 			Limit:               10,
 			ExpectedHabitsCount: 0,
 		},
+		{
+			ExpectedCode: http.StatusInternalServerError,
+			MockPrepFunc: func() {
+				hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), gomock.Any()).Return(habits, nil)
+				hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(0, errors.New("service error"))
+			},
+			Page:  1,
+			Limit: 10,
+		},
 	}
+	hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, nil).AnyTimes()
 	for _, tc := range testCases {
 		tc.MockPrepFunc()
 		rr := httptest.NewRecorder()
@@ -501,102 +879,1988 @@ func TestGetHabits(t *testing.T) {
 			var resp api.GetHabitsResponse
 			err := sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp)
 			require.NoError(t, err)
-			assert.Equal(t, tc.ExpectedHabitsCount, len(resp.Habits))
+			assert.Equal(t, tc.ExpectedHabitsCount, len(resp.Items))
+			assert.Equal(t, tc.Page, resp.Page.Page)
+			require.NotNil(t, resp.Total)
+			assert.Equal(t, 10, *resp.Total)
+			require.NotNil(t, resp.TotalPages)
+			assert.Equal(t, tc.ExpectedTotalPages, *resp.TotalPages)
 		}
 	}
 }
-func TestDeleteHabit(t *testing.T) {
+func TestGetHabitsProjection(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	hService := mocks.NewMockHabitsServiceI(ctrl)
+	hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(0, nil).AnyTimes()
 	serv := api.New(&api.ServicesList{
 		HabitsService: hService,
 	})
-	habitID := uuid.New()
+	habit := &entity.Habit{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Title:       "test_habit",
+		Description: "blah blah blah",
+		Color:       "#FF0000",
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
 	testCases := []struct {
-		ExpectedCode int
-		MockPrepFunc func()
+		Desc           string
+		Fields         string
+		ExpectedFields []string
 	}{
-		{
-			ExpectedCode: http.StatusOK,
-			MockPrepFunc: func() {
-				hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(nil)
-			},
-		},
-		{
-			ExpectedCode: http.StatusNotFound,
-			MockPrepFunc: func() {
-				hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(errorvalues.ErrHabitNotFound)
-			},
-		},
-		{
-			ExpectedCode: http.StatusNotFound,
-			MockPrepFunc: func() {
-				hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(errorvalues.ErrWrongOwner)
-			},
-		},
-		{
-			ExpectedCode: http.StatusInternalServerError,
-			MockPrepFunc: func() {
-				hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(errors.New("service error"))
-			},
-		},
+		{Desc: "id and title", Fields: "id,title", ExpectedFields: []string{"id", "title"}},
+		{Desc: "unknown and repeated ignored", Fields: "color, password_hash,color", ExpectedFields: []string{"color"}},
+		{Desc: "only unknown gives whole habit", Fields: "secret", ExpectedFields: []string{"id", "uid", "title", "desc", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}},
 	}
 	for _, tc := range testCases {
-		tc.MockPrepFunc()
+		t.Run(tc.Desc, func(t *testing.T) {
+			hService.EXPECT().LastModified(gomock.Any(), userID).Return(habit.UpdatedAt, nil)
+			hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), gomock.Any()).Return([]*entity.Habit{habit}, nil)
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/habits?fields="+url.QueryEscape(tc.Fields), nil)
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			serv.GetHabits(rr, r)
+			assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+			var resp struct {
+				Items []map[string]any `json:"items"`
+			}
+			require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+			require.Len(t, resp.Items, 1)
+			keys := make([]string, 0, len(resp.Items[0]))
+			for key := range resp.Items[0] {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, tc.ExpectedFields, keys)
+		})
+	}
+}
+
+func TestGetHabitsWithToday(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(0, nil).AnyTimes()
+	cService := mocks.NewMockHabitChecksServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+		ChecksService: cService,
+	})
+	habits := []*entity.Habit{
+		{ID: uuid.New(), UserID: userID, Title: "checked"},
+		{ID: uuid.New(), UserID: userID, Title: "not checked"},
+		{ID: uuid.New(), UserID: userID, Title: "checked too"},
+	}
+	ids := []uuid.UUID{habits[0].ID, habits[1].ID, habits[2].ID}
+	checked := map[uuid.UUID]bool{habits[0].ID: true, habits[2].ID: true}
+	get := func(query string) *httptest.ResponseRecorder {
 		rr := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodDelete, "/api/habits/"+habitID.String(), nil)
+		r := httptest.NewRequest(http.MethodGet, "/api/habits"+query, nil)
 		r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
-		r.SetPathValue("id", habitID.String())
-		serv.DeleteHabit(rr, r)
-		assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+		serv.GetHabits(rr, r)
+		return rr
 	}
-}
-func TestHabitsCRUDIntegrational(t *testing.T) {
-	cfg := setupUsersTestDB(t)
-	usersRepo := repository.NewUsersRepo(cfg)
-	habitsRepo := repository.NewHabitsRepo(cfg)
-	usersService := service.NewUserService(usersRepo)
-	habitsService := service.NewHabitsService(habitsRepo)
-	server := api.New(&api.ServicesList{
-		UserService:   usersService,
-		HabitsService: habitsService,
-		JwtService:    jwtservice.New("secret"),
+	t.Run("whole habits", func(t *testing.T) {
+		hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), gomock.Any()).Return(habits, nil)
+		cService.EXPECT().CheckedToday(gomock.Any(), userID, ids).Return(checked, nil)
+		rr := get("?with_today=true")
+		require.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		var resp api.HabitsWithTodayResponse
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		require.Len(t, resp.Items, 3)
+		assert.True(t, resp.Items[0].CheckedToday)
+		assert.False(t, resp.Items[1].CheckedToday)
+		assert.True(t, resp.Items[2].CheckedToday)
+		assert.Equal(t, "not checked", resp.Items[1].Title)
 	})
-	body, err := sonic.ConfigDefault.Marshal(api.RegisterRequest{
-		Name:     username,
-		Password: password,
+	t.Run("projection", func(t *testing.T) {
+		hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), gomock.Any()).Return(habits, nil)
+		cService.EXPECT().CheckedToday(gomock.Any(), userID, ids).Return(checked, nil)
+		rr := get("?with_today=true&fields=title")
+		require.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		var resp api.ProjectedHabitsResponse
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		require.Len(t, resp.Items, 3)
+		assert.Equal(t, map[string]any{"title": "checked", "checked_today": true}, resp.Items[0])
+		assert.Equal(t, map[string]any{"title": "not checked", "checked_today": false}, resp.Items[1])
 	})
-	if err != nil {
-		t.Fatal(err)
-	}
-	var uid uuid.UUID
-	serverAddr := "localhost:9090"
-	address := "http://" + serverAddr
-	go func() {
-		err = server.Run(serverAddr)
-		require.NoError(t, err)
-	}()
-	time.Sleep(time.Millisecond * 100)
-	t.Run("registering new user", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, address+"/api/v1/auth/register", bytes.NewReader(body))
-		require.NoError(t, err)
-
-		resp, err := http.DefaultClient.Do(req)
-		require.NoError(t, err)
-		assert.Equal(t, http.StatusCreated, resp.StatusCode)
-
-		result := make(map[string]any)
-		err = sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&result)
-		if err != nil {
-			t.Fatal(err)
-		}
-		defer resp.Body.Close()
-		uidStr, ok := result["uid"].(string)
-		if ok {
-			uid = uuid.MustParse(uidStr)
-		} else {
-			t.Error("invalid response body")
-		}
+	t.Run("off by default", func(t *testing.T) {
+		hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, nil)
+		hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), gomock.Any()).Return(habits, nil)
+		rr := get("")
+		require.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		assert.NotContains(t, rr.Body.String(), "checked_today")
+	})
+	t.Run("checks service error", func(t *testing.T) {
+		hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), gomock.Any()).Return(habits, nil)
+		cService.EXPECT().CheckedToday(gomock.Any(), userID, ids).Return(nil, errors.New("db down"))
+		rr := get("?with_today=true")
+		assert.Equal(t, http.StatusInternalServerError, rr.Result().StatusCode)
+	})
+}
+
+func TestGetHabitsByCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 123456000, time.UTC)
+	first := []*entity.Habit{
+		{ID: uuid.New(), UserID: userID, Title: "first", CreatedAt: createdAt},
+		{ID: uuid.New(), UserID: userID, Title: "second", CreatedAt: createdAt},
+	}
+	second := []*entity.Habit{{ID: uuid.New(), UserID: userID, Title: "third", CreatedAt: createdAt.Add(time.Second)}}
+	get := func(query string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/habits"+query, nil)
+		r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+		serv.GetHabits(rr, r)
+		return rr
+	}
+	var nextCursor string
+	t.Run("first page", func(t *testing.T) {
+		hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, nil)
+		hService.EXPECT().GetUserHabitsAfter(gomock.Any(), userID, nil, 2).Return(first, nil)
+		rr := get("?cursor=&limit=2")
+		require.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		var resp api.GetHabitsResponse
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		assert.Len(t, resp.Items, 2)
+		assert.NotEmpty(t, resp.NextCursor)
+		// Cursor pages aren't counted
+		assert.Nil(t, resp.Total)
+		assert.Nil(t, resp.TotalPages)
+		nextCursor = resp.NextCursor
+	})
+	t.Run("cursor round-tripped", func(t *testing.T) {
+		// Cursor points to the last habit of previous page, with full time precision
+		hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, nil)
+		hService.EXPECT().GetUserHabitsAfter(gomock.Any(), userID, gomock.Any(), 2).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, cursor *entity.HabitCursor, _ int) ([]*entity.Habit, error) {
+				require.NotNil(t, cursor)
+				assert.Equal(t, first[1].ID, cursor.ID)
+				assert.True(t, cursor.CreatedAt.Equal(createdAt))
+				return second, nil
+			})
+		rr := get("?limit=2&cursor=" + nextCursor)
+		require.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		var resp api.GetHabitsResponse
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		assert.Len(t, resp.Items, 1)
+		// Short page is the last one
+		assert.Empty(t, resp.NextCursor)
+	})
+	t.Run("invalid cursor", func(t *testing.T) {
+		rr := get("?cursor=not-a-cursor")
+		assert.Equal(t, http.StatusBadRequest, rr.Result().StatusCode)
+	})
+	t.Run("sort by position with cursor", func(t *testing.T) {
+		rr := get("?cursor=&sort=position")
+		assert.Equal(t, http.StatusBadRequest, rr.Result().StatusCode)
+	})
+	t.Run("offset pagination by position", func(t *testing.T) {
+		hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, nil)
+		hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(0, nil)
+		hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSortPosition, service.PaginationOpts{Limit: 2, Offset: 0}).Return(first, nil)
+		rr := get("?limit=2&sort=position")
+		assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+	})
+	t.Run("unknown sort", func(t *testing.T) {
+		hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, nil)
+		hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort("title"), gomock.Any()).
+			Return(nil, errors.Join(errorvalues.ErrValidation, errors.New("invalid sort")))
+		rr := get("?sort=title")
+		assert.Equal(t, http.StatusBadRequest, rr.Result().StatusCode)
+	})
+	t.Run("offset pagination without cursor", func(t *testing.T) {
+		hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, nil)
+		hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(3, nil)
+		hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), service.PaginationOpts{Limit: 2, Offset: 2}).Return(second, nil)
+		rr := get("?limit=2&page=2")
+		require.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		var resp api.GetHabitsResponse
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		assert.Empty(t, resp.NextCursor)
+		require.NotNil(t, resp.Total)
+		assert.Equal(t, 3, *resp.Total)
+	})
+}
+
+func TestGetGroupHabits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	groupID := uuid.New()
+	get := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/groups/"+groupID.String()+"/habits?page=2&limit=1", nil)
+		r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+		r.SetPathValue("id", groupID.String())
+		serv.GetGroupHabits(rr, r)
+		return rr
+	}
+	t.Run("page with total", func(t *testing.T) {
+		hService.EXPECT().GetGroupHabits(gomock.Any(), groupID, userID, service.PaginationOpts{Limit: 1, Offset: 1}).
+			Return([]*entity.Habit{{ID: uuid.New(), UserID: userID, Title: "second"}}, 3, nil)
+		rr := get()
+		require.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		var resp api.GroupHabitsResponse
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		assert.Equal(t, groupID.String(), resp.GroupID)
+		assert.Len(t, resp.Items, 1)
+		assert.Equal(t, 2, resp.Page.Page)
+		require.NotNil(t, resp.Total)
+		assert.Equal(t, 3, *resp.Total)
+		require.NotNil(t, resp.TotalPages)
+		assert.Equal(t, 3, *resp.TotalPages)
+	})
+	t.Run("not a member", func(t *testing.T) {
+		hService.EXPECT().GetGroupHabits(gomock.Any(), groupID, userID, gomock.Any()).Return(nil, 0, errorvalues.ErrGroupNotFound)
+		rr := get()
+		assert.Equal(t, http.StatusNotFound, rr.Result().StatusCode)
+	})
+}
+
+func TestGetHabitsIfModifiedSince(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(0, nil).AnyTimes()
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	lastModified := time.Date(2025, 6, 10, 12, 30, 15, 500, time.UTC)
+	header := "Tue, 10 Jun 2025 12:30:15 GMT"
+	testCases := []struct {
+		Desc            string
+		IfModifiedSince string
+		LastModified    time.Time
+		ExpectedCode    int
+		ExpectedHeader  string
+	}{
+		{Desc: "not modified since last modification", IfModifiedSince: header, LastModified: lastModified, ExpectedCode: http.StatusNotModified, ExpectedHeader: header},
+		{Desc: "not modified since later time", IfModifiedSince: "Tue, 10 Jun 2025 13:00:00 GMT", LastModified: lastModified, ExpectedCode: http.StatusNotModified, ExpectedHeader: header},
+		{Desc: "modified", IfModifiedSince: "Tue, 10 Jun 2025 12:30:14 GMT", LastModified: lastModified, ExpectedCode: http.StatusOK, ExpectedHeader: header},
+		{Desc: "no condition", LastModified: lastModified, ExpectedCode: http.StatusOK, ExpectedHeader: header},
+		{Desc: "invalid condition ignored", IfModifiedSince: "yesterday", LastModified: lastModified, ExpectedCode: http.StatusOK, ExpectedHeader: header},
+		{Desc: "user without habits", IfModifiedSince: header, ExpectedCode: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			hService.EXPECT().LastModified(gomock.Any(), userID).Return(tc.LastModified, nil)
+			if tc.ExpectedCode == http.StatusOK {
+				hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), gomock.Any()).Return([]*entity.Habit{}, nil)
+			}
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/habits", nil)
+			if tc.IfModifiedSince != "" {
+				r.Header.Set("If-Modified-Since", tc.IfModifiedSince)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			serv.GetHabits(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			assert.Equal(t, tc.ExpectedHeader, rr.Result().Header.Get("Last-Modified"))
+			if tc.ExpectedCode == http.StatusNotModified {
+				assert.Empty(t, rr.Body.Bytes())
+			}
+		})
+	}
+	t.Run("list provided if last modification is unknown", func(t *testing.T) {
+		hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, errors.New("service error"))
+		hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), gomock.Any()).Return([]*entity.Habit{}, nil)
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/habits", nil)
+		r.Header.Set("If-Modified-Since", header)
+		r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+		serv.GetHabits(rr, r)
+		assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		assert.Empty(t, rr.Result().Header.Get("Last-Modified"))
+	})
+}
+
+func TestGetHabitsConfiguredLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(0, nil).AnyTimes()
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	}, api.WithPageLimits(5, 20))
+	testCases := []struct {
+		Desc          string
+		Query         string
+		ExpectedLimit int
+	}{
+		{Desc: "clamped to max", Query: "?limit=100", ExpectedLimit: 20},
+		{Desc: "default", Query: "", ExpectedLimit: 5},
+	}
+	hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, nil).AnyTimes()
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), service.PaginationOpts{
+				Limit:  tc.ExpectedLimit,
+				Offset: 0,
+			}).Return([]*entity.Habit{}, nil)
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/habits"+tc.Query, nil)
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			serv.GetHabits(rr, r)
+			assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+			var resp api.GetHabitsResponse
+			require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+			assert.Equal(t, tc.ExpectedLimit, resp.Limit)
+		})
+	}
+}
+
+// Habit endpoints act on behalf of token's user only, uid smuggled into query or body is ignored
+func TestHabitEndpointsUseTokenUID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	hService.EXPECT().CountUserHabits(gomock.Any(), userID).Return(0, nil).AnyTimes()
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	otherID := uuid.New()
+	foreignQuery := "?uid=" + otherID.String() + "&user_id=" + otherID.String()
+	habitID := uuid.New()
+	request := func(method, target string, body io.Reader) *http.Request {
+		r := httptest.NewRequest(method, target, body)
+		return r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+	}
+	t.Run("get habits", func(t *testing.T) {
+		hService.EXPECT().LastModified(gomock.Any(), userID).Return(time.Time{}, nil)
+		hService.EXPECT().GetUserHabits(gomock.Any(), userID, entity.HabitSort(""), gomock.Any()).Return([]*entity.Habit{}, nil)
+		rr := httptest.NewRecorder()
+		serv.GetHabits(rr, request(http.MethodGet, "/api/habits"+foreignQuery, nil))
+		assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+		var resp api.GetHabitsResponse
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		assert.Equal(t, userID.String(), resp.UserID)
+	})
+	t.Run("create habit", func(t *testing.T) {
+		body := `{"title":"test_habit","uid":"` + otherID.String() + `","user_id":"` + otherID.String() + `"}`
+		hService.EXPECT().CreateHabit(gomock.Any(), userID, service.CreateHabitRequest{Title: "test_habit"}).
+			Return(&entity.Habit{ID: habitID, UserID: userID, Title: "test_habit"}, nil)
+		rr := httptest.NewRecorder()
+		serv.CreateHabit(rr, request(http.MethodPost, "/api/habits"+foreignQuery, strings.NewReader(body)))
+		assert.Equal(t, http.StatusCreated, rr.Result().StatusCode)
+	})
+	t.Run("get habit", func(t *testing.T) {
+		hService.EXPECT().GetHabit(gomock.Any(), habitID, userID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		rr := httptest.NewRecorder()
+		r := request(http.MethodGet, "/api/habits/"+habitID.String()+foreignQuery, nil)
+		r.SetPathValue("id", habitID.String())
+		serv.GetHabit(rr, r)
+		assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+	})
+	t.Run("delete habit", func(t *testing.T) {
+		hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(nil)
+		rr := httptest.NewRecorder()
+		r := request(http.MethodDelete, "/api/habits/"+habitID.String()+foreignQuery, nil)
+		r.SetPathValue("id", habitID.String())
+		serv.DeleteHabit(rr, r)
+		assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+	})
+	t.Run("list tags", func(t *testing.T) {
+		hService.EXPECT().ListTags(gomock.Any(), userID).Return([]string{}, nil)
+		rr := httptest.NewRecorder()
+		serv.ListHabitTags(rr, request(http.MethodGet, "/api/habits/tags"+foreignQuery, nil))
+		assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+	})
+}
+
+func TestDeleteHabit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	habitID := uuid.New()
+	testCases := []struct {
+		ExpectedCode int
+		MockPrepFunc func()
+	}{
+		{
+			ExpectedCode: http.StatusOK,
+			MockPrepFunc: func() {
+				hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(nil)
+			},
+		},
+		{
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(errorvalues.ErrHabitNotFound)
+			},
+		},
+		{
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(errorvalues.ErrWrongOwner)
+			},
+		},
+		{
+			ExpectedCode: http.StatusInternalServerError,
+			MockPrepFunc: func() {
+				hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(errors.New("service error"))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.MockPrepFunc()
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodDelete, "/api/habits/"+habitID.String(), nil)
+		r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+		r.SetPathValue("id", habitID.String())
+		serv.DeleteHabit(rr, r)
+		assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+	}
+}
+
+type schemaVersionStub struct {
+	version int64
+	err     error
+}
+
+func (stub schemaVersionStub) SchemaVersion(ctx context.Context) (int64, error) {
+	return stub.version, stub.err
+}
+
+func TestSchemaVersion(t *testing.T) {
+	testCases := []struct {
+		Desc            string
+		Source          api.SchemaVersionSource
+		ExpectedCode    int
+		ExpectedVersion int64
+	}{
+		{Desc: "migrated", Source: schemaVersionStub{version: 14}, ExpectedCode: http.StatusOK, ExpectedVersion: 14},
+		{Desc: "not migrated", Source: schemaVersionStub{err: errorvalues.ErrSchemaNotMigrated}, ExpectedCode: http.StatusServiceUnavailable},
+		{Desc: "db error", Source: schemaVersionStub{err: errors.New("db error")}, ExpectedCode: http.StatusInternalServerError},
+		{Desc: "no source", ExpectedCode: http.StatusServiceUnavailable},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			serv := api.New(&api.ServicesList{}, api.WithSchemaVersionSource(tc.Source))
+			rr := httptest.NewRecorder()
+			serv.SchemaVersion(rr, httptest.NewRequest(http.MethodGet, "/api/v1/version/schema", nil))
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			if tc.ExpectedCode == http.StatusOK {
+				var resp api.SchemaVersionResponse
+				require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, tc.ExpectedVersion, resp.Version)
+			}
+		})
+	}
+}
+
+// Signs tokens, but can't parse them back, as if it were configured with another key
+type brokenJWTService struct {
+	api.JWTServiceI
+}
+
+func (brokenJWTService) ParseToken(tokenString string) (*api.JWTClaims, error) {
+	return nil, errorvalues.ErrInvalidToken
+}
+
+func TestReadiness(t *testing.T) {
+	testCases := []struct {
+		Desc         string
+		JwtService   api.JWTServiceI
+		Source       api.SchemaVersionSource
+		ExpectedCode int
+	}{
+		{Desc: "ready", JwtService: jwtservice.New("secret"), Source: schemaVersionStub{version: 14}, ExpectedCode: http.StatusOK},
+		{Desc: "without schema source", JwtService: jwtservice.New("secret"), ExpectedCode: http.StatusOK},
+		{Desc: "empty jwt secret", JwtService: jwtservice.New(""), ExpectedCode: http.StatusServiceUnavailable},
+		{Desc: "tokens aren't parsed", JwtService: brokenJWTService{jwtservice.New("secret")}, ExpectedCode: http.StatusServiceUnavailable},
+		{Desc: "no jwt service", ExpectedCode: http.StatusServiceUnavailable},
+		{Desc: "db error", JwtService: jwtservice.New("secret"), Source: schemaVersionStub{err: errors.New("db error")}, ExpectedCode: http.StatusServiceUnavailable},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			serv := api.New(&api.ServicesList{JwtService: tc.JwtService}, api.WithSchemaVersionSource(tc.Source))
+			rr := httptest.NewRecorder()
+			serv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			assert.Equal(t, tc.ExpectedCode, rr.Code)
+			if tc.ExpectedCode == http.StatusOK {
+				var resp api.ReadinessResponse
+				require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, "ready", resp.Status)
+			}
+		})
+	}
+}
+
+func TestHeadHabit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	habitID := uuid.New()
+	testCases := []struct {
+		ExpectedCode int
+		MockPrepFunc func()
+	}{
+		{
+			ExpectedCode: http.StatusOK,
+			MockPrepFunc: func() {
+				hService.EXPECT().GetHabit(gomock.Any(), habitID, userID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+			},
+		},
+		{
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				hService.EXPECT().GetHabit(gomock.Any(), habitID, userID).Return(nil, errorvalues.ErrHabitNotFound)
+			},
+		},
+		{
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				hService.EXPECT().GetHabit(gomock.Any(), habitID, userID).Return(nil, errorvalues.ErrWrongOwner)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc.MockPrepFunc()
+		rr := httptest.NewRecorder()
+		// Stats are never loaded for HEAD
+		r := httptest.NewRequest(http.MethodHead, "/api/habits/"+habitID.String()+"?include=stats", nil)
+		r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+		r.SetPathValue("id", habitID.String())
+		serv.HeadHabit(rr, r)
+		assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+		assert.Empty(t, rr.Body.Bytes())
+	}
+}
+
+func TestPatchHabit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	habitID := uuid.New()
+	title, desc := "new_title", "new_desc"
+	testCases := []struct {
+		Desc         string
+		ExpectedCode int
+		MockPrepFunc func()
+		Body         string
+	}{
+		{
+			Desc:         "title only",
+			ExpectedCode: http.StatusOK,
+			MockPrepFunc: func() {
+				hService.EXPECT().UpdateHabit(gomock.Any(), habitID, userID, service.UpdateHabitRequest{
+					Title: &title,
+				}).Return(&entity.Habit{ID: habitID, UserID: userID, Title: title}, nil)
+			},
+			Body: `{"title": "new_title"}`,
+		},
+		{
+			Desc:         "desc only",
+			ExpectedCode: http.StatusOK,
+			MockPrepFunc: func() {
+				hService.EXPECT().UpdateHabit(gomock.Any(), habitID, userID, service.UpdateHabitRequest{
+					Description: &desc,
+				}).Return(&entity.Habit{ID: habitID, UserID: userID, Description: desc}, nil)
+			},
+			Body: `{"desc": "new_desc"}`,
+		},
+		{
+			Desc:         "both",
+			ExpectedCode: http.StatusOK,
+			MockPrepFunc: func() {
+				hService.EXPECT().UpdateHabit(gomock.Any(), habitID, userID, service.UpdateHabitRequest{
+					Title:       &title,
+					Description: &desc,
+				}).Return(&entity.Habit{ID: habitID, UserID: userID, Title: title, Description: desc}, nil)
+			},
+			Body: `{"title": "new_title", "desc": "new_desc"}`,
+		},
+		{
+			Desc:         "tags",
+			ExpectedCode: http.StatusOK,
+			MockPrepFunc: func() {
+				hService.EXPECT().UpdateHabit(gomock.Any(), habitID, userID, service.UpdateHabitRequest{
+					Tags: &[]string{"health", "evening"},
+				}).Return(&entity.Habit{ID: habitID, UserID: userID, Tags: []string{"health", "evening"}}, nil)
+			},
+			Body: `{"tags": ["health", "evening"]}`,
+		},
+		{
+			Desc:         "wrong owner",
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				hService.EXPECT().UpdateHabit(gomock.Any(), habitID, userID, gomock.Any()).Return(nil, errorvalues.ErrWrongOwner)
+			},
+			Body: `{"title": "new_title"}`,
+		},
+		{
+			Desc:         "invalid color",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {
+				hService.EXPECT().UpdateHabit(gomock.Any(), habitID, userID, gomock.Any()).Return(nil, errorvalues.ErrValidation)
+			},
+			Body: `{"color": "red"}`,
+		},
+		{
+			Desc:         "title taken",
+			ExpectedCode: http.StatusConflict,
+			MockPrepFunc: func() {
+				hService.EXPECT().UpdateHabit(gomock.Any(), habitID, userID, gomock.Any()).Return(nil, errorvalues.ErrUserHasHabit)
+			},
+			Body: `{"title": "new_title"}`,
+		},
+		{
+			Desc:         "invalid body",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {},
+			Body:         "corrupted",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, "/api/habits/"+habitID.String(), bytes.NewReader([]byte(tc.Body)))
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			r.SetPathValue("id", habitID.String())
+			serv.PatchHabit(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+		})
+	}
+}
+
+func TestMergeHabits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	targetID := uuid.New()
+	sourceID := uuid.New()
+	testCases := []struct {
+		Desc            string
+		ExpectedCode    int
+		ExpectedMessage string
+		MockPrepFunc    func()
+		Body            string
+	}{
+		{
+			Desc:         "merged",
+			ExpectedCode: http.StatusNoContent,
+			MockPrepFunc: func() {
+				hService.EXPECT().MergeHabits(gomock.Any(), sourceID, targetID, userID).Return(nil)
+			},
+			Body: `{"source_id": "` + sourceID.String() + `"}`,
+		},
+		{
+			Desc:            "malformed uuid in body",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedMessage: "invalid source_id",
+			MockPrepFunc:    func() {},
+			Body:            `{"source_id": "not-a-uuid"}`,
+		},
+		{
+			Desc:            "missing uuid in body",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedMessage: "invalid source_id",
+			MockPrepFunc:    func() {},
+			Body:            `{}`,
+		},
+		{
+			Desc:         "source not found",
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				hService.EXPECT().MergeHabits(gomock.Any(), sourceID, targetID, userID).Return(errorvalues.ErrHabitNotFound)
+			},
+			Body: `{"source_id": "` + sourceID.String() + `"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/habits/"+targetID.String()+"/merge", strings.NewReader(tc.Body))
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			r.SetPathValue("id", targetID.String())
+			serv.MergeHabits(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			if tc.ExpectedMessage != "" {
+				var resp httputil.ErrorResponse
+				require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, tc.ExpectedMessage, resp.Message)
+			}
+		})
+	}
+}
+
+func TestReorderHabits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		HabitsService: hService,
+	})
+	firstID, secondID := uuid.New(), uuid.New()
+	body := `{"habit_ids": ["` + secondID.String() + `", "` + firstID.String() + `"]}`
+	testCases := []struct {
+		Desc            string
+		ExpectedCode    int
+		ExpectedMessage string
+		MockPrepFunc    func()
+		Body            string
+	}{
+		{
+			Desc:         "reordered",
+			ExpectedCode: http.StatusNoContent,
+			MockPrepFunc: func() {
+				hService.EXPECT().ReorderHabits(gomock.Any(), userID, []uuid.UUID{secondID, firstID}).Return(nil)
+			},
+			Body: body,
+		},
+		{
+			Desc:            "malformed uuid in body",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedMessage: "invalid habit id in habit_ids",
+			MockPrepFunc:    func() {},
+			Body:            `{"habit_ids": ["not-a-uuid"]}`,
+		},
+		{
+			Desc:         "habit of other user",
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				hService.EXPECT().ReorderHabits(gomock.Any(), userID, gomock.Any()).Return(errorvalues.ErrWrongOwner)
+			},
+			Body: body,
+		},
+		{
+			Desc:         "duplicates",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {
+				hService.EXPECT().ReorderHabits(gomock.Any(), userID, gomock.Any()).
+					Return(errors.Join(errorvalues.ErrValidation, errors.New("habit is listed more than once")))
+			},
+			Body: `{"habit_ids": ["` + firstID.String() + `", "` + firstID.String() + `"]}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/api/habits/order", strings.NewReader(tc.Body))
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			serv.ReorderHabits(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			if tc.ExpectedMessage != "" {
+				var resp httputil.ErrorResponse
+				require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, tc.ExpectedMessage, resp.Message)
+			}
+		})
+	}
+}
+
+func TestCheckHabit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cService := mocks.NewMockHabitChecksServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		ChecksService: cService,
+	})
+	habitID := uuid.New()
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		Desc         string
+		ExpectedCode int
+		MockPrepFunc func()
+		Body         string
+	}{
+		{
+			Desc:         "with note",
+			ExpectedCode: http.StatusCreated,
+			MockPrepFunc: func() {
+				cService.EXPECT().CheckHabit(gomock.Any(), habitID, userID, date, "felt great").Return(nil)
+			},
+			Body: `{"date": "2025-01-01", "note": "felt great"}`,
+		},
+		{
+			Desc:         "today in user's timezone by default",
+			ExpectedCode: http.StatusCreated,
+			MockPrepFunc: func() {
+				cService.EXPECT().Today(gomock.Any(), userID).Return(date, nil)
+				cService.EXPECT().CheckHabit(gomock.Any(), habitID, userID, date, "").Return(nil)
+			},
+			Body: `{}`,
+		},
+		{
+			Desc:         "invalid date",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {},
+			Body:         `{"date": "01.01.2025"}`,
+		},
+		{
+			Desc:         "timestamp instead of date",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {},
+			Body:         `{"date": "2025-01-01T10:00:00Z"}`,
+		},
+		{
+			Desc:         "already checked",
+			ExpectedCode: http.StatusConflict,
+			MockPrepFunc: func() {
+				cService.EXPECT().CheckHabit(gomock.Any(), habitID, userID, date, "").Return(errorvalues.ErrCheckExist)
+			},
+			Body: `{"date": "2025-01-01"}`,
+		},
+		{
+			Desc:         "wrong owner",
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				cService.EXPECT().CheckHabit(gomock.Any(), habitID, userID, date, "").Return(errorvalues.ErrWrongOwner)
+			},
+			Body: `{"date": "2025-01-01"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/habits/"+habitID.String()+"/checks", strings.NewReader(tc.Body))
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			r.SetPathValue("id", habitID.String())
+			serv.CheckHabit(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			if tc.ExpectedCode == http.StatusBadRequest {
+				var resp httputil.ErrorResponse
+				require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, "date must be YYYY-MM-DD", resp.Message)
+			}
+		})
+	}
+}
+
+func TestCanCheckHabit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cService := mocks.NewMockHabitChecksServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		ChecksService: cService,
+	})
+	habitID := uuid.New()
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		Desc             string
+		Query            string
+		ExpectedCode     int
+		ExpectedResponse api.CanCheckResponse
+		MockPrepFunc     func()
+	}{
+		{
+			Desc:             "allowed",
+			Query:            "?date=2025-01-01",
+			ExpectedCode:     http.StatusOK,
+			ExpectedResponse: api.CanCheckResponse{Allowed: true},
+			MockPrepFunc: func() {
+				cService.EXPECT().CanCheck(gomock.Any(), habitID, userID, date).Return(true, "", nil)
+			},
+		},
+		{
+			Desc:             "rejected for today",
+			ExpectedCode:     http.StatusOK,
+			ExpectedResponse: api.CanCheckResponse{Reason: service.CheckRejectedExists},
+			MockPrepFunc: func() {
+				cService.EXPECT().Today(gomock.Any(), userID).Return(date, nil)
+				cService.EXPECT().CanCheck(gomock.Any(), habitID, userID, date).Return(false, service.CheckRejectedExists, nil)
+			},
+		},
+		{
+			Desc:         "invalid date",
+			Query:        "?date=01.01.2025",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {},
+		},
+		{
+			Desc:         "wrong owner",
+			Query:        "?date=2025-01-01",
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				cService.EXPECT().CanCheck(gomock.Any(), habitID, userID, date).Return(false, "", errorvalues.ErrWrongOwner)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/habits/"+habitID.String()+"/checks/can"+tc.Query, nil)
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			r.SetPathValue("id", habitID.String())
+			serv.CanCheckHabit(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			if tc.ExpectedCode == http.StatusOK {
+				var resp api.CanCheckResponse
+				require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, tc.ExpectedResponse, resp)
+			}
+		})
+	}
+}
+
+func TestGetHabitChecks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cService := mocks.NewMockHabitChecksServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		ChecksService: cService,
+	})
+	habitID := uuid.New()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC)
+	skip := entity.HabitCheck{ID: 1, HabitID: habitID, CheckDate: from.AddDate(0, 0, 2), Status: entity.CheckStatusSkipped,
+		CreatedAt: time.Date(2025, 1, 3, 12, 0, 0, 123456789, time.FixedZone("MSK", 3*60*60))}
+	testCases := []struct {
+		Desc          string
+		Query         string
+		ExpectedCode  int
+		ExpectedCount int
+		// Part of response body, checked if set
+		ExpectedBody string
+		MockPrepFunc func()
+	}{
+		{
+			Desc:          "skips only",
+			Query:         "?from=2025-01-01&to=2025-01-30&status=skipped",
+			ExpectedCode:  http.StatusOK,
+			ExpectedCount: 1,
+			// In UTC without fractional seconds
+			ExpectedBody: `"created_at":"2025-01-03T09:00:00Z"`,
+			MockPrepFunc: func() {
+				cService.EXPECT().GetHabitChecks(gomock.Any(), habitID, userID, from, to, entity.CheckOrder(""), entity.CheckStatusSkipped).
+					Return([]entity.HabitCheck{skip}, nil)
+			},
+		},
+		{
+			Desc:         "last 30 days by default",
+			ExpectedCode: http.StatusOK,
+			MockPrepFunc: func() {
+				cService.EXPECT().Today(gomock.Any(), userID).Return(to, nil)
+				cService.EXPECT().GetHabitChecks(gomock.Any(), habitID, userID, from, to, entity.CheckOrder(""), entity.CheckStatus("")).
+					Return([]entity.HabitCheck{}, nil)
+			},
+		},
+		{
+			Desc:         "unknown status",
+			Query:        "?from=2025-01-01&to=2025-01-30&status=multiple",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {
+				cService.EXPECT().GetHabitChecks(gomock.Any(), habitID, userID, from, to, entity.CheckOrder(""), entity.CheckStatus("multiple")).
+					Return(nil, errors.Join(errorvalues.ErrValidation, errors.New("invalid status")))
+			},
+		},
+		{
+			Desc:         "invalid date",
+			Query:        "?from=01.01.2025&to=2025-01-30",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {},
+		},
+		{
+			Desc:         "too long period",
+			Query:        "?from=2024-01-01&to=2025-01-30",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {},
+		},
+		{
+			Desc:         "wrong owner",
+			Query:        "?from=2025-01-01&to=2025-01-30&status=checked",
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				cService.EXPECT().GetHabitChecks(gomock.Any(), habitID, userID, from, to, entity.CheckOrder(""), entity.CheckStatusChecked).
+					Return(nil, errorvalues.ErrWrongOwner)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/habits/"+habitID.String()+"/checks"+tc.Query, nil)
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			r.SetPathValue("id", habitID.String())
+			serv.GetHabitChecks(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			if tc.ExpectedBody != "" {
+				assert.Contains(t, rr.Body.String(), tc.ExpectedBody)
+			}
+			if tc.ExpectedCode == http.StatusOK {
+				var resp api.HabitChecksResponse
+				require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+				assert.Len(t, resp.Checks, tc.ExpectedCount)
+				assert.Equal(t, "2025-01-01", resp.From)
+			}
+		})
+	}
+}
+
+func TestUncheckHabit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cService := mocks.NewMockHabitChecksServiceI(ctrl)
+	serv := api.New(&api.ServicesList{
+		ChecksService: cService,
+	})
+	habitID := uuid.New()
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		Desc         string
+		ExpectedCode int
+		MockPrepFunc func()
+		Date         string
+	}{
+		{
+			Desc:         "successful",
+			ExpectedCode: http.StatusNoContent,
+			MockPrepFunc: func() {
+				cService.EXPECT().UncheckHabit(gomock.Any(), habitID, userID, date).Return(nil)
+			},
+			Date: "2025-01-01",
+		},
+		{
+			Desc:         "malformed date",
+			ExpectedCode: http.StatusBadRequest,
+			MockPrepFunc: func() {},
+			Date:         "2025-13-01",
+		},
+		{
+			Desc:         "no check",
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				cService.EXPECT().UncheckHabit(gomock.Any(), habitID, userID, date).Return(errorvalues.ErrCheckNotFound)
+			},
+			Date: "2025-01-01",
+		},
+		{
+			Desc:         "wrong owner",
+			ExpectedCode: http.StatusNotFound,
+			MockPrepFunc: func() {
+				cService.EXPECT().UncheckHabit(gomock.Any(), habitID, userID, date).Return(errorvalues.ErrWrongOwner)
+			},
+			Date: "2025-01-01",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/api/habits/"+habitID.String()+"/checks/"+tc.Date, nil)
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			r.SetPathValue("id", habitID.String())
+			r.SetPathValue("date", tc.Date)
+			serv.UncheckHabit(rr, r)
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+		})
+	}
+}
+
+func TestDebugErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	habitID := uuid.New()
+	testCases := []struct {
+		Desc            string
+		DebugErrors     bool
+		ExpectedDetails string
+	}{
+		{
+			Desc:            "debug errors on",
+			DebugErrors:     true,
+			ExpectedDetails: "service error",
+		},
+		{
+			Desc:            "debug errors off",
+			DebugErrors:     false,
+			ExpectedDetails: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			serv := api.New(&api.ServicesList{
+				HabitsService: hService,
+			}, api.WithDebugErrors(tc.DebugErrors))
+			hService.EXPECT().DeleteHabit(gomock.Any(), habitID, userID).Return(errors.New("service error"))
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/api/habits/"+habitID.String(), nil)
+			r = r.WithContext(context.WithValue(r.Context(), "User-ID", userID))
+			r.SetPathValue("id", habitID.String())
+			serv.DeleteHabit(rr, r)
+			assert.Equal(t, http.StatusInternalServerError, rr.Result().StatusCode)
+			var resp httputil.ErrorResponse
+			require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+			assert.Equal(t, tc.ExpectedDetails, resp.Details)
+		})
+	}
+}
+
+func TestAppErrorMapping(t *testing.T) {
+	testCases := []struct {
+		Err     error
+		Status  int
+		Code    string
+		Message string
+	}{
+		{errorvalues.ErrValidation, http.StatusBadRequest, "validation_failed", errorvalues.ErrValidation.Error()},
+		{errorvalues.ErrUserExists, http.StatusConflict, "user_exists", errorvalues.ErrUserExists.Error()},
+		{errorvalues.ErrNameTaken, http.StatusConflict, "name_taken", errorvalues.ErrNameTaken.Error()},
+		{errorvalues.ErrUserNotFound, http.StatusNotFound, "user_not_found", errorvalues.ErrUserNotFound.Error()},
+		{errorvalues.ErrOwnerNotFound, http.StatusNotFound, "user_not_found", errorvalues.ErrOwnerNotFound.Error()},
+		{errorvalues.ErrWrongCredentials, http.StatusForbidden, "wrong_credentials", errorvalues.ErrWrongCredentials.Error()},
+		{errorvalues.ErrInvalidToken, http.StatusUnauthorized, "invalid_token", errorvalues.ErrInvalidToken.Error()},
+		{errorvalues.ErrUserHasHabit, http.StatusConflict, "habit_exists", errorvalues.ErrUserHasHabit.Error()},
+		{errorvalues.ErrHabitNotFound, http.StatusNotFound, "habit_not_found", errorvalues.ErrHabitNotFound.Error()},
+		// Masked as unexist habit
+		{errorvalues.ErrWrongOwner, http.StatusNotFound, "habit_not_found", errorvalues.ErrHabitNotFound.Error()},
+		{errorvalues.ErrCheckExist, http.StatusConflict, "check_exists", errorvalues.ErrCheckExist.Error()},
+		{errorvalues.ErrCheckNotFound, http.StatusNotFound, "check_not_found", errorvalues.ErrCheckNotFound.Error()},
+		{errorvalues.ErrCheckDateNotAllowed, http.StatusBadRequest, "check_date_not_allowed", errorvalues.ErrCheckDateNotAllowed.Error()},
+		{errorvalues.ErrGroupNotFound, http.StatusNotFound, "group_not_found", errorvalues.ErrGroupNotFound.Error()},
+		{errorvalues.ErrHabitPaused, http.StatusConflict, "habit_paused", errorvalues.ErrHabitPaused.Error()},
+		{errorvalues.ErrHabitNotPaused, http.StatusConflict, "habit_not_paused", errorvalues.ErrHabitNotPaused.Error()},
+	}
+	decode := func(t *testing.T, rr *httptest.ResponseRecorder) httputil.ErrorResponse {
+		var resp httputil.ErrorResponse
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		return resp
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Code, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			// Sentinel is found through wrapping
+			httputil.WriteAppError(rr, fmt.Errorf("service error: %w", tc.Err))
+			assert.Equal(t, tc.Status, rr.Result().StatusCode)
+			resp := decode(t, rr)
+			assert.Equal(t, tc.Status, resp.Code)
+			assert.Equal(t, tc.Code, resp.ErrorCode)
+			assert.Equal(t, tc.Message, resp.Message)
+		})
+	}
+	t.Run("validation details provided", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		httputil.WriteAppError(rr, errors.Join(errorvalues.ErrValidation, errors.New("title is required")))
+		resp := decode(t, rr)
+		assert.Equal(t, errorvalues.ErrValidation.Error(), resp.Message)
+		assert.Contains(t, resp.Details, "title is required")
+	})
+	t.Run("other details hidden", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		httputil.WriteAppError(rr, fmt.Errorf("habits repository error: %w", errorvalues.ErrHabitNotFound))
+		resp := decode(t, rr)
+		assert.Equal(t, errorvalues.ErrHabitNotFound.Error(), resp.Message)
+		assert.Empty(t, resp.Details)
+	})
+	t.Run("other details provided in debug", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		httputil.WriteAppErrorWithDetails(rr, fmt.Errorf("habits repository error: %w", errorvalues.ErrHabitNotFound))
+		resp := decode(t, rr)
+		assert.Equal(t, "habit_not_found", resp.ErrorCode)
+		assert.Contains(t, resp.Details, "habits repository error")
+	})
+	t.Run("explicit app error prevails", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		httputil.WriteAppError(rr, &httputil.AppError{Err: errorvalues.ErrWrongCredentials, Status: http.StatusUnauthorized, Code: "wrong_credentials"})
+		assert.Equal(t, http.StatusUnauthorized, rr.Result().StatusCode)
+	})
+	t.Run("unknown error", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		httputil.WriteAppError(rr, errors.New("db error"))
+		assert.Equal(t, http.StatusInternalServerError, rr.Result().StatusCode)
+		resp := decode(t, rr)
+		assert.Empty(t, resp.ErrorCode)
+		assert.Empty(t, resp.Details)
+	})
+}
+
+func TestVersion(t *testing.T) {
+	serv := api.New(&api.ServicesList{})
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/version", nil)
+	serv.Version(rr, r)
+	assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+	var resp map[string]string
+	require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+	// Not injected in tests, so defaults expected
+	assert.Equal(t, map[string]string{
+		"version":    "dev",
+		"commit":     "unknown",
+		"build_time": "unknown",
+	}, resp)
+}
+
+func TestMetricsRoute(t *testing.T) {
+	t.Run("public by default", func(t *testing.T) {
+		serv := api.New(&api.ServicesList{})
+		rr := httptest.NewRecorder()
+		serv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+	})
+	t.Run("separate address", func(t *testing.T) {
+		serv := api.New(&api.ServicesList{}, api.WithMetricsAddress("127.0.0.1:0"))
+		rr := httptest.NewRecorder()
+		serv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusNotFound, rr.Result().StatusCode)
+	})
+}
+
+func TestListErrorCodes(t *testing.T) {
+	serv := api.New(&api.ServicesList{})
+	rr := httptest.NewRecorder()
+	serv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/errors", nil))
+	assert.Equal(t, http.StatusOK, rr.Result().StatusCode)
+	var resp api.ErrorsCatalogResponse
+	require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+	codes := make(map[string]api.ErrorCodeInfo)
+	for _, info := range resp.Errors {
+		_, repeated := codes[info.Code]
+		assert.False(t, repeated, "code %s listed twice", info.Code)
+		codes[info.Code] = info
+	}
+	assert.Equal(t, api.ErrorCodeInfo{
+		Code:    "habit_not_found",
+		Status:  http.StatusNotFound,
+		Message: errorvalues.ErrHabitNotFound.Error(),
+	}, codes["habit_not_found"])
+	assert.Contains(t, codes, "check_exists")
+	assert.Contains(t, codes, "validation_failed")
+}
+
+func TestErrorResponseShape(t *testing.T) {
+	serv := api.New(&api.ServicesList{})
+	strict := sonic.Config{DisallowUnknownFields: true}.Froze()
+	testCases := []struct {
+		Desc         string
+		Method       string
+		Path         string
+		ExpectedCode int
+	}{
+		{Desc: "unknown path", Method: http.MethodGet, Path: "/api/v1/unknown", ExpectedCode: http.StatusNotFound},
+		{Desc: "wrong method", Method: http.MethodPost, Path: "/api/v1/version", ExpectedCode: http.StatusMethodNotAllowed},
+		{Desc: "no token", Method: http.MethodGet, Path: "/api/v1/habits", ExpectedCode: http.StatusUnauthorized},
+		{Desc: "invalid body", Method: http.MethodPost, Path: "/api/v1/auth/login", ExpectedCode: http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			serv.Handler().ServeHTTP(rr, httptest.NewRequest(tc.Method, tc.Path, strings.NewReader("{")))
+			require.Equal(t, tc.ExpectedCode, rr.Code)
+			var resp httputil.ErrorResponse
+			require.NoError(t, strict.Unmarshal(rr.Body.Bytes(), &resp))
+			assert.Equal(t, tc.ExpectedCode, resp.Code)
+			assert.NotEmpty(t, resp.Message)
+		})
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	serv := api.New(&api.ServicesList{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+	t.Run("slow handler cut off", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		start := time.Now()
+		serv.TimeoutMiddleware(50*time.Millisecond)(slow).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		var resp httputil.ErrorResponse
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		assert.Equal(t, "request timed out", resp.Message)
+	})
+	t.Run("fast handler passes", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		fast := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httputil.WriteJSONResponse(w, http.StatusOK, map[string]string{"status": "ok"})
+		})
+		serv.TimeoutMiddleware(time.Second)(fast).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestDeadlineMiddleware(t *testing.T) {
+	serv := api.New(&api.ServicesList{})
+	t.Run("context bounded, response not buffered", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		streamed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, ok := r.Context().Deadline()
+			assert.True(t, ok)
+			w.WriteHeader(http.StatusOK)
+			// Written straight to client, TimeoutHandler's writer doesn't support flushing
+			_, flushable := w.(http.Flusher)
+			assert.True(t, flushable)
+		})
+		serv.DeadlineMiddleware(time.Second)(streamed).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestCORSMaxAge(t *testing.T) {
+	serv := api.New(&api.ServicesList{}, api.WithCORSOrigins("https://app.example.com"), api.WithCORSMaxAge(5*time.Minute))
+	handler := serv.CORSMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Run("preflight", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodOptions, "/api/v1/habits", nil)
+		r.Header.Set("Origin", "https://app.example.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		handler.ServeHTTP(rr, r)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		assert.Equal(t, "300", rr.Header().Get("Access-Control-Max-Age"))
+		assert.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+	t.Run("actual request", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/habits", nil)
+		r.Header.Set("Origin", "https://app.example.com")
+		handler.ServeHTTP(rr, r)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("Access-Control-Max-Age"))
+		assert.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+	t.Run("default on preflight", func(t *testing.T) {
+		serv := api.New(&api.ServicesList{}, api.WithCORSOrigins("*"))
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodOptions, "/api/v1/habits", nil)
+		r.Header.Set("Origin", "https://other.example.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		serv.CORSMiddleware(http.NotFoundHandler()).ServeHTTP(rr, r)
+		assert.Equal(t, "600", rr.Header().Get("Access-Control-Max-Age"))
+	})
+	t.Run("other origin", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodOptions, "/api/v1/habits", nil)
+		r.Header.Set("Origin", "https://evil.example.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		handler.ServeHTTP(rr, r)
+		assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rr.Header().Get("Access-Control-Max-Age"))
+	})
+}
+
+func TestHTTPSRedirectMiddleware(t *testing.T) {
+	serv := api.New(&api.ServicesList{}, api.WithHTTPSRedirect(true), api.WithHSTS(365*24*time.Hour))
+	handler := serv.Handler()
+	t.Run("get redirected", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/api/v1/version?x=1", nil))
+		assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+		assert.Equal(t, "https://example.com/api/v1/version?x=1", rr.Header().Get("Location"))
+		// HSTS is ignored over plain HTTP, so isn't sent
+		assert.Empty(t, rr.Header().Get("Strict-Transport-Security"))
+	})
+	t.Run("post keeps method", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "http://example.com/api/v1/auth/login", nil))
+		assert.Equal(t, http.StatusPermanentRedirect, rr.Code)
+		assert.Equal(t, "https://example.com/api/v1/auth/login", rr.Header().Get("Location"))
+	})
+	t.Run("https behind proxy", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "http://example.com/api/v1/version", nil)
+		r.Header.Set("X-Forwarded-Proto", "https")
+		handler.ServeHTTP(rr, r)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "max-age=31536000; includeSubDomains", rr.Header().Get("Strict-Transport-Security"))
+	})
+	t.Run("https", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "https://example.com/api/v1/version", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotEmpty(t, rr.Header().Get("Strict-Transport-Security"))
+	})
+	t.Run("probe not redirected", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/metrics", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+	t.Run("off by default", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		api.New(&api.ServicesList{}).Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/api/v1/version", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("Strict-Transport-Security"))
+	})
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	handler := api.New(&api.ServicesList{}).Handler()
+	for _, path := range []string{"/api/v1/version", "/api/v1/unknown"} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"), path)
+		assert.Equal(t, "DENY", rr.Header().Get("X-Frame-Options"), path)
+	}
+}
+
+func TestDrainingMiddleware(t *testing.T) {
+	serv := api.New(&api.ServicesList{})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := serv.DrainingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	inFlight := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(inFlight, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+	<-started
+	serv.Drain()
+
+	t.Run("new request rejected", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Equal(t, "5", rr.Header().Get("Retry-After"))
+	})
+	t.Run("in-flight request finished", func(t *testing.T) {
+		close(release)
+		<-done
+		assert.Equal(t, http.StatusOK, inFlight.Code)
+	})
+}
+
+func TestInFlightLimitMiddleware(t *testing.T) {
+	serv := api.New(&api.ServicesList{}, api.WithMaxInFlight(1))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := serv.InFlightLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	inFlight := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(inFlight, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+	<-started
+
+	t.Run("rejected while saturated", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+	})
+	t.Run("served once slot is free", func(t *testing.T) {
+		close(release)
+		<-done
+		assert.Equal(t, http.StatusOK, inFlight.Code)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestUserRateLimitMiddleware(t *testing.T) {
+	serv := api.New(&api.ServicesList{}, api.WithUserRateLimit(1, 3))
+	handler := serv.UserRateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	check := func(method string, uid uuid.UUID) int {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(method, "/api/v1/habits/"+uuid.NewString()+"/checks", nil)
+		r = r.WithContext(context.WithValue(r.Context(), "User-ID", uid))
+		handler.ServeHTTP(rr, r)
+		return rr.Code
+	}
+	firstUser, secondUser := uuid.New(), uuid.New()
+	t.Run("burst exhausted", func(t *testing.T) {
+		for range 3 {
+			assert.Equal(t, http.StatusCreated, check(http.MethodPost, firstUser))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, check(http.MethodPost, firstUser))
+	})
+	t.Run("reads are not limited", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, check(http.MethodGet, firstUser))
+	})
+	t.Run("another user unaffected", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, check(http.MethodPost, secondUser))
+	})
+}
+
+func TestListUsers(t *testing.T) {
+	mock := UserServiceMock{}
+	mock.ChangeState(true)
+	admin := uuid.New()
+	serv := api.New(&api.ServicesList{UserService: &mock}, api.WithAdmins(admin))
+	handler := serv.AdminMiddleware(http.HandlerFunc(serv.ListUsers))
+	list := func(uid uuid.UUID) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users?q=test&sort=-name", nil)
+		r = r.WithContext(context.WithValue(r.Context(), "User-ID", uid))
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+	t.Run("admin", func(t *testing.T) {
+		rr := list(admin)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "password")
+		var resp api.ListUsersResponse
+		require.NoError(t, sonic.ConfigDefault.Unmarshal(rr.Body.Bytes(), &resp))
+		require.NotNil(t, resp.Total)
+		assert.Equal(t, 1, *resp.Total)
+		require.Len(t, resp.Items, 1)
+		assert.Equal(t, username, resp.Items[0].Name)
+		assert.Contains(t, rr.Body.String(), `"last_login_at":"2025-01-01T09:00:00Z"`)
+	})
+	t.Run("not admin", func(t *testing.T) {
+		rr := list(uuid.New())
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestServerStats(t *testing.T) {
+	admin := uuid.New()
+	serv := api.New(&api.ServicesList{}, api.WithAdmins(admin))
+	router := serv.Handler()
+	for _, path := range []string{"/api/v1/version", "/api/v1/version", "/api/v1/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	handler := serv.AdminMiddleware(http.HandlerFunc(serv.GetServerStats))
+	stats := func(uid uuid.UUID) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/stats/server", nil)
+		r = r.WithContext(context.WithValue(r.Context(), "User-ID", uid))
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+	t.Run("admin", func(t *testing.T) {
+		rr := stats(admin)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp api.ServerStatsResponse
+		require.NoError(t, sonic.ConfigDefault.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.EqualValues(t, 3, resp.TotalRequests)
+		assert.Equal(t, map[string]int64{"2xx": 2, "4xx": 1}, resp.ByStatusClass)
+		assert.False(t, time.Time(resp.Since).IsZero())
+		assert.Regexp(t, `"since":"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z"`, rr.Body.String())
+	})
+	t.Run("counters increment", func(t *testing.T) {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+		var resp api.ServerStatsResponse
+		require.NoError(t, sonic.ConfigDefault.Unmarshal(stats(admin).Body.Bytes(), &resp))
+		assert.EqualValues(t, 4, resp.TotalRequests)
+		assert.EqualValues(t, 3, resp.ByStatusClass["2xx"])
+	})
+	t.Run("not admin", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, stats(uuid.New()).Code)
+	})
+}
+
+func TestDisableUser(t *testing.T) {
+	mock := UserServiceMock{}
+	admin := uuid.New()
+	serv := api.New(&api.ServicesList{UserService: &mock}, api.WithAdmins(admin))
+	handler := serv.AdminMiddleware(http.HandlerFunc(serv.DisableUser))
+	disable := func(caller uuid.UUID, id string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+id+"/disable", nil)
+		r.SetPathValue("id", id)
+		r = r.WithContext(context.WithValue(r.Context(), "User-ID", caller))
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+	t.Run("disabled", func(t *testing.T) {
+		mock.ChangeState(true)
+		assert.Equal(t, http.StatusNoContent, disable(admin, uid.String()).Code)
+	})
+	t.Run("not found", func(t *testing.T) {
+		mock.ChangeState(false)
+		assert.Equal(t, http.StatusNotFound, disable(admin, uid.String()).Code)
+	})
+	t.Run("invalid id", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, disable(admin, "not-uuid").Code)
+	})
+	t.Run("not admin", func(t *testing.T) {
+		mock.ChangeState(true)
+		assert.Equal(t, http.StatusForbidden, disable(uuid.New(), uid.String()).Code)
+	})
+}
+
+func TestAuthMiddlewareDisabledAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	uService := mocks.NewMockUserServiceI(ctrl)
+	jwtService := jwtservice.New("secret")
+	serv := api.New(&api.ServicesList{
+		UserService: uService,
+		JwtService:  jwtService,
+	})
+	user := &entity.User{ID: userID, Name: "test_name"}
+	// Token is issued before account gets disabled
+	token, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	uService.EXPECT().GetByID(gomock.Any(), userID).Return(&entity.User{ID: userID, Name: "test_name", Disabled: true}, nil)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/endpoint", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	serv.AuthMiddleware(http.HandlerFunc(testHandler)).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	var resp httputil.ErrorResponse
+	require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "account_disabled", resp.ErrorCode)
+}
+
+func TestRejectGetBodyMiddleware(t *testing.T) {
+	testCases := []struct {
+		Desc         string
+		Enabled      bool
+		Method       string
+		Body         io.Reader
+		ExpectedCode int
+	}{
+		{Desc: "GET with body rejected", Enabled: true, Method: http.MethodGet, Body: strings.NewReader(`{"a":1}`), ExpectedCode: http.StatusBadRequest},
+		{Desc: "GET without body passed", Enabled: true, Method: http.MethodGet, ExpectedCode: http.StatusOK},
+		{Desc: "disabled", Enabled: false, Method: http.MethodGet, Body: strings.NewReader(`{"a":1}`), ExpectedCode: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			serv := api.New(&api.ServicesList{}, api.WithRejectGetBodies(tc.Enabled))
+			rr := httptest.NewRecorder()
+			serv.Handler().ServeHTTP(rr, httptest.NewRequest(tc.Method, "/api/v1/version", tc.Body))
+			assert.Equal(t, tc.ExpectedCode, rr.Code)
+		})
+	}
+}
+
+func TestBodyLoggingMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	serv := api.New(&api.ServicesList{}, api.WithLogger(logging.New(&logs, "debug", logging.FormatJSON)))
+	handler := serv.SettingUpLoggerMiddleware(serv.BodyLoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req api.LoginRequest
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(r.Body).Decode(&req))
+		httputil.WriteJSONResponse(w, http.StatusOK, map[string]string{"name": req.Name, "token": "xxxx.yyyy.zzzz"})
+	})))
+	body := `{"name":"test_name","password":"secret_passw0rd"}`
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "xxxx.yyyy.zzzz")
+	assert.Contains(t, logs.String(), "test_name")
+	assert.Contains(t, logs.String(), "[REDACTED]")
+	assert.NotContains(t, logs.String(), "secret_passw0rd")
+	assert.NotContains(t, logs.String(), "xxxx.yyyy.zzzz")
+	t.Run("malformed body", func(t *testing.T) {
+		logs.Reset()
+		malformed := serv.SettingUpLoggerMiddleware(serv.BodyLoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			httputil.WriteJSONResponse(w, http.StatusBadRequest, nil)
+		})))
+		body := `{"name":"test_name","password":"secret_passw0rd",}`
+		malformed.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(body)))
+		assert.Contains(t, logs.String(), "<unparseable body>")
+		assert.NotContains(t, logs.String(), "secret_passw0rd")
+	})
+}
+
+func TestRecoverMiddlewareLogsRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	uService := mocks.NewMockUserServiceI(ctrl)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	jwtService := jwtservice.New("secret")
+	var logs bytes.Buffer
+	serv := api.New(&api.ServicesList{
+		UserService:   uService,
+		HabitsService: hService,
+		JwtService:    jwtService,
+	}, api.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	user := &entity.User{ID: userID, Name: "test_name"}
+	token, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	uService.EXPECT().GetByID(gomock.Any(), userID).Return(user, nil)
+	hService.EXPECT().CreateHabit(gomock.Any(), userID, gomock.Any()).
+		DoAndReturn(func(context.Context, uuid.UUID, service.CreateHabitRequest) (*entity.Habit, error) {
+			panic("unexpected nil habit")
+		})
+
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/habits/", strings.NewReader(`{"title":"habit"}`))
+	r.Header.Set("Authorization", "Bearer "+token)
+	serv.Handler().ServeHTTP(rr, r)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	var entry map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if strings.Contains(line, "handler panicked") {
+			require.NoError(t, sonic.ConfigDefault.UnmarshalFromString(line, &entry))
+		}
+	}
+	require.NotNil(t, entry, "panic isn't logged")
+	// Route "/" of habits subrouter, chi reports pattern without trailing slash
+	assert.Equal(t, "/api/v1/habits", entry["route"])
+	assert.Equal(t, "unexpected nil habit", entry["panic"])
+	assert.Equal(t, userID.String(), entry["uid"])
+	assert.Equal(t, rr.Header().Get("X-Request-ID"), entry["request_id"])
+}
+
+func TestShutdownCleanupError(t *testing.T) {
+	var logs bytes.Buffer
+	serv := api.New(&api.ServicesList{}, api.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	cleanup.Register(&cleanup.Job{
+		Name: "failing test job",
+		F: func() error {
+			return errors.New("test cleanup error")
+		},
+	})
+	err := serv.Shutdown(context.Background())
+	assert.ErrorContains(t, err, "failing test job: test cleanup error")
+	assert.Contains(t, logs.String(), `"msg":"cleanup job failed"`)
+	assert.Contains(t, logs.String(), `"failed":1`)
+}
+
+func TestConfiguredLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	serv := api.New(&api.ServicesList{}, api.WithLogger(logging.New(&buf, "info", logging.FormatJSON)))
+	handler := serv.SettingUpLoggerMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := api.GetLoggerFromCtx(r.Context())
+		logger.Debug("debug message")
+		logger.Info("info message")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NotContains(t, buf.String(), "debug message")
+	assert.Contains(t, buf.String(), `"msg":"info message"`)
+}
+
+func TestTraceContextMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	serv := api.New(&api.ServicesList{}, api.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	handler := serv.RequestIDMiddleware(serv.TraceContextMiddleware(serv.SettingUpLoggerMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			api.GetLoggerFromCtx(r.Context()).Info("handled")
+		}),
+	)))
+	t.Run("inbound traceparent", func(t *testing.T) {
+		logs.Reset()
+		traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+		handler.ServeHTTP(rr, r)
+		var entry map[string]any
+		require.NoError(t, sonic.ConfigDefault.Unmarshal(logs.Bytes(), &entry))
+		assert.Equal(t, traceID, entry["trace_id"])
+		assert.NotEmpty(t, rr.Header().Get("X-Request-ID"))
+		parts := strings.Split(rr.Header().Get("traceparent"), "-")
+		require.Len(t, parts, 4)
+		assert.Equal(t, traceID, parts[1])
+		// Span of this request replaces parent one
+		assert.NotEqual(t, "00f067aa0ba902b7", parts[2])
+	})
+	t.Run("invalid traceparent replaced", func(t *testing.T) {
+		logs.Reset()
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+		handler.ServeHTTP(rr, r)
+		var entry map[string]any
+		require.NoError(t, sonic.ConfigDefault.Unmarshal(logs.Bytes(), &entry))
+		traceID, _ := entry["trace_id"].(string)
+		assert.Len(t, traceID, 32)
+		assert.NotEqual(t, "00000000000000000000000000000000", traceID)
+	})
+}
+
+// Generates ids "generated-1", "generated-2" etc.
+type sequentialIDs struct {
+	last int
+}
+
+func (g *sequentialIDs) NewID() string {
+	g.last++
+	return "generated-" + strconv.Itoa(g.last)
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	amznTraceID := "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+	testCases := []struct {
+		Desc      string
+		Inherit   bool
+		RequestID string
+		AmznTrace string
+		Expected  string
+	}{
+		{Desc: "generated", Expected: "generated-1"},
+		{Desc: "inbound ignored by default", RequestID: "proxy-42", AmznTrace: amznTraceID, Expected: "generated-1"},
+		{Desc: "inherited", Inherit: true, RequestID: "proxy-42", Expected: "proxy-42"},
+		{Desc: "request id preferred", Inherit: true, RequestID: "proxy-42", AmznTrace: amznTraceID, Expected: "proxy-42"},
+		{Desc: "amzn trace root", Inherit: true, AmznTrace: amznTraceID, Expected: "1-5759e988-bd862e3fe1be46a994272793"},
+		{Desc: "invalid request id", Inherit: true, RequestID: "id with spaces", Expected: "generated-1"},
+		{Desc: "invalid amzn trace", Inherit: true, AmznTrace: "Root=1-xyz;Sampled=1", Expected: "generated-1"},
+		{Desc: "nothing to inherit", Inherit: true, Expected: "generated-1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			var logs bytes.Buffer
+			serv := api.New(&api.ServicesList{}, api.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+				api.WithRequestIDGenerator(&sequentialIDs{}), api.WithInheritedRequestID(tc.Inherit))
+			handler := serv.RequestIDMiddleware(serv.SettingUpLoggerMiddleware(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					api.GetLoggerFromCtx(r.Context()).Info("handled")
+				}),
+			))
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.RequestID != "" {
+				r.Header.Set("X-Request-ID", tc.RequestID)
+			}
+			if tc.AmznTrace != "" {
+				r.Header.Set("X-Amzn-Trace-Id", tc.AmznTrace)
+			}
+			handler.ServeHTTP(rr, r)
+			assert.Equal(t, tc.Expected, rr.Header().Get("X-Request-ID"))
+			var entry map[string]any
+			require.NoError(t, sonic.ConfigDefault.Unmarshal(logs.Bytes(), &entry))
+			assert.Equal(t, tc.Expected, entry["request_id"])
+		})
+	}
+	t.Run("generated per request", func(t *testing.T) {
+		serv := api.New(&api.ServicesList{}, api.WithRequestIDGenerator(&sequentialIDs{}))
+		handler := serv.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		for _, expected := range []string{"generated-1", "generated-2"} {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, expected, rr.Header().Get("X-Request-ID"))
+		}
+	})
+}
+
+func TestCorrelationIDLogging(t *testing.T) {
+	var logs bytes.Buffer
+	serv := api.New(&api.ServicesList{}, api.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	handler := serv.RequestIDMiddleware(serv.SettingUpLoggerMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			api.GetLoggerFromCtx(r.Context()).Info("handled")
+		}),
+	))
+	testCases := []struct {
+		Desc     string
+		Header   string
+		Expected any
+	}{
+		{Desc: "supplied", Header: "client-7f3a:42", Expected: "client-7f3a:42"},
+		{Desc: "absent", Header: "", Expected: nil},
+		{Desc: "too long", Header: strings.Repeat("a", 129), Expected: nil},
+		{Desc: "forged log line", Header: "id\n{\"level\":\"ERROR\"}", Expected: nil},
+		{Desc: "spaces", Header: "id with spaces", Expected: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			logs.Reset()
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.Header != "" {
+				r.Header.Set("X-Correlation-ID", tc.Header)
+			}
+			handler.ServeHTTP(rr, r)
+			var entry map[string]any
+			require.NoError(t, sonic.ConfigDefault.Unmarshal(logs.Bytes(), &entry))
+			assert.Equal(t, tc.Expected, entry["correlation_id"])
+			// Server's own id is kept apart
+			assert.Equal(t, rr.Header().Get("X-Request-ID"), entry["request_id"])
+		})
+	}
+}
+
+func TestUnmatchedRoutes(t *testing.T) {
+	handler := api.New(&api.ServicesList{}).Handler()
+	testCases := []struct {
+		Desc         string
+		Method       string
+		Path         string
+		ExpectedCode int
+	}{
+		{
+			Desc:         "wrong method",
+			Method:       http.MethodDelete,
+			Path:         "/api/v1/auth/login",
+			ExpectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			Desc:         "unknown path",
+			Method:       http.MethodGet,
+			Path:         "/api/v1/unknown",
+			ExpectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(tc.Method, tc.Path, nil))
+			assert.Equal(t, tc.ExpectedCode, rr.Result().StatusCode)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			var resp httputil.ErrorResponse
+			require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+			assert.Equal(t, tc.ExpectedCode, resp.Code)
+		})
+	}
+}
+
+func TestHabitsCRUDIntegrational(t *testing.T) {
+	cfg := setupUsersTestDB(t)
+	usersRepo := repository.NewUsersRepo(cfg)
+	habitsRepo := repository.NewHabitsRepo(cfg)
+	usersService := service.NewUserService(usersRepo)
+	habitsService := service.NewHabitsService(habitsRepo)
+	server := api.New(&api.ServicesList{
+		UserService:   usersService,
+		HabitsService: habitsService,
+		JwtService:    jwtservice.New("secret"),
+	})
+	body, err := sonic.ConfigDefault.Marshal(api.RegisterRequest{
+		Name:     username,
+		Password: password,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uid uuid.UUID
+	serverAddr := "localhost:9090"
+	address := "http://" + serverAddr
+	go func() {
+		err = server.Run(serverAddr)
+		require.NoError(t, err)
+	}()
+	time.Sleep(time.Millisecond * 100)
+	t.Run("registering new user", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, address+"/api/v1/auth/register", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		result := make(map[string]any)
+		err = sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		uidStr, ok := result["uid"].(string)
+		if ok {
+			uid = uuid.MustParse(uidStr)
+		} else {
+			t.Error("invalid response body")
+		}
 	})
 	var token string
 	t.Run("logging in", func(t *testing.T) {
@@ -652,7 +2916,9 @@ func TestHabitsCRUDIntegrational(t *testing.T) {
 		var result api.GetHabitsResponse
 		err = sonic.ConfigDefault.NewDecoder(resp.Body).Decode(&result)
 		require.NoError(t, err)
-		assert.Equal(t, 1, len(result.Habits))
+		assert.Equal(t, 1, len(result.Items))
+		require.NotNil(t, result.Total)
+		assert.Equal(t, 1, *result.Total)
 		assert.Equal(t, uid.String(), result.UserID)
 	})
 	t.Run("deleting habit", func(t *testing.T) {
@@ -710,3 +2976,75 @@ func setupUsersTestDB(t *testing.T) *testPGConfig {
 		connStr: connStr,
 	}
 }
+
+func TestExportAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	uService := mocks.NewMockUserServiceI(ctrl)
+	hService := mocks.NewMockHabitsServiceI(ctrl)
+	cService := mocks.NewMockHabitChecksServiceI(ctrl)
+	jwtService := jwtservice.New("secret")
+	serv := api.New(&api.ServicesList{
+		UserService:   uService,
+		HabitsService: hService,
+		ChecksService: cService,
+		JwtService:    jwtService,
+	})
+	user := &entity.User{ID: userID, Name: "test_name", Timezone: "UTC"}
+	token, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	habits := []*entity.Habit{
+		{ID: uuid.New(), UserID: userID, Title: "first"},
+		{ID: uuid.New(), UserID: userID, Title: "second"},
+	}
+	check := &entity.HabitCheck{ID: 1, HabitID: habits[0].ID, CheckDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Status: entity.CheckStatusChecked}
+	export := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/auth/export", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		serv.Handler().ServeHTTP(rr, r)
+		return rr
+	}
+	t.Run("exported", func(t *testing.T) {
+		// Looked up by auth middleware and for profile
+		uService.EXPECT().GetByID(gomock.Any(), userID).Return(user, nil).Times(2)
+		hService.EXPECT().ExportHabits(gomock.Any(), userID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, fn func(*entity.Habit) error) error {
+				for _, h := range habits {
+					if err := fn(h); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		cService.EXPECT().ExportChecks(gomock.Any(), userID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, fn func(*entity.HabitCheck) error) error {
+				return fn(check)
+			})
+		rr := export()
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `attachment; filename="discipline-export.json"`, rr.Header().Get("Content-Disposition"))
+		var resp api.AccountExport
+		require.NoError(t, sonic.ConfigDefault.NewDecoder(rr.Body).Decode(&resp))
+		assert.Equal(t, userID.String(), resp.Profile.UserID)
+		assert.Equal(t, "test_name", resp.Profile.Name)
+		require.Len(t, resp.Habits, 2)
+		assert.Equal(t, habits[1].ID, resp.Habits[1].ID)
+		require.Len(t, resp.Checks, 1)
+		assert.Equal(t, check.HabitID, resp.Checks[0].HabitID)
+		assert.True(t, check.CheckDate.Equal(resp.Checks[0].CheckDate))
+	})
+	t.Run("empty account", func(t *testing.T) {
+		uService.EXPECT().GetByID(gomock.Any(), userID).Return(user, nil).Times(2)
+		hService.EXPECT().ExportHabits(gomock.Any(), userID, gomock.Any()).Return(nil)
+		cService.EXPECT().ExportChecks(gomock.Any(), userID, gomock.Any()).Return(nil)
+		rr := export()
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"profile":{"uid":"`+userID.String()+`","name":"test_name","timezone":"UTC"},"habits":[],"checks":[]}`, rr.Body.String())
+	})
+	t.Run("failed midway", func(t *testing.T) {
+		uService.EXPECT().GetByID(gomock.Any(), userID).Return(user, nil).Times(2)
+		hService.EXPECT().ExportHabits(gomock.Any(), userID, gomock.Any()).Return(errors.New("db down"))
+		// Connection is aborted instead of completing document
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() { export() })
+	})
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/limbo/discipline/pkg/entity"
 )
@@ -10,8 +12,20 @@ type JWTServiceI interface {
 	ParseToken(tokenString string) (*JWTClaims, error)
 }
 
+// Source of applied database schema version, see repository.SchemaInspector
+type SchemaVersionSource interface {
+	SchemaVersion(ctx context.Context) (int64, error)
+}
+
+// Generates request ids, see reqid.New for provided formats. Must be safe for concurrent use
+type IDGenerator interface {
+	NewID() string
+}
+
 type JWTClaims struct {
 	jwt.RegisteredClaims
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
+	// User's token version at issue time, token is revoked once it changes
+	TokenVersion int `json:"tv"`
 }
@@ -1,13 +1,24 @@
 package api
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"io"
 	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/bytedance/sonic"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
 	"github.com/limbo/discipline/pkg/httputil"
@@ -17,24 +28,511 @@ var (
 	requestIDKContextKey = "Request-ID"
 	loggerContextKey     = "Logger"
 	uidContextKey        = "User-ID"
+	traceIDContextKey    = "Trace-ID"
+	spanIDContextKey     = "Span-ID"
+	scopeContextKey      = "Request-Scope"
 )
 
+// Request details learnt by inner middlewares, shared by pointer so outer ones (RecoverMiddleware) see them.
+// Request's context can't carry them back, inner middlewares derive new one.
+type requestScope struct {
+	// Set by AuthMiddleware, uuid.Nil for unauthorized requests
+	uid uuid.UUID
+}
+
+// Stores request id in context and sends it back in X-Request-ID header. Id is generated
+// unless inbound one is inherited (see WithInheritedRequestID).
 func (s *Server) RequestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		reqID := uuid.New()
-		ctx := context.WithValue(r.Context(), requestIDKContextKey, reqID.String())
+		var reqID string
+		if s.inheritRequestID {
+			reqID = inboundRequestID(r.Header)
+		}
+		if reqID == "" {
+			reqID = s.requestIDs.NewID()
+		}
+		ctx := context.WithValue(r.Context(), requestIDKContextKey, reqID)
 		r = r.WithContext(ctx)
+		w.Header().Set("X-Request-ID", reqID)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Returns valid X-Request-ID or root of valid X-Amzn-Trace-Id (e.g. "1-5759e988-bd862e3fe1be46a994272793"),
+// empty string if there are none.
+func inboundRequestID(header http.Header) string {
+	if reqID := header.Get("X-Request-ID"); isCorrelationID(reqID) {
+		return reqID
+	}
+	for _, field := range strings.Split(header.Get("X-Amzn-Trace-Id"), ";") {
+		root, ok := strings.CutPrefix(field, "Root=")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(root, "-")
+		if len(parts) == 3 && parts[0] == "1" && isHex(parts[1], 8) && isHex(parts[2], 24) {
+			return root
+		}
+		return ""
+	}
+	return ""
+}
+
+// Continues W3C trace from incoming traceparent header or starts new one if it's absent or invalid.
+// Trace id and id of span representing this request are stored in context
+// and sent back in traceparent response header.
+func (s *Server) TraceContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID, flags, ok := parseTraceparent(r.Header.Get("traceparent"))
+		if !ok {
+			traceID, flags = randomHex(16), "01"
+		}
+		spanID := randomHex(8)
+		ctx := context.WithValue(r.Context(), traceIDContextKey, traceID)
+		ctx = context.WithValue(ctx, spanIDContextKey, spanID)
+		r = r.WithContext(ctx)
+		w.Header().Set("traceparent", "00-"+traceID+"-"+spanID+"-"+flags)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Methods and headers allowed in cross-origin requests
+const (
+	corsAllowedMethods = "GET, HEAD, POST, PUT, PATCH, DELETE"
+	corsAllowedHeaders = "Authorization, Content-Type, If-Match, If-None-Match, If-Modified-Since, traceparent, X-Correlation-ID"
+)
+
+// Lets browsers make cross-origin requests from allowed origins (see WithCORSOrigins).
+// Preflight requests are answered right away with allowed methods and headers,
+// Access-Control-Max-Age is sent only on them. Requests from other origins pass through
+// without CORS headers, so browsers block their responses.
+func (s *Server) CORSMiddleware(next http.Handler) http.Handler {
+	_, anyOrigin := s.corsOrigins["*"]
+	maxAge := strconv.Itoa(int(s.corsMaxAge.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if _, ok := s.corsOrigins[origin]; !ok && !anyOrigin {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, ETag, Last-Modified, Retry-After")
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			w.Header().Set("Access-Control-Max-Age", maxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Tells if request came over HTTPS, directly or through proxy terminating TLS.
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// Sets headers hardening browsers' handling of responses: content types aren't sniffed
+// and pages can't be framed. Strict-Transport-Security is added to HTTPS responses if enabled (see WithHSTS),
+// browsers ignore it over plain HTTP.
+func (s *Server) SecurityHeadersMiddleware(next http.Handler) http.Handler {
+	hsts := "max-age=" + strconv.Itoa(int(s.hstsMaxAge.Seconds())) + "; includeSubDomains"
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("X-Frame-Options", "DENY")
+		if s.hstsMaxAge > 0 && isSecureRequest(r) {
+			w.Header().Set("Strict-Transport-Security", hsts)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Paths served over plain HTTP even with redirect enabled, probes usually can't follow it
+var httpsRedirectExempt = map[string]struct{}{
+	"/readyz":  {},
+	"/metrics": {},
+}
+
+// Redirects plain HTTP requests to same URL over HTTPS (see WithHTTPSRedirect).
+// GET and HEAD get 301, others get 308 so clients repeat them with same method and body.
+func (s *Server) HTTPSRedirectMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, exempt := httpsRedirectExempt[r.URL.Path]; exempt || isSecureRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		status := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+		GetLoggerFromCtx(r.Context()).Info("redirecting to https")
+		http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), status)
+	})
+}
+
+// Passes response through, keeping its status.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if sw.status == 0 {
+		sw.status = status
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(p []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(p)
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// Counts requests with their status and latency (see GetServerStats).
+// Goes first, so responses of other middlewares (e.g. 503 on draining) are counted too.
+func (s *Server) RequestStatsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			// Nothing written means implicit 200
+			s.requestStats.Observe(cmp.Or(sw.status, http.StatusOK), time.Since(start))
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// Recovers from panic in handler, so it fails only its request: client gets 500 and panic is logged
+// along with matched route pattern (e.g. /api/v1/habits/{id}), request id and uid if request got authorized.
+// Must go after SettingUpLoggerMiddleware. http.ErrAbortHandler is passed on, it's meant to abort response.
+func (s *Server) RecoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := &requestScope{}
+		r = r.WithContext(context.WithValue(r.Context(), scopeContextKey, scope))
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			attrs := []any{
+				slog.Any("panic", rec),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+			}
+			// Routing is done by now, so pattern is complete
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				attrs = append(attrs, slog.String("route", rctx.RoutePattern()))
+			}
+			if scope.uid != uuid.Nil {
+				attrs = append(attrs, slog.String("uid", scope.uid.String()))
+			}
+			attrs = append(attrs, slog.String("stack", string(debug.Stack())))
+			GetLoggerFromCtx(r.Context()).Error("handler panicked", attrs...)
+			s.writeError(w, http.StatusInternalServerError, "internal error", nil)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Seconds clients are told to wait before retrying request rejected while server is draining
+const drainingRetryAfter = "5"
+
+// Rejects requests with 503 and Retry-After once server is draining (see Drain), so clients
+// retry them against another instance instead of getting dropped connection. Requests
+// passed through before that are served as usual.
+func (s *Server) DrainingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.draining.Load() {
+			GetLoggerFromCtx(r.Context()).Warn("rejected request while draining")
+			w.Header().Set("Retry-After", drainingRetryAfter)
+			s.writeError(w, http.StatusServiceUnavailable, "server is shutting down", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Seconds clients are told to wait before retrying request rejected because server is saturated
+const saturatedRetryAfter = "1"
+
+// Bounds count of requests served at once (see WithMaxInFlight). Requests over limit aren't queued,
+// they are rejected with 503 and Retry-After right away, so DB pool isn't exhausted by bursts.
+func (s *Server) InFlightLimitMiddleware(next http.Handler) http.Handler {
+	if s.inFlight == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case s.inFlight <- struct{}{}:
+			defer func() { <-s.inFlight }()
+			next.ServeHTTP(w, r)
+		default:
+			GetLoggerFromCtx(r.Context()).Warn("rejected request: too many in flight", slog.Int("limit", cap(s.inFlight)))
+			w.Header().Set("Retry-After", saturatedRetryAfter)
+			s.writeError(w, http.StatusServiceUnavailable, "server is busy", nil)
+		}
+	})
+}
+
+// Bounds whole request with deadline d: its context gets cancelled and, if handler hasn't finished by then,
+// client gets 503 with error body. Handlers should use request's context instead of making own timeouts.
+// Response is buffered whole, so streamed ones go through DeadlineMiddleware instead.
+func (s *Server) TimeoutMiddleware(d time.Duration) func(http.Handler) http.Handler {
+	body, _ := sonic.ConfigDefault.MarshalToString(httputil.ErrorResponse{
+		Code:    http.StatusServiceUnavailable,
+		Message: "request timed out",
+	})
+	return func(next http.Handler) http.Handler {
+		timeoutHandler := http.TimeoutHandler(next, d, body)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Timeout message is written without handler's headers
+			w.Header().Set("Content-Type", "application/json")
+			timeoutHandler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Same as TimeoutMiddleware, but request is bounded by context deadline only and response isn't buffered.
+// Handler is responsible to stop once context is done.
+func (s *Server) DeadlineMiddleware(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Rejects GET and HEAD requests with non-empty body, as it's ignored anyway and likely is client's bug.
+// DELETE isn't covered: account deletion takes password in body.
+func (s *Server) RejectGetBodyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method == http.MethodGet || r.Method == http.MethodHead) && hasBody(r) {
+			GetLoggerFromCtx(r.Context()).Warn("rejected request with unexpected body", slog.String("method", r.Method))
+			s.writeError(w, http.StatusBadRequest, "unexpected request body", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Reports if request carries non-empty body, body of unknown length is peeked.
+func hasBody(r *http.Request) bool {
+	if r.ContentLength != -1 {
+		return r.ContentLength > 0
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	var b [1]byte
+	n, _ := io.ReadFull(r.Body, b[:])
+	return n > 0
+}
+
+// Sizes of bodies logged by BodyLoggingMiddleware. Bodies over capture limit aren't logged at all,
+// as cut JSON can't be redacted.
+const (
+	bodyCaptureLimit = 64 << 10
+	loggedBodyLimit  = 2048
+)
+
+// Fields whose values are never logged, matched by key case-insensitively
+var redactedBodyFields = []string{"password", "token"}
+
+// Logs request and response bodies at debug level, with passwords and tokens redacted.
+// Must go after SettingUpLoggerMiddleware. Meant for debugging clients, off by default (WithBodyLogging).
+func (s *Server) BodyLoggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqBody := &cappedBuffer{limit: bodyCaptureLimit}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(r.Body, reqBody), r.Body}
+		}
+		bw := &bodyLogWriter{ResponseWriter: w, body: cappedBuffer{limit: bodyCaptureLimit}, status: http.StatusOK}
+		next.ServeHTTP(bw, r)
+		GetLoggerFromCtx(r.Context()).Debug("http bodies",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("request_body", redactBody(reqBody)),
+			slog.Int("status", bw.status),
+			slog.String("response_body", redactBody(&bw.body)),
+		)
+	})
+}
+
+// Keeps first limit bytes written to it and remembers if there was more.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (cb *cappedBuffer) Write(p []byte) (int, error) {
+	if room := cb.limit - cb.Len(); len(p) > room {
+		cb.overflow = true
+		cb.Buffer.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return cb.Buffer.Write(p)
+}
+
+// Passes response through, keeping copy of its body and status.
+type bodyLogWriter struct {
+	http.ResponseWriter
+	body   cappedBuffer
+	status int
+}
+
+func (bw *bodyLogWriter) WriteHeader(status int) {
+	bw.status = status
+	bw.ResponseWriter.WriteHeader(status)
+}
+
+func (bw *bodyLogWriter) Write(p []byte) (int, error) {
+	bw.body.Write(p)
+	return bw.ResponseWriter.Write(p)
+}
+
+func (bw *bodyLogWriter) Unwrap() http.ResponseWriter {
+	return bw.ResponseWriter
+}
+
+// Returns body ready for logging: JSON with sensitive fields replaced, truncated to loggedBodyLimit.
+// Body which isn't valid JSON isn't logged, sensitive fields can't be found in it.
+func redactBody(body *cappedBuffer) string {
+	if body.overflow {
+		return "<body too large to log>"
+	}
+	raw := body.Bytes()
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return ""
+	}
+	var parsed any
+	if err := sonic.Unmarshal(raw, &parsed); err != nil {
+		return "<unparseable body>"
+	}
+	redactFields(parsed)
+	raw, err := sonic.Marshal(parsed)
+	if err != nil {
+		return "<unparseable body>"
+	}
+	if len(raw) > loggedBodyLimit {
+		return string(raw[:loggedBodyLimit]) + "...(truncated)"
+	}
+	return string(raw)
+}
+
+// Replaces values of sensitive fields in decoded JSON value in place, nested objects included.
+func redactFields(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isRedactedField(key) {
+				v[key] = "[REDACTED]"
+				continue
+			}
+			redactFields(value)
+		}
+	case []any:
+		for _, item := range v {
+			redactFields(item)
+		}
+	}
+}
+
+func isRedactedField(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range redactedBodyFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lets through only users configured as admins (WithAdmins), others get 403.
+// Must go after AuthMiddleware.
+func (s *Server) AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := GetLoggerFromCtx(r.Context())
+		uid, err := GetUIDFromContext(r)
+		if err != nil {
+			logger.Error("admin access error: unauthorized")
+			s.writeError(w, http.StatusUnauthorized, "no authorization", err)
+			return
+		}
+		if _, ok := s.admins[uid]; !ok {
+			logger.Warn("admin access denied")
+			s.writeError(w, http.StatusForbidden, "admin access required", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Limits rate of write requests (all but GET and HEAD) per authorized user, so users behind one NAT
+// don't share limit. Must be mounted after AuthMiddleware, falls back to client's IP if there is no uid.
+// Passes everything through if limiter is disabled.
+func (s *Server) UserRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.userLimiter == nil || r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var key string
+		if uid, err := GetUIDFromContext(r); err == nil {
+			key = "uid:" + uid.String()
+		} else {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+			key = "ip:" + host
+		}
+		if !s.userLimiter.Allow(key) {
+			GetLoggerFromCtx(r.Context()).Error("rate limit exceeded")
+			retryAfter := int(math.Ceil(s.userLimiter.Interval().Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			s.writeError(w, http.StatusTooManyRequests, "too many requests", nil)
+			return
+		}
 		next.ServeHTTP(w, r)
 	})
 }
 
 func (s *Server) SettingUpLoggerMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logger := slog.Default()
+		logger := s.logger
 		reqID, ok := r.Context().Value(requestIDKContextKey).(string)
 		if ok && reqID != "" {
 			logger = logger.With(slog.String("request_id", reqID))
 		}
+		traceID, ok := r.Context().Value(traceIDContextKey).(string)
+		if ok && traceID != "" {
+			logger = logger.With(slog.String("trace_id", traceID))
+		}
+		// Client's own id, so its traces can be matched with ours. Invalid one is dropped
+		if correlationID := r.Header.Get("X-Correlation-ID"); isCorrelationID(correlationID) {
+			logger = logger.With(slog.String("correlation_id", correlationID))
+		}
 		logger = logger.With(slog.String("from", r.RemoteAddr))
 		ctx := context.WithValue(r.Context(), loggerContextKey, logger)
 		r = r.WithContext(ctx)
@@ -62,56 +560,83 @@ func (s *Server) AuthMiddleware(next http.Handler) http.Handler {
 		tokenString, err := GetTokenFromHeader(r)
 		if err != nil {
 			logger.Error("auth failed: invalid token")
-			httputil.WriteErrorResponse(w, http.StatusUnauthorized, "authorization failed: invalid token", nil)
+			s.writeAuthError(w, r, "authorization failed: invalid token", err)
 			return
 		}
 		// Getting claims from token string
 		tokenClaims, err := s.jwtService.ParseToken(tokenString)
 		if err != nil {
-			switch {
-			case errors.Is(err, errorvalues.ErrInvalidToken):
-				logger.Error("auth failed: error parsing token")
-				httputil.WriteErrorResponse(w, http.StatusUnauthorized, "authorization failed: invalid token", nil)
-				return
-			default:
-				logger.Error("auth failed: internal error while parsing token", slog.String("error", err.Error()))
-				httputil.WriteErrorResponse(w, http.StatusInternalServerError, "error parsing token", nil)
+			logger.Error("auth failed: error parsing token", slog.String("error", err.Error()))
+			if errors.Is(err, errorvalues.ErrInvalidToken) {
+				s.writeAuthError(w, r, "authorization failed: invalid token", err)
 				return
 			}
+			s.writeAppError(w, err)
+			return
 		}
 		// Assuring if token is alive
 		now := time.Now()
 		if tokenClaims.ExpiresAt.Time.Before(now) || tokenClaims.NotBefore.Time.After(now) {
 			logger.Error("tried to auth with expired or not ready token")
-			httputil.WriteErrorResponse(w, http.StatusUnauthorized, "token expired or not ready", nil)
+			s.writeAuthError(w, r, "token expired or not ready", err)
 			return
 		}
 		uid, err := uuid.Parse(tokenClaims.UserID)
 		if err != nil {
 			logger.Error("invalid uid in token claims")
-			httputil.WriteErrorResponse(w, http.StatusUnauthorized, "invalid token payload", nil)
+			s.writeAuthError(w, r, "invalid token payload", err)
 			return
 		}
-		// Assuring if user still exists
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-		_, err = s.userService.GetByID(ctx, uid)
+		// Assuring if user still exists and isn't suspended
+		user, err := s.userService.GetByID(r.Context(), uid)
 		if err != nil {
-			if errors.Is(err, errorvalues.ErrUserNotFound) {
-				logger.Error("user doesn't exist")
-				httputil.WriteErrorResponse(w, http.StatusNotFound, "auth failed: user not found", nil)
+			logger.Error("auth failed: error while searching for user", slog.String("error", err.Error()))
+			// Response for deleted user must not differ from unknown path either
+			if s.hideAuthFailures && errors.Is(err, errorvalues.ErrUserNotFound) {
+				s.writeAuthError(w, r, "", err)
 				return
 			}
-			logger.Error("error while searching for user", slog.String("error", err.Error()))
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, "internal error while searching for user", nil)
+			s.writeAppError(w, err)
+			return
+		}
+		if user.Disabled {
+			logger.Warn("auth failed: account is disabled")
+			s.writeAppError(w, errorvalues.ErrAccountDisabled)
+			return
+		}
+		// Tokens issued before user revoked sessions carry older version
+		if tokenClaims.TokenVersion != user.TokenVersion {
+			logger.Warn("auth failed: token is revoked")
+			s.writeAuthError(w, r, "token revoked", nil)
 			return
 		}
-		ctx = context.WithValue(r.Context(), uidContextKey, uid)
+		if scope, ok := r.Context().Value(scopeContextKey).(*requestScope); ok {
+			scope.uid = uid
+		}
+		ctx := context.WithValue(r.Context(), uidContextKey, uid)
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
 	})
 }
 
+// Responds to failed authorization with 401, or with same 404 as unknown paths get
+// if auth failures are hidden (see WithHiddenAuthFailures).
+func (s *Server) writeAuthError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	if s.hideAuthFailures {
+		s.NotFound(w, r)
+		return
+	}
+	s.writeError(w, http.StatusUnauthorized, message, err)
+}
+
+// Writes error response, underlying err gets into details only if debug errors are enabled.
+func (s *Server) writeError(w http.ResponseWriter, statusCode int, message string, err error) {
+	if !s.debugErrors {
+		err = nil
+	}
+	httputil.WriteErrorResponse(w, statusCode, message, err)
+}
+
 func GetLoggerFromCtx(ctx context.Context) *slog.Logger {
 	logger, ok := ctx.Value(loggerContextKey).(*slog.Logger)
 	if ok {
@@ -120,6 +645,60 @@ func GetLoggerFromCtx(ctx context.Context) *slog.Logger {
 	return slog.Default()
 }
 
+// Parses traceparent header value ("00-<trace id>-<parent id>-<flags>"),
+// returns trace id and flags.
+func parseTraceparent(header string) (traceID, flags string, ok bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 || parts[0] == "ff" || !isHex(parts[0], 2) ||
+		!isHex(parts[1], 32) || !isHex(parts[2], 16) || !isHex(parts[3], 2) {
+		return "", "", false
+	}
+	// All-zero ids are invalid
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false
+	}
+	return parts[1], parts[3], true
+}
+
+// Reports if s is lowercase hex string of given length
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, char := range s {
+		if (char < '0' || char > '9') && (char < 'a' || char > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Longest X-Correlation-ID put into logs
+const maxCorrelationIDLength = 128
+
+// Reports if s is correlation id safe to log: non-empty string up to maxCorrelationIDLength
+// of letters, digits and "-_.:" (covers uuids, ulids and such), so it can't forge log lines.
+func isCorrelationID(s string) bool {
+	if s == "" || len(s) > maxCorrelationIDLength {
+		return false
+	}
+	for _, char := range s {
+		switch {
+		case char >= '0' && char <= '9', char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z':
+		case char == '-', char == '_', char == '.', char == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(bytesCount int) string {
+	b := make([]byte, bytesCount)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 func GetTokenFromHeader(r *http.Request) (string, error) {
 	token := r.Header.Get("Authorization")
 	if token == "" {
@@ -132,6 +711,8 @@ func GetTokenFromHeader(r *http.Request) (string, error) {
 	return parts[1], nil
 }
 
+// Returns uid of authorizated user put by AuthMiddleware. It's the only source of
+// acting user for handlers: uids from query or body never replace it.
 func GetUIDFromContext(r *http.Request) (uuid.UUID, error) {
 	uid, ok := r.Context().Value(uidContextKey).(uuid.UUID)
 	if !ok {
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/limbo/discipline/pkg/ratelimit"
+)
+
+// Option configures optional Server behaviour.
+type Option func(*Server)
+
+// Makes error responses include underlying error in details.
+// Helps debugging in development, must be off in production.
+func WithDebugErrors(enabled bool) Option {
+	return func(s *Server) {
+		s.debugErrors = enabled
+	}
+}
+
+// Sets page size used when client provides none and the biggest one allowed.
+// Non-positive values keep defaults (10 and 50).
+func WithPageLimits(defaultLimit, maxLimit int) Option {
+	return func(s *Server) {
+		if defaultLimit > 0 {
+			s.defaultPageLimit = defaultLimit
+		}
+		if maxLimit > 0 {
+			s.maxPageLimit = maxLimit
+		}
+		if s.defaultPageLimit > s.maxPageLimit {
+			s.defaultPageLimit = s.maxPageLimit
+		}
+	}
+}
+
+// Sets deadline for whole request, non-positive value keeps default (15 seconds).
+func WithRequestTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		if d > 0 {
+			s.requestTimeout = d
+		}
+	}
+}
+
+// Sets base logger, request loggers are derived from it. Nil keeps slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// Sets per user limit for write requests: rate per second and burst.
+// Non-positive rate disables limiting, non-positive burst is replaced with 1.
+func WithUserRateLimit(rate float64, burst int) Option {
+	return func(s *Server) {
+		if rate <= 0 {
+			s.userLimiter = nil
+			return
+		}
+		s.userLimiter = ratelimit.NewKeyed(rate, max(burst, 1))
+	}
+}
+
+// Grants access to admin endpoints to users with given ids. Nobody is admin by default.
+func WithAdmins(ids ...uuid.UUID) Option {
+	return func(s *Server) {
+		s.admins = make(map[uuid.UUID]struct{}, len(ids))
+		for _, id := range ids {
+			s.admins[id] = struct{}{}
+		}
+	}
+}
+
+// Makes GET and HEAD requests carrying body fail with 400 instead of body being ignored.
+// Helps to catch misconfigured clients, off by default.
+func WithRejectGetBodies(enabled bool) Option {
+	return func(s *Server) {
+		s.rejectGetBodies = enabled
+	}
+}
+
+// Makes failed authorization on protected routes respond with same 404 as unknown paths instead of 401,
+// so unauthorized clients can't tell protected routes exist. Off by default.
+func WithHiddenAuthFailures(enabled bool) Option {
+	return func(s *Server) {
+		s.hideAuthFailures = enabled
+	}
+}
+
+// Sets source of schema version provided on /version/schema. Without it endpoint responds with 503.
+func WithSchemaVersionSource(src SchemaVersionSource) Option {
+	return func(s *Server) {
+		s.schemaVersion = src
+	}
+}
+
+// Makes request and response bodies logged at debug level, with passwords and tokens redacted.
+// Meant for debugging clients, off by default.
+func WithBodyLogging(enabled bool) Option {
+	return func(s *Server) {
+		s.logBodies = enabled
+	}
+}
+
+// Allows cross-origin requests from given origins, "*" allows any. CORS headers aren't sent by default.
+func WithCORSOrigins(origins ...string) Option {
+	return func(s *Server) {
+		s.corsOrigins = make(map[string]struct{}, len(origins))
+		for _, origin := range origins {
+			s.corsOrigins[origin] = struct{}{}
+		}
+	}
+}
+
+// Sets how long browsers may cache preflight results (Access-Control-Max-Age),
+// non-positive value keeps default (10 minutes).
+func WithCORSMaxAge(d time.Duration) Option {
+	return func(s *Server) {
+		if d > 0 {
+			s.corsMaxAge = d
+		}
+	}
+}
+
+// Sets limit of requests served at once, ones over it are rejected with 503.
+// Non-positive value disables limiting (default).
+func WithMaxInFlight(n int) Option {
+	return func(s *Server) {
+		s.inFlight = nil
+		if n > 0 {
+			s.inFlight = make(chan struct{}, n)
+		}
+	}
+}
+
+// Makes plain HTTP requests redirected to HTTPS, X-Forwarded-Proto is honored behind proxy.
+// Probes (/readyz, /metrics) are served over HTTP anyway. Off by default.
+func WithHTTPSRedirect(enabled bool) Option {
+	return func(s *Server) {
+		s.httpsRedirect = enabled
+	}
+}
+
+// Sets generator of request ids, nil keeps default (random UUIDs).
+func WithRequestIDGenerator(gen IDGenerator) Option {
+	return func(s *Server) {
+		if gen != nil {
+			s.requestIDs = gen
+		}
+	}
+}
+
+// Makes inbound request id used instead of generated one: X-Request-ID if it's valid, otherwise
+// root of X-Amzn-Trace-Id. Enable only behind proxy setting them, clients can send any. Off by default.
+func WithInheritedRequestID(enabled bool) Option {
+	return func(s *Server) {
+		s.inheritRequestID = enabled
+	}
+}
+
+// Makes HTTPS responses carry Strict-Transport-Security with given max-age, so browsers
+// don't use plain HTTP for it. Non-positive value disables header (default).
+func WithHSTS(maxAge time.Duration) Option {
+	return func(s *Server) {
+		s.hstsMaxAge = max(maxAge, 0)
+	}
+}
+
+// Serves /metrics on separate address (e.g. one not exposed publicly) instead of API's router.
+// Empty address keeps it with API (default).
+func WithMetricsAddress(address string) Option {
+	return func(s *Server) {
+		s.metricsAddress = address
+	}
+}
@@ -2,16 +2,25 @@ package api
 
 import (
 	"context"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/limbo/discipline/internal/service"
 	"github.com/limbo/discipline/pkg/cleanup"
+	"github.com/limbo/discipline/pkg/ratelimit"
+	"github.com/limbo/discipline/pkg/reqid"
+	"github.com/limbo/discipline/pkg/reqstats"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -21,17 +30,64 @@ type Server struct {
 	userService  service.UserServiceI
 	jwtService   JWTServiceI
 	habitService service.HabitsServiceI
+	checkService service.HabitChecksServiceI
+	debugErrors  bool
+	mountOnce    sync.Once
+	// Pagination limits for lists
+	defaultPageLimit int
+	maxPageLimit     int
+	// Deadline for whole request, handlers rely on request's context
+	requestTimeout time.Duration
+	// Base for request loggers
+	logger *slog.Logger
+	// Limits writes per user, nil if disabled
+	userLimiter *ratelimit.Keyed
+	// Users allowed to admin endpoints
+	admins map[uuid.UUID]struct{}
+	// Rejects GET requests with body if set
+	rejectGetBodies bool
+	// Logs request and response bodies at debug level if set
+	logBodies bool
+	// Auth failures are responded with 404 instead of 401 if set
+	hideAuthFailures bool
+	// Nil if schema version isn't provided
+	schemaVersion SchemaVersionSource
+	// Origins allowed to make cross-origin requests, CORS is disabled if empty
+	corsOrigins map[string]struct{}
+	// Preflight results cache duration told to browsers
+	corsMaxAge time.Duration
+	// Set once shutdown begins, new requests are rejected after that
+	draining atomic.Bool
+	// Semaphore of requests being served, nil if their count isn't limited
+	inFlight chan struct{}
+	// Plain HTTP requests are redirected to HTTPS if set
+	httpsRedirect bool
+	// Strict-Transport-Security max-age sent on HTTPS responses, header isn't sent if zero
+	hstsMaxAge time.Duration
+	// Counters of served requests provided on /stats/server
+	requestStats *reqstats.Counters
+	// Server start, stats are counted since then
+	startedAt time.Time
+	// Makes ids of requests which don't bring own one
+	requestIDs IDGenerator
+	// Valid inbound X-Request-ID or X-Amzn-Trace-Id is used as request id if set
+	inheritRequestID bool
+	// /metrics is served on this address instead of API's router if set
+	metricsAddress string
+	// Serves /metrics on metricsAddress, nil until Run or if it's not set
+	metricsServer *http.Server
 }
 
 type ServicesList struct {
 	UserService   service.UserServiceI
 	JwtService    JWTServiceI
 	HabitsService service.HabitsServiceI
+	ChecksService service.HabitChecksServiceI
 }
 
-func New(servicesOptions *ServicesList) *Server {
+func New(servicesOptions *ServicesList, opts ...Option) *Server {
 	mx := chi.NewMux()
-	return &Server{
+	s := &Server{
 		mx: mx,
 		server: &http.Server{
 			Handler: mx,
@@ -39,54 +95,198 @@ func New(servicesOptions *ServicesList) *Server {
 		userService:  servicesOptions.UserService,
 		jwtService:   servicesOptions.JwtService,
 		habitService: servicesOptions.HabitsService,
+		checkService: servicesOptions.ChecksService,
+
+		defaultPageLimit: 10,
+		maxPageLimit:     50,
+		requestTimeout:   15 * time.Second,
+		logger:           slog.Default(),
+		userLimiter:      ratelimit.NewKeyed(5, 10),
+		corsMaxAge:       10 * time.Minute,
+		requestStats:     &reqstats.Counters{},
+		startedAt:        time.Now(),
+		requestIDs:       reqid.UUID{},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *Server) mountEndpoint() {
-	s.mx.Use(s.RequestIDMiddleware, s.SettingUpLoggerMiddleware)
+	s.mountOnce.Do(s.mountRoutes)
+}
+
+func (s *Server) mountRoutes() {
+	s.mx.Use(s.RequestStatsMiddleware, s.RequestIDMiddleware, s.TraceContextMiddleware, s.SettingUpLoggerMiddleware, s.RecoverMiddleware, s.DrainingMiddleware, s.InFlightLimitMiddleware,
+		s.SecurityHeadersMiddleware)
+	if s.httpsRedirect {
+		s.mx.Use(s.HTTPSRedirectMiddleware)
+	}
+	if len(s.corsOrigins) != 0 {
+		s.mx.Use(s.CORSMiddleware)
+	}
+	if s.rejectGetBodies {
+		s.mx.Use(s.RejectGetBodyMiddleware)
+	}
+	if s.logBodies {
+		s.mx.Use(s.BodyLoggingMiddleware)
+	}
+	// Must be set before subrouters are created to be inherited by them
+	s.mx.NotFound(s.NotFound)
+	s.mx.MethodNotAllowed(s.MethodNotAllowed)
 	s.mx.Route("/api/v1", func(r chi.Router) {
-		r.Route("/auth", func(r chi.Router) {
-			r.Use(s.SettingUpLoggerMiddleware)
-			r.Post("/register", s.Register)
-			r.Post("/login", s.Login)
-		})
-		r.Route("/habits", func(r chi.Router) {
-			r.Use(s.AuthMiddleware, s.LoggerExtensionMiddleware)
-			r.Post("/", s.CreateHabit)
-			r.Get("/", s.GetHabits)
-			r.Delete("/{id}", s.DeleteHabit)
+		// Streamed export can't be buffered by TimeoutMiddleware, it's bounded by context deadline only
+		r.With(s.DeadlineMiddleware(s.requestTimeout), s.SettingUpLoggerMiddleware, s.AuthMiddleware, s.LoggerExtensionMiddleware).
+			Get("/auth/export", s.ExportAccount)
+		r.Group(func(r chi.Router) {
+			r.Use(s.TimeoutMiddleware(s.requestTimeout))
+			r.Get("/version", s.Version)
+			r.Get("/version/schema", s.SchemaVersion)
+			r.Get("/errors", s.ListErrorCodes)
+			r.Route("/auth", func(r chi.Router) {
+				r.Use(s.SettingUpLoggerMiddleware)
+				r.Post("/register", s.Register)
+				r.Post("/login", s.Login)
+				r.With(s.AuthMiddleware, s.LoggerExtensionMiddleware).Get("/profile", s.GetProfile)
+				r.With(s.AuthMiddleware, s.LoggerExtensionMiddleware).Put("/username", s.ChangeUsername)
+				r.With(s.AuthMiddleware, s.LoggerExtensionMiddleware).Put("/timezone", s.SetTimezone)
+				r.With(s.AuthMiddleware, s.LoggerExtensionMiddleware).Delete("/account", s.DeleteAccount)
+				r.With(s.AuthMiddleware, s.LoggerExtensionMiddleware).Get("/account/summary", s.GetAccountSummary)
+				r.With(s.AuthMiddleware, s.LoggerExtensionMiddleware).Post("/sessions/revoke-all", s.RevokeSessions)
+			})
+			r.Route("/habits", func(r chi.Router) {
+				r.Use(s.AuthMiddleware, s.LoggerExtensionMiddleware, s.UserRateLimitMiddleware)
+				r.Post("/", s.CreateHabit)
+				r.Post("/batch", s.CreateHabitsBatch)
+				r.Get("/", s.GetHabits)
+				r.Get("/tags", s.ListHabitTags)
+				r.Put("/order", s.ReorderHabits)
+				r.Get("/{id}", s.GetHabit)
+				r.Head("/{id}", s.HeadHabit)
+				r.Delete("/{id}", s.DeleteHabit)
+				r.Patch("/{id}", s.PatchHabit)
+				r.Post("/{id}/restore", s.RestoreHabit)
+				r.Post("/{id}/merge", s.MergeHabits)
+				r.Post("/{id}/checks", s.CheckHabit)
+				r.Get("/{id}/checks", s.GetHabitChecks)
+				r.Get("/{id}/checks/count", s.CountHabitChecks)
+				r.Get("/{id}/checks/can", s.CanCheckHabit)
+				r.Get("/{id}/checks/by-month", s.GetMonthlyChecks)
+				r.Get("/{id}/checks/latest", s.GetLastCheck)
+				r.Delete("/{id}/checks/{date}", s.UncheckHabit)
+				r.Get("/{id}/adherence", s.GetHabitAdherence)
+				r.Post("/{id}/skip", s.SkipHabit)
+				r.Post("/{id}/pause", s.PauseHabit)
+				r.Post("/{id}/resume", s.ResumeHabit)
+				r.Get("/{id}/stats/weekdays", s.GetWeekdayDistribution)
+			})
+			r.Route("/groups", func(r chi.Router) {
+				r.Use(s.AuthMiddleware, s.LoggerExtensionMiddleware)
+				r.Get("/{id}/habits", s.GetGroupHabits)
+			})
+			r.Route("/checks", func(r chi.Router) {
+				r.Use(s.AuthMiddleware, s.LoggerExtensionMiddleware)
+				r.Get("/", s.GetUserChecks)
+				r.With(s.UserRateLimitMiddleware).Post("/batch", s.CheckHabitsBatch)
+			})
+			r.Route("/reminders", func(r chi.Router) {
+				r.Use(s.AuthMiddleware, s.LoggerExtensionMiddleware)
+				r.Get("/at-risk", s.GetAtRiskHabits)
+			})
+			r.Route("/stats", func(r chi.Router) {
+				r.Use(s.AuthMiddleware, s.LoggerExtensionMiddleware)
+				r.Get("/summary", s.GetStatsSummary)
+				r.With(s.AdminMiddleware).Get("/server", s.GetServerStats)
+			})
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(s.AuthMiddleware, s.LoggerExtensionMiddleware, s.AdminMiddleware)
+				r.Get("/users", s.ListUsers)
+				r.Post("/users/{id}/disable", s.DisableUser)
+			})
 		})
 	})
+	if s.metricsAddress == "" {
+		s.mx.Handle("/metrics", promhttp.Handler())
+	}
+	s.mx.Get("/readyz", s.Readiness)
 	s.mx.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
 	))
 }
 
+// Returns router with all endpoints mounted
+func (s *Server) Handler() http.Handler {
+	s.mountEndpoint()
+	return s.mx
+}
+
 func (s *Server) Run(address string) error {
 	s.mountEndpoint()
 	s.server.Addr = address
 	go func() {
-		log.Printf("Server starting on %s", address)
-		if err := s.server.ListenAndServe(); err != nil {
+		s.logger.Info("server starting", slog.String("address", address))
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal("Server failed: " + err.Error())
 		}
 	}()
+	if s.metricsAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		s.metricsServer = &http.Server{Addr: s.metricsAddress, Handler: mux}
+		go func() {
+			s.logger.Info("metrics server starting", slog.String("address", s.metricsAddress))
+			if err := s.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal("Metrics server failed: " + err.Error())
+			}
+		}()
+	}
 	return s.waitForShutdown()
 }
 
 func (s *Server) waitForShutdown() error {
 	closeCh := make(chan os.Signal, 1)
 	signal.Notify(closeCh, syscall.SIGINT, syscall.SIGTERM)
-	<-closeCh
-	log.Println("Shutting down server...")
-	cleanup.CleanUp()
-
+	sig := <-closeCh
+	s.logger.Info("shutdown signal received", slog.String("signal", sig.String()))
+	s.Drain()
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
 	defer cancel()
-	if err := s.server.Shutdown(ctx); err != nil {
-		log.Printf("Server failed to shutdown: %v", err)
+	return s.Shutdown(ctx)
+}
+
+// Switches server to draining: new requests get 503 with Retry-After (see DrainingMiddleware),
+// in-flight ones are let finish. Can't be undone, server is expected to be shut down next.
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
+
+// Runs cleanup jobs and stops server, waiting for active requests until ctx is done.
+// Returns non-nil error if any cleanup job failed or server didn't stop gracefully.
+func (s *Server) Shutdown(ctx context.Context) error {
+	start := time.Now()
+	s.logger.Info("shutting down server")
+	s.Drain()
+	cleanupErr := cleanup.CleanUp(s.logger)
+	shutdownErr := s.server.Shutdown(ctx)
+	if shutdownErr != nil {
+		s.logger.Error("server failed to shutdown", slog.String("error", shutdownErr.Error()))
+		shutdownErr = errors.New("server shutdown error: " + shutdownErr.Error())
+	}
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Shutdown(ctx); err != nil {
+			s.logger.Error("metrics server failed to shutdown", slog.String("error", err.Error()))
+			shutdownErr = errors.Join(shutdownErr, errors.New("metrics server shutdown error: "+err.Error()))
+		}
+	}
+	if cleanupErr != nil {
+		cleanupErr = errors.New("cleanup error: " + cleanupErr.Error())
+	}
+	err := errors.Join(cleanupErr, shutdownErr)
+	if err != nil {
+		s.logger.Error("server stopped with errors", slog.Duration("duration", time.Since(start)), slog.String("error", err.Error()))
 		return err
 	}
-	log.Println("Server stopped")
+	s.logger.Info("server stopped", slog.Duration("duration", time.Since(start)))
 	return nil
 }
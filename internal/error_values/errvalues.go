@@ -1,10 +1,14 @@
 package errorvalues
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrUserExists          = errors.New("such user already exists")
 	ErrUserNotFound        = errors.New("user doesn't exists")
+	ErrAccountDisabled     = errors.New("account is disabled")
 	ErrWrongCredentials    = errors.New("wrong name or password")
 	ErrInvalidToken        = errors.New("invalid token")
 	ErrUserHasHabit        = errors.New("habit with such title already owned by user")
@@ -14,4 +18,12 @@ var (
 	ErrCheckExist          = errors.New("habit already checked on this date")
 	ErrCheckNotFound       = errors.New("habit check on this date not found")
 	ErrCheckDateNotAllowed = errors.New("can't check habit on date in the future")
+	ErrValidation          = errors.New("validation failed")
+	ErrGroupNotFound       = errors.New("group doesn't exists")
+	ErrHabitPaused         = errors.New("habit is already paused")
+	ErrHabitNotPaused      = errors.New("habit is not paused")
+	ErrSchemaNotMigrated   = errors.New("database schema isn't migrated")
+
+	// Conflicts on particular user's field, they are ErrUserExists as well
+	ErrNameTaken = fmt.Errorf("name is taken: %w", ErrUserExists)
 )
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/limbo/discipline/internal/repository"
+	"github.com/limbo/discipline/pkg/cleanup"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Anything reporting connection pool stats, e.g. pool-backed repositories
+type PoolStatsSource interface {
+	PoolStats() (repository.PoolStats, bool)
+}
+
+// Adapts function to PoolStatsSource, e.g. repository's ReplicaPoolStats method
+type PoolStatsFunc func() (repository.PoolStats, bool)
+
+func (f PoolStatsFunc) PoolStats() (repository.PoolStats, bool) {
+	return f()
+}
+
+// Reports connections of named pools to db_connections gauge, labeled by pool and state
+// (acquired, idle, total).
+type PoolCollector struct {
+	sources map[string]PoolStatsSource
+	gauge   *prometheus.GaugeVec
+}
+
+// Creates collector for pools in sources (by pool name) and registers its gauge in reg.
+func NewPoolCollector(reg prometheus.Registerer, sources map[string]PoolStatsSource) *PoolCollector {
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "discipline",
+		Name:      "db_connections",
+		Help:      "Connections of database pool by state.",
+	}, []string{"pool", "state"})
+	reg.MustRegister(gauge)
+	return &PoolCollector{
+		sources: sources,
+		gauge:   gauge,
+	}
+}
+
+// Reads stats of all pools once and updates gauge. Sources without pool are skipped.
+func (c *PoolCollector) Collect() {
+	for name, source := range c.sources {
+		stats, ok := source.PoolStats()
+		if !ok {
+			continue
+		}
+		c.gauge.WithLabelValues(name, "acquired").Set(float64(stats.AcquiredConns))
+		c.gauge.WithLabelValues(name, "idle").Set(float64(stats.IdleConns))
+		c.gauge.WithLabelValues(name, "total").Set(float64(stats.TotalConns))
+	}
+}
+
+// Collects stats every interval in background until cleanup.
+func (c *PoolCollector) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		c.Collect()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				c.Collect()
+			}
+		}
+	}()
+	cleanup.Register(&cleanup.Job{
+		Name: "stopping db pool stats collector",
+		F: func() error {
+			ticker.Stop()
+			close(done)
+			return nil
+		},
+	})
+}
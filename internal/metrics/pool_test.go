@@ -0,0 +1,59 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/limbo/discipline/internal/metrics"
+	"github.com/limbo/discipline/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubStats struct {
+	stats repository.PoolStats
+	ok    bool
+}
+
+func (s *stubStats) PoolStats() (repository.PoolStats, bool) {
+	return s.stats, s.ok
+}
+
+func TestPoolCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	habits := &stubStats{stats: repository.PoolStats{AcquiredConns: 3, IdleConns: 1, TotalConns: 4}, ok: true}
+	noPool := &stubStats{}
+	collector := metrics.NewPoolCollector(reg, map[string]metrics.PoolStatsSource{
+		"habits": habits,
+		"mocked": noPool,
+		"habits_replica": metrics.PoolStatsFunc(func() (repository.PoolStats, bool) {
+			return repository.PoolStats{AcquiredConns: 2, IdleConns: 2, TotalConns: 4}, true
+		}),
+	})
+	collector.Collect()
+	assert.Equal(t, 6, testutil.CollectAndCount(reg))
+	gauge := func(pool, state string) float64 {
+		families, err := reg.Gather()
+		assert.NoError(t, err)
+		for _, family := range families {
+			for _, metric := range family.GetMetric() {
+				labels := map[string]string{}
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				if labels["pool"] == pool && labels["state"] == state {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+		return -1
+	}
+	assert.Equal(t, float64(3), gauge("habits", "acquired"))
+	assert.Equal(t, float64(1), gauge("habits", "idle"))
+	assert.Equal(t, float64(4), gauge("habits", "total"))
+	assert.Equal(t, float64(2), gauge("habits_replica", "acquired"))
+	// Gauge follows pool on next collection
+	habits.stats.AcquiredConns = 0
+	collector.Collect()
+	assert.Equal(t, float64(0), gauge("habits", "acquired"))
+}
@@ -21,10 +21,11 @@ import (
 func TestCreateCheck(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock)
-	query := regexp.QuoteMeta(`INSERT INTO habit_checks (habit_id, check_date) VALUES ($1, $2);`)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`INSERT INTO habit_checks (habit_id, check_date, note) VALUES ($1, $2, $3);`)
 	habitID := uuid.New()
 	checkDate := time.Now()
+	note := "felt great"
 	testCases := []struct {
 		Desc            string
 		Error           error
@@ -34,14 +35,14 @@ func TestCreateCheck(t *testing.T) {
 			Desc:  "successful",
 			Error: nil,
 			MockPrepareFunc: func() {
-				mock.ExpectExec(query).WithArgs(habitID, checkDate).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				mock.ExpectExec(query).WithArgs(habitID, checkDate, note).WillReturnResult(pgxmock.NewResult("INSERT", 1))
 			},
 		},
 		{
 			Desc:  "unique violation",
 			Error: errorvalues.ErrCheckExist,
 			MockPrepareFunc: func() {
-				mock.ExpectExec(query).WithArgs(habitID, checkDate).WillReturnError(&pgconn.PgError{
+				mock.ExpectExec(query).WithArgs(habitID, checkDate, note).WillReturnError(&pgconn.PgError{
 					Code: "23505",
 				})
 			},
@@ -50,16 +51,36 @@ func TestCreateCheck(t *testing.T) {
 			Desc:  "fk violation",
 			Error: errorvalues.ErrHabitNotFound,
 			MockPrepareFunc: func() {
-				mock.ExpectExec(query).WithArgs(habitID, checkDate).WillReturnError(&pgconn.PgError{
+				mock.ExpectExec(query).WithArgs(habitID, checkDate, note).WillReturnError(&pgconn.PgError{
 					Code: "23503",
 				})
 			},
 		},
+		{
+			Desc:  "future date",
+			Error: errorvalues.ErrCheckDateNotAllowed,
+			MockPrepareFunc: func() {
+				mock.ExpectExec(query).WithArgs(habitID, checkDate, note).WillReturnError(&pgconn.PgError{
+					Code:           "23514",
+					ConstraintName: "habit_checks_check_date_not_future",
+				})
+			},
+		},
+		{
+			Desc:  "other check violation",
+			Error: errors.New("creating check error: :  (SQLSTATE 23514)"),
+			MockPrepareFunc: func() {
+				mock.ExpectExec(query).WithArgs(habitID, checkDate, note).WillReturnError(&pgconn.PgError{
+					Code:           "23514",
+					ConstraintName: "habit_checks_status_check",
+				})
+			},
+		},
 		{
 			Desc:  "db error",
 			Error: errors.New("creating check error: db error"),
 			MockPrepareFunc: func() {
-				mock.ExpectExec(query).WithArgs(habitID, checkDate).WillReturnError(errors.New("db error"))
+				mock.ExpectExec(query).WithArgs(habitID, checkDate, note).WillReturnError(errors.New("db error"))
 			},
 		},
 	}
@@ -67,7 +88,7 @@ func TestCreateCheck(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.Desc, func(t *testing.T) {
 			tc.MockPrepareFunc()
-			err := habitChecksRepo.Create(ctx, habitID, checkDate)
+			err := habitChecksRepo.Create(ctx, habitID, checkDate, note)
 			if tc.Error != nil {
 				assert.EqualError(t, err, tc.Error.Error())
 			} else {
@@ -77,10 +98,201 @@ func TestCreateCheck(t *testing.T) {
 	}
 }
 
+func TestUpsertCheck(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`INSERT INTO habit_checks (habit_id, check_date, note) VALUES ($1, $2, $3)
+		ON CONFLICT (habit_id, check_date) WHERE NOT repeatable DO UPDATE SET note = EXCLUDED.note, status = 'checked' RETURNING xmax = 0;`)
+	habitID := uuid.New()
+	checkDate := time.Now()
+	note := "felt even better"
+	testCases := []struct {
+		Desc            string
+		Created         bool
+		Error           error
+		MockPrepareFunc func()
+	}{
+		{
+			Desc:    "created",
+			Created: true,
+			MockPrepareFunc: func() {
+				mock.ExpectQuery(query).WithArgs(habitID, checkDate, note).WillReturnRows(pgxmock.NewRows([]string{"created"}).AddRow(true))
+			},
+		},
+		{
+			Desc:    "note updated on conflict",
+			Created: false,
+			MockPrepareFunc: func() {
+				mock.ExpectQuery(query).WithArgs(habitID, checkDate, note).WillReturnRows(pgxmock.NewRows([]string{"created"}).AddRow(false))
+			},
+		},
+		{
+			Desc:  "fk violation",
+			Error: errorvalues.ErrHabitNotFound,
+			MockPrepareFunc: func() {
+				mock.ExpectQuery(query).WithArgs(habitID, checkDate, note).WillReturnError(&pgconn.PgError{
+					Code: "23503",
+				})
+			},
+		},
+		{
+			Desc:  "future date",
+			Error: errorvalues.ErrCheckDateNotAllowed,
+			MockPrepareFunc: func() {
+				mock.ExpectQuery(query).WithArgs(habitID, checkDate, note).WillReturnError(&pgconn.PgError{
+					Code:           "23514",
+					ConstraintName: "habit_checks_check_date_not_future",
+				})
+			},
+		},
+		{
+			Desc:  "db error",
+			Error: errors.New("upserting check error: db error"),
+			MockPrepareFunc: func() {
+				mock.ExpectQuery(query).WithArgs(habitID, checkDate, note).WillReturnError(errors.New("db error"))
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepareFunc()
+			created, err := habitChecksRepo.Upsert(ctx, habitID, checkDate, note)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.Created, created)
+			}
+		})
+	}
+}
+
+func TestCreateSkip(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`INSERT INTO habit_checks (habit_id, check_date, status) VALUES ($1, $2, 'skipped');`)
+	habitID := uuid.New()
+	date := time.Now()
+	testCases := []struct {
+		Desc            string
+		Error           error
+		MockPrepareFunc func()
+	}{
+		{
+			Desc:  "successful",
+			Error: nil,
+			MockPrepareFunc: func() {
+				mock.ExpectExec(query).WithArgs(habitID, date).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			},
+		},
+		{
+			Desc:  "unique violation",
+			Error: errorvalues.ErrCheckExist,
+			MockPrepareFunc: func() {
+				mock.ExpectExec(query).WithArgs(habitID, date).WillReturnError(&pgconn.PgError{
+					Code: "23505",
+				})
+			},
+		},
+		{
+			Desc:  "future date",
+			Error: errorvalues.ErrCheckDateNotAllowed,
+			MockPrepareFunc: func() {
+				mock.ExpectExec(query).WithArgs(habitID, date).WillReturnError(&pgconn.PgError{
+					Code:           "23514",
+					ConstraintName: "habit_checks_check_date_not_future",
+				})
+			},
+		},
+		{
+			Desc:  "db error",
+			Error: errors.New("creating skip error: db error"),
+			MockPrepareFunc: func() {
+				mock.ExpectExec(query).WithArgs(habitID, date).WillReturnError(errors.New("db error"))
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepareFunc()
+			err := habitChecksRepo.CreateSkip(ctx, habitID, date)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCreateChecksBatch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`INSERT INTO habit_checks (habit_id, check_date) SELECT $1, unnest($2::date[])
+		ON CONFLICT (habit_id, check_date) WHERE NOT repeatable DO NOTHING;`)
+	habitID := uuid.New()
+	dates := []time.Time{time.Now().AddDate(0, 0, -2), time.Now().AddDate(0, 0, -1), time.Now()}
+	ctx := context.Background()
+	t.Run("successful", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(habitID, dates).WillReturnResult(pgxmock.NewResult("INSERT", 3))
+		assert.NoError(t, habitChecksRepo.CreateBatch(ctx, habitID, dates))
+	})
+	t.Run("existing checks skipped", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(habitID, dates).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		assert.NoError(t, habitChecksRepo.CreateBatch(ctx, habitID, dates))
+	})
+	t.Run("fk violation", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(habitID, dates).WillReturnError(&pgconn.PgError{
+			Code: "23503",
+		})
+		assert.ErrorIs(t, habitChecksRepo.CreateBatch(ctx, habitID, dates), errorvalues.ErrHabitNotFound)
+	})
+	t.Run("future date", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(habitID, dates).WillReturnError(&pgconn.PgError{
+			Code:           "23514",
+			ConstraintName: "habit_checks_check_date_not_future",
+		})
+		assert.ErrorIs(t, habitChecksRepo.CreateBatch(ctx, habitID, dates), errorvalues.ErrCheckDateNotAllowed)
+	})
+	t.Run("no dates", func(t *testing.T) {
+		assert.NoError(t, habitChecksRepo.CreateBatch(ctx, habitID, nil))
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCheckNote(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`UPDATE habit_checks SET note = $1 WHERE habit_id = $2 AND check_date = $3;`)
+	habitID := uuid.New()
+	checkDate := time.Now()
+	note := "skipped warmup"
+	ctx := context.Background()
+	t.Run("updated", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(note, habitID, checkDate).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		assert.NoError(t, habitChecksRepo.UpdateNote(ctx, habitID, checkDate, note))
+	})
+	t.Run("check not found", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(note, habitID, checkDate).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		assert.ErrorIs(t, habitChecksRepo.UpdateNote(ctx, habitID, checkDate, note), errorvalues.ErrCheckNotFound)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(note, habitID, checkDate).WillReturnError(errors.New("db error"))
+		assert.Error(t, habitChecksRepo.UpdateNote(ctx, habitID, checkDate, note))
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeleteCheck(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
 	query := regexp.QuoteMeta(`DELETE FROM habit_checks WHERE habit_id = $1 AND check_date = $2;`)
 	habitID := uuid.New()
 	checkDate := time.Now()
@@ -129,7 +341,7 @@ func TestDeleteCheck(t *testing.T) {
 func TestExistsCheck(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
 	query := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM habit_checks WHERE habitID = $1 AND check_date = $2);`)
 	habitID := uuid.New()
 	checkDate := time.Now()
@@ -188,8 +400,8 @@ func TestExistsCheck(t *testing.T) {
 func TestGetByHabitAndDateRange(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock)
-	query := regexp.QuoteMeta(`SELECT id, habit_id, check_date, created_at FROM habit_checks WHERE habitID = $1 AND check_date >= $2 AND check_date <= $3;`)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT id, habit_id, check_date, status, note, created_at FROM habit_checks WHERE habit_id = $1 AND check_date >= $2 AND check_date <= $3 ORDER BY check_date ASC;`)
 	habitID := uuid.New()
 	fromDate := time.Now().Add(time.Hour * -24)
 	toDate := time.Now().Add(time.Hour * 24)
@@ -198,18 +410,22 @@ func TestGetByHabitAndDateRange(t *testing.T) {
 			ID:        1,
 			HabitID:   habitID,
 			CheckDate: fromDate,
+			Status:    entity.CheckStatusChecked,
 			CreatedAt: fromDate,
 		},
 		{
 			ID:        2,
 			HabitID:   habitID,
 			CheckDate: time.Now(),
+			Status:    entity.CheckStatusSkipped,
+			Note:      "felt great",
 			CreatedAt: time.Now(),
 		},
 		{
 			ID:        3,
 			HabitID:   habitID,
 			CheckDate: toDate,
+			Status:    entity.CheckStatusChecked,
 			CreatedAt: toDate,
 		},
 	}
@@ -224,9 +440,9 @@ func TestGetByHabitAndDateRange(t *testing.T) {
 			Error:        nil,
 			ChecksResult: returnedChecks,
 			MockPrepFunc: func() {
-				rows := pgxmock.NewRows([]string{"id", "habit_id", "check_date", "created_at"})
+				rows := pgxmock.NewRows([]string{"id", "habit_id", "check_date", "status", "note", "created_at"})
 				for _, check := range returnedChecks {
-					rows.AddRow(check.ID, check.HabitID, check.CheckDate, check.CreatedAt)
+					rows.AddRow(check.ID, check.HabitID, check.CheckDate, check.Status, check.Note, check.CreatedAt)
 				}
 				mock.ExpectQuery(query).
 					WithArgs(habitID, fromDate, toDate).
@@ -248,7 +464,7 @@ func TestGetByHabitAndDateRange(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.Desc, func(t *testing.T) {
 			tc.MockPrepFunc()
-			result, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habitID, fromDate, toDate)
+			result, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habitID, fromDate, toDate, entity.CheckOrderAsc, "")
 			if tc.Error != nil {
 				assert.EqualError(t, err, tc.Error.Error())
 			} else {
@@ -257,13 +473,43 @@ func TestGetByHabitAndDateRange(t *testing.T) {
 			}
 		})
 	}
+	orderCases := []struct {
+		Desc    string
+		Order   entity.CheckOrder
+		OrderBy string
+	}{
+		{Desc: "descending order", Order: entity.CheckOrderDesc, OrderBy: "ORDER BY check_date DESC;"},
+		{Desc: "unknown order is ascending", Order: "check_date; DROP TABLE habit_checks", OrderBy: "ORDER BY check_date ASC;"},
+		{Desc: "no order is ascending", Order: "", OrderBy: "ORDER BY check_date ASC;"},
+	}
+	for _, tc := range orderCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(tc.OrderBy)).
+				WithArgs(habitID, fromDate, toDate).
+				WillReturnRows(pgxmock.NewRows([]string{"id", "habit_id", "check_date", "status", "note", "created_at"}))
+			_, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habitID, fromDate, toDate, tc.Order, "")
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+	t.Run("filtered by status", func(t *testing.T) {
+		rows := pgxmock.NewRows([]string{"id", "habit_id", "check_date", "status", "note", "created_at"}).
+			AddRow(returnedChecks[1].ID, habitID, returnedChecks[1].CheckDate, returnedChecks[1].Status, returnedChecks[1].Note, returnedChecks[1].CreatedAt)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, habit_id, check_date, status, note, created_at FROM habit_checks WHERE habit_id = $1 AND check_date >= $2 AND check_date <= $3 AND status = $4 ORDER BY check_date ASC;`)).
+			WithArgs(habitID, fromDate, toDate, entity.CheckStatusSkipped).
+			WillReturnRows(rows)
+		result, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habitID, fromDate, toDate, entity.CheckOrderAsc, entity.CheckStatusSkipped)
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.HabitCheck{returnedChecks[1]}, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestGetLastCheckDate(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock)
-	query := regexp.QuoteMeta(`SELECT check_date FROM habit_checks WHERE habit_id = $1 ORDER BY check_date DESC LIMIT 1;`)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT check_date FROM habit_checks WHERE habit_id = $1 AND status = 'checked' ORDER BY check_date DESC LIMIT 1;`)
 	habitID := uuid.New()
 	returnedDate := time.Now().Add(time.Hour * -24)
 	testCases := []struct {
@@ -326,8 +572,8 @@ func TestGetLastCheckDate(t *testing.T) {
 func TestCountByHabitID(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock)
-	query := regexp.QuoteMeta(`SELECT COUNT(*) FROM habit_checks WHERE habit_id = $1;`)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT COUNT(*) FROM habit_checks WHERE habit_id = $1 AND status = 'checked';`)
 	habitID := uuid.New()
 	testCases := []struct {
 		Desc         string
@@ -376,6 +622,7 @@ func TestHabitChecksIntegrational(t *testing.T) {
 		UserID:      userID,
 		Title:       "test_habit",
 		Description: "test_habit_description",
+		StartDate:   testStartDate,
 	}
 	var err error
 	// Adding new habit to operate on its checks
@@ -390,15 +637,36 @@ func TestHabitChecksIntegrational(t *testing.T) {
 	t.Run("create", func(t *testing.T) {
 		t.Run("success", func(t *testing.T) {
 			for i := range len(checkDates) {
-				err = habitChecksRepo.Create(ctx, habit.ID, checkDates[i])
+				err = habitChecksRepo.Create(ctx, habit.ID, checkDates[i], "")
 			}
 		})
 		t.Run("unique violation error", func(t *testing.T) {
-			err = habitChecksRepo.Create(ctx, habit.ID, checkDates[0])
+			err = habitChecksRepo.Create(ctx, habit.ID, checkDates[0], "")
 			assert.ErrorIs(t, err, errorvalues.ErrCheckExist)
 		})
+		t.Run("same day at other time conflicts", func(t *testing.T) {
+			morning := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+			require.NoError(t, habitChecksRepo.Create(ctx, habit.ID, morning, ""))
+			err = habitChecksRepo.Create(ctx, habit.ID, morning.Add(12*time.Hour), "")
+			assert.ErrorIs(t, err, errorvalues.ErrCheckExist)
+			require.NoError(t, habitChecksRepo.Delete(ctx, habit.ID, morning.Add(time.Hour)))
+		})
+		t.Run("upsert replaces note on conflict", func(t *testing.T) {
+			day := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+			created, err := habitChecksRepo.Upsert(ctx, habit.ID, day, "first")
+			require.NoError(t, err)
+			assert.True(t, created)
+			created, err = habitChecksRepo.Upsert(ctx, habit.ID, day, "second")
+			require.NoError(t, err)
+			assert.False(t, created)
+			checks, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habit.ID, day, day, entity.CheckOrderAsc, "")
+			require.NoError(t, err)
+			require.Len(t, checks, 1)
+			assert.Equal(t, "second", checks[0].Note)
+			require.NoError(t, habitChecksRepo.Delete(ctx, habit.ID, day))
+		})
 		t.Run("check on unexist habit error", func(t *testing.T) {
-			err = habitChecksRepo.Create(ctx, uuid.New(), checkDates[0])
+			err = habitChecksRepo.Create(ctx, uuid.New(), checkDates[0], "")
 			assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
 		})
 	})
@@ -416,7 +684,7 @@ func TestHabitChecksIntegrational(t *testing.T) {
 	})
 	t.Run("get by range", func(t *testing.T) {
 		t.Run("success: all checks", func(t *testing.T) {
-			result, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habit.ID, checkDates[0], checkDates[len(checkDates)-1])
+			result, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habit.ID, checkDates[0], checkDates[len(checkDates)-1], entity.CheckOrderAsc, "")
 			assert.NoError(t, err)
 			assert.Equal(t, 3, len(result))
 			for i := range result {
@@ -425,7 +693,7 @@ func TestHabitChecksIntegrational(t *testing.T) {
 			}
 		})
 		t.Run("success: got some", func(t *testing.T) {
-			result, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habit.ID, checkDates[0], checkDates[1])
+			result, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habit.ID, checkDates[0], checkDates[1], entity.CheckOrderAsc, "")
 			assert.NoError(t, err)
 			assert.Equal(t, 2, len(result))
 			for i := range result {
@@ -471,4 +739,506 @@ func TestHabitChecksIntegrational(t *testing.T) {
 			assert.ErrorIs(t, err, errorvalues.ErrCheckNotFound)
 		})
 	})
+	t.Run("create batch", func(t *testing.T) {
+		t.Run("success with duplicates skipped", func(t *testing.T) {
+			require.NoError(t, habitChecksRepo.Create(ctx, habit.ID, checkDates[0], ""))
+			err := habitChecksRepo.CreateBatch(ctx, habit.ID, checkDates)
+			assert.NoError(t, err)
+			count, err := habitChecksRepo.CountByHabitID(ctx, habit.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, len(checkDates), count)
+		})
+		t.Run("unexist habit", func(t *testing.T) {
+			err := habitChecksRepo.CreateBatch(ctx, uuid.New(), checkDates)
+			assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+		})
+	})
+	t.Run("notes", func(t *testing.T) {
+		getNote := func(date time.Time) string {
+			checks, err := habitChecksRepo.GetByHabitAndDateRange(ctx, habit.ID, date, date, entity.CheckOrderAsc, "")
+			require.NoError(t, err)
+			require.Len(t, checks, 1)
+			return checks[0].Note
+		}
+		t.Run("round-trip", func(t *testing.T) {
+			require.NoError(t, habitChecksRepo.Delete(ctx, habit.ID, checkDates[0]))
+			require.NoError(t, habitChecksRepo.Create(ctx, habit.ID, checkDates[0], "felt great"))
+			assert.Equal(t, "felt great", getNote(checkDates[0]))
+			require.NoError(t, habitChecksRepo.UpdateNote(ctx, habit.ID, checkDates[0], "skipped warmup"))
+			assert.Equal(t, "skipped warmup", getNote(checkDates[0]))
+		})
+		t.Run("check not found", func(t *testing.T) {
+			err := habitChecksRepo.UpdateNote(ctx, uuid.New(), checkDates[0], "note")
+			assert.ErrorIs(t, err, errorvalues.ErrCheckNotFound)
+		})
+	})
+	t.Run("habits not checked today", func(t *testing.T) {
+		habitRepo := repository.NewHabitsRepo(cfg)
+		today := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+		create := func(title string, marked ...time.Time) uuid.UUID {
+			id, err := habitRepo.Create(ctx, &entity.Habit{UserID: userID, Title: title, StartDate: testStartDate})
+			require.NoError(t, err)
+			for _, day := range marked {
+				require.NoError(t, habitChecksRepo.Create(ctx, id, day, ""))
+			}
+			return id
+		}
+		atRisk := create("at_risk_habit", today.AddDate(0, 0, -2), today.AddDate(0, 0, -1))
+		skipped, err := habitRepo.Create(ctx, &entity.Habit{UserID: userID, Title: "at_risk_skipped_habit", StartDate: testStartDate})
+		require.NoError(t, err)
+		require.NoError(t, habitChecksRepo.CreateSkip(ctx, skipped, today.AddDate(0, 0, -1)))
+		checkedToday := create("checked_today_habit", today.AddDate(0, 0, -1), today)
+		brokenStreak := create("broken_streak_habit", today.AddDate(0, 0, -2))
+		paused := create("paused_habit", today.AddDate(0, 0, -1))
+		require.NoError(t, habitChecksRepo.Pause(ctx, paused, today))
+
+		result, err := habitChecksRepo.HabitsNotCheckedToday(ctx, userID, today)
+		require.NoError(t, err)
+		ids := make([]uuid.UUID, 0, len(result))
+		for _, h := range result {
+			ids = append(ids, h.ID)
+		}
+		// Sorted by title
+		assert.Equal(t, []uuid.UUID{atRisk, skipped}, ids)
+		for _, id := range []uuid.UUID{atRisk, skipped, checkedToday, brokenStreak, paused} {
+			require.NoError(t, habitRepo.Delete(ctx, id))
+		}
+	})
+	t.Run("merge habits", func(t *testing.T) {
+		habitRepo := repository.NewHabitsRepo(cfg)
+		day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+		source, err := habitRepo.Create(ctx, &entity.Habit{UserID: userID, Title: "merge_source", StartDate: testStartDate})
+		require.NoError(t, err)
+		target, err := habitRepo.Create(ctx, &entity.Habit{UserID: userID, Title: "merge_target", StartDate: testStartDate})
+		require.NoError(t, err)
+		// Days 0 and 1 are source's only, day 2 collides, day 3 is target's only
+		require.NoError(t, habitChecksRepo.Create(ctx, source, day, "source"))
+		require.NoError(t, habitChecksRepo.CreateSkip(ctx, source, day.AddDate(0, 0, 1)))
+		require.NoError(t, habitChecksRepo.Create(ctx, source, day.AddDate(0, 0, 2), "source"))
+		require.NoError(t, habitChecksRepo.Create(ctx, target, day.AddDate(0, 0, 2), "target"))
+		require.NoError(t, habitChecksRepo.Create(ctx, target, day.AddDate(0, 0, 3), "target"))
+
+		moved, err := habitRepo.Merge(ctx, source, target, userID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, moved)
+		marks, err := habitChecksRepo.GetByHabitAndDateRange(ctx, target, day, day.AddDate(0, 0, 3), entity.CheckOrderAsc, "")
+		require.NoError(t, err)
+		require.Len(t, marks, 4)
+		assert.Equal(t, entity.CheckStatusSkipped, marks[1].Status)
+		// Target's mark is kept on collision
+		assert.Equal(t, "target", marks[2].Note)
+		count, err := habitChecksRepo.CountByHabitID(ctx, target)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		// Source is gone with all its marks
+		orphans, err := habitChecksRepo.GetByHabitAndDateRange(ctx, source, day, day.AddDate(0, 0, 3), entity.CheckOrderAsc, "")
+		require.NoError(t, err)
+		assert.Empty(t, orphans)
+		_, err = habitRepo.GetDeletedByID(ctx, source)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+		_, err = habitRepo.Merge(ctx, source, target, userID)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+		require.NoError(t, habitRepo.Delete(ctx, target))
+	})
+	t.Run("partial reorder", func(t *testing.T) {
+		habitRepo := repository.NewHabitsRepo(cfg)
+		ids := make([]uuid.UUID, 0, 3)
+		for _, title := range []string{"reorder_a", "reorder_b", "reorder_c"} {
+			id, err := habitRepo.Create(ctx, &entity.Habit{UserID: userID, Title: title, StartDate: testStartDate})
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+		a, b, c := ids[0], ids[1], ids[2]
+		require.NoError(t, habitRepo.Reorder(ctx, userID, []uuid.UUID{c, b}))
+		habits, err := habitRepo.GetByUserID(ctx, userID, entity.HabitSortPosition, 100, 0)
+		require.NoError(t, err)
+		order := make([]uuid.UUID, 0, len(habits))
+		for _, h := range habits {
+			order = append(order, h.ID)
+		}
+		// Listed habits go first, unlisted one isn't tied with them
+		require.GreaterOrEqual(t, len(order), 3)
+		assert.Equal(t, []uuid.UUID{c, b}, order[:2])
+		assert.Contains(t, order[2:], a)
+		for _, id := range ids {
+			require.NoError(t, habitRepo.Delete(ctx, id))
+		}
+	})
+	t.Run("merge repeated marks into single mark habit", func(t *testing.T) {
+		habitRepo := repository.NewHabitsRepo(cfg)
+		day := time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC)
+		source, err := habitRepo.Create(ctx, &entity.Habit{UserID: userID, Title: "merge_repeated_source", StartDate: testStartDate, AllowMultiplePerDay: true})
+		require.NoError(t, err)
+		target, err := habitRepo.Create(ctx, &entity.Habit{UserID: userID, Title: "merge_single_target", StartDate: testStartDate})
+		require.NoError(t, err)
+		require.NoError(t, habitChecksRepo.CreateRepeated(ctx, source, day, "first"))
+		require.NoError(t, habitChecksRepo.CreateRepeated(ctx, source, day, "second"))
+
+		_, err = habitRepo.Merge(ctx, source, target, uuid.New())
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+		moved, err := habitRepo.Merge(ctx, source, target, userID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, moved)
+		marks, err := habitChecksRepo.GetByHabitAndDateRange(ctx, target, day, day, entity.CheckOrderAsc, "")
+		require.NoError(t, err)
+		require.Len(t, marks, 1)
+		assert.Equal(t, "first", marks[0].Note)
+		// Collapsed mark is single one of its day, so another can't be added
+		assert.ErrorIs(t, habitChecksRepo.Create(ctx, target, day, ""), errorvalues.ErrCheckExist)
+		require.NoError(t, habitRepo.Delete(ctx, target))
+	})
+	t.Run("stream user's data for export", func(t *testing.T) {
+		habitRepo := repository.NewHabitsRepo(cfg)
+		day := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+		exported, err := habitRepo.Create(ctx, &entity.Habit{UserID: userID, Title: "exported_habit", StartDate: testStartDate})
+		require.NoError(t, err)
+		require.NoError(t, habitChecksRepo.Create(ctx, exported, day, "exported"))
+		require.NoError(t, habitChecksRepo.CreateSkip(ctx, exported, day.AddDate(0, 0, 1)))
+		deleted, err := habitRepo.Create(ctx, &entity.Habit{UserID: userID, Title: "deleted_habit", StartDate: testStartDate})
+		require.NoError(t, err)
+		require.NoError(t, habitChecksRepo.Create(ctx, deleted, day, ""))
+		require.NoError(t, habitRepo.Delete(ctx, deleted))
+
+		habitIDs := make([]uuid.UUID, 0)
+		err = habitRepo.StreamByUserID(ctx, userID, func(h *entity.Habit) error {
+			habitIDs = append(habitIDs, h.ID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Contains(t, habitIDs, exported)
+		assert.NotContains(t, habitIDs, deleted)
+		marks := make([]*entity.HabitCheck, 0)
+		err = habitChecksRepo.StreamByUserID(ctx, userID, func(c *entity.HabitCheck) error {
+			if c.HabitID == deleted {
+				t.Errorf("check of deleted habit exported")
+			}
+			if c.HabitID == exported {
+				marks = append(marks, c)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, marks, 2)
+		assert.Equal(t, "exported", marks[0].Note)
+		assert.Equal(t, entity.CheckStatusSkipped, marks[1].Status)
+		// Nothing of other users
+		err = habitRepo.StreamByUserID(ctx, uuid.New(), func(h *entity.Habit) error {
+			return errors.New("unexpected habit")
+		})
+		assert.NoError(t, err)
+		require.NoError(t, habitRepo.Delete(ctx, exported))
+	})
+}
+
+func TestHabitsNotCheckedToday(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT h.id, h.user_id, h.title, h.description, h.color, h.icon, h.start_date, h.created_at, h.updated_at, h.allow_multiple_per_day, h.tags
+			FROM habits h LEFT JOIN habit_checks hc ON hc.habit_id = h.id AND hc.check_date = $2
+			WHERE h.user_id = $1 AND h.deleted_at IS NULL AND hc.id IS NULL
+			AND EXISTS (SELECT 1 FROM habit_checks y WHERE y.habit_id = h.id AND y.check_date = $2::date - 1)
+			AND NOT EXISTS (SELECT 1 FROM habit_pauses p WHERE p.habit_id = h.id AND p.resumed_at IS NULL)
+			ORDER BY h.title;`)
+	columns := []string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	t.Run("habits at risk", func(t *testing.T) {
+		habit := entity.Habit{ID: uuid.New(), UserID: userID, Title: "morning run", CreatedAt: today, UpdatedAt: today}
+		mock.ExpectQuery(query).WithArgs(userID, today).WillReturnRows(pgxmock.NewRows(columns).
+			AddRow(habit.ID, habit.UserID, habit.Title, habit.Description, habit.Color, habit.Icon, habit.StartDate, habit.CreatedAt, habit.UpdatedAt, habit.AllowMultiplePerDay, habit.Tags))
+		result, err := habitChecksRepo.HabitsNotCheckedToday(ctx, userID, today)
+		assert.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, habit, *result[0])
+	})
+	t.Run("nothing at risk", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID, today).WillReturnRows(pgxmock.NewRows(columns))
+		result, err := habitChecksRepo.HabitsNotCheckedToday(ctx, userID, today)
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+		assert.Empty(t, result)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID, today).WillReturnError(errors.New("db error"))
+		_, err := habitChecksRepo.HabitsNotCheckedToday(ctx, userID, today)
+		assert.EqualError(t, err, "getting habits not checked today error: db error")
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetHabitIDsByDate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT DISTINCT habit_id FROM habit_checks WHERE habit_id = ANY($1) AND check_date = $2 AND status = 'checked';`)
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	checked, unchecked := uuid.New(), uuid.New()
+	ids := []uuid.UUID{checked, unchecked}
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(ids, today).WillReturnRows(pgxmock.NewRows([]string{"habit_id"}).AddRow(checked))
+		result, err := habitChecksRepo.GetHabitIDsByDate(ctx, ids, today)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{checked}, result)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(ids, today).WillReturnError(errors.New("db error"))
+		_, err := habitChecksRepo.GetHabitIDsByDate(ctx, ids, today)
+		assert.EqualError(t, err, "getting habits checked on date error: db error")
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCheckedDates(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT check_date, status FROM habit_checks WHERE habit_id = $1 AND check_date >= $2 AND check_date <= $3;`)
+	habitID := uuid.New()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	t.Run("successful", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(habitID, from, to).
+			WillReturnRows(pgxmock.NewRows([]string{"check_date", "status"}).
+				AddRow(from, entity.CheckStatusChecked).
+				AddRow(from.AddDate(0, 0, 2), entity.CheckStatusSkipped).
+				AddRow(to, entity.CheckStatusChecked))
+		dates, err := habitChecksRepo.GetCheckedDates(ctx, habitID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]entity.CheckStatus{
+			"2025-03-01": entity.CheckStatusChecked,
+			"2025-03-03": entity.CheckStatusSkipped,
+			"2025-03-07": entity.CheckStatusChecked,
+		}, dates)
+		_, ok := dates["2025-03-02"]
+		assert.False(t, ok)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(habitID, from, to).
+			WillReturnError(errors.New("db error"))
+		_, err := habitChecksRepo.GetCheckedDates(ctx, habitID, from, to)
+		assert.Error(t, err)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountByHabitAndDateRange(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT COUNT(*) FROM habit_checks WHERE habit_id = $1 AND status = 'checked' AND check_date BETWEEN $2 AND $3;`)
+	habitID := uuid.New()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	t.Run("successful", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(habitID, from, to).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(12))
+		count, err := habitChecksRepo.CountByHabitAndDateRange(ctx, habitID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, 12, count)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(habitID, from, to).
+			WillReturnError(errors.New("db error"))
+		_, err := habitChecksRepo.CountByHabitAndDateRange(ctx, habitID, from, to)
+		assert.EqualError(t, err, "error counting checks for period: db error")
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountByWeekday(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT EXTRACT(DOW FROM check_date)::int AS weekday, COUNT(*) FROM habit_checks
+			WHERE habit_id = $1 AND status = 'checked' GROUP BY weekday;`)
+	habitID := uuid.New()
+	ctx := context.Background()
+	t.Run("successful", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(habitID).
+			WillReturnRows(pgxmock.NewRows([]string{"weekday", "count"}).AddRow(1, 5).AddRow(0, 2).AddRow(6, 1))
+		counts, err := habitChecksRepo.CountByWeekday(ctx, habitID)
+		assert.NoError(t, err)
+		assert.Equal(t, [7]int{2, 5, 0, 0, 0, 0, 1}, counts)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(habitID).
+			WillReturnError(errors.New("db error"))
+		_, err := habitChecksRepo.CountByWeekday(ctx, habitID)
+		assert.EqualError(t, err, "counting checks by weekday error: db error")
+	})
+}
+
+func TestPauseResumeHabit(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	pauseQuery := regexp.QuoteMeta(`INSERT INTO habit_pauses (habit_id, paused_at) VALUES ($1, $2);`)
+	resumeQuery := regexp.QuoteMeta(`UPDATE habit_pauses SET resumed_at = GREATEST($2, paused_at) WHERE habit_id = $1 AND resumed_at IS NULL;`)
+	habitID := uuid.New()
+	day := time.Now()
+	ctx := context.Background()
+	t.Run("paused", func(t *testing.T) {
+		mock.ExpectExec(pauseQuery).WithArgs(habitID, day).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		assert.NoError(t, habitChecksRepo.Pause(ctx, habitID, day))
+	})
+	t.Run("error already paused", func(t *testing.T) {
+		mock.ExpectExec(pauseQuery).WithArgs(habitID, day).WillReturnError(&pgconn.PgError{Code: "23505"})
+		assert.ErrorIs(t, habitChecksRepo.Pause(ctx, habitID, day), errorvalues.ErrHabitPaused)
+	})
+	t.Run("resumed", func(t *testing.T) {
+		mock.ExpectExec(resumeQuery).WithArgs(habitID, day).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		assert.NoError(t, habitChecksRepo.Resume(ctx, habitID, day))
+	})
+	t.Run("error not paused", func(t *testing.T) {
+		mock.ExpectExec(resumeQuery).WithArgs(habitID, day).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		assert.ErrorIs(t, habitChecksRepo.Resume(ctx, habitID, day), errorvalues.ErrHabitNotPaused)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetByUserAndDate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT hc.id, hc.habit_id, h.title, hc.check_date, hc.status, hc.note, hc.created_at FROM habit_checks hc
+			JOIN habits h ON h.id = hc.habit_id WHERE h.user_id = $1 AND h.deleted_at IS NULL AND hc.check_date = $2 ORDER BY h.title;`)
+	columns := []string{"id", "habit_id", "title", "check_date", "status", "note", "created_at"}
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	t.Run("checks with habits titles", func(t *testing.T) {
+		expected := []entity.UserCheck{
+			{
+				HabitCheck: entity.HabitCheck{ID: 1, HabitID: uuid.New(), CheckDate: day, Status: entity.CheckStatusChecked, Note: "felt great", CreatedAt: day},
+				HabitTitle: "morning run",
+			},
+			{
+				HabitCheck: entity.HabitCheck{ID: 2, HabitID: uuid.New(), CheckDate: day, Status: entity.CheckStatusSkipped, CreatedAt: day},
+				HabitTitle: "reading",
+			},
+		}
+		rows := pgxmock.NewRows(columns)
+		for _, check := range expected {
+			rows.AddRow(check.ID, check.HabitID, check.HabitTitle, check.CheckDate, check.Status, check.Note, check.CreatedAt)
+		}
+		mock.ExpectQuery(query).WithArgs(userID, day).WillReturnRows(rows)
+		result, err := habitChecksRepo.GetByUserAndDate(ctx, userID, day)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+	t.Run("no checks", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID, day).WillReturnRows(pgxmock.NewRows(columns))
+		result, err := habitChecksRepo.GetByUserAndDate(ctx, userID, day)
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+		assert.Empty(t, result)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID, day).WillReturnError(errors.New("db error"))
+		_, err := habitChecksRepo.GetByUserAndDate(ctx, userID, day)
+		assert.EqualError(t, err, "getting user's checks on date error: db error")
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepeatedChecks(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	createQuery := regexp.QuoteMeta(`INSERT INTO habit_checks (habit_id, check_date, note, repeatable) VALUES ($1, $2, $3, TRUE);`)
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM habit_checks WHERE id = (SELECT id FROM habit_checks WHERE habit_id = $1 AND check_date = $2 ORDER BY created_at DESC, id DESC LIMIT 1);`)
+	habitID := uuid.New()
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	t.Run("created", func(t *testing.T) {
+		mock.ExpectExec(createQuery).WithArgs(habitID, day, "").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		assert.NoError(t, habitChecksRepo.CreateRepeated(ctx, habitID, day, ""))
+	})
+	t.Run("error habit not found", func(t *testing.T) {
+		mock.ExpectExec(createQuery).WithArgs(habitID, day, "").WillReturnError(&pgconn.PgError{Code: "23503"})
+		assert.ErrorIs(t, habitChecksRepo.CreateRepeated(ctx, habitID, day, ""), errorvalues.ErrHabitNotFound)
+	})
+	t.Run("error future date", func(t *testing.T) {
+		mock.ExpectExec(createQuery).WithArgs(habitID, day, "").WillReturnError(&pgconn.PgError{Code: "23514", ConstraintName: "habit_checks_check_date_not_future"})
+		assert.ErrorIs(t, habitChecksRepo.CreateRepeated(ctx, habitID, day, ""), errorvalues.ErrCheckDateNotAllowed)
+	})
+	t.Run("latest deleted", func(t *testing.T) {
+		mock.ExpectExec(deleteQuery).WithArgs(habitID, day).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		assert.NoError(t, habitChecksRepo.DeleteLatest(ctx, habitID, day))
+	})
+	t.Run("error nothing to delete", func(t *testing.T) {
+		mock.ExpectExec(deleteQuery).WithArgs(habitID, day).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		assert.ErrorIs(t, habitChecksRepo.DeleteLatest(ctx, habitID, day), errorvalues.ErrCheckNotFound)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveGetStats(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habitChecksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	getQuery := regexp.QuoteMeta(`SELECT total_checks, current_streak, max_streak, last_check, completion_rate, paused, as_of, since FROM habit_stats WHERE habit_id = $1;`)
+	saveQuery := regexp.QuoteMeta(`INSERT INTO habit_stats (habit_id, total_checks, current_streak, max_streak, last_check, completion_rate, paused, as_of, since)`)
+	columns := []string{"total_checks", "current_streak", "max_streak", "last_check", "completion_rate", "paused", "as_of", "since"}
+	habitID := uuid.New()
+	today := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	since := today.AddDate(0, 0, -9)
+	ctx := context.Background()
+	t.Run("never saved", func(t *testing.T) {
+		mock.ExpectQuery(getQuery).WithArgs(habitID).WillReturnRows(pgxmock.NewRows(columns))
+		stored, err := habitChecksRepo.GetStats(ctx, habitID)
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+	t.Run("saved", func(t *testing.T) {
+		lastCheck := today.AddDate(0, 0, -1)
+		mock.ExpectQuery(getQuery).WithArgs(habitID).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(3, 2, 2, &lastCheck, 0.3, false, today, since))
+		stored, err := habitChecksRepo.GetStats(ctx, habitID)
+		require.NoError(t, err)
+		assert.Equal(t, &repository.StoredStats{
+			Stats: entity.HabitStats{
+				ID:             habitID,
+				TotalChecks:    3,
+				CurrentStreak:  2,
+				MaxStreak:      2,
+				LastCheck:      lastCheck,
+				CompletionRate: 0.3,
+			},
+			AsOf:  today,
+			Since: since,
+		}, stored)
+	})
+	t.Run("saved without checks", func(t *testing.T) {
+		mock.ExpectExec(saveQuery).
+			WithArgs(habitID, 0, 0, 0, (*time.Time)(nil), 0.0, true, today, since).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		err := habitChecksRepo.SaveStats(ctx, &repository.StoredStats{
+			Stats: entity.HabitStats{ID: habitID, Paused: true},
+			AsOf:  today,
+			Since: since,
+		})
+		assert.NoError(t, err)
+	})
+	t.Run("error habit not found", func(t *testing.T) {
+		mock.ExpectExec(saveQuery).
+			WithArgs(habitID, 0, 0, 0, (*time.Time)(nil), 0.0, false, today, since).
+			WillReturnError(&pgconn.PgError{Code: "23503"})
+		err := habitChecksRepo.SaveStats(ctx, &repository.StoredStats{
+			Stats: entity.HabitStats{ID: habitID},
+			AsOf:  today,
+			Since: since,
+		})
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
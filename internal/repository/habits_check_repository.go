@@ -3,57 +3,169 @@ package repository
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
-	"github.com/limbo/discipline/pkg/cleanup"
 	"github.com/limbo/discipline/pkg/entity"
 )
 
+// Constraint rejecting checks on future dates, see migration 21
+const checkDateNotFutureConstraint = "habit_checks_check_date_not_future"
+
 type HabitChecksRepository struct {
 	conn PgConnection
+	// Connection for read-only queries. Points to conn if no replica configured.
+	readConn    PgConnection
+	slowQueries slowQueryLog
 }
 
 func NewHabitChecksRepo(cfg DBConfig) *HabitChecksRepository {
-	pool, err := pgxpool.New(context.Background(), cfg.ConnString())
-	if err != nil {
-		log.Fatal("creating connection for usersRepo error: " + err.Error())
+	return NewHabitChecksRepoWithReplica(cfg, nil)
+}
+
+// Creates repository with reads offloaded to replica described by replicaCfg.
+// If replicaCfg is nil, all queries go to primary.
+func NewHabitChecksRepoWithReplica(cfg, replicaCfg DBConfig) *HabitChecksRepository {
+	pool := connectPool(cfg, "habitChecksRepo")
+	return &HabitChecksRepository{
+		conn:     pool,
+		readConn: connectReadPool(pool, replicaCfg, "habitChecksRepo"),
 	}
-	err = pool.Ping(context.Background())
-	if err != nil {
-		log.Fatal("error while pinging connection for usersRepo: " + err.Error())
+}
+
+// Creates repository on given connections. readConn is optional,
+// if nil, conn is used for reads too.
+func NewHabitChecksRepoWithConn(conn, readConn PgConnection) *HabitChecksRepository {
+	return &HabitChecksRepository{
+		conn:     conn,
+		readConn: pingConns(conn, readConn, "habitChecksRepo"),
 	}
-	cleanup.Register(&cleanup.Job{
-		Name: "closing pgxpool",
-		F: func() error {
-			pool.Close()
-			return nil
-		},
-	})
+}
+
+// Returns stats of primary connection pool, false if repository isn't backed by pgxpool.
+func (checksRepo *HabitChecksRepository) PoolStats() (PoolStats, bool) {
+	return poolStats(checksRepo.conn)
+}
+
+// Returns stats of replica connection pool, false if repository has no replica or it isn't backed by pgxpool.
+func (checksRepo *HabitChecksRepository) ReplicaPoolStats() (PoolStats, bool) {
+	return replicaPoolStats(checksRepo.conn, checksRepo.readConn)
+}
+
+// Makes repository log queries running longer than threshold as warnings with logger.
+// Non-positive threshold turns logging off. Must be called before repository is used.
+func (checksRepo *HabitChecksRepository) SetSlowQueryLogging(logger *slog.Logger, threshold time.Duration) {
+	checksRepo.slowQueries = slowQueryLog{logger: logger, threshold: threshold}
+	checksRepo.conn = checksRepo.slowQueries.wrap(checksRepo.conn)
+	checksRepo.readConn = checksRepo.slowQueries.wrap(checksRepo.readConn)
+}
+
+func (checksRepo *HabitChecksRepository) WithTx(tx pgx.Tx) HabitChecksRepositoryI {
+	conn := checksRepo.slowQueries.wrap(txConn{tx})
+	// Reads go to tx too, so they see its uncommited changes
 	return &HabitChecksRepository{
-		conn: pool,
+		conn:        conn,
+		readConn:    conn,
+		slowQueries: checksRepo.slowQueries,
 	}
 }
 
-func NewHabitChecksRepoWithConn(conn PgConnection) *HabitChecksRepository {
-	err := conn.Ping(context.Background())
+func (checksRepo *HabitChecksRepository) Create(ctx context.Context, habitID uuid.UUID, date time.Time, note string) error {
+	_, err := checksRepo.conn.Exec(
+		ctx,
+		`INSERT INTO habit_checks (habit_id, check_date, note) VALUES ($1, $2, $3);`,
+		habitID,
+		date,
+		note,
+	)
 	if err != nil {
-		log.Fatal("error while pingin connection for habitsRepo: " + err.Error())
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			// Unique violation
+			case "23505":
+				return errorvalues.ErrCheckExist
+			// FK violation
+			case "23503":
+				return errorvalues.ErrHabitNotFound
+			// Check violation, date is in the future (status has own check)
+			case "23514":
+				if pgErr.ConstraintName == checkDateNotFutureConstraint {
+					return errorvalues.ErrCheckDateNotAllowed
+				}
+			}
+		}
+		return fmt.Errorf("creating check error: %w", err)
 	}
-	return &HabitChecksRepository{
-		conn: conn,
+	return nil
+}
+
+func (checksRepo *HabitChecksRepository) CreateRepeated(ctx context.Context, habitID uuid.UUID, date time.Time, note string) error {
+	_, err := checksRepo.conn.Exec(
+		ctx,
+		`INSERT INTO habit_checks (habit_id, check_date, note, repeatable) VALUES ($1, $2, $3, TRUE);`,
+		habitID,
+		date,
+		note,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			// FK violation
+			case "23503":
+				return errorvalues.ErrHabitNotFound
+			// Check violation, date is in the future (status has own check)
+			case "23514":
+				if pgErr.ConstraintName == checkDateNotFutureConstraint {
+					return errorvalues.ErrCheckDateNotAllowed
+				}
+			}
+		}
+		return fmt.Errorf("creating repeated check error: %w", err)
 	}
+	return nil
 }
 
-func (checksRepo *HabitChecksRepository) Create(ctx context.Context, habitID uuid.UUID, date time.Time) error {
+func (checksRepo *HabitChecksRepository) Upsert(ctx context.Context, habitID uuid.UUID, date time.Time, note string) (bool, error) {
+	// xmax is zero only for freshly inserted row
+	var created bool
+	err := checksRepo.conn.QueryRow(
+		ctx,
+		`INSERT INTO habit_checks (habit_id, check_date, note) VALUES ($1, $2, $3)
+		ON CONFLICT (habit_id, check_date) WHERE NOT repeatable DO UPDATE SET note = EXCLUDED.note, status = 'checked' RETURNING xmax = 0;`,
+		habitID,
+		date,
+		note,
+	).Scan(&created)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			// FK violation
+			case "23503":
+				return false, errorvalues.ErrHabitNotFound
+			// Check violation, date is in the future
+			case "23514":
+				if pgErr.ConstraintName == checkDateNotFutureConstraint {
+					return false, errorvalues.ErrCheckDateNotAllowed
+				}
+			}
+		}
+		return false, fmt.Errorf("upserting check error: %w", err)
+	}
+	return created, nil
+}
+
+func (checksRepo *HabitChecksRepository) CreateSkip(ctx context.Context, habitID uuid.UUID, date time.Time) error {
 	_, err := checksRepo.conn.Exec(
 		ctx,
-		`INSERT INTO habit_checks (habit_id, check_date) VALUES ($1, $2);`,
+		`INSERT INTO habit_checks (habit_id, check_date, status) VALUES ($1, $2, 'skipped');`,
 		habitID,
 		date,
 	)
@@ -67,22 +179,58 @@ func (checksRepo *HabitChecksRepository) Create(ctx context.Context, habitID uui
 			// FK violation
 			case "23503":
 				return errorvalues.ErrHabitNotFound
+			// Check violation, date is in the future (status has own check)
+			case "23514":
+				if pgErr.ConstraintName == checkDateNotFutureConstraint {
+					return errorvalues.ErrCheckDateNotAllowed
+				}
 			}
 		}
-		return errors.New("creating check error: " + err.Error())
+		return fmt.Errorf("creating skip error: %w", err)
 	}
 	return nil
 }
 
-func (checksRepo *HabitChecksRepository) Delete(ctx context.Context, habitID uuid.UUID, date time.Time) error {
+func (checksRepo *HabitChecksRepository) CreateBatch(ctx context.Context, habitID uuid.UUID, dates []time.Time) error {
+	if len(dates) == 0 {
+		return nil
+	}
+	_, err := checksRepo.conn.Exec(
+		ctx,
+		`INSERT INTO habit_checks (habit_id, check_date) SELECT $1, unnest($2::date[])
+		ON CONFLICT (habit_id, check_date) WHERE NOT repeatable DO NOTHING;`,
+		habitID,
+		dates,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			// FK violation
+			case "23503":
+				return errorvalues.ErrHabitNotFound
+			// Check violation, some of dates is in the future
+			case "23514":
+				if pgErr.ConstraintName == checkDateNotFutureConstraint {
+					return errorvalues.ErrCheckDateNotAllowed
+				}
+			}
+		}
+		return fmt.Errorf("creating checks batch error: %w", err)
+	}
+	return nil
+}
+
+func (checksRepo *HabitChecksRepository) UpdateNote(ctx context.Context, habitID uuid.UUID, date time.Time, note string) error {
 	ct, err := checksRepo.conn.Exec(
 		ctx,
-		`DELETE FROM habit_checks WHERE habit_id = $1 AND check_date = $2;`,
+		`UPDATE habit_checks SET note = $1 WHERE habit_id = $2 AND check_date = $3;`,
+		note,
 		habitID,
 		date,
 	)
 	if err != nil {
-		return errors.New("deleting check error: " + err.Error())
+		return fmt.Errorf("updating check note error: %w", err)
 	}
 	if ct.RowsAffected() == 0 {
 		return errorvalues.ErrCheckNotFound
@@ -90,72 +238,494 @@ func (checksRepo *HabitChecksRepository) Delete(ctx context.Context, habitID uui
 	return nil
 }
 
-func (checksRepo *HabitChecksRepository) Exists(ctx context.Context, habitID uuid.UUID, date time.Time) (bool, error) {
-	var exists bool
-	row := checksRepo.conn.QueryRow(
+func (checksRepo *HabitChecksRepository) Delete(ctx context.Context, habitID uuid.UUID, date time.Time) error {
+	ct, err := checksRepo.conn.Exec(
 		ctx,
-		`SELECT EXISTS(SELECT 1 FROM habit_checks WHERE habit_id = $1 AND check_date = $2);`,
+		`DELETE FROM habit_checks WHERE habit_id = $1 AND check_date = $2;`,
 		habitID,
 		date,
 	)
-	err := row.Scan(&exists)
 	if err != nil {
-		return false, errors.New("inspecting if check exists error: " + err.Error())
+		return fmt.Errorf("deleting check error: %w", err)
 	}
-	return exists, nil
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrCheckNotFound
+	}
+	return nil
 }
 
-func (checksRepo *HabitChecksRepository) GetByHabitAndDateRange(ctx context.Context, habitID uuid.UUID, from, to time.Time) ([]entity.HabitCheck, error) {
-	rows, err := checksRepo.conn.Query(
+func (checksRepo *HabitChecksRepository) DeleteLatest(ctx context.Context, habitID uuid.UUID, date time.Time) error {
+	ct, err := checksRepo.conn.Exec(
 		ctx,
-		`SELECT id, habit_id, check_date, created_at FROM habit_checks WHERE habit_id = $1 AND check_date >= $2 AND check_date <= $3;`,
+		`DELETE FROM habit_checks WHERE id = (SELECT id FROM habit_checks WHERE habit_id = $1 AND check_date = $2 ORDER BY created_at DESC, id DESC LIMIT 1);`,
 		habitID,
-		from,
-		to,
+		date,
 	)
 	if err != nil {
-		return nil, errors.New("getting checks for period error: " + err.Error())
+		return fmt.Errorf("deleting latest check error: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrCheckNotFound
+	}
+	return nil
+}
+
+func (checksRepo *HabitChecksRepository) Exists(ctx context.Context, habitID uuid.UUID, date time.Time) (bool, error) {
+	var exists bool
+	err := withRetry(ctx, func() error {
+		row := checksRepo.readConn.QueryRow(
+			ctx,
+			`SELECT EXISTS(SELECT 1 FROM habit_checks WHERE habit_id = $1 AND check_date = $2);`,
+			habitID,
+			date,
+		)
+		return row.Scan(&exists)
+	})
+	if err != nil {
+		return false, fmt.Errorf("inspecting if check exists error: %w", err)
+	}
+	return exists, nil
+}
+
+// Only these are put into query
+var checksOrders = map[entity.CheckOrder]string{
+	entity.CheckOrderAsc:  "check_date ASC",
+	entity.CheckOrderDesc: "check_date DESC",
+}
+
+func (checksRepo *HabitChecksRepository) GetByHabitAndDateRange(ctx context.Context, habitID uuid.UUID, from, to time.Time, order entity.CheckOrder, status entity.CheckStatus) ([]entity.HabitCheck, error) {
+	orderBy, ok := checksOrders[order]
+	if !ok {
+		orderBy = checksOrders[entity.CheckOrderAsc]
+	}
+	args := []any{habitID, from, to}
+	var statusFilter string
+	if status != "" {
+		statusFilter = " AND status = $4"
+		args = append(args, status)
+	}
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = checksRepo.readConn.Query(
+			ctx,
+			`SELECT id, habit_id, check_date, status, note, created_at FROM habit_checks WHERE habit_id = $1 AND check_date >= $2 AND check_date <= $3`+statusFilter+` ORDER BY `+orderBy+`;`,
+			args...,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting checks for period error: %w", err)
 	}
 	result := make([]entity.HabitCheck, 0, 2)
 	for rows.Next() {
 		check := entity.HabitCheck{}
-		err = rows.Scan(&check.ID, &check.HabitID, &check.CheckDate, &check.CreatedAt)
+		err = rows.Scan(&check.ID, &check.HabitID, &check.CheckDate, &check.Status, &check.Note, &check.CreatedAt)
 		if err != nil {
-			return nil, errors.New("check row parsing error: " + err.Error())
+			return nil, fmt.Errorf("check row parsing error: %w", err)
 		}
 		result = append(result, check)
 	}
 	if rows.Err() != nil {
-		return nil, errors.New("unexpected check rows error: " + err.Error())
+		return nil, fmt.Errorf("unexpected check rows error: %w", err)
 	}
 	return result, nil
 }
 
-func (checksRepo *HabitChecksRepository) GetLastCheckDate(ctx context.Context, habitID uuid.UUID) (*time.Time, error) {
-	row := checksRepo.conn.QueryRow(
+func (checksRepo *HabitChecksRepository) StreamByUserID(ctx context.Context, userID uuid.UUID, fn func(check *entity.HabitCheck) error) error {
+	rows, err := checksRepo.readConn.Query(
 		ctx,
-		`SELECT check_date FROM habit_checks WHERE habit_id = $1 ORDER BY check_date DESC LIMIT 1;`,
-		habitID,
+		`SELECT hc.id, hc.habit_id, hc.check_date, hc.status, hc.note, hc.created_at FROM habit_checks hc
+		JOIN habits h ON h.id = hc.habit_id WHERE h.user_id = $1 AND h.deleted_at IS NULL ORDER BY hc.habit_id, hc.check_date, hc.id;`,
+		userID,
 	)
+	if err != nil {
+		return fmt.Errorf("streaming user's checks error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		check := entity.HabitCheck{}
+		err = rows.Scan(&check.ID, &check.HabitID, &check.CheckDate, &check.Status, &check.Note, &check.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("check row parsing error: %w", err)
+		}
+		if err = fn(&check); err != nil {
+			return err
+		}
+	}
+	if rows.Err() != nil {
+		return fmt.Errorf("unexpected check rows error: %w", rows.Err())
+	}
+	return nil
+}
+
+func (checksRepo *HabitChecksRepository) GetByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time) ([]entity.UserCheck, error) {
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = checksRepo.readConn.Query(
+			ctx,
+			`SELECT hc.id, hc.habit_id, h.title, hc.check_date, hc.status, hc.note, hc.created_at FROM habit_checks hc
+			JOIN habits h ON h.id = hc.habit_id WHERE h.user_id = $1 AND h.deleted_at IS NULL AND hc.check_date = $2 ORDER BY h.title;`,
+			userID,
+			date,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting user's checks on date error: %w", err)
+	}
+	defer rows.Close()
+	result := make([]entity.UserCheck, 0)
+	for rows.Next() {
+		check := entity.UserCheck{}
+		err = rows.Scan(&check.ID, &check.HabitID, &check.HabitTitle, &check.CheckDate, &check.Status, &check.Note, &check.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("check row parsing error: %w", err)
+		}
+		result = append(result, check)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected check rows error: %w", rows.Err())
+	}
+	return result, nil
+}
+
+func (checksRepo *HabitChecksRepository) HabitsNotCheckedToday(ctx context.Context, userID uuid.UUID, today time.Time) ([]*entity.Habit, error) {
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = checksRepo.readConn.Query(
+			ctx,
+			`SELECT h.id, h.user_id, h.title, h.description, h.color, h.icon, h.start_date, h.created_at, h.updated_at, h.allow_multiple_per_day, h.tags
+			FROM habits h LEFT JOIN habit_checks hc ON hc.habit_id = h.id AND hc.check_date = $2
+			WHERE h.user_id = $1 AND h.deleted_at IS NULL AND hc.id IS NULL
+			AND EXISTS (SELECT 1 FROM habit_checks y WHERE y.habit_id = h.id AND y.check_date = $2::date - 1)
+			AND NOT EXISTS (SELECT 1 FROM habit_pauses p WHERE p.habit_id = h.id AND p.resumed_at IS NULL)
+			ORDER BY h.title;`,
+			userID,
+			today,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting habits not checked today error: %w", err)
+	}
+	defer rows.Close()
+	habits := make([]*entity.Habit, 0)
+	for rows.Next() {
+		h := entity.Habit{}
+		err = rows.Scan(&h.ID, &h.UserID, &h.Title, &h.Description, &h.Color, &h.Icon, &h.StartDate, &h.CreatedAt, &h.UpdatedAt, &h.AllowMultiplePerDay, &h.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("habit row parsing error: %w", err)
+		}
+		habits = append(habits, &h)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected habit rows error: %w", rows.Err())
+	}
+	return habits, nil
+}
+
+func (checksRepo *HabitChecksRepository) GetCheckedDates(ctx context.Context, habitID uuid.UUID, from, to time.Time) (map[string]entity.CheckStatus, error) {
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = checksRepo.readConn.Query(
+			ctx,
+			`SELECT check_date, status FROM habit_checks WHERE habit_id = $1 AND check_date >= $2 AND check_date <= $3;`,
+			habitID,
+			from,
+			to,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting checked dates for period error: %w", err)
+	}
+	defer rows.Close()
+	result := make(map[string]entity.CheckStatus)
+	for rows.Next() {
+		var date time.Time
+		var status entity.CheckStatus
+		err = rows.Scan(&date, &status)
+		if err != nil {
+			return nil, fmt.Errorf("check row parsing error: %w", err)
+		}
+		result[date.Format(time.DateOnly)] = status
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected check rows error: %w", rows.Err())
+	}
+	return result, nil
+}
+
+func (checksRepo *HabitChecksRepository) GetHabitIDsByDate(ctx context.Context, habitIDs []uuid.UUID, date time.Time) ([]uuid.UUID, error) {
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = checksRepo.readConn.Query(
+			ctx,
+			`SELECT DISTINCT habit_id FROM habit_checks WHERE habit_id = ANY($1) AND check_date = $2 AND status = 'checked';`,
+			habitIDs,
+			date,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting habits checked on date error: %w", err)
+	}
+	defer rows.Close()
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("habit id parsing error: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected error after scanning: %w", rows.Err())
+	}
+	return ids, nil
+}
+
+func (checksRepo *HabitChecksRepository) GetCheckedDatesByHabits(ctx context.Context, habitIDs []uuid.UUID, from, to time.Time) (map[uuid.UUID]map[string]entity.CheckStatus, error) {
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = checksRepo.readConn.Query(
+			ctx,
+			`SELECT habit_id, check_date, status FROM habit_checks WHERE habit_id = ANY($1) AND check_date >= $2 AND check_date <= $3;`,
+			habitIDs,
+			from,
+			to,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting checked dates of habits error: %w", err)
+	}
+	defer rows.Close()
+	result := make(map[uuid.UUID]map[string]entity.CheckStatus)
+	for rows.Next() {
+		var habitID uuid.UUID
+		var date time.Time
+		var status entity.CheckStatus
+		err = rows.Scan(&habitID, &date, &status)
+		if err != nil {
+			return nil, fmt.Errorf("check row parsing error: %w", err)
+		}
+		if result[habitID] == nil {
+			result[habitID] = make(map[string]entity.CheckStatus)
+		}
+		result[habitID][date.Format(time.DateOnly)] = status
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected check rows error: %w", rows.Err())
+	}
+	return result, nil
+}
+
+func (checksRepo *HabitChecksRepository) GetLastCheckDate(ctx context.Context, habitID uuid.UUID) (*time.Time, error) {
 	var date time.Time
-	if err := row.Scan(&date); err != nil {
+	err := withRetry(ctx, func() error {
+		row := checksRepo.readConn.QueryRow(
+			ctx,
+			`SELECT check_date FROM habit_checks WHERE habit_id = $1 AND status = 'checked' ORDER BY check_date DESC LIMIT 1;`,
+			habitID,
+		)
+		return row.Scan(&date)
+	})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
-		return nil, errors.New("getting last check date error: " + err.Error())
+		return nil, fmt.Errorf("getting last check date error: %w", err)
 	}
 	return &date, nil
 }
 
+func (checksRepo *HabitChecksRepository) Pause(ctx context.Context, habitID uuid.UUID, day time.Time) error {
+	_, err := checksRepo.conn.Exec(ctx, `INSERT INTO habit_pauses (habit_id, paused_at) VALUES ($1, $2);`, habitID, day)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			// Ongoing pause exists
+			case "23505":
+				return errorvalues.ErrHabitPaused
+			case "23503":
+				return errorvalues.ErrHabitNotFound
+			}
+		}
+		return fmt.Errorf("pausing habit error: %w", err)
+	}
+	return nil
+}
+
+func (checksRepo *HabitChecksRepository) Resume(ctx context.Context, habitID uuid.UUID, day time.Time) error {
+	// Pause can't end before it started
+	ct, err := checksRepo.conn.Exec(ctx, `UPDATE habit_pauses SET resumed_at = GREATEST($2, paused_at) WHERE habit_id = $1 AND resumed_at IS NULL;`, habitID, day)
+	if err != nil {
+		return fmt.Errorf("resuming habit error: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrHabitNotPaused
+	}
+	return nil
+}
+
+func (checksRepo *HabitChecksRepository) GetPauses(ctx context.Context, habitIDs []uuid.UUID) (map[uuid.UUID][]entity.HabitPause, error) {
+	result := make(map[uuid.UUID][]entity.HabitPause)
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = checksRepo.readConn.Query(
+			ctx,
+			`SELECT habit_id, paused_at, resumed_at FROM habit_pauses WHERE habit_id = ANY($1) ORDER BY paused_at, id;`,
+			habitIDs,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting pauses error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var habitID uuid.UUID
+		var pause entity.HabitPause
+		if err = rows.Scan(&habitID, &pause.PausedAt, &pause.ResumedAt); err != nil {
+			return nil, fmt.Errorf("unmarshalling pause error: %w", err)
+		}
+		result[habitID] = append(result[habitID], pause)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected error after scanning: %w", rows.Err())
+	}
+	return result, nil
+}
+
 func (checksRepo *HabitChecksRepository) CountByHabitID(ctx context.Context, habitID uuid.UUID) (int, error) {
-	row := checksRepo.conn.QueryRow(
-		ctx,
-		`SELECT COUNT(*) FROM habit_checks WHERE habit_id = $1;`,
-		habitID,
-	)
 	var count int
-	if err := row.Scan(&count); err != nil {
-		return 0, errors.New("error counting checks: " + err.Error())
+	err := withRetry(ctx, func() error {
+		row := checksRepo.readConn.QueryRow(
+			ctx,
+			`SELECT COUNT(*) FROM habit_checks WHERE habit_id = $1 AND status = 'checked';`,
+			habitID,
+		)
+		return row.Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error counting checks: %w", err)
 	}
 	return count, nil
 }
+
+func (checksRepo *HabitChecksRepository) CountByHabitAndDateRange(ctx context.Context, habitID uuid.UUID, from, to time.Time) (int, error) {
+	var count int
+	err := withRetry(ctx, func() error {
+		row := checksRepo.readConn.QueryRow(
+			ctx,
+			`SELECT COUNT(*) FROM habit_checks WHERE habit_id = $1 AND status = 'checked' AND check_date BETWEEN $2 AND $3;`,
+			habitID,
+			from,
+			to,
+		)
+		return row.Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error counting checks for period: %w", err)
+	}
+	return count, nil
+}
+
+func (checksRepo *HabitChecksRepository) CountByWeekday(ctx context.Context, habitID uuid.UUID) ([7]int, error) {
+	var counts [7]int
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = checksRepo.readConn.Query(
+			ctx,
+			`SELECT EXTRACT(DOW FROM check_date)::int AS weekday, COUNT(*) FROM habit_checks
+			WHERE habit_id = $1 AND status = 'checked' GROUP BY weekday;`,
+			habitID,
+		)
+		return err
+	})
+	if err != nil {
+		return counts, fmt.Errorf("counting checks by weekday error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var weekday, count int
+		err = rows.Scan(&weekday, &count)
+		if err != nil {
+			return counts, fmt.Errorf("weekday row parsing error: %w", err)
+		}
+		if weekday >= 0 && weekday < len(counts) {
+			counts[weekday] = count
+		}
+	}
+	if rows.Err() != nil {
+		return counts, fmt.Errorf("unexpected weekday rows error: %w", rows.Err())
+	}
+	return counts, nil
+}
+
+func (checksRepo *HabitChecksRepository) LockHabit(ctx context.Context, habitID uuid.UUID) error {
+	var id uuid.UUID
+	// Doesn't block foreign keys checks of inserted checks
+	err := checksRepo.conn.QueryRow(ctx, `SELECT id FROM habits WHERE id = $1 FOR NO KEY UPDATE;`, habitID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errorvalues.ErrHabitNotFound
+		}
+		return fmt.Errorf("locking habit error: %w", err)
+	}
+	return nil
+}
+
+func (checksRepo *HabitChecksRepository) GetStats(ctx context.Context, habitID uuid.UUID) (*StoredStats, error) {
+	stored := StoredStats{Stats: entity.HabitStats{ID: habitID}}
+	var lastCheck *time.Time
+	err := withRetry(ctx, func() error {
+		// Primary is queried, so stats changed by user's own write are seen at once
+		row := checksRepo.conn.QueryRow(
+			ctx,
+			`SELECT total_checks, current_streak, max_streak, last_check, completion_rate, paused, as_of, since FROM habit_stats WHERE habit_id = $1;`,
+			habitID,
+		)
+		return row.Scan(&stored.Stats.TotalChecks, &stored.Stats.CurrentStreak, &stored.Stats.MaxStreak, &lastCheck,
+			&stored.Stats.CompletionRate, &stored.Stats.Paused, &stored.AsOf, &stored.Since)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting stats error: %w", err)
+	}
+	if lastCheck != nil {
+		stored.Stats.LastCheck = *lastCheck
+	}
+	return &stored, nil
+}
+
+func (checksRepo *HabitChecksRepository) SaveStats(ctx context.Context, stored *StoredStats) error {
+	if stored == nil {
+		return errors.New("stats are nil")
+	}
+	var lastCheck *time.Time
+	if !stored.Stats.LastCheck.IsZero() {
+		lastCheck = &stored.Stats.LastCheck
+	}
+	_, err := checksRepo.conn.Exec(ctx, `INSERT INTO habit_stats (habit_id, total_checks, current_streak, max_streak, last_check, completion_rate, paused, as_of, since)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (habit_id) DO UPDATE SET total_checks = EXCLUDED.total_checks, current_streak = EXCLUDED.current_streak,
+		max_streak = EXCLUDED.max_streak, last_check = EXCLUDED.last_check, completion_rate = EXCLUDED.completion_rate,
+		paused = EXCLUDED.paused, as_of = EXCLUDED.as_of, since = EXCLUDED.since, updated_at = NOW();`,
+		stored.Stats.ID, stored.Stats.TotalChecks, stored.Stats.CurrentStreak, stored.Stats.MaxStreak, lastCheck,
+		stored.Stats.CompletionRate, stored.Stats.Paused, stored.AsOf, stored.Since,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return errorvalues.ErrHabitNotFound
+		}
+		return fmt.Errorf("saving stats error: %w", err)
+	}
+	return nil
+}
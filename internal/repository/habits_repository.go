@@ -3,49 +3,72 @@ package repository
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
-	"github.com/limbo/discipline/pkg/cleanup"
 	"github.com/limbo/discipline/pkg/entity"
 )
 
 type HabitsRepository struct {
 	conn PgConnection
+	// Connection for read-only queries. Points to conn if no replica configured.
+	readConn    PgConnection
+	slowQueries slowQueryLog
 }
 
 func NewHabitsRepo(cfg DBConfig) *HabitsRepository {
-	pool, err := pgxpool.New(context.Background(), cfg.ConnString())
-	if err != nil {
-		log.Fatal("creating connection for usersRepo error: " + err.Error())
-	}
-	err = pool.Ping(context.Background())
-	if err != nil {
-		log.Fatal("error while pinging connection for usersRepo: " + err.Error())
-	}
-	cleanup.Register(&cleanup.Job{
-		Name: "closing pgxpool",
-		F: func() error {
-			pool.Close()
-			return nil
-		},
-	})
+	return NewHabitsRepoWithReplica(cfg, nil)
+}
+
+// Creates repository with reads offloaded to replica described by replicaCfg.
+// If replicaCfg is nil, all queries go to primary.
+func NewHabitsRepoWithReplica(cfg, replicaCfg DBConfig) *HabitsRepository {
+	pool := connectPool(cfg, "habitsRepo")
 	return &HabitsRepository{
-		conn: pool,
+		conn:     pool,
+		readConn: connectReadPool(pool, replicaCfg, "habitsRepo"),
 	}
 }
 
-func NewHabitsRepoWithConn(conn PgConnection) *HabitsRepository {
-	err := conn.Ping(context.Background())
-	if err != nil {
-		log.Fatal("error while pingin connection for habitsRepo: " + err.Error())
+// Creates repository on given connections. readConn is optional,
+// if nil, conn is used for reads too.
+func NewHabitsRepoWithConn(conn, readConn PgConnection) *HabitsRepository {
+	return &HabitsRepository{
+		conn:     conn,
+		readConn: pingConns(conn, readConn, "habitsRepo"),
 	}
+}
+
+// Returns stats of primary connection pool, false if repository isn't backed by pgxpool.
+func (hr *HabitsRepository) PoolStats() (PoolStats, bool) {
+	return poolStats(hr.conn)
+}
+
+// Returns stats of replica connection pool, false if repository has no replica or it isn't backed by pgxpool.
+func (hr *HabitsRepository) ReplicaPoolStats() (PoolStats, bool) {
+	return replicaPoolStats(hr.conn, hr.readConn)
+}
+
+// Makes repository log queries running longer than threshold as warnings with logger.
+// Non-positive threshold turns logging off. Must be called before repository is used.
+func (hr *HabitsRepository) SetSlowQueryLogging(logger *slog.Logger, threshold time.Duration) {
+	hr.slowQueries = slowQueryLog{logger: logger, threshold: threshold}
+	hr.conn = hr.slowQueries.wrap(hr.conn)
+	hr.readConn = hr.slowQueries.wrap(hr.readConn)
+}
+
+func (hr *HabitsRepository) WithTx(tx pgx.Tx) HabitsRepositoryI {
+	conn := hr.slowQueries.wrap(txConn{tx})
+	// Reads go to tx too, so they see its uncommited changes
 	return &HabitsRepository{
-		conn: conn,
+		conn:        conn,
+		readConn:    conn,
+		slowQueries: hr.slowQueries,
 	}
 }
 
@@ -53,87 +76,342 @@ func (hr *HabitsRepository) Create(ctx context.Context, habit *entity.Habit) (uu
 	if habit == nil {
 		return uuid.UUID{}, errors.New("habit is nil")
 	}
-	tx, err := hr.conn.Begin(ctx)
-	if err != nil {
-		return uuid.UUID{}, errors.New("creating habit: tx start error: " + err.Error())
+	if habit.StartDate.IsZero() {
+		return uuid.UUID{}, errStartDateRequired
 	}
-	defer tx.Rollback(ctx)
-	_, err = tx.Exec(ctx, `INSERT INTO habits (user_id, title, description) VALUES ($1, $2, $3);`,
+	// Title conflict is reported by empty RETURNING, so concurrent creations of same habit don't race.
+	// Created row is returned right away, so it isn't read back from replica which may lag
+	var id uuid.UUID
+	habit.Tags = nonNilTags(habit.Tags)
+	row := hr.conn.QueryRow(ctx, `INSERT INTO habits (user_id, title, description, color, icon, start_date, allow_multiple_per_day, tags) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, title) WHERE deleted_at IS NULL DO NOTHING RETURNING id, created_at, updated_at;`,
 		habit.UserID,
 		habit.Title,
 		habit.Description,
+		habit.Color,
+		habit.Icon,
+		habit.StartDate,
+		habit.AllowMultiplePerDay,
+		habit.Tags,
 	)
+	err := row.Scan(&id, &habit.CreatedAt, &habit.UpdatedAt)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.UUID{}, errorvalues.ErrUserHasHabit
+		}
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			switch pgErr.Code {
-			// Unique violation
-			case "23505":
-				return uuid.UUID{}, errorvalues.ErrUserHasHabit
-			// FK violation
-			case "23503":
-				return uuid.UUID{}, errorvalues.ErrOwnerNotFound
-			}
+		// FK violation
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return uuid.UUID{}, errorvalues.ErrOwnerNotFound
 		}
-		return uuid.UUID{}, errors.New("creating habit db error: " + err.Error())
+		return uuid.UUID{}, fmt.Errorf("creating habit db error: %w", err)
 	}
-	var id uuid.UUID
-	row := tx.QueryRow(ctx, `SELECT id FROM habits WHERE title = $1 AND user_id = $2;`, habit.Title, habit.UserID)
-	if err = row.Scan(&id); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return id, errors.New("error searching id: habit not found after creation")
+	habit.ID = id
+	return id, nil
+}
+
+var errStartDateRequired = errors.New("habit start date is required")
+
+// Column of tags isn't nullable, so habit without them is stored (and returned) with empty list.
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// Returns habit's start date as query argument, nil if it's not set (so it's kept on update).
+func startDateArg(habit *entity.Habit) *time.Time {
+	if habit.StartDate.IsZero() {
+		return nil
+	}
+	return &habit.StartDate
+}
+
+func (hr *HabitsRepository) CreateMany(ctx context.Context, habits []*entity.Habit) ([]bool, error) {
+	tx, err := hr.conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating habits: tx start error: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	created := make([]bool, len(habits))
+	for i, habit := range habits {
+		if habit.StartDate.IsZero() {
+			return nil, errStartDateRequired
+		}
+		habit.Tags = nonNilTags(habit.Tags)
+		row := tx.QueryRow(ctx, `INSERT INTO habits (user_id, title, description, color, icon, start_date, allow_multiple_per_day, tags) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, title) WHERE deleted_at IS NULL DO NOTHING RETURNING id, start_date, created_at, updated_at;`,
+			habit.UserID,
+			habit.Title,
+			habit.Description,
+			habit.Color,
+			habit.Icon,
+			habit.StartDate,
+			habit.AllowMultiplePerDay,
+			habit.Tags,
+		)
+		err = row.Scan(&habit.ID, &habit.StartDate, &habit.CreatedAt, &habit.UpdatedAt)
+		if err != nil {
+			// Nothing returned on conflict
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return nil, errorvalues.ErrOwnerNotFound
+			}
+			return nil, fmt.Errorf("creating habits db error: %w", err)
 		}
-		return id, errors.New("error searching id: " + err.Error())
+		created[i] = true
 	}
 	err = tx.Commit(ctx)
 	if err != nil {
-		return id, errors.New("commiting tx error: " + err.Error())
+		return nil, fmt.Errorf("commiting tx error: %w", err)
 	}
-	return id, nil
+	return created, nil
 }
 
 func (hr *HabitsRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Habit, error) {
 	var habit entity.Habit
 	habit.ID = id
-	row := hr.conn.QueryRow(ctx, `SELECT user_id, title, description, created_at, updated_at FROM habits WHERE id = $1;`, id)
-	if err := row.Scan(&habit.UserID, &habit.Title, &habit.Description, &habit.CreatedAt, &habit.UpdatedAt); err != nil {
+	err := withRetry(ctx, func() error {
+		// Primary is read, since result is used to check ownership and replica may lag behind
+		row := hr.conn.QueryRow(ctx, `SELECT user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags FROM habits WHERE id = $1 AND deleted_at IS NULL;`, id)
+		return row.Scan(&habit.UserID, &habit.Title, &habit.Description, &habit.Color, &habit.Icon, &habit.StartDate, &habit.CreatedAt, &habit.UpdatedAt, &habit.AllowMultiplePerDay, &habit.Tags)
+	})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errorvalues.ErrHabitNotFound
 		}
-		return nil, errors.New("getting habit by id error: " + err.Error())
+		return nil, fmt.Errorf("getting habit by id error: %w", err)
 	}
 	return &habit, nil
 
 }
 
-func (hr *HabitsRepository) GetByUserID(ctx context.Context, uid uuid.UUID, limit, offset int) ([]*entity.Habit, error) {
+func (hr *HabitsRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.Habit, error) {
+	habits := make(map[uuid.UUID]*entity.Habit, len(ids))
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		// Primary is read for the same reason as in GetByID
+		rows, err = hr.conn.Query(ctx, `SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE id = ANY($1) AND deleted_at IS NULL;`, ids)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting habits by ids error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		h := entity.Habit{}
+		err = rows.Scan(&h.ID, &h.UserID, &h.Title, &h.Description, &h.Color, &h.Icon, &h.StartDate, &h.CreatedAt, &h.UpdatedAt, &h.AllowMultiplePerDay, &h.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("unmarhalling habit error: %w", err)
+		}
+		habits[h.ID] = &h
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected error after scanning: %w", rows.Err())
+	}
+	return habits, nil
+}
+
+// ORDER BY clauses of user's habits list by sort option. Client's value never gets into query itself,
+// id keeps order stable for pagination.
+var habitsListOrders = map[entity.HabitSort]string{
+	entity.HabitSortCreatedAt: "created_at, id",
+	entity.HabitSortPosition:  "position, id",
+}
+
+func (hr *HabitsRepository) GetByUserID(ctx context.Context, uid uuid.UUID, sort entity.HabitSort, limit, offset int) ([]*entity.Habit, error) {
+	order, ok := habitsListOrders[sort]
+	if !ok {
+		order = habitsListOrders[entity.HabitSortCreatedAt]
+	}
+	habits := make([]*entity.Habit, 0)
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = hr.readConn.Query(ctx, `SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY `+order+` LIMIT $2 OFFSET $3;`, uid, limit, offset)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting habits by uid error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		h := entity.Habit{}
+		err = rows.Scan(&h.ID, &h.UserID, &h.Title, &h.Description, &h.Color, &h.Icon, &h.StartDate, &h.CreatedAt, &h.UpdatedAt, &h.AllowMultiplePerDay, &h.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("unmarhalling habit error: %w", err)
+		}
+		habits = append(habits, &h)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected error after scanning: %w", err)
+	}
+	return habits, nil
+}
+
+func (hr *HabitsRepository) CountByUserID(ctx context.Context, uid uuid.UUID) (int, error) {
+	var count int
+	err := withRetry(ctx, func() error {
+		row := hr.readConn.QueryRow(ctx, `SELECT COUNT(*) FROM habits WHERE user_id = $1 AND deleted_at IS NULL;`, uid)
+		return row.Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("counting habits by uid error: %w", err)
+	}
+	return count, nil
+}
+
+func (hr *HabitsRepository) StreamByUserID(ctx context.Context, uid uuid.UUID, fn func(habit *entity.Habit) error) error {
+	rows, err := hr.readConn.Query(ctx, `SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at, id;`, uid)
+	if err != nil {
+		return fmt.Errorf("streaming habits by uid error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		h := entity.Habit{}
+		err = rows.Scan(&h.ID, &h.UserID, &h.Title, &h.Description, &h.Color, &h.Icon, &h.StartDate, &h.CreatedAt, &h.UpdatedAt, &h.AllowMultiplePerDay, &h.Tags)
+		if err != nil {
+			return fmt.Errorf("unmarhalling habit error: %w", err)
+		}
+		if err = fn(&h); err != nil {
+			return err
+		}
+	}
+	if rows.Err() != nil {
+		return fmt.Errorf("unexpected error after scanning: %w", rows.Err())
+	}
+	return nil
+}
+
+func (hr *HabitsRepository) GetByUserIDAfter(ctx context.Context, uid uuid.UUID, cursor *entity.HabitCursor, limit int) ([]*entity.Habit, error) {
+	habits := make([]*entity.Habit, 0)
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		if cursor == nil {
+			rows, err = hr.readConn.Query(ctx, `SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+			FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at, id LIMIT $2;`, uid, limit)
+			return err
+		}
+		rows, err = hr.readConn.Query(ctx, `SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE user_id = $1 AND deleted_at IS NULL AND (created_at, id) > ($2, $3) ORDER BY created_at, id LIMIT $4;`,
+			uid, cursor.CreatedAt, cursor.ID, limit)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting habits by uid after cursor error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		h := entity.Habit{}
+		err = rows.Scan(&h.ID, &h.UserID, &h.Title, &h.Description, &h.Color, &h.Icon, &h.StartDate, &h.CreatedAt, &h.UpdatedAt, &h.AllowMultiplePerDay, &h.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("unmarhalling habit error: %w", err)
+		}
+		habits = append(habits, &h)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected error after scanning: %w", rows.Err())
+	}
+	return habits, nil
+}
+
+func (hr *HabitsRepository) ListTags(ctx context.Context, uid uuid.UUID) ([]string, error) {
+	tags := make([]string, 0)
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = hr.readConn.Query(ctx, `SELECT DISTINCT unnest(tags) FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY 1;`, uid)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing tags error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag string
+		if err = rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("unmarhalling tag error: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected error after scanning: %w", rows.Err())
+	}
+	return tags, nil
+}
+
+func (hr *HabitsRepository) MaxUpdatedAt(ctx context.Context, uid uuid.UUID) (time.Time, error) {
+	var maxUpdated *time.Time
+	err := withRetry(ctx, func() error {
+		// GREATEST skips NULLs, so deletion counts as change of list too
+		// Habits gone from table (purged or merged) are tracked by user's habits_changed_at
+		return hr.readConn.QueryRow(ctx, `SELECT GREATEST(
+			(SELECT MAX(GREATEST(created_at, updated_at, deleted_at)) FROM habits WHERE user_id = $1),
+			(SELECT habits_changed_at FROM users WHERE id = $1));`, uid).Scan(&maxUpdated)
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("getting last update time error: %w", err)
+	}
+	if maxUpdated == nil {
+		return time.Time{}, nil
+	}
+	return *maxUpdated, nil
+}
+
+func (hr *HabitsRepository) GetByUserIDs(ctx context.Context, uids []uuid.UUID, limit, offset int) ([]*entity.Habit, error) {
 	habits := make([]*entity.Habit, 0)
-	rows, err := hr.conn.Query(ctx, `SELECT id, user_id, title, description, created_at, updated_at 
-		FROM habits WHERE user_id = $1 LIMIT $2 OFFSET $3;`, uid, limit, offset)
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = hr.readConn.Query(ctx, `SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE user_id = ANY($1) AND deleted_at IS NULL ORDER BY created_at, id LIMIT $2 OFFSET $3;`, uids, limit, offset)
+		return err
+	})
 	if err != nil {
-		return nil, errors.New("getting habits by uid error: " + err.Error())
+		return nil, fmt.Errorf("getting habits by uids error: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
 		h := entity.Habit{}
-		err = rows.Scan(&h.ID, &h.UserID, &h.Title, &h.Description, &h.CreatedAt, &h.UpdatedAt)
+		err = rows.Scan(&h.ID, &h.UserID, &h.Title, &h.Description, &h.Color, &h.Icon, &h.StartDate, &h.CreatedAt, &h.UpdatedAt, &h.AllowMultiplePerDay, &h.Tags)
 		if err != nil {
-			return nil, errors.New("unmarhalling habit error: " + err.Error())
+			return nil, fmt.Errorf("unmarhalling habit error: %w", err)
 		}
 		habits = append(habits, &h)
 	}
 	if rows.Err() != nil {
-		return nil, errors.New("unexpected error after scanning: " + err.Error())
+		return nil, fmt.Errorf("unexpected error after scanning: %w", rows.Err())
 	}
 	return habits, nil
 }
 
+func (hr *HabitsRepository) CountByUserIDs(ctx context.Context, uids []uuid.UUID) (int, error) {
+	var count int
+	err := withRetry(ctx, func() error {
+		row := hr.readConn.QueryRow(ctx, `SELECT COUNT(*) FROM habits WHERE user_id = ANY($1) AND deleted_at IS NULL;`, uids)
+		return row.Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("counting habits by uids error: %w", err)
+	}
+	return count, nil
+}
+
 func (hr *HabitsRepository) Update(ctx context.Context, habit *entity.Habit) error {
-	ct, err := hr.conn.Exec(ctx, `UPDATE habits SET title = $1, description = $2, updated_at = NOW() WHERE id = $3;`,
-		habit.Title, habit.Description, habit.ID,
+	ct, err := hr.conn.Exec(ctx, `UPDATE habits SET title = $1, description = $2, color = $3, icon = $4, start_date = COALESCE($5, start_date), tags = COALESCE($6, tags),
+		updated_at = NOW() WHERE id = $7 AND deleted_at IS NULL;`,
+		habit.Title, habit.Description, habit.Color, habit.Icon, startDateArg(habit), habit.Tags, habit.ID,
 	)
 	if err != nil {
-		return errors.New("error updating habit: " + err.Error())
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return errorvalues.ErrUserHasHabit
+		}
+		return fmt.Errorf("error updating habit: %w", err)
 	}
 	if ct.RowsAffected() == 0 {
 		return errorvalues.ErrHabitNotFound
@@ -141,13 +419,193 @@ func (hr *HabitsRepository) Update(ctx context.Context, habit *entity.Habit) err
 	return nil
 }
 
+func (hr *HabitsRepository) UpdateTitle(ctx context.Context, id uuid.UUID, title string) error {
+	ct, err := hr.conn.Exec(ctx, `UPDATE habits SET title = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL;`, title, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return errorvalues.ErrUserHasHabit
+		}
+		return fmt.Errorf("error updating habit title: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrHabitNotFound
+	}
+	return nil
+}
+
+func (hr *HabitsRepository) UpdateDescription(ctx context.Context, id uuid.UUID, description string) error {
+	ct, err := hr.conn.Exec(ctx, `UPDATE habits SET description = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL;`, description, id)
+	if err != nil {
+		return fmt.Errorf("error updating habit description: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrHabitNotFound
+	}
+	return nil
+}
+
+func (hr *HabitsRepository) Merge(ctx context.Context, sourceID, targetID, userID uuid.UUID) (int64, error) {
+	tx, err := hr.conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("merging habits: tx start error: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	// Both habits are locked, so no marks are added to source and owners don't change while they are moved.
+	// Locks are taken in id order, so opposite merges of the same pair don't deadlock
+	rows, err := tx.Query(ctx, `SELECT id, user_id, allow_multiple_per_day FROM habits WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id FOR UPDATE;`,
+		[]uuid.UUID{sourceID, targetID})
+	if err != nil {
+		return 0, fmt.Errorf("locking merged habits error: %w", err)
+	}
+	found := 0
+	wrongOwner := false
+	targetAllowsMultiple := false
+	for rows.Next() {
+		var id, ownerID uuid.UUID
+		var allowMultiple bool
+		if err = rows.Scan(&id, &ownerID, &allowMultiple); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning merged habit error: %w", err)
+		}
+		found++
+		wrongOwner = wrongOwner || ownerID != userID
+		if id == targetID {
+			targetAllowsMultiple = allowMultiple
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("locking merged habits error: %w", err)
+	}
+	if found != 2 {
+		return 0, errorvalues.ErrHabitNotFound
+	}
+	if wrongOwner {
+		return 0, errorvalues.ErrWrongOwner
+	}
+	var ct pgconn.CommandTag
+	if targetAllowsMultiple {
+		ct, err = tx.Exec(ctx, `UPDATE habit_checks s SET habit_id = $2 WHERE s.habit_id = $1
+		AND NOT EXISTS (SELECT 1 FROM habit_checks t WHERE t.habit_id = $2 AND t.check_date = s.check_date);`, sourceID, targetID)
+	} else {
+		// Target allows single mark a day, so repeated marks of source are collapsed into the earliest one
+		ct, err = tx.Exec(ctx, `UPDATE habit_checks s SET habit_id = $2, repeatable = FALSE
+		WHERE s.id IN (SELECT DISTINCT ON (check_date) id FROM habit_checks WHERE habit_id = $1 ORDER BY check_date, created_at, id)
+		AND NOT EXISTS (SELECT 1 FROM habit_checks t WHERE t.habit_id = $2 AND t.check_date = s.check_date);`, sourceID, targetID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("moving checks error: %w", err)
+	}
+	moved := ct.RowsAffected()
+	// Conflicting marks, pauses and stats of source go with it
+	_, err = tx.Exec(ctx, `WITH removed AS (DELETE FROM habits WHERE id = $1 RETURNING user_id)
+		UPDATE users SET habits_changed_at = NOW() WHERE id IN (SELECT user_id FROM removed);`, sourceID)
+	if err != nil {
+		return 0, fmt.Errorf("deleting merged habit error: %w", err)
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM habit_stats WHERE habit_id = $1;`, targetID)
+	if err != nil {
+		return 0, fmt.Errorf("dropping stats of merged habit error: %w", err)
+	}
+	_, err = tx.Exec(ctx, `UPDATE habits SET updated_at = NOW() WHERE id = $1;`, targetID)
+	if err != nil {
+		return 0, fmt.Errorf("updating merged habit error: %w", err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("commiting tx error: %w", err)
+	}
+	return moved, nil
+}
+
+func (hr *HabitsRepository) Reorder(ctx context.Context, uid uuid.UUID, orderedIDs []uuid.UUID) error {
+	tx, err := hr.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("reordering habits: tx start error: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	// All user's habits are locked, so concurrent reorders and creations don't interleave positions
+	var listed int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FILTER (WHERE id = ANY($2)) FROM (SELECT id FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY id FOR UPDATE) h;`,
+		uid, orderedIDs).Scan(&listed)
+	if err != nil {
+		return fmt.Errorf("locking user's habits error: %w", err)
+	}
+	// Some of habits are unknown or owned by another user, none is moved then
+	if listed != len(orderedIDs) {
+		return errorvalues.ErrHabitNotFound
+	}
+	// Whole list is renumbered from 1: listed habits first, then the rest in their current order,
+	// so positions stay unique
+	_, err = tx.Exec(ctx, `UPDATE habits SET position = ordered.n, updated_at = NOW()
+		FROM (SELECT h.id, ROW_NUMBER() OVER (ORDER BY l.n NULLS LAST, h.position, h.id) AS n
+			FROM habits h LEFT JOIN unnest($2::uuid[]) WITH ORDINALITY AS l(id, n) ON l.id = h.id
+			WHERE h.user_id = $1 AND h.deleted_at IS NULL) ordered
+		WHERE habits.id = ordered.id AND habits.position IS DISTINCT FROM ordered.n;`, uid, orderedIDs)
+	if err != nil {
+		return fmt.Errorf("updating habits positions error: %w", err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("commiting tx error: %w", err)
+	}
+	return nil
+}
+
 func (hr *HabitsRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	ct, err := hr.conn.Exec(ctx, `DELETE FROM habits WHERE id = $1;`, id)
+	ct, err := hr.conn.Exec(ctx, `UPDATE habits SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL;`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting habit: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrHabitNotFound
+	}
+	return nil
+}
+
+func (hr *HabitsRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entity.Habit, error) {
+	var habit entity.Habit
+	habit.ID = id
+	err := withRetry(ctx, func() error {
+		row := hr.conn.QueryRow(ctx, `SELECT user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags, deleted_at
+		FROM habits WHERE id = $1 AND deleted_at IS NOT NULL;`, id)
+		return row.Scan(&habit.UserID, &habit.Title, &habit.Description, &habit.Color, &habit.Icon, &habit.StartDate, &habit.CreatedAt, &habit.UpdatedAt, &habit.AllowMultiplePerDay, &habit.Tags, &habit.DeletedAt)
+	})
 	if err != nil {
-		return errors.New("error deleting habit: " + err.Error())
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errorvalues.ErrHabitNotFound
+		}
+		return nil, fmt.Errorf("getting deleted habit error: %w", err)
+	}
+	return &habit, nil
+}
+
+func (hr *HabitsRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	ct, err := hr.conn.Exec(ctx, `UPDATE habits SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at IS NOT NULL;`, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		// Title was taken by new habit since deletion
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return errorvalues.ErrUserHasHabit
+		}
+		return fmt.Errorf("error restoring habit: %w", err)
 	}
 	if ct.RowsAffected() == 0 {
 		return errorvalues.ErrHabitNotFound
 	}
 	return nil
 }
+
+func (hr *HabitsRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	// Owners keep deletion time, so their lists don't look older after purge
+	var purged int64
+	err := hr.conn.QueryRow(ctx, `WITH purged AS (DELETE FROM habits WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING user_id, deleted_at),
+		owners AS (UPDATE users u SET habits_changed_at = GREATEST(u.habits_changed_at, p.deleted_at)
+			FROM (SELECT user_id, MAX(deleted_at) AS deleted_at FROM purged GROUP BY user_id) p WHERE u.id = p.user_id)
+		SELECT COUNT(*) FROM purged;`, before).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("error purging deleted habits: %w", err)
+	}
+	return purged, nil
+}
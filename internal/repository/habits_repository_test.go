@@ -19,6 +19,7 @@ import (
 	"github.com/pashagolub/pgxmock/v2"
 	"github.com/pressly/goose"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -26,6 +27,8 @@ import (
 
 var (
 	userID = uuid.New()
+	// Start date of habits created in tests, repository requires it set
+	testStartDate = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 )
 
 func TestCreateHabit(t *testing.T) {
@@ -33,56 +36,131 @@ func TestCreateHabit(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	repo := repository.NewHabitsRepoWithConn(mock)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
 	habit := entity.Habit{
 		UserID:      userID,
 		Title:       "test_habit",
 		Description: "blah blah blah",
+		Color:       "#00ff00",
+		Icon:        "dumbbell",
+		StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Tags:        []string{"health", "morning"},
 	}
 	hid := uuid.New()
+	now := time.Now()
 	ctx := context.Background()
-	query := regexp.QuoteMeta(`INSERT INTO habits (user_id, title, description) VALUES ($1, $2, $3);`)
-	selectQuery := regexp.QuoteMeta(`SELECT id FROM habits WHERE title = $1 AND user_id = $2;`)
+	query := regexp.QuoteMeta(`INSERT INTO habits (user_id, title, description, color, icon, start_date, allow_multiple_per_day, tags) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, title) WHERE deleted_at IS NULL DO NOTHING RETURNING id, created_at, updated_at;`)
 	t.Run("successfully created", func(t *testing.T) {
-		mock.ExpectBegin()
-		mock.ExpectExec(query).
-			WithArgs(habit.UserID, habit.Title, habit.Description).
-			WillReturnResult(pgxmock.NewResult("INSERT", 1))
-		mock.ExpectQuery(selectQuery).
-			WithArgs(habit.Title, habit.UserID).
-			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(hid))
-		mock.ExpectCommit()
+		mock.ExpectQuery(query).
+			WithArgs(habit.UserID, habit.Title, habit.Description, habit.Color, habit.Icon, habit.StartDate, habit.AllowMultiplePerDay, habit.Tags).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(hid, now, now))
 		id, err := repo.Create(ctx, &habit)
 		assert.NoError(t, err)
 		assert.Equal(t, hid, id)
+		// Row is filled from RETURNING, no read back needed
+		assert.Equal(t, hid, habit.ID)
+		assert.Equal(t, now, habit.CreatedAt)
+		assert.Equal(t, now, habit.UpdatedAt)
 	})
-	t.Run("Unique violation", func(t *testing.T) {
-		mock.ExpectBegin()
-		mock.ExpectExec(query).
-			WithArgs(habit.UserID, habit.Title, habit.Description).
-			WillReturnError(&pgconn.PgError{Code: "23505"})
-		mock.ExpectRollback()
+	t.Run("title conflict", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(habit.UserID, habit.Title, habit.Description, habit.Color, habit.Icon, habit.StartDate, habit.AllowMultiplePerDay, habit.Tags).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}))
 		_, err := repo.Create(ctx, &habit)
 		assert.ErrorIs(t, err, errorvalues.ErrUserHasHabit)
 	})
 	t.Run("FK violation", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(habit.UserID, habit.Title, habit.Description, habit.Color, habit.Icon, habit.StartDate, habit.AllowMultiplePerDay, habit.Tags).
+			WillReturnError(&pgconn.PgError{Code: "23503"})
+		_, err := repo.Create(ctx, &habit)
+		assert.ErrorIs(t, err, errorvalues.ErrOwnerNotFound)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(habit.UserID, habit.Title, habit.Description, habit.Color, habit.Icon, habit.StartDate, habit.AllowMultiplePerDay, habit.Tags).
+			WillReturnError(errors.New("db error"))
+		_, err := repo.Create(ctx, &habit)
+		assert.Error(t, err)
+	})
+	t.Run("no tags stored as empty list", func(t *testing.T) {
+		untagged := entity.Habit{UserID: userID, Title: "untagged", StartDate: habit.StartDate}
+		mock.ExpectQuery(query).
+			WithArgs(userID, "untagged", "", "", "", habit.StartDate, false, []string{}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(hid, now, now))
+		_, err := repo.Create(ctx, &untagged)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{}, untagged.Tags)
+	})
+	t.Run("start date required", func(t *testing.T) {
+		_, err := repo.Create(ctx, &entity.Habit{UserID: userID, Title: "test_habit"})
+		assert.Error(t, err)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateManyHabits(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	ctx := context.Background()
+	query := regexp.QuoteMeta(`INSERT INTO habits (user_id, title, description, color, icon, start_date, allow_multiple_per_day, tags) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, title) WHERE deleted_at IS NULL DO NOTHING RETURNING id, start_date, created_at, updated_at;`)
+	newHabits := func() []*entity.Habit {
+		return []*entity.Habit{
+			{UserID: userID, Title: "first", StartDate: testStartDate, AllowMultiplePerDay: true},
+			{UserID: userID, Title: "second", StartDate: testStartDate},
+		}
+	}
+	columns := []string{"id", "start_date", "created_at", "updated_at"}
+	now := time.Now()
+	t.Run("created with conflict skipped", func(t *testing.T) {
+		habits := newHabits()
+		hid := uuid.New()
 		mock.ExpectBegin()
-		mock.ExpectExec(query).
-			WithArgs(habit.UserID, habit.Title, habit.Description).
+		mock.ExpectQuery(query).
+			WithArgs(userID, "first", "", "", "", testStartDate, true, []string{}).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(hid, testStartDate, now, now))
+		mock.ExpectQuery(query).
+			WithArgs(userID, "second", "", "", "", testStartDate, false, []string{}).
+			WillReturnRows(pgxmock.NewRows(columns))
+		mock.ExpectCommit()
+		created, err := repo.CreateMany(ctx, habits)
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true, false}, created)
+		assert.Equal(t, hid, habits[0].ID)
+		assert.Equal(t, testStartDate, habits[0].StartDate)
+		assert.Equal(t, now, habits[0].CreatedAt)
+		assert.True(t, habits[0].AllowMultiplePerDay)
+	})
+	t.Run("FK violation", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).
+			WithArgs(userID, "first", "", "", "", testStartDate, true, []string{}).
 			WillReturnError(&pgconn.PgError{Code: "23503"})
 		mock.ExpectRollback()
-		_, err := repo.Create(ctx, &habit)
+		_, err := repo.CreateMany(ctx, newHabits())
 		assert.ErrorIs(t, err, errorvalues.ErrOwnerNotFound)
 	})
 	t.Run("db error", func(t *testing.T) {
 		mock.ExpectBegin()
-		mock.ExpectExec(query).
-			WithArgs(habit.UserID, habit.Title, habit.Description).
+		mock.ExpectQuery(query).
+			WithArgs(userID, "first", "", "", "", testStartDate, true, []string{}).
 			WillReturnError(errors.New("db error"))
 		mock.ExpectRollback()
-		_, err := repo.Create(ctx, &habit)
+		_, err := repo.CreateMany(ctx, newHabits())
 		assert.Error(t, err)
 	})
+	t.Run("start date required", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		_, err := repo.CreateMany(ctx, []*entity.Habit{{UserID: userID, Title: "first"}})
+		assert.Error(t, err)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestGetHabitByID(t *testing.T) {
@@ -90,22 +168,24 @@ func TestGetHabitByID(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	repo := repository.NewHabitsRepoWithConn(mock)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
 	habit := entity.Habit{
 		ID:          uuid.New(),
 		UserID:      userID,
 		Title:       "test_habit",
 		Description: "blah blah blah",
+		Color:       "#00ff00",
+		Icon:        "dumbbell",
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
-	query := regexp.QuoteMeta(`SELECT user_id, title, description, created_at, updated_at FROM habits WHERE id = $1;`)
+	query := regexp.QuoteMeta(`SELECT user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags FROM habits WHERE id = $1 AND deleted_at IS NULL;`)
 	ctx := context.Background()
 	t.Run("success", func(t *testing.T) {
 		mock.ExpectQuery(query).
 			WithArgs(habit.ID).
-			WillReturnRows(pgxmock.NewRows([]string{"user_id", "title", "description", "created_at", "updated_at"}).
-				AddRow(habit.UserID, habit.Title, habit.Description, habit.CreatedAt, habit.UpdatedAt),
+			WillReturnRows(pgxmock.NewRows([]string{"user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}).
+				AddRow(habit.UserID, habit.Title, habit.Description, habit.Color, habit.Icon, habit.StartDate, habit.CreatedAt, habit.UpdatedAt, habit.AllowMultiplePerDay, habit.Tags),
 			)
 		result, err := repo.GetByID(ctx, habit.ID)
 		assert.NoError(t, err)
@@ -127,12 +207,216 @@ func TestGetHabitByID(t *testing.T) {
 	})
 }
 
+func TestGetHabitsByIDs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	habit := entity.Habit{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Title:     "test_habit",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	missing := uuid.New()
+	query := regexp.QuoteMeta(`SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE id = ANY($1) AND deleted_at IS NULL;`)
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs([]uuid.UUID{habit.ID, missing}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}).
+				AddRow(habit.ID, habit.UserID, habit.Title, habit.Description, habit.Color, habit.Icon, habit.StartDate, habit.CreatedAt, habit.UpdatedAt, habit.AllowMultiplePerDay, habit.Tags),
+			)
+		result, err := repo.GetByIDs(ctx, []uuid.UUID{habit.ID, missing})
+		assert.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]*entity.Habit{habit.ID: &habit}, result)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs([]uuid.UUID{habit.ID}).
+			WillReturnError(errors.New("db error"))
+		_, err := repo.GetByIDs(ctx, []uuid.UUID{habit.ID})
+		assert.Error(t, err)
+	})
+}
+
+func TestCountHabitsByUserID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT COUNT(*) FROM habits WHERE user_id = $1 AND deleted_at IS NULL;`)
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(7))
+		count, err := repo.CountByUserID(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, count)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(userID).
+			WillReturnError(errors.New("db error"))
+		_, err := repo.CountByUserID(ctx, userID)
+		assert.Error(t, err)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamHabitsByUserID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at, id;`)
+	columns := []string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}
+	rows := func() *pgxmock.Rows {
+		return pgxmock.NewRows(columns).
+			AddRow(uuid.New(), userID, "first", "", "", "", time.Time{}, time.Time{}, time.Time{}, false, []string{}).
+			AddRow(uuid.New(), userID, "second", "", "", "", time.Time{}, time.Time{}, time.Time{}, false, []string{})
+	}
+	ctx := context.Background()
+	t.Run("all habits", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID).WillReturnRows(rows())
+		titles := make([]string, 0)
+		err := repo.StreamByUserID(ctx, userID, func(h *entity.Habit) error {
+			titles = append(titles, h.Title)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, titles)
+	})
+	t.Run("stopped by fn", func(t *testing.T) {
+		errStop := errors.New("stop")
+		mock.ExpectQuery(query).WithArgs(userID).WillReturnRows(rows())
+		calls := 0
+		err := repo.StreamByUserID(ctx, userID, func(h *entity.Habit) error {
+			calls++
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, calls)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeHabits(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	sourceID, targetID := uuid.New(), uuid.New()
+	lockQuery := regexp.QuoteMeta(`SELECT id, user_id, allow_multiple_per_day FROM habits WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id FOR UPDATE;`)
+	moveQuery := regexp.QuoteMeta(`UPDATE habit_checks s SET habit_id = $2 WHERE s.habit_id = $1
+		AND NOT EXISTS (SELECT 1 FROM habit_checks t WHERE t.habit_id = $2 AND t.check_date = s.check_date);`)
+	collapseQuery := regexp.QuoteMeta(`UPDATE habit_checks s SET habit_id = $2, repeatable = FALSE
+		WHERE s.id IN (SELECT DISTINCT ON (check_date) id FROM habit_checks WHERE habit_id = $1 ORDER BY check_date, created_at, id)
+		AND NOT EXISTS (SELECT 1 FROM habit_checks t WHERE t.habit_id = $2 AND t.check_date = s.check_date);`)
+	lockedRows := func(targetOwner uuid.UUID, targetAllowsMultiple bool) *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"id", "user_id", "allow_multiple_per_day"}).
+			AddRow(sourceID, userID, true).
+			AddRow(targetID, targetOwner, targetAllowsMultiple)
+	}
+	expectRemoval := func() {
+		mock.ExpectExec(regexp.QuoteMeta(`WITH removed AS (DELETE FROM habits WHERE id = $1 RETURNING user_id)
+		UPDATE users SET habits_changed_at = NOW() WHERE id IN (SELECT user_id FROM removed);`)).WithArgs(sourceID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM habit_stats WHERE habit_id = $1;`)).WithArgs(targetID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE habits SET updated_at = NOW() WHERE id = $1;`)).WithArgs(targetID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectCommit()
+	}
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs([]uuid.UUID{sourceID, targetID}).WillReturnRows(lockedRows(userID, true))
+		mock.ExpectExec(moveQuery).WithArgs(sourceID, targetID).WillReturnResult(pgxmock.NewResult("UPDATE", 4))
+		expectRemoval()
+		moved, err := repo.Merge(ctx, sourceID, targetID, userID)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 4, moved)
+	})
+	t.Run("repeated marks collapsed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs([]uuid.UUID{sourceID, targetID}).WillReturnRows(lockedRows(userID, false))
+		mock.ExpectExec(collapseQuery).WithArgs(sourceID, targetID).WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+		expectRemoval()
+		moved, err := repo.Merge(ctx, sourceID, targetID, userID)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, moved)
+	})
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs([]uuid.UUID{sourceID, targetID}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "allow_multiple_per_day"}).AddRow(targetID, userID, false))
+		mock.ExpectRollback()
+		_, err := repo.Merge(ctx, sourceID, targetID, userID)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+	})
+	t.Run("wrong owner", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs([]uuid.UUID{sourceID, targetID}).WillReturnRows(lockedRows(uuid.New(), false))
+		mock.ExpectRollback()
+		_, err := repo.Merge(ctx, sourceID, targetID, userID)
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorderHabits(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	lockQuery := regexp.QuoteMeta(`SELECT COUNT(*) FILTER (WHERE id = ANY($2)) FROM (SELECT id FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY id FOR UPDATE) h;`)
+	query := regexp.QuoteMeta(`UPDATE habits SET position = ordered.n, updated_at = NOW()
+		FROM (SELECT h.id, ROW_NUMBER() OVER (ORDER BY l.n NULLS LAST, h.position, h.id) AS n
+			FROM habits h LEFT JOIN unnest($2::uuid[]) WITH ORDINALITY AS l(id, n) ON l.id = h.id
+			WHERE h.user_id = $1 AND h.deleted_at IS NULL) ordered
+		WHERE habits.id = ordered.id AND habits.position IS DISTINCT FROM ordered.n;`)
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(userID, ids).WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectExec(query).WithArgs(userID, ids).WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+		mock.ExpectCommit()
+		assert.NoError(t, repo.Reorder(ctx, userID, ids))
+	})
+	t.Run("partial list renumbers the rest", func(t *testing.T) {
+		// Third habit isn't listed, it's moved after listed ones
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(userID, ids).WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectExec(query).WithArgs(userID, ids).WillReturnResult(pgxmock.NewResult("UPDATE", 3))
+		mock.ExpectCommit()
+		assert.NoError(t, repo.Reorder(ctx, userID, ids))
+	})
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(userID, ids).WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+		assert.ErrorIs(t, repo.Reorder(ctx, userID, ids), errorvalues.ErrHabitNotFound)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetHabitsByUserID(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
 		t.Fatal(err)
 	}
-	repo := repository.NewHabitsRepoWithConn(mock)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
 	habits := []*entity.Habit{
 		{
 			ID:        uuid.New(),
@@ -156,20 +440,20 @@ func TestGetHabitsByUserID(t *testing.T) {
 			UpdatedAt: time.Now().Add(time.Hour * 2),
 		},
 	}
-	query := regexp.QuoteMeta(`SELECT id, user_id, title, description, created_at, updated_at 
-		FROM habits WHERE user_id = $1 LIMIT $2 OFFSET $3;`)
+	query := regexp.QuoteMeta(`SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at, id LIMIT $2 OFFSET $3;`)
 	ctx := context.Background()
 	t.Run("success", func(t *testing.T) {
 		limit := 3
 		offset := 0
-		rows := pgxmock.NewRows([]string{"id", "user_id", "title", "description", "created_at", "updated_at"})
+		rows := pgxmock.NewRows([]string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"})
 		for _, h := range habits {
-			rows.AddRow(h.ID, h.UserID, h.Title, h.Description, h.CreatedAt, h.UpdatedAt)
+			rows.AddRow(h.ID, h.UserID, h.Title, h.Description, h.Color, h.Icon, h.StartDate, h.CreatedAt, h.UpdatedAt, h.AllowMultiplePerDay, h.Tags)
 		}
 		mock.ExpectQuery(query).
 			WithArgs(userID, limit, offset).
 			WillReturnRows(rows)
-		result, err := repo.GetByUserID(ctx, userID, limit, offset)
+		result, err := repo.GetByUserID(ctx, userID, entity.HabitSortCreatedAt, limit, offset)
 		assert.NoError(t, err)
 		for i := range result {
 			assert.Equal(t, *habits[i], *result[i])
@@ -178,12 +462,12 @@ func TestGetHabitsByUserID(t *testing.T) {
 	t.Run("used limit and offset", func(t *testing.T) {
 		limit := 1
 		offset := 1
-		rows := pgxmock.NewRows([]string{"id", "user_id", "title", "description", "created_at", "updated_at"})
-		rows.AddRow(habits[1].ID, habits[1].UserID, habits[1].Title, habits[1].Description, habits[1].CreatedAt, habits[1].UpdatedAt)
+		rows := pgxmock.NewRows([]string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"})
+		rows.AddRow(habits[1].ID, habits[1].UserID, habits[1].Title, habits[1].Description, habits[1].Color, habits[1].Icon, habits[1].StartDate, habits[1].CreatedAt, habits[1].UpdatedAt, habits[1].AllowMultiplePerDay, habits[1].Tags)
 		mock.ExpectQuery(query).
 			WithArgs(userID, limit, offset).
 			WillReturnRows(rows)
-		result, err := repo.GetByUserID(ctx, userID, limit, offset)
+		result, err := repo.GetByUserID(ctx, userID, entity.HabitSortCreatedAt, limit, offset)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(result))
 		assert.Equal(t, *habits[1], *result[0])
@@ -194,9 +478,211 @@ func TestGetHabitsByUserID(t *testing.T) {
 		mock.ExpectQuery(query).
 			WithArgs(userID, limit, offset).
 			WillReturnError(errors.New("db error"))
-		_, err := repo.GetByUserID(ctx, userID, limit, offset)
+		_, err := repo.GetByUserID(ctx, userID, entity.HabitSortCreatedAt, limit, offset)
+		assert.Error(t, err)
+	})
+	t.Run("by position", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY position, id LIMIT $2 OFFSET $3;`)).
+			WithArgs(userID, 10, 0).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}))
+		_, err := repo.GetByUserID(ctx, userID, entity.HabitSortPosition, 10, 0)
+		assert.NoError(t, err)
+	})
+	t.Run("unknown sort", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(userID, 10, 0).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}))
+		_, err := repo.GetByUserID(ctx, userID, entity.HabitSort("title; DROP TABLE habits"), 10, 0)
+		assert.NoError(t, err)
+	})
+}
+
+func TestGetHabitsByUserIDAfter(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	columns := []string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}
+	habit := entity.Habit{ID: uuid.New(), UserID: userID, Title: "test_habit", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	firstPageQuery := regexp.QuoteMeta(`SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+			FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at, id LIMIT $2;`)
+	afterQuery := regexp.QuoteMeta(`SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE user_id = $1 AND deleted_at IS NULL AND (created_at, id) > ($2, $3) ORDER BY created_at, id LIMIT $4;`)
+	ctx := context.Background()
+	t.Run("first page", func(t *testing.T) {
+		rows := pgxmock.NewRows(columns).
+			AddRow(habit.ID, habit.UserID, habit.Title, habit.Description, habit.Color, habit.Icon, habit.StartDate, habit.CreatedAt, habit.UpdatedAt, habit.AllowMultiplePerDay, habit.Tags)
+		mock.ExpectQuery(firstPageQuery).
+			WithArgs(userID, 2).
+			WillReturnRows(rows)
+		result, err := repo.GetByUserIDAfter(ctx, userID, nil, 2)
+		assert.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, habit, *result[0])
+	})
+	t.Run("after cursor", func(t *testing.T) {
+		cursor := entity.HabitCursor{CreatedAt: time.Now().Add(-time.Hour), ID: uuid.New()}
+		rows := pgxmock.NewRows(columns).
+			AddRow(habit.ID, habit.UserID, habit.Title, habit.Description, habit.Color, habit.Icon, habit.StartDate, habit.CreatedAt, habit.UpdatedAt, habit.AllowMultiplePerDay, habit.Tags)
+		mock.ExpectQuery(afterQuery).
+			WithArgs(userID, cursor.CreatedAt, cursor.ID, 2).
+			WillReturnRows(rows)
+		result, err := repo.GetByUserIDAfter(ctx, userID, &cursor, 2)
+		assert.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, habit, *result[0])
+	})
+	t.Run("db error", func(t *testing.T) {
+		cursor := entity.HabitCursor{CreatedAt: time.Now(), ID: uuid.New()}
+		mock.ExpectQuery(afterQuery).
+			WithArgs(userID, cursor.CreatedAt, cursor.ID, 2).
+			WillReturnError(errors.New("db error"))
+		_, err := repo.GetByUserIDAfter(ctx, userID, &cursor, 2)
+		assert.Error(t, err)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetHabitsByUserIDs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	otherID := uuid.New()
+	uids := []uuid.UUID{userID, otherID}
+	// Ordered by creation time regardless of owner
+	habits := []*entity.Habit{
+		{ID: uuid.New(), UserID: otherID, Title: "test_habit_1", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: uuid.New(), UserID: userID, Title: "test_habit_2", CreatedAt: time.Now().Add(time.Hour), UpdatedAt: time.Now().Add(time.Hour)},
+		{ID: uuid.New(), UserID: otherID, Title: "test_habit_3", CreatedAt: time.Now().Add(2 * time.Hour), UpdatedAt: time.Now().Add(2 * time.Hour)},
+	}
+	query := regexp.QuoteMeta(`SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE user_id = ANY($1) AND deleted_at IS NULL ORDER BY created_at, id LIMIT $2 OFFSET $3;`)
+	columns := []string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		rows := pgxmock.NewRows(columns)
+		for _, h := range habits {
+			rows.AddRow(h.ID, h.UserID, h.Title, h.Description, h.Color, h.Icon, h.StartDate, h.CreatedAt, h.UpdatedAt, h.AllowMultiplePerDay, h.Tags)
+		}
+		mock.ExpectQuery(query).
+			WithArgs(uids, 10, 0).
+			WillReturnRows(rows)
+		result, err := repo.GetByUserIDs(ctx, uids, 10, 0)
+		assert.NoError(t, err)
+		require.Equal(t, len(habits), len(result))
+		for i := range result {
+			assert.Equal(t, *habits[i], *result[i])
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("no habits", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(uids, 10, 0).
+			WillReturnRows(pgxmock.NewRows(columns))
+		result, err := repo.GetByUserIDs(ctx, uids, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(uids, 10, 0).
+			WillReturnError(errors.New("db error"))
+		_, err := repo.GetByUserIDs(ctx, uids, 10, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestCountHabitsByUserIDs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	uids := []uuid.UUID{userID, uuid.New()}
+	query := regexp.QuoteMeta(`SELECT COUNT(*) FROM habits WHERE user_id = ANY($1) AND deleted_at IS NULL;`)
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(uids).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+		count, err := repo.CountByUserIDs(ctx, uids)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(uids).
+			WillReturnError(errors.New("db error"))
+		_, err := repo.CountByUserIDs(ctx, uids)
+		assert.Error(t, err)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMaxUpdatedAt(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT GREATEST(
+			(SELECT MAX(GREATEST(created_at, updated_at, deleted_at)) FROM habits WHERE user_id = $1),
+			(SELECT habits_changed_at FROM users WHERE id = $1));`)
+	ctx := context.Background()
+	t.Run("latest change", func(t *testing.T) {
+		updated := time.Date(2025, 6, 10, 12, 30, 0, 0, time.UTC)
+		mock.ExpectQuery(query).
+			WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"max"}).AddRow(&updated))
+		result, err := repo.MaxUpdatedAt(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, updated, result)
+	})
+	t.Run("no habits", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"max"}).AddRow(nil))
+		result, err := repo.MaxUpdatedAt(ctx, userID)
+		assert.NoError(t, err)
+		assert.True(t, result.IsZero())
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(userID).
+			WillReturnError(errors.New("db error"))
+		_, err := repo.MaxUpdatedAt(ctx, userID)
 		assert.Error(t, err)
 	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListTags(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT DISTINCT unnest(tags) FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY 1;`)
+	ctx := context.Background()
+	t.Run("distinct sorted tags", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"unnest"}).AddRow("health").AddRow("morning"))
+		tags, err := repo.ListTags(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"health", "morning"}, tags)
+	})
+	t.Run("no tags", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(userID).
+			WillReturnRows(pgxmock.NewRows([]string{"unnest"}))
+		tags, err := repo.ListTags(ctx, userID)
+		assert.NoError(t, err)
+		// Empty list rather than null in response
+		assert.NotNil(t, tags)
+		assert.Empty(t, tags)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(userID).
+			WillReturnError(errors.New("db error"))
+		_, err := repo.ListTags(ctx, userID)
+		assert.Error(t, err)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestUpdateHabit(t *testing.T) {
@@ -204,60 +690,186 @@ func TestUpdateHabit(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	repo := repository.NewHabitsRepoWithConn(mock)
-	query := regexp.QuoteMeta(`UPDATE habits SET title = $1, description = $2, updated_at = NOW() WHERE id = $3;`)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`UPDATE habits SET title = $1, description = $2, color = $3, icon = $4, start_date = COALESCE($5, start_date), tags = COALESCE($6, tags),
+		updated_at = NOW() WHERE id = $7 AND deleted_at IS NULL;`)
 	habit := entity.Habit{
 		ID:          uuid.New(),
 		UserID:      userID,
 		Title:       "test_habit",
 		Description: "blah blah blah",
+		StartDate:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+		Tags:        []string{"health"},
 	}
 	ctx := context.Background()
 	t.Run("success", func(t *testing.T) {
 		mock.ExpectExec(query).
-			WithArgs(habit.Title, habit.Description, habit.ID).
+			WithArgs(habit.Title, habit.Description, habit.Color, habit.Icon, &habit.StartDate, habit.Tags, habit.ID).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 		err := repo.Update(ctx, &habit)
 		assert.NoError(t, err)
 	})
+	t.Run("nil tags kept", func(t *testing.T) {
+		untouched := habit
+		untouched.Tags = nil
+		mock.ExpectExec(query).
+			WithArgs(habit.Title, habit.Description, habit.Color, habit.Icon, &untouched.StartDate, []string(nil), habit.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		err := repo.Update(ctx, &untouched)
+		assert.NoError(t, err)
+	})
 	t.Run("not found", func(t *testing.T) {
 		mock.ExpectExec(query).
-			WithArgs(habit.Title, habit.Description, habit.ID).
+			WithArgs(habit.Title, habit.Description, habit.Color, habit.Icon, &habit.StartDate, habit.Tags, habit.ID).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 		err := repo.Update(ctx, &habit)
 		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
 	})
 	t.Run("db error", func(t *testing.T) {
 		mock.ExpectExec(query).
-			WithArgs(habit.Title, habit.Description, habit.ID).
+			WithArgs(habit.Title, habit.Description, habit.Color, habit.Icon, &habit.StartDate, habit.Tags, habit.ID).
 			WillReturnError(errors.New("db error"))
 		err := repo.Update(ctx, &habit)
 		assert.Error(t, err)
 	})
 }
 
+func TestUpdateHabitTitle(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`UPDATE habits SET title = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL;`)
+	id := uuid.New()
+	title := "new_title"
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(title, id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		err := repo.UpdateTitle(ctx, id, title)
+		assert.NoError(t, err)
+	})
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(title, id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		err := repo.UpdateTitle(ctx, id, title)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+	})
+	t.Run("unique violation", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(title, id).
+			WillReturnError(&pgconn.PgError{Code: "23505"})
+		err := repo.UpdateTitle(ctx, id, title)
+		assert.ErrorIs(t, err, errorvalues.ErrUserHasHabit)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(title, id).
+			WillReturnError(errors.New("db error"))
+		err := repo.UpdateTitle(ctx, id, title)
+		assert.Error(t, err)
+	})
+}
+
+func TestUpdateHabitDescription(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`UPDATE habits SET description = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL;`)
+	id := uuid.New()
+	desc := "new description"
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(desc, id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		err := repo.UpdateDescription(ctx, id, desc)
+		assert.NoError(t, err)
+	})
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(desc, id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		err := repo.UpdateDescription(ctx, id, desc)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(desc, id).
+			WillReturnError(errors.New("db error"))
+		err := repo.UpdateDescription(ctx, id, desc)
+		assert.Error(t, err)
+	})
+}
+
+func TestRestoreHabit(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	ctx := context.Background()
+	id := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE habits SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at IS NOT NULL;`)
+	t.Run("restored", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		assert.NoError(t, repo.Restore(ctx, id))
+	})
+	t.Run("title taken", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id).WillReturnError(&pgconn.PgError{Code: "23505"})
+		assert.ErrorIs(t, repo.Restore(ctx, id), errorvalues.ErrUserHasHabit)
+	})
+	t.Run("not deleted", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		assert.ErrorIs(t, repo.Restore(ctx, id), errorvalues.ErrHabitNotFound)
+	})
+}
+
+func TestPurgeDeletedHabits(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	before := time.Now().Add(-time.Hour)
+	mock.ExpectQuery(regexp.QuoteMeta(`WITH purged AS (DELETE FROM habits WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING user_id, deleted_at),
+		owners AS (UPDATE users u SET habits_changed_at = GREATEST(u.habits_changed_at, p.deleted_at)
+			FROM (SELECT user_id, MAX(deleted_at) AS deleted_at FROM purged GROUP BY user_id) p WHERE u.id = p.user_id)
+		SELECT COUNT(*) FROM purged;`)).
+		WithArgs(before).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(3)))
+	purged, err := repo.PurgeDeleted(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+}
+
 func TestDeleteHabit(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
 		t.Fatal(err)
 	}
-	repo := repository.NewHabitsRepoWithConn(mock)
-	query := regexp.QuoteMeta(`DELETE FROM habits WHERE id = $1;`)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`UPDATE habits SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL;`)
 	ctx := context.Background()
 	id := uuid.New()
 	t.Run("success", func(t *testing.T) {
 		mock.ExpectExec(query).
 			WithArgs(id).
-			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 		err := repo.Delete(ctx, id)
 		assert.NoError(t, err)
 	})
 	t.Run("not found", func(t *testing.T) {
 		mock.ExpectExec(query).
 			WithArgs(id).
-			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 		err := repo.Delete(ctx, id)
 		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
 	})
@@ -270,6 +882,62 @@ func TestDeleteHabit(t *testing.T) {
 	})
 }
 
+func TestHabitsReadWriteSeparation(t *testing.T) {
+	primary, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	replica, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	repo := repository.NewHabitsRepoWithConn(primary, replica)
+	ctx := context.Background()
+	id := uuid.New()
+	t.Run("reads go to replica", func(t *testing.T) {
+		replica.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at, id LIMIT $2 OFFSET $3;`)).
+			WithArgs(userID, 10, 0).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}))
+		_, err = repo.GetByUserID(ctx, userID, entity.HabitSortCreatedAt, 10, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, replica.ExpectationsWereMet())
+		assert.NoError(t, primary.ExpectationsWereMet())
+	})
+	t.Run("ownership lookups go to primary", func(t *testing.T) {
+		// Habit created just now may be missing on replica yet
+		primary.ExpectQuery(regexp.QuoteMeta(`SELECT user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags FROM habits WHERE id = $1 AND deleted_at IS NULL;`)).
+			WithArgs(id).
+			WillReturnRows(pgxmock.NewRows([]string{"user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}).
+				AddRow(userID, "test_habit", "blah blah blah", "", "", time.Now(), time.Now(), time.Now(), false, []string{}),
+			)
+		primary.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags
+		FROM habits WHERE id = ANY($1) AND deleted_at IS NULL;`)).
+			WithArgs([]uuid.UUID{id}).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}))
+		_, err := repo.GetByID(ctx, id)
+		assert.NoError(t, err)
+		_, err = repo.GetByIDs(ctx, []uuid.UUID{id})
+		assert.NoError(t, err)
+		assert.NoError(t, primary.ExpectationsWereMet())
+		assert.NoError(t, replica.ExpectationsWereMet())
+	})
+	t.Run("writes go to primary", func(t *testing.T) {
+		primary.ExpectExec(regexp.QuoteMeta(`UPDATE habits SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL;`)).
+			WithArgs(id).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		err := repo.Delete(ctx, id)
+		assert.NoError(t, err)
+		assert.NoError(t, primary.ExpectationsWereMet())
+		assert.NoError(t, replica.ExpectationsWereMet())
+	})
+	t.Run("no replica: reads go to primary", func(t *testing.T) {
+		repo := repository.NewHabitsRepoWithConn(primary, nil)
+		primary.ExpectQuery(regexp.QuoteMeta(`SELECT user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags FROM habits WHERE id = $1 AND deleted_at IS NULL;`)).
+			WithArgs(id).
+			WillReturnError(pgx.ErrNoRows)
+		_, err := repo.GetByID(ctx, id)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+		assert.NoError(t, primary.ExpectationsWereMet())
+	})
+}
+
 func TestHabitsIntegrational(t *testing.T) {
 	cfg := setupHabitsTestDB(t)
 	repo := repository.NewHabitsRepo(cfg)
@@ -279,6 +947,7 @@ func TestHabitsIntegrational(t *testing.T) {
 			UserID:      userID,
 			Title:       fmt.Sprintf("habit_n%d", i),
 			Description: fmt.Sprintf("desc_n%d", i),
+			StartDate:   testStartDate,
 		})
 	}
 	ctx := context.Background()
@@ -297,6 +966,7 @@ func TestHabitsIntegrational(t *testing.T) {
 				UserID:      uuid.New(),
 				Title:       "ttt",
 				Description: "ddd",
+				StartDate:   testStartDate,
 			})
 			assert.ErrorIs(t, err, errorvalues.ErrOwnerNotFound)
 		})
@@ -312,7 +982,7 @@ func TestHabitsIntegrational(t *testing.T) {
 	t.Run("get habits by user_id", func(t *testing.T) {
 		t.Run("list all habits", func(t *testing.T) {
 			limit, offset := 5, 0
-			result, err := repo.GetByUserID(ctx, userID, limit, offset)
+			result, err := repo.GetByUserID(ctx, userID, entity.HabitSortCreatedAt, limit, offset)
 			assert.NoError(t, err)
 			assert.Equal(t, 5, len(result))
 			for i := range result {
@@ -323,7 +993,7 @@ func TestHabitsIntegrational(t *testing.T) {
 		})
 		t.Run("list limited", func(t *testing.T) {
 			limit, offset := 3, 2
-			result, err := repo.GetByUserID(ctx, userID, limit, offset)
+			result, err := repo.GetByUserID(ctx, userID, entity.HabitSortCreatedAt, limit, offset)
 			assert.NoError(t, err)
 			assert.Equal(t, 3, len(result))
 			for i := offset; i < 5; i++ {
@@ -331,7 +1001,7 @@ func TestHabitsIntegrational(t *testing.T) {
 			}
 		})
 		t.Run("list for unknown user", func(t *testing.T) {
-			result, err := repo.GetByUserID(ctx, uuid.New(), 10, 0)
+			result, err := repo.GetByUserID(ctx, uuid.New(), entity.HabitSortCreatedAt, 10, 0)
 			assert.NoError(t, err)
 			assert.Equal(t, 0, len(result))
 		})
@@ -371,6 +1041,43 @@ func TestHabitsIntegrational(t *testing.T) {
 			assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
 		})
 	})
+	t.Run("empty description", func(t *testing.T) {
+		id, err := repo.Create(ctx, &entity.Habit{UserID: userID, Title: "no_description", StartDate: testStartDate})
+		require.NoError(t, err)
+		h, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "", h.Description)
+		listed, err := repo.GetByUserID(ctx, userID, entity.HabitSortCreatedAt, 100, 0)
+		require.NoError(t, err)
+		found := false
+		for _, l := range listed {
+			if l.ID == id {
+				found = true
+				assert.Equal(t, "", l.Description)
+			}
+		}
+		assert.True(t, found)
+		h.Description = "some"
+		require.NoError(t, repo.Update(ctx, h))
+		h.Description = ""
+		require.NoError(t, repo.Update(ctx, h))
+		h, err = repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "", h.Description)
+		// Rows inserted without description get empty one rather than NULL
+		conn, err := pgx.Connect(ctx, cfg.ConnString())
+		require.NoError(t, err)
+		defer conn.Close(ctx)
+		var rawID uuid.UUID
+		require.NoError(t, conn.QueryRow(ctx, `INSERT INTO habits (user_id, title, start_date) VALUES ($1, 'raw_insert', CURRENT_DATE) RETURNING id;`, userID).Scan(&rawID))
+		h, err = repo.GetByID(ctx, rawID)
+		require.NoError(t, err)
+		assert.Equal(t, "", h.Description)
+		_, err = conn.Exec(ctx, `UPDATE habits SET description = NULL WHERE id = $1;`, rawID)
+		assert.Error(t, err)
+		require.NoError(t, repo.Delete(ctx, id))
+		require.NoError(t, repo.Delete(ctx, rawID))
+	})
 	t.Run("delete", func(t *testing.T) {
 		t.Run("success", func(t *testing.T) {
 			err := repo.Delete(ctx, habits[0].ID)
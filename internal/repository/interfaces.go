@@ -14,7 +14,8 @@ import (
 
 type UsersRepositoryI interface {
 	// Creates new user in database.
-	// If user already exists, returns errorvalues.ErrUserExists
+	// If name is already taken, returns errorvalues.ErrNameTaken (which is errorvalues.ErrUserExists too),
+	// other unique conflicts are errorvalues.ErrUserExists
 	Create(ctx context.Context, user *entity.User) error
 	// Looks up user by name.
 	// If there is no user with such name, returns errorvalues.ErrUserNotFound
@@ -25,49 +26,221 @@ type UsersRepositoryI interface {
 	// Updates user's info.
 	// If there is no user with such uid to update, returns errorvalues.ErrUserNotFound
 	Update(ctx context.Context, user *entity.User) error
+	// Renames user with id.
+	// If name is already taken, returns errorvalues.ErrNameTaken.
+	// If there is no user with such uid, returns errorvalues.ErrUserNotFound
+	UpdateName(ctx context.Context, id uuid.UUID, newName string) error
+	// Replaces user's password hash, other fields are left untouched.
+	// If there is no user with such uid, returns errorvalues.ErrUserNotFound
+	UpdatePasswordHash(ctx context.Context, id uuid.UUID, hash string) error
+	// Sets user's timezone (IANA name), validation is up to caller.
+	// If there is no user with such uid, returns errorvalues.ErrUserNotFound
+	UpdateTimezone(ctx context.Context, id uuid.UUID, timezone string) error
+	// Sets user's last login time to now.
+	// If there is no user with such uid, returns errorvalues.ErrUserNotFound
+	TouchLastLogin(ctx context.Context, id uuid.UUID) error
+	// Activates or suspends user's account, suspended one is loaded with entity.User.Disabled set.
+	// If there is no user with such uid, returns errorvalues.ErrUserNotFound
+	SetActive(ctx context.Context, id uuid.UUID, active bool) error
+	// Increments user's token version, so tokens issued before are revoked. Returns new version.
+	// If there is no user with such uid, returns errorvalues.ErrUserNotFound
+	BumpTokenVersion(ctx context.Context, id uuid.UUID) (int, error)
 	// Deletes user.
 	// If there is no user with such uid to delete, returns errorvalues.ErrUserNotFound
 	Delete(ctx context.Context, uid uuid.UUID) error
+	// Deletes user with all habits and checks in single transaction, returns count of removed rows.
+	// If there is no user with such uid, nothing is deleted and errorvalues.ErrUserNotFound returned
+	Erase(ctx context.Context, uid uuid.UUID) (*entity.ErasureSummary, error)
+	// Counts rows Erase (or deletion by cascade) would remove, without removing anything.
+	// If there is no user with such uid, returns errorvalues.ErrUserNotFound
+	CountErasure(ctx context.Context, uid uuid.UUID) (*entity.ErasureSummary, error)
+	// Lists users whose name contains query (case-insensitive, empty one matches all) in given order,
+	// unknown sort falls back to entity.UserSortName. Password hashes aren't loaded.
+	List(ctx context.Context, query string, sort entity.UserSort, limit, offset int) ([]*entity.User, error)
+	// Returns count of users whose name contains query, as List does without pagination.
+	Count(ctx context.Context, query string) (int, error)
 }
 
 type HabitsRepositoryI interface {
-	// Creates new habits in database. In habit only Title, UserID, Description are necessary.
+	// Creates new habits in database. In habit only Title, UserID, Description and StartDate are necessary,
+	// zero StartDate is rejected (default one is user's today, which repository doesn't know).
 	// If there was habit with such name and userID, returns errorvalues.ErrUserHasHabit.
-	// If there is no user with owned habit, returns errorvalues.ErrOwnerNotFound
+	// If there is no user with owned habit, returns errorvalues.ErrOwnerNotFound.
+	// Created habit gets ID and timestamps filled, its id is returned too
 	Create(ctx context.Context, habit *entity.Habit) (uuid.UUID, error)
-	// Searches habit with given id.
+	// Creates habits in single transaction. Habits with title already owned by user
+	// (or repeated in batch) are skipped. Returns flags, aligned with habits, reporting
+	// which ones were created; created habits get ID and timestamps filled. StartDate is required as in Create.
+	// If there is no user to own habits, returns errorvalues.ErrOwnerNotFound and nothing is created
+	CreateMany(ctx context.Context, habits []*entity.Habit) ([]bool, error)
+	// Searches habit with given id. Reads primary even if replica is set, so fresh writes and owners are seen.
 	// If there is not habit with such id, returns errorvalues.ErrHabitNotFound
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Habit, error)
-	// Lists habits owned by user with uid. Requires pagination params provided.
+	// Same as GetByID, but for several habits in one query, found habits are keyed by id.
+	// Unexist (or deleted) habits are just absent in result.
+	GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.Habit, error)
+	// Lists habits owned by user with uid in given order, unknown sort falls back to entity.HabitSortCreatedAt.
+	// Requires pagination params provided.
 	// If there is no habits owned by user or user doesn't exist, returns zero-len slice and nil.
-	GetByUserID(ctx context.Context, uid uuid.UUID, limit, offset int) ([]*entity.Habit, error)
-	// Updates habit by ID (ID in habit is necessary).
+	GetByUserID(ctx context.Context, uid uuid.UUID, sort entity.HabitSort, limit, offset int) ([]*entity.Habit, error)
+	// Returns count of not deleted habits owned by user with uid, 0 if there are none or user doesn't exist.
+	CountByUserID(ctx context.Context, uid uuid.UUID) (int, error)
+	// Lists habits owned by user with uid ordered by (created_at, id), starting right after cursor
+	// (from the first habit if cursor is nil). Unlike GetByUserID, concurrent inserts don't shift pages.
+	// If there is no habits after cursor, returns zero-len slice and nil.
+	GetByUserIDAfter(ctx context.Context, uid uuid.UUID, cursor *entity.HabitCursor, limit int) ([]*entity.Habit, error)
+	// Lists habits owned by any of users with uids, ordered by creation time. Requires pagination params provided.
+	// Users without habits or unexist ones are just absent in result.
+	GetByUserIDs(ctx context.Context, uids []uuid.UUID, limit, offset int) ([]*entity.Habit, error)
+	// Counts habits owned by any of users with uids, as listed by GetByUserIDs.
+	CountByUserIDs(ctx context.Context, uids []uuid.UUID) (int, error)
+	// Calls fn for each not deleted habit owned by user with uid, ordered by (created_at, id), as rows are read,
+	// so whole list isn't kept in memory. Query isn't retried, as fn may have already been called.
+	// Iteration stops on first error of fn, which is returned as is.
+	StreamByUserID(ctx context.Context, uid uuid.UUID, fn func(habit *entity.Habit) error) error
+	// Lists distinct tags of habits owned by user with uid, sorted.
+	// If user has no tagged habits, returns zero-len slice and nil.
+	ListTags(ctx context.Context, uid uuid.UUID) ([]string, error)
+	// Returns latest time user's list of habits changed: any habit was created, updated or deleted,
+	// including ones purged or merged away since.
+	// If user has no habits, returns zero time and nil.
+	MaxUpdatedAt(ctx context.Context, uid uuid.UUID) (time.Time, error)
+	// Updates habit by ID (ID in habit is necessary), zero StartDate and nil Tags stay untouched.
 	// If there is not habit with such id (in habit arg), returns errorvalues.ErrHabitNotFound
 	Update(ctx context.Context, habit *entity.Habit) error
-	// Deletes habit with id.
+	// Updates only title of habit with id, description stays untouched.
+	// If there is not habit with such id, returns errorvalues.ErrHabitNotFound.
+	// If user already has habit with such title, returns errorvalues.ErrUserHasHabit
+	UpdateTitle(ctx context.Context, id uuid.UUID, title string) error
+	// Updates only description of habit with id, title stays untouched.
+	// If there is not habit with such id, returns errorvalues.ErrHabitNotFound
+	UpdateDescription(ctx context.Context, id uuid.UUID, description string) error
+	// Moves checks and skips of habit with sourceID to habit with targetID and deletes source permanently,
+	// all in one transaction. Source's marks on days target is marked already are dropped, target's ones are kept.
+	// If target doesn't allow several marks a day, only the earliest source's mark of each day is moved.
+	// Materialized stats of target are dropped, so they are recalculated. Returns count of moved marks.
+	// If any of habits doesn't exist (or deleted), returns errorvalues.ErrHabitNotFound and nothing is changed.
+	// If userID doesn't own both habits, returns errorvalues.ErrWrongOwner and nothing is changed
+	Merge(ctx context.Context, sourceID, targetID, userID uuid.UUID) (int64, error)
+	// Sets positions of user's habits by their order in orderedIDs in one transaction. Habits not listed
+	// go after listed ones in their previous order, so positions are unique.
+	// If any of habits doesn't exist (or deleted) or isn't owned by user with uid,
+	// returns errorvalues.ErrHabitNotFound and nothing is changed
+	Reorder(ctx context.Context, uid uuid.UUID, orderedIDs []uuid.UUID) error
+	// Marks habit with id as deleted, it's hidden from other methods but can be restored until purged.
 	// If there is not habit with such id, returns errorvalues.ErrHabitNotFound
 	Delete(ctx context.Context, id uuid.UUID) error
+	// Looks up deleted (not purged yet) habit, DeletedAt is filled.
+	// If there is no deleted habit with such id, returns errorvalues.ErrHabitNotFound
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*entity.Habit, error)
+	// Brings deleted habit back.
+	// If its title is used by another habit of owner, returns errorvalues.ErrUserHasHabit.
+	// If there is no deleted habit with such id, returns errorvalues.ErrHabitNotFound
+	Restore(ctx context.Context, id uuid.UUID) error
+	// Deletes permanently habits deleted before given time, with their checks. Returns count of purged habits.
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	// Returns repository running all its queries within tx (see TxManager).
+	WithTx(tx pgx.Tx) HabitsRepositoryI
+}
+
+// Stats of habit materialized on AsOf day for habit tracked since Since day.
+// They are outdated once any of these days changes.
+type StoredStats struct {
+	Stats entity.HabitStats
+	AsOf  time.Time
+	Since time.Time
+}
+
+// Runs function within transaction, see TxManager
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error
 }
 
 type HabitChecksRepositoryI interface {
 	// Creates new check on habit with habitID.
 	// There is no habit for check, returns errorvalues.ErrHabitNotFound.
-	// If habit was already checked, returns errorvalues.ErrCheckExist
-	Create(ctx context.Context, habitID uuid.UUID, date time.Time) error
-	// Deletes check on habit with habitID (uncheck).
+	// If habit was already checked, returns errorvalues.ErrCheckExist.
+	// If date is in the future, returns errorvalues.ErrCheckDateNotAllowed
+	Create(ctx context.Context, habitID uuid.UUID, date time.Time, note string) error
+	// Creates one more check on habit with habitID, date may already have checks
+	// (for habits allowing multiple checks per day).
+	// There is no habit for check, returns errorvalues.ErrHabitNotFound.
+	// If date is in the future, returns errorvalues.ErrCheckDateNotAllowed
+	CreateRepeated(ctx context.Context, habitID uuid.UUID, date time.Time, note string) error
+	// Same as Create, but existing check on date gets note replaced instead of failing (skip becomes check).
+	// Reports if check was created rather than updated.
+	Upsert(ctx context.Context, habitID uuid.UUID, date time.Time, note string) (bool, error)
+	// Marks date as skipped on habit with habitID.
+	// There is no habit for skip, returns errorvalues.ErrHabitNotFound.
+	// If date was already checked or skipped, returns errorvalues.ErrCheckExist.
+	// If date is in the future, returns errorvalues.ErrCheckDateNotAllowed
+	CreateSkip(ctx context.Context, habitID uuid.UUID, date time.Time) error
+	// Creates checks on habit with habitID for all dates in one query.
+	// Dates already checked are silently skipped.
+	// There is no habit for checks, returns errorvalues.ErrHabitNotFound.
+	// If any of dates is in the future, returns errorvalues.ErrCheckDateNotAllowed and nothing is created
+	CreateBatch(ctx context.Context, habitID uuid.UUID, dates []time.Time) error
+	// Replaces note of check (all checks) on habit with habitID on date.
+	// If there is no such check, returns errorvalues.ErrCheckNotFound
+	UpdateNote(ctx context.Context, habitID uuid.UUID, date time.Time, note string) error
+	// Deletes check (all checks) on habit with habitID (uncheck).
 	// If there is no such check, returns errorvalues.CheckNotFound
 	Delete(ctx context.Context, habitID uuid.UUID, date time.Time) error
-	// Inspects if check exists
+	// Deletes only latest created check on habit with habitID on date.
+	// If there is no such check, returns errorvalues.CheckNotFound
+	DeleteLatest(ctx context.Context, habitID uuid.UUID, date time.Time) error
+	// Inspects if check (or skip) exists
 	Exists(ctx context.Context, habitID uuid.UUID, date time.Time) (bool, error)
-	// Provides checks of habitID for a period. If there is no habit with habitID,
-	// returns zero-len slice and nil error.
-	GetByHabitAndDateRange(ctx context.Context, habitID uuid.UUID, from, to time.Time) ([]entity.HabitCheck, error)
-	// Returns date of last check on habitID. If there is no checks on habit,
+	// Provides checks and skips of habitID for a period, sorted by check date in order
+	// (unknown order falls back to entity.CheckOrderAsc). Only marks with status are provided if it's set,
+	// empty one means all. If there is no habit with habitID, returns zero-len slice and nil error.
+	GetByHabitAndDateRange(ctx context.Context, habitID uuid.UUID, from, to time.Time, order entity.CheckOrder, status entity.CheckStatus) ([]entity.HabitCheck, error)
+	// Provides checks and skips on date of all not deleted habits owned by user with userID, sorted by habit title.
+	// If user has no marks on date, returns zero-len slice and nil error.
+	GetByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time) ([]entity.UserCheck, error)
+	// Calls fn for each check and skip of not deleted habits owned by user with userID, ordered by habit and date,
+	// as rows are read. Same as HabitsRepositoryI.StreamByUserID, query isn't retried and fn's error is returned as is.
+	StreamByUserID(ctx context.Context, userID uuid.UUID, fn func(check *entity.HabitCheck) error) error
+	// Provides not deleted and not paused habits of user with userID whose streak is at risk: marked (checked or skipped)
+	// on day before today, but not on today yet. Habits are sorted by title.
+	// If there are no such habits, returns zero-len slice and nil error.
+	HabitsNotCheckedToday(ctx context.Context, userID uuid.UUID, today time.Time) ([]*entity.Habit, error)
+	// Same as GetByHabitAndDateRange, but provides only marked dates (time.DateOnly) with their status,
+	// so callers can test days for membership right away.
+	GetCheckedDates(ctx context.Context, habitID uuid.UUID, from, to time.Time) (map[string]entity.CheckStatus, error)
+	// Returns ids of given habits checked on date, skips are ignored. Order of ids is unspecified.
+	GetHabitIDsByDate(ctx context.Context, habitIDs []uuid.UUID, date time.Time) ([]uuid.UUID, error)
+	// Same as GetCheckedDates, but for several habits in one query, dates are grouped by habit.
+	// Habits without marks in period are absent in result.
+	GetCheckedDatesByHabits(ctx context.Context, habitIDs []uuid.UUID, from, to time.Time) (map[uuid.UUID]map[string]entity.CheckStatus, error)
+	// Returns date of last check on habitID, skips are ignored. If there is no checks on habit,
 	// returns nil time and nil error.
 	GetLastCheckDate(ctx context.Context, habitID uuid.UUID) (*time.Time, error)
-	// Returns count of checks for habitID. If there is no habit with habitID,
+	// Returns count of checks for habitID, skips are ignored. If there is no habit with habitID,
 	// returns 0 and nil error.
 	CountByHabitID(ctx context.Context, habitID uuid.UUID) (int, error)
+	// Same as CountByHabitID, but only checks within period (bounds included) are counted.
+	CountByHabitAndDateRange(ctx context.Context, habitID uuid.UUID, from, to time.Time) (int, error)
+	// Returns count of checks for habitID grouped by day of week, indexed as time.Weekday (Sunday is 0).
+	// Skips are ignored.
+	CountByWeekday(ctx context.Context, habitID uuid.UUID) ([7]int, error)
+	// Starts pause of habit on day. If habit is paused already, returns errorvalues.ErrHabitPaused.
+	// If there is no such habit, returns errorvalues.ErrHabitNotFound
+	Pause(ctx context.Context, habitID uuid.UUID, day time.Time) error
+	// Ends ongoing pause of habit, day is tracked again. If habit isn't paused, returns errorvalues.ErrHabitNotPaused
+	Resume(ctx context.Context, habitID uuid.UUID, day time.Time) error
+	// Returns pauses of given habits ordered by start. Habits without pauses are absent in result.
+	GetPauses(ctx context.Context, habitIDs []uuid.UUID) (map[uuid.UUID][]entity.HabitPause, error)
+	// Locks habit till the end of transaction, so its stats are recalculated by one writer at time.
+	// Must be called on repository bound to transaction (see WithTx).
+	// If there is no such habit, returns errorvalues.ErrHabitNotFound
+	LockHabit(ctx context.Context, habitID uuid.UUID) error
+	// Returns materialized stats of habit, nil if they aren't saved yet.
+	GetStats(ctx context.Context, habitID uuid.UUID) (*StoredStats, error)
+	// Saves materialized stats of habit, replacing previous ones.
+	SaveStats(ctx context.Context, stats *StoredStats) error
+	// Returns repository running all its queries within tx (see TxManager).
+	WithTx(tx pgx.Tx) HabitChecksRepositoryI
 }
 
 type DBConfig interface {
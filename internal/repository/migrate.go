@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/limbo/discipline/migrations"
+	"github.com/pressly/goose"
+)
+
+// Applies all embedded migrations not applied yet to database described by cfg.
+func Migrate(ctx context.Context, cfg DBConfig) error {
+	db, err := sql.Open("pgx", cfg.ConnString())
+	if err != nil {
+		return fmt.Errorf("opening db for migrations error: %w", err)
+	}
+	defer db.Close()
+	if err = db.PingContext(ctx); err != nil {
+		return fmt.Errorf("pinging db for migrations error: %w", err)
+	}
+	// Used goose version reads migrations only from filesystem,
+	// so embedded ones are extracted to temporary directory
+	dir, err := os.MkdirTemp("", "discipline-migrations-")
+	if err != nil {
+		return fmt.Errorf("creating migrations dir error: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	if err != nil {
+		return fmt.Errorf("listing embedded migrations error: %w", err)
+	}
+	for _, name := range files {
+		data, err := migrations.FS.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("reading embedded migration error: %w", err)
+		}
+		if err = os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			return fmt.Errorf("extracting migration error: %w", err)
+		}
+	}
+	if err = goose.SetDialect("postgres"); err != nil {
+		return fmt.Errorf("setting migrations dialect error: %w", err)
+	}
+	if err = goose.Up(db, dir); err != nil {
+		return fmt.Errorf("applying migrations error: %w", err)
+	}
+	return nil
+}
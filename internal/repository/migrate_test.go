@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	errorvalues "github.com/limbo/discipline/internal/error_values"
+	"github.com/limbo/discipline/internal/repository"
+	"github.com/limbo/discipline/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestMigrateIntegrational(t *testing.T) {
+	container, err := postgres.Run(context.Background(), "postgres:17",
+		postgres.WithUsername("test_user"),
+		postgres.WithDatabase("barn"),
+		postgres.WithPassword("test_password"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(30*time.Second),
+		),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		container.Terminate(context.Background())
+	})
+	connStr, err := container.ConnectionString(context.Background())
+	require.NoError(t, err)
+	connStr += "sslmode=disable"
+	ctx := context.Background()
+	inspector := repository.NewSchemaInspector(&testPGConfig{connStr: connStr})
+	_, err = inspector.SchemaVersion(ctx)
+	assert.ErrorIs(t, err, errorvalues.ErrSchemaNotMigrated)
+	require.NoError(t, repository.Migrate(ctx, &testPGConfig{connStr: connStr}))
+	// Applying again must be no-op
+	require.NoError(t, repository.Migrate(ctx, &testPGConfig{connStr: connStr}))
+	version, err := inspector.SchemaVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, latestMigration(t), version)
+
+	conn, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer conn.Close()
+	for _, table := range []string{"users", "habits", "habit_checks"} {
+		var exists bool
+		err = conn.QueryRow(`SELECT to_regclass($1) IS NOT NULL;`, "public."+table).Scan(&exists)
+		assert.NoError(t, err)
+		assert.True(t, exists, "table %s must exist", table)
+	}
+}
+
+// Returns version of latest embedded migration (its numeric name prefix)
+func latestMigration(t *testing.T) int64 {
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	require.NoError(t, err)
+	var latest int64
+	for _, name := range files {
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		require.NoError(t, err)
+		latest = max(latest, version)
+	}
+	return latest
+}
@@ -13,6 +13,7 @@ import (
 	uuid "github.com/google/uuid"
 	pgx "github.com/jackc/pgx/v5"
 	pgconn "github.com/jackc/pgx/v5/pgconn"
+	repository "github.com/limbo/discipline/internal/repository"
 	entity "github.com/limbo/discipline/pkg/entity"
 )
 
@@ -39,6 +40,51 @@ func (m *MockUsersRepositoryI) EXPECT() *MockUsersRepositoryIMockRecorder {
 	return m.recorder
 }
 
+// BumpTokenVersion mocks base method.
+func (m *MockUsersRepositoryI) BumpTokenVersion(ctx context.Context, id uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BumpTokenVersion", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BumpTokenVersion indicates an expected call of BumpTokenVersion.
+func (mr *MockUsersRepositoryIMockRecorder) BumpTokenVersion(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BumpTokenVersion", reflect.TypeOf((*MockUsersRepositoryI)(nil).BumpTokenVersion), ctx, id)
+}
+
+// Count mocks base method.
+func (m *MockUsersRepositoryI) Count(ctx context.Context, query string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, query)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockUsersRepositoryIMockRecorder) Count(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockUsersRepositoryI)(nil).Count), ctx, query)
+}
+
+// CountErasure mocks base method.
+func (m *MockUsersRepositoryI) CountErasure(ctx context.Context, uid uuid.UUID) (*entity.ErasureSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountErasure", ctx, uid)
+	ret0, _ := ret[0].(*entity.ErasureSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountErasure indicates an expected call of CountErasure.
+func (mr *MockUsersRepositoryIMockRecorder) CountErasure(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountErasure", reflect.TypeOf((*MockUsersRepositoryI)(nil).CountErasure), ctx, uid)
+}
+
 // Create mocks base method.
 func (m *MockUsersRepositoryI) Create(ctx context.Context, user *entity.User) error {
 	m.ctrl.T.Helper()
@@ -67,6 +113,21 @@ func (mr *MockUsersRepositoryIMockRecorder) Delete(ctx, uid interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUsersRepositoryI)(nil).Delete), ctx, uid)
 }
 
+// Erase mocks base method.
+func (m *MockUsersRepositoryI) Erase(ctx context.Context, uid uuid.UUID) (*entity.ErasureSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erase", ctx, uid)
+	ret0, _ := ret[0].(*entity.ErasureSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Erase indicates an expected call of Erase.
+func (mr *MockUsersRepositoryIMockRecorder) Erase(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockUsersRepositoryI)(nil).Erase), ctx, uid)
+}
+
 // FindByID mocks base method.
 func (m *MockUsersRepositoryI) FindByID(ctx context.Context, uid uuid.UUID) (*entity.User, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +158,49 @@ func (mr *MockUsersRepositoryIMockRecorder) FindByName(ctx, name interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockUsersRepositoryI)(nil).FindByName), ctx, name)
 }
 
+// List mocks base method.
+func (m *MockUsersRepositoryI) List(ctx context.Context, query string, sort entity.UserSort, limit, offset int) ([]*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query, sort, limit, offset)
+	ret0, _ := ret[0].([]*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockUsersRepositoryIMockRecorder) List(ctx, query, sort, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUsersRepositoryI)(nil).List), ctx, query, sort, limit, offset)
+}
+
+// SetActive mocks base method.
+func (m *MockUsersRepositoryI) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActive", ctx, id, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetActive indicates an expected call of SetActive.
+func (mr *MockUsersRepositoryIMockRecorder) SetActive(ctx, id, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActive", reflect.TypeOf((*MockUsersRepositoryI)(nil).SetActive), ctx, id, active)
+}
+
+// TouchLastLogin mocks base method.
+func (m *MockUsersRepositoryI) TouchLastLogin(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchLastLogin", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchLastLogin indicates an expected call of TouchLastLogin.
+func (mr *MockUsersRepositoryIMockRecorder) TouchLastLogin(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchLastLogin", reflect.TypeOf((*MockUsersRepositoryI)(nil).TouchLastLogin), ctx, id)
+}
+
 // Update mocks base method.
 func (m *MockUsersRepositoryI) Update(ctx context.Context, user *entity.User) error {
 	m.ctrl.T.Helper()
@@ -111,6 +215,48 @@ func (mr *MockUsersRepositoryIMockRecorder) Update(ctx, user interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUsersRepositoryI)(nil).Update), ctx, user)
 }
 
+// UpdateName mocks base method.
+func (m *MockUsersRepositoryI) UpdateName(ctx context.Context, id uuid.UUID, newName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateName", ctx, id, newName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateName indicates an expected call of UpdateName.
+func (mr *MockUsersRepositoryIMockRecorder) UpdateName(ctx, id, newName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateName", reflect.TypeOf((*MockUsersRepositoryI)(nil).UpdateName), ctx, id, newName)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockUsersRepositoryI) UpdatePasswordHash(ctx context.Context, id uuid.UUID, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, id, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockUsersRepositoryIMockRecorder) UpdatePasswordHash(ctx, id, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockUsersRepositoryI)(nil).UpdatePasswordHash), ctx, id, hash)
+}
+
+// UpdateTimezone mocks base method.
+func (m *MockUsersRepositoryI) UpdateTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTimezone", ctx, id, timezone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTimezone indicates an expected call of UpdateTimezone.
+func (mr *MockUsersRepositoryIMockRecorder) UpdateTimezone(ctx, id, timezone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTimezone", reflect.TypeOf((*MockUsersRepositoryI)(nil).UpdateTimezone), ctx, id, timezone)
+}
+
 // MockHabitsRepositoryI is a mock of HabitsRepositoryI interface.
 type MockHabitsRepositoryI struct {
 	ctrl     *gomock.Controller
@@ -134,6 +280,36 @@ func (m *MockHabitsRepositoryI) EXPECT() *MockHabitsRepositoryIMockRecorder {
 	return m.recorder
 }
 
+// CountByUserID mocks base method.
+func (m *MockHabitsRepositoryI) CountByUserID(ctx context.Context, uid uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByUserID", ctx, uid)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByUserID indicates an expected call of CountByUserID.
+func (mr *MockHabitsRepositoryIMockRecorder) CountByUserID(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByUserID", reflect.TypeOf((*MockHabitsRepositoryI)(nil).CountByUserID), ctx, uid)
+}
+
+// CountByUserIDs mocks base method.
+func (m *MockHabitsRepositoryI) CountByUserIDs(ctx context.Context, uids []uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByUserIDs", ctx, uids)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByUserIDs indicates an expected call of CountByUserIDs.
+func (mr *MockHabitsRepositoryIMockRecorder) CountByUserIDs(ctx, uids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByUserIDs", reflect.TypeOf((*MockHabitsRepositoryI)(nil).CountByUserIDs), ctx, uids)
+}
+
 // Create mocks base method.
 func (m *MockHabitsRepositoryI) Create(ctx context.Context, habit *entity.Habit) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -149,6 +325,21 @@ func (mr *MockHabitsRepositoryIMockRecorder) Create(ctx, habit interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHabitsRepositoryI)(nil).Create), ctx, habit)
 }
 
+// CreateMany mocks base method.
+func (m *MockHabitsRepositoryI) CreateMany(ctx context.Context, habits []*entity.Habit) ([]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMany", ctx, habits)
+	ret0, _ := ret[0].([]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMany indicates an expected call of CreateMany.
+func (mr *MockHabitsRepositoryIMockRecorder) CreateMany(ctx, habits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMany", reflect.TypeOf((*MockHabitsRepositoryI)(nil).CreateMany), ctx, habits)
+}
+
 // Delete mocks base method.
 func (m *MockHabitsRepositoryI) Delete(ctx context.Context, id uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -178,19 +369,181 @@ func (mr *MockHabitsRepositoryIMockRecorder) GetByID(ctx, id interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockHabitsRepositoryI)(nil).GetByID), ctx, id)
 }
 
+// GetByIDs mocks base method.
+func (m *MockHabitsRepositoryI) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].(map[uuid.UUID]*entity.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockHabitsRepositoryIMockRecorder) GetByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockHabitsRepositoryI)(nil).GetByIDs), ctx, ids)
+}
+
 // GetByUserID mocks base method.
-func (m *MockHabitsRepositoryI) GetByUserID(ctx context.Context, uid uuid.UUID, limit, offset int) ([]*entity.Habit, error) {
+func (m *MockHabitsRepositoryI) GetByUserID(ctx context.Context, uid uuid.UUID, sort entity.HabitSort, limit, offset int) ([]*entity.Habit, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByUserID", ctx, uid, limit, offset)
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, uid, sort, limit, offset)
 	ret0, _ := ret[0].([]*entity.Habit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByUserID indicates an expected call of GetByUserID.
-func (mr *MockHabitsRepositoryIMockRecorder) GetByUserID(ctx, uid, limit, offset interface{}) *gomock.Call {
+func (mr *MockHabitsRepositoryIMockRecorder) GetByUserID(ctx, uid, sort, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockHabitsRepositoryI)(nil).GetByUserID), ctx, uid, sort, limit, offset)
+}
+
+// GetByUserIDAfter mocks base method.
+func (m *MockHabitsRepositoryI) GetByUserIDAfter(ctx context.Context, uid uuid.UUID, cursor *entity.HabitCursor, limit int) ([]*entity.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserIDAfter", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]*entity.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserIDAfter indicates an expected call of GetByUserIDAfter.
+func (mr *MockHabitsRepositoryIMockRecorder) GetByUserIDAfter(ctx, uid, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserIDAfter", reflect.TypeOf((*MockHabitsRepositoryI)(nil).GetByUserIDAfter), ctx, uid, cursor, limit)
+}
+
+// GetByUserIDs mocks base method.
+func (m *MockHabitsRepositoryI) GetByUserIDs(ctx context.Context, uids []uuid.UUID, limit, offset int) ([]*entity.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserIDs", ctx, uids, limit, offset)
+	ret0, _ := ret[0].([]*entity.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserIDs indicates an expected call of GetByUserIDs.
+func (mr *MockHabitsRepositoryIMockRecorder) GetByUserIDs(ctx, uids, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserIDs", reflect.TypeOf((*MockHabitsRepositoryI)(nil).GetByUserIDs), ctx, uids, limit, offset)
+}
+
+// GetDeletedByID mocks base method.
+func (m *MockHabitsRepositoryI) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entity.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedByID", ctx, id)
+	ret0, _ := ret[0].(*entity.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedByID indicates an expected call of GetDeletedByID.
+func (mr *MockHabitsRepositoryIMockRecorder) GetDeletedByID(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockHabitsRepositoryI)(nil).GetByUserID), ctx, uid, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedByID", reflect.TypeOf((*MockHabitsRepositoryI)(nil).GetDeletedByID), ctx, id)
+}
+
+// ListTags mocks base method.
+func (m *MockHabitsRepositoryI) ListTags(ctx context.Context, uid uuid.UUID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", ctx, uid)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockHabitsRepositoryIMockRecorder) ListTags(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockHabitsRepositoryI)(nil).ListTags), ctx, uid)
+}
+
+// MaxUpdatedAt mocks base method.
+func (m *MockHabitsRepositoryI) MaxUpdatedAt(ctx context.Context, uid uuid.UUID) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxUpdatedAt", ctx, uid)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MaxUpdatedAt indicates an expected call of MaxUpdatedAt.
+func (mr *MockHabitsRepositoryIMockRecorder) MaxUpdatedAt(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxUpdatedAt", reflect.TypeOf((*MockHabitsRepositoryI)(nil).MaxUpdatedAt), ctx, uid)
+}
+
+// Merge mocks base method.
+func (m *MockHabitsRepositoryI) Merge(ctx context.Context, sourceID, targetID, userID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", ctx, sourceID, targetID, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockHabitsRepositoryIMockRecorder) Merge(ctx, sourceID, targetID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockHabitsRepositoryI)(nil).Merge), ctx, sourceID, targetID, userID)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockHabitsRepositoryI) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockHabitsRepositoryIMockRecorder) PurgeDeleted(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockHabitsRepositoryI)(nil).PurgeDeleted), ctx, before)
+}
+
+// Reorder mocks base method.
+func (m *MockHabitsRepositoryI) Reorder(ctx context.Context, uid uuid.UUID, orderedIDs []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reorder", ctx, uid, orderedIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reorder indicates an expected call of Reorder.
+func (mr *MockHabitsRepositoryIMockRecorder) Reorder(ctx, uid, orderedIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reorder", reflect.TypeOf((*MockHabitsRepositoryI)(nil).Reorder), ctx, uid, orderedIDs)
+}
+
+// Restore mocks base method.
+func (m *MockHabitsRepositoryI) Restore(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockHabitsRepositoryIMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockHabitsRepositoryI)(nil).Restore), ctx, id)
+}
+
+// StreamByUserID mocks base method.
+func (m *MockHabitsRepositoryI) StreamByUserID(ctx context.Context, uid uuid.UUID, fn func(*entity.Habit) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamByUserID", ctx, uid, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamByUserID indicates an expected call of StreamByUserID.
+func (mr *MockHabitsRepositoryIMockRecorder) StreamByUserID(ctx, uid, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamByUserID", reflect.TypeOf((*MockHabitsRepositoryI)(nil).StreamByUserID), ctx, uid, fn)
 }
 
 // Update mocks base method.
@@ -207,6 +560,85 @@ func (mr *MockHabitsRepositoryIMockRecorder) Update(ctx, habit interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockHabitsRepositoryI)(nil).Update), ctx, habit)
 }
 
+// UpdateDescription mocks base method.
+func (m *MockHabitsRepositoryI) UpdateDescription(ctx context.Context, id uuid.UUID, description string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDescription", ctx, id, description)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDescription indicates an expected call of UpdateDescription.
+func (mr *MockHabitsRepositoryIMockRecorder) UpdateDescription(ctx, id, description interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDescription", reflect.TypeOf((*MockHabitsRepositoryI)(nil).UpdateDescription), ctx, id, description)
+}
+
+// UpdateTitle mocks base method.
+func (m *MockHabitsRepositoryI) UpdateTitle(ctx context.Context, id uuid.UUID, title string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTitle", ctx, id, title)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTitle indicates an expected call of UpdateTitle.
+func (mr *MockHabitsRepositoryIMockRecorder) UpdateTitle(ctx, id, title interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTitle", reflect.TypeOf((*MockHabitsRepositoryI)(nil).UpdateTitle), ctx, id, title)
+}
+
+// WithTx mocks base method.
+func (m *MockHabitsRepositoryI) WithTx(tx pgx.Tx) repository.HabitsRepositoryI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(repository.HabitsRepositoryI)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockHabitsRepositoryIMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockHabitsRepositoryI)(nil).WithTx), tx)
+}
+
+// MockTxRunner is a mock of TxRunner interface.
+type MockTxRunner struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxRunnerMockRecorder
+}
+
+// MockTxRunnerMockRecorder is the mock recorder for MockTxRunner.
+type MockTxRunnerMockRecorder struct {
+	mock *MockTxRunner
+}
+
+// NewMockTxRunner creates a new mock instance.
+func NewMockTxRunner(ctrl *gomock.Controller) *MockTxRunner {
+	mock := &MockTxRunner{ctrl: ctrl}
+	mock.recorder = &MockTxRunnerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxRunner) EXPECT() *MockTxRunnerMockRecorder {
+	return m.recorder
+}
+
+// WithTx mocks base method.
+func (m *MockTxRunner) WithTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockTxRunnerMockRecorder) WithTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTxRunner)(nil).WithTx), ctx, fn)
+}
+
 // MockHabitChecksRepositoryI is a mock of HabitChecksRepositoryI interface.
 type MockHabitChecksRepositoryI struct {
 	ctrl     *gomock.Controller
@@ -230,6 +662,21 @@ func (m *MockHabitChecksRepositoryI) EXPECT() *MockHabitChecksRepositoryIMockRec
 	return m.recorder
 }
 
+// CountByHabitAndDateRange mocks base method.
+func (m *MockHabitChecksRepositoryI) CountByHabitAndDateRange(ctx context.Context, habitID uuid.UUID, from, to time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByHabitAndDateRange", ctx, habitID, from, to)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByHabitAndDateRange indicates an expected call of CountByHabitAndDateRange.
+func (mr *MockHabitChecksRepositoryIMockRecorder) CountByHabitAndDateRange(ctx, habitID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByHabitAndDateRange", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).CountByHabitAndDateRange), ctx, habitID, from, to)
+}
+
 // CountByHabitID mocks base method.
 func (m *MockHabitChecksRepositoryI) CountByHabitID(ctx context.Context, habitID uuid.UUID) (int, error) {
 	m.ctrl.T.Helper()
@@ -245,18 +692,75 @@ func (mr *MockHabitChecksRepositoryIMockRecorder) CountByHabitID(ctx, habitID in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByHabitID", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).CountByHabitID), ctx, habitID)
 }
 
+// CountByWeekday mocks base method.
+func (m *MockHabitChecksRepositoryI) CountByWeekday(ctx context.Context, habitID uuid.UUID) ([7]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByWeekday", ctx, habitID)
+	ret0, _ := ret[0].([7]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByWeekday indicates an expected call of CountByWeekday.
+func (mr *MockHabitChecksRepositoryIMockRecorder) CountByWeekday(ctx, habitID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByWeekday", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).CountByWeekday), ctx, habitID)
+}
+
 // Create mocks base method.
-func (m *MockHabitChecksRepositoryI) Create(ctx context.Context, habitID uuid.UUID, date time.Time) error {
+func (m *MockHabitChecksRepositoryI) Create(ctx context.Context, habitID uuid.UUID, date time.Time, note string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, habitID, date)
+	ret := m.ctrl.Call(m, "Create", ctx, habitID, date, note)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockHabitChecksRepositoryIMockRecorder) Create(ctx, habitID, date interface{}) *gomock.Call {
+func (mr *MockHabitChecksRepositoryIMockRecorder) Create(ctx, habitID, date, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).Create), ctx, habitID, date, note)
+}
+
+// CreateBatch mocks base method.
+func (m *MockHabitChecksRepositoryI) CreateBatch(ctx context.Context, habitID uuid.UUID, dates []time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBatch", ctx, habitID, dates)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBatch indicates an expected call of CreateBatch.
+func (mr *MockHabitChecksRepositoryIMockRecorder) CreateBatch(ctx, habitID, dates interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBatch", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).CreateBatch), ctx, habitID, dates)
+}
+
+// CreateRepeated mocks base method.
+func (m *MockHabitChecksRepositoryI) CreateRepeated(ctx context.Context, habitID uuid.UUID, date time.Time, note string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRepeated", ctx, habitID, date, note)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRepeated indicates an expected call of CreateRepeated.
+func (mr *MockHabitChecksRepositoryIMockRecorder) CreateRepeated(ctx, habitID, date, note interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).Create), ctx, habitID, date)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepeated", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).CreateRepeated), ctx, habitID, date, note)
+}
+
+// CreateSkip mocks base method.
+func (m *MockHabitChecksRepositoryI) CreateSkip(ctx context.Context, habitID uuid.UUID, date time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSkip", ctx, habitID, date)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSkip indicates an expected call of CreateSkip.
+func (mr *MockHabitChecksRepositoryIMockRecorder) CreateSkip(ctx, habitID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSkip", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).CreateSkip), ctx, habitID, date)
 }
 
 // Delete mocks base method.
@@ -273,6 +777,20 @@ func (mr *MockHabitChecksRepositoryIMockRecorder) Delete(ctx, habitID, date inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).Delete), ctx, habitID, date)
 }
 
+// DeleteLatest mocks base method.
+func (m *MockHabitChecksRepositoryI) DeleteLatest(ctx context.Context, habitID uuid.UUID, date time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLatest", ctx, habitID, date)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLatest indicates an expected call of DeleteLatest.
+func (mr *MockHabitChecksRepositoryIMockRecorder) DeleteLatest(ctx, habitID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLatest", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).DeleteLatest), ctx, habitID, date)
+}
+
 // Exists mocks base method.
 func (m *MockHabitChecksRepositoryI) Exists(ctx context.Context, habitID uuid.UUID, date time.Time) (bool, error) {
 	m.ctrl.T.Helper()
@@ -289,18 +807,78 @@ func (mr *MockHabitChecksRepositoryIMockRecorder) Exists(ctx, habitID, date inte
 }
 
 // GetByHabitAndDateRange mocks base method.
-func (m *MockHabitChecksRepositoryI) GetByHabitAndDateRange(ctx context.Context, habitID uuid.UUID, from, to time.Time) ([]entity.HabitCheck, error) {
+func (m *MockHabitChecksRepositoryI) GetByHabitAndDateRange(ctx context.Context, habitID uuid.UUID, from, to time.Time, order entity.CheckOrder, status entity.CheckStatus) ([]entity.HabitCheck, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByHabitAndDateRange", ctx, habitID, from, to)
+	ret := m.ctrl.Call(m, "GetByHabitAndDateRange", ctx, habitID, from, to, order, status)
 	ret0, _ := ret[0].([]entity.HabitCheck)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByHabitAndDateRange indicates an expected call of GetByHabitAndDateRange.
-func (mr *MockHabitChecksRepositoryIMockRecorder) GetByHabitAndDateRange(ctx, habitID, from, to interface{}) *gomock.Call {
+func (mr *MockHabitChecksRepositoryIMockRecorder) GetByHabitAndDateRange(ctx, habitID, from, to, order, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHabitAndDateRange", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).GetByHabitAndDateRange), ctx, habitID, from, to, order, status)
+}
+
+// GetByUserAndDate mocks base method.
+func (m *MockHabitChecksRepositoryI) GetByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time) ([]entity.UserCheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserAndDate", ctx, userID, date)
+	ret0, _ := ret[0].([]entity.UserCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserAndDate indicates an expected call of GetByUserAndDate.
+func (mr *MockHabitChecksRepositoryIMockRecorder) GetByUserAndDate(ctx, userID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserAndDate", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).GetByUserAndDate), ctx, userID, date)
+}
+
+// GetCheckedDates mocks base method.
+func (m *MockHabitChecksRepositoryI) GetCheckedDates(ctx context.Context, habitID uuid.UUID, from, to time.Time) (map[string]entity.CheckStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCheckedDates", ctx, habitID, from, to)
+	ret0, _ := ret[0].(map[string]entity.CheckStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCheckedDates indicates an expected call of GetCheckedDates.
+func (mr *MockHabitChecksRepositoryIMockRecorder) GetCheckedDates(ctx, habitID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCheckedDates", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).GetCheckedDates), ctx, habitID, from, to)
+}
+
+// GetCheckedDatesByHabits mocks base method.
+func (m *MockHabitChecksRepositoryI) GetCheckedDatesByHabits(ctx context.Context, habitIDs []uuid.UUID, from, to time.Time) (map[uuid.UUID]map[string]entity.CheckStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCheckedDatesByHabits", ctx, habitIDs, from, to)
+	ret0, _ := ret[0].(map[uuid.UUID]map[string]entity.CheckStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCheckedDatesByHabits indicates an expected call of GetCheckedDatesByHabits.
+func (mr *MockHabitChecksRepositoryIMockRecorder) GetCheckedDatesByHabits(ctx, habitIDs, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCheckedDatesByHabits", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).GetCheckedDatesByHabits), ctx, habitIDs, from, to)
+}
+
+// GetHabitIDsByDate mocks base method.
+func (m *MockHabitChecksRepositoryI) GetHabitIDsByDate(ctx context.Context, habitIDs []uuid.UUID, date time.Time) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHabitIDsByDate", ctx, habitIDs, date)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHabitIDsByDate indicates an expected call of GetHabitIDsByDate.
+func (mr *MockHabitChecksRepositoryIMockRecorder) GetHabitIDsByDate(ctx, habitIDs, date interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHabitAndDateRange", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).GetByHabitAndDateRange), ctx, habitID, from, to)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitIDsByDate", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).GetHabitIDsByDate), ctx, habitIDs, date)
 }
 
 // GetLastCheckDate mocks base method.
@@ -318,6 +896,164 @@ func (mr *MockHabitChecksRepositoryIMockRecorder) GetLastCheckDate(ctx, habitID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastCheckDate", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).GetLastCheckDate), ctx, habitID)
 }
 
+// GetPauses mocks base method.
+func (m *MockHabitChecksRepositoryI) GetPauses(ctx context.Context, habitIDs []uuid.UUID) (map[uuid.UUID][]entity.HabitPause, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPauses", ctx, habitIDs)
+	ret0, _ := ret[0].(map[uuid.UUID][]entity.HabitPause)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPauses indicates an expected call of GetPauses.
+func (mr *MockHabitChecksRepositoryIMockRecorder) GetPauses(ctx, habitIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPauses", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).GetPauses), ctx, habitIDs)
+}
+
+// GetStats mocks base method.
+func (m *MockHabitChecksRepositoryI) GetStats(ctx context.Context, habitID uuid.UUID) (*repository.StoredStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, habitID)
+	ret0, _ := ret[0].(*repository.StoredStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockHabitChecksRepositoryIMockRecorder) GetStats(ctx, habitID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).GetStats), ctx, habitID)
+}
+
+// HabitsNotCheckedToday mocks base method.
+func (m *MockHabitChecksRepositoryI) HabitsNotCheckedToday(ctx context.Context, userID uuid.UUID, today time.Time) ([]*entity.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HabitsNotCheckedToday", ctx, userID, today)
+	ret0, _ := ret[0].([]*entity.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HabitsNotCheckedToday indicates an expected call of HabitsNotCheckedToday.
+func (mr *MockHabitChecksRepositoryIMockRecorder) HabitsNotCheckedToday(ctx, userID, today interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HabitsNotCheckedToday", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).HabitsNotCheckedToday), ctx, userID, today)
+}
+
+// LockHabit mocks base method.
+func (m *MockHabitChecksRepositoryI) LockHabit(ctx context.Context, habitID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockHabit", ctx, habitID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockHabit indicates an expected call of LockHabit.
+func (mr *MockHabitChecksRepositoryIMockRecorder) LockHabit(ctx, habitID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockHabit", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).LockHabit), ctx, habitID)
+}
+
+// Pause mocks base method.
+func (m *MockHabitChecksRepositoryI) Pause(ctx context.Context, habitID uuid.UUID, day time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", ctx, habitID, day)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockHabitChecksRepositoryIMockRecorder) Pause(ctx, habitID, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).Pause), ctx, habitID, day)
+}
+
+// Resume mocks base method.
+func (m *MockHabitChecksRepositoryI) Resume(ctx context.Context, habitID uuid.UUID, day time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resume", ctx, habitID, day)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockHabitChecksRepositoryIMockRecorder) Resume(ctx, habitID, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).Resume), ctx, habitID, day)
+}
+
+// SaveStats mocks base method.
+func (m *MockHabitChecksRepositoryI) SaveStats(ctx context.Context, stats *repository.StoredStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveStats", ctx, stats)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveStats indicates an expected call of SaveStats.
+func (mr *MockHabitChecksRepositoryIMockRecorder) SaveStats(ctx, stats interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveStats", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).SaveStats), ctx, stats)
+}
+
+// StreamByUserID mocks base method.
+func (m *MockHabitChecksRepositoryI) StreamByUserID(ctx context.Context, userID uuid.UUID, fn func(*entity.HabitCheck) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamByUserID", ctx, userID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamByUserID indicates an expected call of StreamByUserID.
+func (mr *MockHabitChecksRepositoryIMockRecorder) StreamByUserID(ctx, userID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamByUserID", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).StreamByUserID), ctx, userID, fn)
+}
+
+// UpdateNote mocks base method.
+func (m *MockHabitChecksRepositoryI) UpdateNote(ctx context.Context, habitID uuid.UUID, date time.Time, note string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNote", ctx, habitID, date, note)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNote indicates an expected call of UpdateNote.
+func (mr *MockHabitChecksRepositoryIMockRecorder) UpdateNote(ctx, habitID, date, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNote", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).UpdateNote), ctx, habitID, date, note)
+}
+
+// Upsert mocks base method.
+func (m *MockHabitChecksRepositoryI) Upsert(ctx context.Context, habitID uuid.UUID, date time.Time, note string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, habitID, date, note)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockHabitChecksRepositoryIMockRecorder) Upsert(ctx, habitID, date, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).Upsert), ctx, habitID, date, note)
+}
+
+// WithTx mocks base method.
+func (m *MockHabitChecksRepositoryI) WithTx(tx pgx.Tx) repository.HabitChecksRepositoryI {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", tx)
+	ret0, _ := ret[0].(repository.HabitChecksRepositoryI)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockHabitChecksRepositoryIMockRecorder) WithTx(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockHabitChecksRepositoryI)(nil).WithTx), tx)
+}
+
 // MockDBConfig is a mock of DBConfig interface.
 type MockDBConfig struct {
 	ctrl     *gomock.Controller
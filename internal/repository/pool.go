@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/limbo/discipline/pkg/cleanup"
+)
+
+// Opens pgxpool for cfg, pings it and registers closing on cleanup.
+// Any error is fatal, as repositories can't operate without connection.
+func connectPool(cfg DBConfig, repoName string) *pgxpool.Pool {
+	pool, err := pgxpool.New(context.Background(), cfg.ConnString())
+	if err != nil {
+		log.Fatal("creating connection for " + repoName + " error: " + err.Error())
+	}
+	err = pool.Ping(context.Background())
+	if err != nil {
+		log.Fatal("error while pinging connection for " + repoName + ": " + err.Error())
+	}
+	cleanup.Register(&cleanup.Job{
+		Name: "closing pgxpool",
+		F: func() error {
+			pool.Close()
+			return nil
+		},
+	})
+	return pool
+}
+
+// Returns pool for reads: replica if its config provided, otherwise primary one.
+func connectReadPool(primary PgConnection, replicaCfg DBConfig, repoName string) PgConnection {
+	if replicaCfg == nil {
+		return primary
+	}
+	return connectPool(replicaCfg, repoName+" replica")
+}
+
+// Pings both connections and returns the one to be used for reads:
+// readConn if provided, otherwise conn.
+func pingConns(conn, readConn PgConnection, repoName string) PgConnection {
+	err := conn.Ping(context.Background())
+	if err != nil {
+		log.Fatal("error while pinging connection for " + repoName + ": " + err.Error())
+	}
+	if readConn == nil {
+		return conn
+	}
+	err = readConn.Ping(context.Background())
+	if err != nil {
+		log.Fatal("error while pinging read connection for " + repoName + ": " + err.Error())
+	}
+	return readConn
+}
+
+// Snapshot of connection pool state
+type PoolStats struct {
+	AcquiredConns int32
+	IdleConns     int32
+	TotalConns    int32
+}
+
+// Returns stats of conn if it's pgxpool, false otherwise (e.g. transaction or mock).
+func poolStats(conn PgConnection) (PoolStats, bool) {
+	pool, ok := unwrapConn(conn).(*pgxpool.Pool)
+	if !ok {
+		return PoolStats{}, false
+	}
+	stat := pool.Stat()
+	return PoolStats{
+		AcquiredConns: stat.AcquiredConns(),
+		IdleConns:     stat.IdleConns(),
+		TotalConns:    stat.TotalConns(),
+	}, true
+}
+
+// Same as poolStats for replica readConn, false if reads go to primary conn as well,
+// so its connections aren't reported twice.
+func replicaPoolStats(conn, readConn PgConnection) (PoolStats, bool) {
+	if unwrapConn(readConn) == unwrapConn(conn) {
+		return PoolStats{}, false
+	}
+	return poolStats(readConn)
+}
+
+// Returns connection wrapped for slow queries logging, conn itself if it isn't wrapped.
+func unwrapConn(conn PgConnection) PgConnection {
+	if slow, ok := conn.(*slowQueryConn); ok {
+		return slow.PgConnection
+	}
+	return conn
+}
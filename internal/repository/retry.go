@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Runs fn and retries it once if it failed because of connection-level error
+// (e.g. connection closed after postgres restart).
+// Must be used only with idempotent queries (reads), never with writes.
+func withRetry(ctx context.Context, fn func() error) error {
+	err := fn()
+	if err == nil || ctx.Err() != nil || !isConnError(err) {
+		return err
+	}
+	return fn()
+}
+
+// Reports if err was caused by connection issues rather than by query itself.
+func isConnError(err error) bool {
+	// Errors reported by server (unique violations and others) and empty results are logical ones
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) || errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/limbo/discipline/internal/repository"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRetryOnConnError(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	repo := repository.NewHabitsRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`SELECT user_id, title, description, color, icon, start_date, created_at, updated_at, allow_multiple_per_day, tags FROM habits WHERE id = $1 AND deleted_at IS NULL;`)
+	columns := []string{"user_id", "title", "description", "color", "icon", "start_date", "created_at", "updated_at", "allow_multiple_per_day", "tags"}
+	id := uuid.New()
+	ctx := context.Background()
+	t.Run("retried once after conn error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(id).
+			WillReturnError(io.ErrUnexpectedEOF)
+		mock.ExpectQuery(query).
+			WithArgs(id).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(userID, "test_habit", "blah blah blah", "", "", time.Now(), time.Now(), time.Now(), false, []string{}))
+		h, err := repo.GetByID(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "test_habit", h.Title)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("not retried more than once", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(id).
+			WillReturnError(io.ErrUnexpectedEOF)
+		mock.ExpectQuery(query).
+			WithArgs(id).
+			WillReturnError(io.ErrUnexpectedEOF)
+		_, err := repo.GetByID(ctx, id)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("not retried on logical error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(id).
+			WillReturnError(&pgconn.PgError{Code: "42P01"})
+		_, err := repo.GetByID(ctx, id)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCancelledQueryUnwrappable(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	habits := repository.NewHabitsRepoWithConn(mock, nil)
+	checks := repository.NewHabitChecksRepoWithConn(mock, nil)
+	users := repository.NewUsersRepoWithConn(mock, nil)
+	// Cancellation is emulated by error pgx returns for cancelled query, ones wrapping context's error
+	ctx := context.Background()
+	id := uuid.New()
+	testCases := []struct {
+		Desc string
+		Call func() error
+	}{
+		{Desc: "habits read", Call: func() error {
+			// Cancelled read isn't retried, single expectation is enough
+			mock.ExpectQuery(".*").WithArgs(id).WillReturnError(context.Canceled)
+			_, err := habits.GetByID(ctx, id)
+			return err
+		}},
+		{Desc: "checks write", Call: func() error {
+			date := time.Now()
+			mock.ExpectExec(".*").WithArgs(id, date).WillReturnError(context.Canceled)
+			return checks.Delete(ctx, id, date)
+		}},
+		{Desc: "users read", Call: func() error {
+			mock.ExpectQuery(".*").WithArgs(id).WillReturnError(context.Canceled)
+			_, err := users.FindByID(ctx, id)
+			return err
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			err := tc.Call()
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	errorvalues "github.com/limbo/discipline/internal/error_values"
+)
+
+// Reports state of database schema applied by Migrate (goose).
+type SchemaInspector struct {
+	conn PgConnection
+}
+
+func NewSchemaInspector(cfg DBConfig) *SchemaInspector {
+	return &SchemaInspector{
+		conn: connectPool(cfg, "schemaInspector"),
+	}
+}
+
+func NewSchemaInspectorWithConn(conn PgConnection) *SchemaInspector {
+	return &SchemaInspector{
+		conn: conn,
+	}
+}
+
+// Returns version of latest applied migration.
+// If migrations were never applied (there is no goose_db_version table), returns errorvalues.ErrSchemaNotMigrated
+func (si *SchemaInspector) SchemaVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := withRetry(ctx, func() error {
+		return si.conn.QueryRow(ctx, `SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied;`).Scan(&version)
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		// undefined_table
+		if errors.As(err, &pgErr) && pgErr.Code == "42P01" {
+			return 0, errorvalues.ErrSchemaNotMigrated
+		}
+		return 0, fmt.Errorf("getting schema version error: %w", err)
+	}
+	return version, nil
+}
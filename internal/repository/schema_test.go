@@ -0,0 +1,41 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	errorvalues "github.com/limbo/discipline/internal/error_values"
+	"github.com/limbo/discipline/internal/repository"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	inspector := repository.NewSchemaInspectorWithConn(mock)
+	query := regexp.QuoteMeta(`SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied;`)
+	ctx := context.Background()
+	t.Run("latest applied version", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnRows(pgxmock.NewRows([]string{"coalesce"}).AddRow(int64(14)))
+		version, err := inspector.SchemaVersion(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(14), version)
+	})
+	t.Run("error not migrated", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(&pgconn.PgError{Code: "42P01"})
+		_, err := inspector.SchemaVersion(ctx)
+		assert.ErrorIs(t, err, errorvalues.ErrSchemaNotMigrated)
+	})
+	t.Run("db error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(errors.New("db error"))
+		_, err := inspector.SchemaVersion(ctx)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, errorvalues.ErrSchemaNotMigrated)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Settings of slow queries logging, zero value keeps it off.
+type slowQueryLog struct {
+	logger    *slog.Logger
+	threshold time.Duration
+}
+
+// Returns conn logging queries running longer than threshold, conn itself if logging is off.
+func (l slowQueryLog) wrap(conn PgConnection) PgConnection {
+	if l.threshold <= 0 || l.logger == nil {
+		return conn
+	}
+	return &slowQueryConn{PgConnection: conn, log: l}
+}
+
+// Decorates PgConnection with logging of slow queries.
+// Query is timed until its first response, rows reading isn't counted.
+type slowQueryConn struct {
+	PgConnection
+	log slowQueryLog
+}
+
+func (c *slowQueryConn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	defer c.observe(time.Now(), sql)
+	return c.PgConnection.Exec(ctx, sql, arguments...)
+}
+
+func (c *slowQueryConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	defer c.observe(time.Now(), sql)
+	return c.PgConnection.Query(ctx, sql, args...)
+}
+
+func (c *slowQueryConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	defer c.observe(time.Now(), sql)
+	return c.PgConnection.QueryRow(ctx, sql, args...)
+}
+
+func (c *slowQueryConn) observe(start time.Time, sql string) {
+	elapsed := time.Since(start)
+	if elapsed < c.log.threshold {
+		return
+	}
+	c.log.logger.Warn("slow query",
+		slog.String("method", callerMethod()),
+		slog.Duration("duration", elapsed),
+		slog.Duration("threshold", c.log.threshold),
+		slog.String("query", strings.Join(strings.Fields(sql), " ")),
+	)
+}
+
+// Suffixes of closures, e.g. ones passed to withRetry
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// Returns repository method (e.g. HabitsRepository.GetByID) query is run from.
+func callerMethod() string {
+	pcs := make([]uintptr, 16)
+	// Skips runtime.Callers, callerMethod, observe and deferred call wrapper
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+		name = closureSuffix.ReplaceAllString(strings.TrimPrefix(name, "repository."), "")
+		if !strings.HasPrefix(name, "(*slowQueryConn)") && name != "withRetry" && name != "" {
+			return strings.NewReplacer("(*", "", ")", "").Replace(name)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/limbo/discipline/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// Connection answering every query after delay with empty result
+type slowConn struct {
+	delay time.Duration
+}
+
+func (c slowConn) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c slowConn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	time.Sleep(c.delay)
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (c slowConn) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, pgx.ErrTxClosed
+}
+
+func (c slowConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	time.Sleep(c.delay)
+	return nil, pgx.ErrNoRows
+}
+
+func (c slowConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	time.Sleep(c.delay)
+	return errRow{}
+}
+
+type errRow struct{}
+
+func (errRow) Scan(dest ...any) error {
+	return pgx.ErrNoRows
+}
+
+func TestSlowQueryLogging(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		Desc      string
+		Delay     time.Duration
+		Threshold time.Duration
+		Logged    bool
+	}{
+		{Desc: "above threshold", Delay: 20 * time.Millisecond, Threshold: 5 * time.Millisecond, Logged: true},
+		{Desc: "below threshold", Delay: 0, Threshold: time.Second, Logged: false},
+		{Desc: "off", Delay: 20 * time.Millisecond, Threshold: 0, Logged: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&out, nil))
+			repo := repository.NewHabitsRepoWithConn(slowConn{delay: tc.Delay}, nil)
+			repo.SetSlowQueryLogging(logger, tc.Threshold)
+			_, _ = repo.GetByID(ctx, uuid.New())
+			if !tc.Logged {
+				assert.Empty(t, out.String())
+				return
+			}
+			assert.Contains(t, out.String(), "level=WARN")
+			assert.Contains(t, out.String(), `msg="slow query"`)
+			assert.Contains(t, out.String(), "method=HabitsRepository.GetByID")
+		})
+	}
+	t.Run("writes", func(t *testing.T) {
+		var out bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&out, nil))
+		repo := repository.NewHabitChecksRepoWithConn(slowConn{delay: 20 * time.Millisecond}, nil)
+		repo.SetSlowQueryLogging(logger, 5*time.Millisecond)
+		_ = repo.Delete(ctx, uuid.New(), time.Now())
+		assert.Contains(t, out.String(), "method=HabitChecksRepository.Delete")
+	})
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Runs operations spanning several repositories in one transaction.
+// Repositories join it through their WithTx(tx) copies.
+type TxManager struct {
+	conn PgConnection
+}
+
+func NewTxManager(cfg DBConfig) *TxManager {
+	return &TxManager{
+		conn: connectPool(cfg, "txManager"),
+	}
+}
+
+func NewTxManagerWithConn(conn PgConnection) *TxManager {
+	return &TxManager{
+		conn: conn,
+	}
+}
+
+// Begins transaction and passes it to fn. Transaction is commited if fn succeeds,
+// otherwise it's rolled back and fn's error is returned as is.
+func (m *TxManager) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := m.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("tx start error: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("commiting tx error: %w", err)
+	}
+	return nil
+}
+
+// Adapts pgx.Tx to PgConnection, so repositories run their queries within transaction.
+// Begin on it starts nested transaction (savepoint).
+type txConn struct {
+	pgx.Tx
+}
+
+func (tc txConn) Ping(ctx context.Context) error {
+	return tc.Conn().Ping(ctx)
+}
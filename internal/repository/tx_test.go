@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	errorvalues "github.com/limbo/discipline/internal/error_values"
+	"github.com/limbo/discipline/internal/repository"
+	"github.com/limbo/discipline/pkg/entity"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTx(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	txManager := repository.NewTxManagerWithConn(mock)
+	checksRepo := repository.NewHabitChecksRepoWithConn(mock, nil)
+	query := regexp.QuoteMeta(`INSERT INTO habit_checks (habit_id, check_date, note) VALUES ($1, $2, $3);`)
+	habitID := uuid.New()
+	date := time.Now()
+	ctx := context.Background()
+	t.Run("commited on success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).
+			WithArgs(habitID, date, "").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		err := txManager.WithTx(ctx, func(tx pgx.Tx) error {
+			return checksRepo.WithTx(tx).Create(ctx, habitID, date, "")
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("rolled back on error", func(t *testing.T) {
+		errStep := errors.New("second step error")
+		mock.ExpectBegin()
+		mock.ExpectExec(query).
+			WithArgs(habitID, date, "").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectRollback()
+		err := txManager.WithTx(ctx, func(tx pgx.Tx) error {
+			err := checksRepo.WithTx(tx).Create(ctx, habitID, date, "")
+			if err != nil {
+				return err
+			}
+			return errStep
+		})
+		assert.ErrorIs(t, err, errStep)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTxManagerIntegrational(t *testing.T) {
+	cfg := setupHabitsTestDB(t)
+	txManager := repository.NewTxManager(cfg)
+	habitsRepo := repository.NewHabitsRepo(cfg)
+	checksRepo := repository.NewHabitChecksRepo(cfg)
+	ctx := context.Background()
+	t.Run("failed second step rolls back first", func(t *testing.T) {
+		err := txManager.WithTx(ctx, func(tx pgx.Tx) error {
+			_, err := habitsRepo.WithTx(tx).Create(ctx, &entity.Habit{UserID: userID, Title: "rolled_back_habit", StartDate: testStartDate})
+			if err != nil {
+				return err
+			}
+			// Check on unexist habit fails
+			return checksRepo.WithTx(tx).Create(ctx, uuid.New(), time.Now(), "")
+		})
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+		habits, err := habitsRepo.GetByUserID(ctx, userID, entity.HabitSortCreatedAt, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, habits)
+	})
+	t.Run("both steps commited", func(t *testing.T) {
+		habit := entity.Habit{UserID: userID, Title: "commited_habit", StartDate: testStartDate}
+		err := txManager.WithTx(ctx, func(tx pgx.Tx) error {
+			id, err := habitsRepo.WithTx(tx).Create(ctx, &habit)
+			if err != nil {
+				return err
+			}
+			habit.ID = id
+			return checksRepo.WithTx(tx).Create(ctx, id, time.Now(), "")
+		})
+		require.NoError(t, err)
+		exists, err := checksRepo.Exists(ctx, habit.ID, time.Now())
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
@@ -3,52 +3,83 @@ package repository
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
-	"github.com/limbo/discipline/pkg/cleanup"
 	"github.com/limbo/discipline/pkg/entity"
 )
 
+// Returned when name exceeds users.name column length
+var errNameTooLong = errors.Join(errorvalues.ErrValidation, errors.New("name is too long"))
+
+// Conflicts meant by violations of users unique constraints, keyed by constraint name.
+// Violation of unlisted constraint is reported as errorvalues.ErrUserExists.
+var usersUniqueConflicts = map[string]error{
+	"users_name_key": errorvalues.ErrNameTaken,
+}
+
+// Tells which user's field unique violation pgErr is about, by violated constraint name.
+func userConflict(pgErr *pgconn.PgError) error {
+	if conflict, ok := usersUniqueConflicts[pgErr.ConstraintName]; ok {
+		return conflict
+	}
+	return errorvalues.ErrUserExists
+}
+
 type UsersRepository struct {
 	conn PgConnection
+	// Connection for read-only queries. Points to conn if no replica configured.
+	readConn    PgConnection
+	slowQueries slowQueryLog
 }
 
 func NewUsersRepo(cfg DBConfig) *UsersRepository {
-	pool, err := pgxpool.New(context.Background(), cfg.ConnString())
-	if err != nil {
-		log.Fatal("creating connection for usersRepo error: " + err.Error())
-	}
-	err = pool.Ping(context.Background())
-	if err != nil {
-		log.Fatal("error while pinging connection for usersRepo: " + err.Error())
-	}
-	cleanup.Register(&cleanup.Job{
-		Name: "closing pgxpool",
-		F: func() error {
-			pool.Close()
-			return nil
-		},
-	})
+	return NewUsersRepoWithReplica(cfg, nil)
+}
+
+// Creates repository with reads offloaded to replica described by replicaCfg.
+// If replicaCfg is nil, all queries go to primary.
+func NewUsersRepoWithReplica(cfg, replicaCfg DBConfig) *UsersRepository {
+	pool := connectPool(cfg, "usersRepo")
 	return &UsersRepository{
-		conn: pool,
+		conn:     pool,
+		readConn: connectReadPool(pool, replicaCfg, "usersRepo"),
 	}
 }
 
-func NewUsersRepoWithConn(conn PgConnection) *UsersRepository {
-	err := conn.Ping(context.Background())
-	if err != nil {
-		log.Fatal("error while pinging connection for usersRepo: " + err.Error())
-	}
+// Creates repository on given connections. readConn is optional,
+// if nil, conn is used for reads too.
+func NewUsersRepoWithConn(conn, readConn PgConnection) *UsersRepository {
 	return &UsersRepository{
-		conn: conn,
+		conn:     conn,
+		readConn: pingConns(conn, readConn, "usersRepo"),
 	}
 }
 
+// Returns stats of primary connection pool, false if repository isn't backed by pgxpool.
+func (ur *UsersRepository) PoolStats() (PoolStats, bool) {
+	return poolStats(ur.conn)
+}
+
+// Returns stats of replica connection pool, false if repository has no replica or it isn't backed by pgxpool.
+func (ur *UsersRepository) ReplicaPoolStats() (PoolStats, bool) {
+	return replicaPoolStats(ur.conn, ur.readConn)
+}
+
+// Makes repository log queries running longer than threshold as warnings with logger.
+// Non-positive threshold turns logging off. Must be called before repository is used.
+func (ur *UsersRepository) SetSlowQueryLogging(logger *slog.Logger, threshold time.Duration) {
+	ur.slowQueries = slowQueryLog{logger: logger, threshold: threshold}
+	ur.conn = ur.slowQueries.wrap(ur.conn)
+	ur.readConn = ur.slowQueries.wrap(ur.readConn)
+}
+
 func (ur *UsersRepository) Create(ctx context.Context, user *entity.User) error {
 	if user == nil {
 		return errors.New("user is nil")
@@ -60,34 +91,43 @@ func (ur *UsersRepository) Create(ctx context.Context, user *entity.User) error
 			switch pgErr.Code {
 			// Unique violation
 			case "23505":
-				return errorvalues.ErrUserExists
+				return userConflict(pgErr)
+			// Name longer than column allows
+			case "22001":
+				return errNameTooLong
 			}
 		}
-		return errors.New("creating user db error: " + err.Error())
+		return fmt.Errorf("creating user db error: %w", err)
 	}
 	return nil
 }
 
 func (ur *UsersRepository) FindByName(ctx context.Context, name string) (*entity.User, error) {
 	var user entity.User
-	row := ur.conn.QueryRow(ctx, `SELECT id, name, password_hash FROM users WHERE name = $1;`, name)
-	if err := row.Scan(&user.ID, &user.Name, &user.PasswordHash); err != nil {
+	err := withRetry(ctx, func() error {
+		row := ur.readConn.QueryRow(ctx, `SELECT id, name, password_hash, last_login_at, timezone, NOT is_active, token_version FROM users WHERE name = $1;`, name)
+		return row.Scan(&user.ID, &user.Name, &user.PasswordHash, &user.LastLoginAt, &user.Timezone, &user.Disabled, &user.TokenVersion)
+	})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errorvalues.ErrUserNotFound
 		}
-		return nil, errors.New("searching user by name error: " + err.Error())
+		return nil, fmt.Errorf("searching user by name error: %w", err)
 	}
 	return &user, nil
 }
 
 func (ur *UsersRepository) FindByID(ctx context.Context, uid uuid.UUID) (*entity.User, error) {
 	var user entity.User
-	row := ur.conn.QueryRow(ctx, `SELECT id, name, password_hash FROM users WHERE id = $1;`, uid)
-	if err := row.Scan(&user.ID, &user.Name, &user.PasswordHash); err != nil {
+	err := withRetry(ctx, func() error {
+		row := ur.readConn.QueryRow(ctx, `SELECT id, name, password_hash, last_login_at, timezone, NOT is_active, token_version FROM users WHERE id = $1;`, uid)
+		return row.Scan(&user.ID, &user.Name, &user.PasswordHash, &user.LastLoginAt, &user.Timezone, &user.Disabled, &user.TokenVersion)
+	})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errorvalues.ErrUserNotFound
 		}
-		return nil, errors.New("searching user by id error: " + err.Error())
+		return nil, fmt.Errorf("searching user by id error: %w", err)
 	}
 	return &user, nil
 }
@@ -99,7 +139,60 @@ func (ur *UsersRepository) Update(ctx context.Context, user *entity.User) error
 		user.ID,
 	)
 	if err != nil {
-		return errors.New("updating user error: " + err.Error())
+		return fmt.Errorf("updating user error: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrUserNotFound
+	}
+	return nil
+}
+
+func (ur *UsersRepository) UpdateName(ctx context.Context, id uuid.UUID, newName string) error {
+	ct, err := ur.conn.Exec(ctx, `UPDATE users SET name = $1 WHERE id = $2;`, newName, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return userConflict(pgErr)
+			case "22001":
+				return errNameTooLong
+			}
+		}
+		return fmt.Errorf("updating user name error: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrUserNotFound
+	}
+	return nil
+}
+
+func (ur *UsersRepository) UpdatePasswordHash(ctx context.Context, id uuid.UUID, hash string) error {
+	ct, err := ur.conn.Exec(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2;`, hash, id)
+	if err != nil {
+		return fmt.Errorf("updating user password hash error: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrUserNotFound
+	}
+	return nil
+}
+
+func (ur *UsersRepository) UpdateTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	ct, err := ur.conn.Exec(ctx, `UPDATE users SET timezone = $1 WHERE id = $2;`, timezone, id)
+	if err != nil {
+		return fmt.Errorf("updating user timezone error: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrUserNotFound
+	}
+	return nil
+}
+
+func (ur *UsersRepository) TouchLastLogin(ctx context.Context, id uuid.UUID) error {
+	ct, err := ur.conn.Exec(ctx, `UPDATE users SET last_login_at = NOW() WHERE id = $1;`, id)
+	if err != nil {
+		return fmt.Errorf("updating last login error: %w", err)
 	}
 	if ct.RowsAffected() == 0 {
 		return errorvalues.ErrUserNotFound
@@ -107,13 +200,146 @@ func (ur *UsersRepository) Update(ctx context.Context, user *entity.User) error
 	return nil
 }
 
+func (ur *UsersRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	ct, err := ur.conn.Exec(ctx, `UPDATE users SET is_active = $1 WHERE id = $2;`, active, id)
+	if err != nil {
+		return fmt.Errorf("updating user activity error: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return errorvalues.ErrUserNotFound
+	}
+	return nil
+}
+
+func (ur *UsersRepository) BumpTokenVersion(ctx context.Context, id uuid.UUID) (int, error) {
+	var version int
+	err := ur.conn.QueryRow(ctx, `UPDATE users SET token_version = token_version + 1 WHERE id = $1 RETURNING token_version;`, id).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errorvalues.ErrUserNotFound
+		}
+		return 0, fmt.Errorf("bumping token version error: %w", err)
+	}
+	return version, nil
+}
+
 func (ur *UsersRepository) Delete(ctx context.Context, uid uuid.UUID) error {
 	ct, err := ur.conn.Exec(ctx, `DELETE FROM users WHERE id = $1;`, uid)
 	if err != nil {
-		return errors.New("deleting user error: " + err.Error())
+		return fmt.Errorf("deleting user error: %w", err)
 	}
 	if ct.RowsAffected() == 0 {
 		return errorvalues.ErrUserNotFound
 	}
 	return nil
 }
+
+// ORDER BY clauses of users list by sort option. Client's value never gets into query itself,
+// id keeps order stable for pagination.
+var usersListOrders = map[entity.UserSort]string{
+	entity.UserSortName:          "name ASC, id",
+	entity.UserSortNameDesc:      "name DESC, id",
+	entity.UserSortCreatedAt:     "created_at ASC, id",
+	entity.UserSortCreatedAtDesc: "created_at DESC, id",
+}
+
+// Escapes LIKE wildcards, so query matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func namePattern(query string) string {
+	return "%" + likeEscaper.Replace(query) + "%"
+}
+
+func (ur *UsersRepository) List(ctx context.Context, query string, sort entity.UserSort, limit, offset int) ([]*entity.User, error) {
+	order, ok := usersListOrders[sort]
+	if !ok {
+		order = usersListOrders[entity.UserSortName]
+	}
+	users := make([]*entity.User, 0)
+	var rows pgx.Rows
+	err := withRetry(ctx, func() (err error) {
+		rows, err = ur.readConn.Query(ctx, `SELECT id, name, last_login_at, timezone FROM users 
+		WHERE name ILIKE $1 ORDER BY `+order+` LIMIT $2 OFFSET $3;`, namePattern(query), limit, offset)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing users error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		u := entity.User{}
+		err = rows.Scan(&u.ID, &u.Name, &u.LastLoginAt, &u.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unmarhalling user error: %w", err)
+		}
+		users = append(users, &u)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unexpected error after scanning: %w", rows.Err())
+	}
+	return users, nil
+}
+
+func (ur *UsersRepository) Count(ctx context.Context, query string) (int, error) {
+	var count int
+	err := withRetry(ctx, func() error {
+		row := ur.readConn.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE name ILIKE $1;`, namePattern(query))
+		return row.Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("counting users error: %w", err)
+	}
+	return count, nil
+}
+
+func (ur *UsersRepository) Erase(ctx context.Context, uid uuid.UUID) (*entity.ErasureSummary, error) {
+	tx, err := ur.conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erasing user: tx start error: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	var summary entity.ErasureSummary
+	// Deleting explicitly instead of relying on cascade to count removed rows
+	ct, err := tx.Exec(ctx, `DELETE FROM habit_checks WHERE habit_id IN (SELECT id FROM habits WHERE user_id = $1);`, uid)
+	if err != nil {
+		return nil, fmt.Errorf("erasing user checks error: %w", err)
+	}
+	summary.Checks = ct.RowsAffected()
+	ct, err = tx.Exec(ctx, `DELETE FROM habits WHERE user_id = $1;`, uid)
+	if err != nil {
+		return nil, fmt.Errorf("erasing user habits error: %w", err)
+	}
+	summary.Habits = ct.RowsAffected()
+	ct, err = tx.Exec(ctx, `DELETE FROM users WHERE id = $1;`, uid)
+	if err != nil {
+		return nil, fmt.Errorf("erasing user error: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, errorvalues.ErrUserNotFound
+	}
+	summary.Users = ct.RowsAffected()
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("commiting tx error: %w", err)
+	}
+	return &summary, nil
+}
+
+func (ur *UsersRepository) CountErasure(ctx context.Context, uid uuid.UUID) (*entity.ErasureSummary, error) {
+	var summary entity.ErasureSummary
+	// Counts are batched in one query, so they're consistent with each other
+	err := withRetry(ctx, func() error {
+		row := ur.readConn.QueryRow(ctx, `SELECT
+			(SELECT COUNT(*) FROM users WHERE id = $1),
+			(SELECT COUNT(*) FROM habits WHERE user_id = $1),
+			(SELECT COUNT(*) FROM habit_checks WHERE habit_id IN (SELECT id FROM habits WHERE user_id = $1));`, uid)
+		return row.Scan(&summary.Users, &summary.Habits, &summary.Checks)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("counting user erasure error: %w", err)
+	}
+	if summary.Users == 0 {
+		return nil, errorvalues.ErrUserNotFound
+	}
+	return &summary, nil
+}
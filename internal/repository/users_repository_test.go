@@ -34,7 +34,7 @@ func TestCreateUser(t *testing.T) {
 	}
 	query := regexp.QuoteMeta(`INSERT INTO users (name, password_hash) VALUES ($1, $2);`)
 	ctx := context.Background()
-	repo := repository.NewUsersRepoWithConn(conn)
+	repo := repository.NewUsersRepoWithConn(conn, nil)
 	t.Run("successfully created", func(t *testing.T) {
 		conn.ExpectExec(query).WithArgs(user.Name, user.PasswordHash).WillReturnResult(pgxmock.NewResult("INSERT", 1))
 		err := repo.Create(ctx, &user)
@@ -46,6 +46,23 @@ func TestCreateUser(t *testing.T) {
 		})
 		err := repo.Create(ctx, &user)
 		assert.ErrorIs(t, err, errorvalues.ErrUserExists)
+		assert.NotErrorIs(t, err, errorvalues.ErrNameTaken)
+	})
+	t.Run("name taken error by constraint name", func(t *testing.T) {
+		conn.ExpectExec(query).WithArgs(user.Name, user.PasswordHash).WillReturnError(&pgconn.PgError{
+			Code:           "23505",
+			ConstraintName: "users_name_key",
+		})
+		err := repo.Create(ctx, &user)
+		assert.ErrorIs(t, err, errorvalues.ErrNameTaken)
+		assert.ErrorIs(t, err, errorvalues.ErrUserExists)
+	})
+	t.Run("name too long error", func(t *testing.T) {
+		conn.ExpectExec(query).WithArgs(user.Name, user.PasswordHash).WillReturnError(&pgconn.PgError{
+			Code: "22001",
+		})
+		err := repo.Create(ctx, &user)
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
 	})
 	t.Run("db error", func(t *testing.T) {
 		conn.ExpectExec(query).WithArgs(user.Name, user.PasswordHash).WillReturnError(errors.New("db error"))
@@ -60,17 +77,19 @@ func TestFindByName(t *testing.T) {
 		t.Fatal(err)
 	}
 	ctx := context.Background()
-	repo := repository.NewUsersRepoWithConn(conn)
+	repo := repository.NewUsersRepoWithConn(conn, nil)
 	user := entity.User{
 		ID:           uuid.New(),
 		Name:         "test_user",
 		PasswordHash: "test_password_hash",
+		Timezone:     "UTC",
+		TokenVersion: 2,
 	}
-	query := regexp.QuoteMeta(`SELECT id, name, password_hash FROM users WHERE name = $1;`)
+	query := regexp.QuoteMeta(`SELECT id, name, password_hash, last_login_at, timezone, NOT is_active, token_version FROM users WHERE name = $1;`)
 	t.Run("found", func(t *testing.T) {
 		conn.ExpectQuery(query).
 			WithArgs(user.Name).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "password_hash"}).AddRow(user.ID, user.Name, user.PasswordHash))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "password_hash", "last_login_at", "timezone", "disabled", "token_version"}).AddRow(user.ID, user.Name, user.PasswordHash, user.LastLoginAt, user.Timezone, user.Disabled, user.TokenVersion))
 		result, err := repo.FindByName(ctx, user.Name)
 		assert.NoError(t, err)
 		assert.Equal(t, user, *result)
@@ -97,17 +116,19 @@ func TestFindByID(t *testing.T) {
 		t.Fatal(err)
 	}
 	ctx := context.Background()
-	repo := repository.NewUsersRepoWithConn(conn)
+	repo := repository.NewUsersRepoWithConn(conn, nil)
 	user := entity.User{
 		ID:           uuid.New(),
 		Name:         "test_user",
 		PasswordHash: "test_password_hash",
+		Timezone:     "UTC",
+		TokenVersion: 2,
 	}
-	query := regexp.QuoteMeta(`SELECT id, name, password_hash FROM users WHERE id = $1;`)
+	query := regexp.QuoteMeta(`SELECT id, name, password_hash, last_login_at, timezone, NOT is_active, token_version FROM users WHERE id = $1;`)
 	t.Run("found", func(t *testing.T) {
 		conn.ExpectQuery(query).
 			WithArgs(user.ID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "password_hash"}).AddRow(user.ID, user.Name, user.PasswordHash))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "password_hash", "last_login_at", "timezone", "disabled", "token_version"}).AddRow(user.ID, user.Name, user.PasswordHash, user.LastLoginAt, user.Timezone, user.Disabled, user.TokenVersion))
 		result, err := repo.FindByID(ctx, user.ID)
 		assert.NoError(t, err)
 		assert.Equal(t, user, *result)
@@ -134,7 +155,7 @@ func TestUpdateUser(t *testing.T) {
 		t.Fatal(err)
 	}
 	ctx := context.Background()
-	repo := repository.NewUsersRepoWithConn(conn)
+	repo := repository.NewUsersRepoWithConn(conn, nil)
 	user := entity.User{
 		ID:           uuid.New(),
 		Name:         "test_user",
@@ -164,13 +185,244 @@ func TestUpdateUser(t *testing.T) {
 	})
 }
 
+func TestUpdatePasswordHash(t *testing.T) {
+	conn, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := repository.NewUsersRepoWithConn(conn, nil)
+	uid := uuid.New()
+	hash := "new_password_hash"
+	query := regexp.QuoteMeta(`UPDATE users SET password_hash = $1 WHERE id = $2;`)
+	t.Run("updated", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(hash, uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		assert.NoError(t, repo.UpdatePasswordHash(ctx, uid, hash))
+	})
+	t.Run("not found", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(hash, uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		assert.ErrorIs(t, repo.UpdatePasswordHash(ctx, uid, hash), errorvalues.ErrUserNotFound)
+	})
+	t.Run("db error", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(hash, uid).
+			WillReturnError(errors.New("db error"))
+		assert.Error(t, repo.UpdatePasswordHash(ctx, uid, hash))
+	})
+	assert.NoError(t, conn.ExpectationsWereMet())
+}
+
+func TestUpdateUserName(t *testing.T) {
+	conn, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := repository.NewUsersRepoWithConn(conn, nil)
+	uid := uuid.New()
+	name := "new_name"
+	query := regexp.QuoteMeta(`UPDATE users SET name = $1 WHERE id = $2;`)
+	t.Run("updated", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(name, uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		err := repo.UpdateName(ctx, uid, name)
+		assert.NoError(t, err)
+	})
+	t.Run("name taken", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(name, uid).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_name_key"})
+		err := repo.UpdateName(ctx, uid, name)
+		assert.ErrorIs(t, err, errorvalues.ErrNameTaken)
+	})
+	t.Run("not found", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(name, uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		err := repo.UpdateName(ctx, uid, name)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+}
+
+func TestUpdateUserTimezone(t *testing.T) {
+	conn, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := repository.NewUsersRepoWithConn(conn, nil)
+	uid := uuid.New()
+	tz := "Asia/Vladivostok"
+	query := regexp.QuoteMeta(`UPDATE users SET timezone = $1 WHERE id = $2;`)
+	t.Run("updated", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(tz, uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		err := repo.UpdateTimezone(ctx, uid, tz)
+		assert.NoError(t, err)
+	})
+	t.Run("not found", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(tz, uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		err := repo.UpdateTimezone(ctx, uid, tz)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+}
+
+func TestListUsers(t *testing.T) {
+	conn, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := repository.NewUsersRepoWithConn(conn, nil)
+	columns := []string{"id", "name", "last_login_at", "timezone"}
+	t.Run("filtered and sorted", func(t *testing.T) {
+		uid := uuid.New()
+		conn.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, last_login_at, timezone FROM users 
+		WHERE name ILIKE $1 ORDER BY created_at DESC, id LIMIT $2 OFFSET $3;`)).
+			WithArgs("%bob%", 10, 20).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(uid, "Bobby", nil, "UTC"))
+		users, err := repo.List(ctx, "bob", entity.UserSortCreatedAtDesc, 10, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, []*entity.User{{ID: uid, Name: "Bobby", Timezone: "UTC"}}, users)
+	})
+	t.Run("wildcards matched literally", func(t *testing.T) {
+		conn.ExpectQuery(regexp.QuoteMeta(`ORDER BY name ASC, id`)).
+			WithArgs(`%50\%\_off%`, 10, 0).
+			WillReturnRows(pgxmock.NewRows(columns))
+		users, err := repo.List(ctx, "50%_off", entity.UserSortName, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+	})
+	t.Run("unknown sort falls back to name", func(t *testing.T) {
+		conn.ExpectQuery(regexp.QuoteMeta(`ORDER BY name ASC, id`)).
+			WithArgs("%%", 10, 0).
+			WillReturnRows(pgxmock.NewRows(columns))
+		_, err := repo.List(ctx, "", entity.UserSort("password_hash"), 10, 0)
+		assert.NoError(t, err)
+	})
+	t.Run("count honors filter", func(t *testing.T) {
+		conn.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM users WHERE name ILIKE $1;`)).
+			WithArgs("%bob%").
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+		count, err := repo.Count(ctx, "bob")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+	assert.NoError(t, conn.ExpectationsWereMet())
+}
+
+func TestTouchLastLogin(t *testing.T) {
+	conn, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := repository.NewUsersRepoWithConn(conn, nil)
+	uid := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE users SET last_login_at = NOW() WHERE id = $1;`)
+	t.Run("updated", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		err := repo.TouchLastLogin(ctx, uid)
+		assert.NoError(t, err)
+	})
+	t.Run("not found", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		err := repo.TouchLastLogin(ctx, uid)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+	t.Run("db error", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(uid).
+			WillReturnError(errors.New("db error"))
+		err := repo.TouchLastLogin(ctx, uid)
+		assert.Error(t, err)
+	})
+}
+
+func TestSetUserActive(t *testing.T) {
+	conn, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := repository.NewUsersRepoWithConn(conn, nil)
+	uid := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE users SET is_active = $1 WHERE id = $2;`)
+	t.Run("disabled", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(false, uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		err := repo.SetActive(ctx, uid, false)
+		assert.NoError(t, err)
+	})
+	t.Run("not found", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(true, uid).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		err := repo.SetActive(ctx, uid, true)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+	t.Run("db error", func(t *testing.T) {
+		conn.ExpectExec(query).
+			WithArgs(false, uid).
+			WillReturnError(errors.New("db error"))
+		err := repo.SetActive(ctx, uid, false)
+		assert.Error(t, err)
+	})
+}
+
+func TestBumpTokenVersion(t *testing.T) {
+	conn, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := repository.NewUsersRepoWithConn(conn, nil)
+	uid := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE users SET token_version = token_version + 1 WHERE id = $1 RETURNING token_version;`)
+	t.Run("bumped", func(t *testing.T) {
+		conn.ExpectQuery(query).
+			WithArgs(uid).
+			WillReturnRows(pgxmock.NewRows([]string{"token_version"}).AddRow(3))
+		version, err := repo.BumpTokenVersion(ctx, uid)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, version)
+	})
+	t.Run("not found", func(t *testing.T) {
+		conn.ExpectQuery(query).
+			WithArgs(uid).
+			WillReturnError(pgx.ErrNoRows)
+		_, err := repo.BumpTokenVersion(ctx, uid)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+	t.Run("db error", func(t *testing.T) {
+		conn.ExpectQuery(query).
+			WithArgs(uid).
+			WillReturnError(errors.New("db error"))
+		_, err := repo.BumpTokenVersion(ctx, uid)
+		assert.Error(t, err)
+	})
+}
+
 func TestDeleteUser(t *testing.T) {
 	conn, err := pgxmock.NewPool()
 	if err != nil {
 		t.Fatal(err)
 	}
 	ctx := context.Background()
-	repo := repository.NewUsersRepoWithConn(conn)
+	repo := repository.NewUsersRepoWithConn(conn, nil)
 	uid := uuid.New()
 	query := regexp.QuoteMeta(`DELETE FROM users WHERE id = $1;`)
 	t.Run("deleted", func(t *testing.T) {
@@ -196,12 +448,124 @@ func TestDeleteUser(t *testing.T) {
 	})
 }
 
+func TestEraseUser(t *testing.T) {
+	conn, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := repository.NewUsersRepoWithConn(conn, nil)
+	uid := uuid.New()
+	checksQuery := regexp.QuoteMeta(`DELETE FROM habit_checks WHERE habit_id IN (SELECT id FROM habits WHERE user_id = $1);`)
+	habitsQuery := regexp.QuoteMeta(`DELETE FROM habits WHERE user_id = $1;`)
+	userQuery := regexp.QuoteMeta(`DELETE FROM users WHERE id = $1;`)
+	t.Run("erased", func(t *testing.T) {
+		conn.ExpectBegin()
+		conn.ExpectExec(checksQuery).WithArgs(uid).WillReturnResult(pgxmock.NewResult("DELETE", 7))
+		conn.ExpectExec(habitsQuery).WithArgs(uid).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		conn.ExpectExec(userQuery).WithArgs(uid).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		conn.ExpectCommit()
+		summary, err := repo.Erase(ctx, uid)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.ErasureSummary{Users: 1, Habits: 2, Checks: 7}, summary)
+		assert.NoError(t, conn.ExpectationsWereMet())
+	})
+	t.Run("not found rolled back", func(t *testing.T) {
+		conn.ExpectBegin()
+		conn.ExpectExec(checksQuery).WithArgs(uid).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		conn.ExpectExec(habitsQuery).WithArgs(uid).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		conn.ExpectExec(userQuery).WithArgs(uid).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		conn.ExpectRollback()
+		_, err := repo.Erase(ctx, uid)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+		assert.NoError(t, conn.ExpectationsWereMet())
+	})
+}
+
+func TestCountUserErasure(t *testing.T) {
+	conn, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := repository.NewUsersRepoWithConn(conn, nil)
+	uid := uuid.New()
+	query := regexp.QuoteMeta(`SELECT
+			(SELECT COUNT(*) FROM users WHERE id = $1),
+			(SELECT COUNT(*) FROM habits WHERE user_id = $1),
+			(SELECT COUNT(*) FROM habit_checks WHERE habit_id IN (SELECT id FROM habits WHERE user_id = $1));`)
+	t.Run("counted", func(t *testing.T) {
+		conn.ExpectQuery(query).WithArgs(uid).
+			WillReturnRows(pgxmock.NewRows([]string{"users", "habits", "checks"}).AddRow(int64(1), int64(2), int64(7)))
+		summary, err := repo.CountErasure(ctx, uid)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.ErasureSummary{Users: 1, Habits: 2, Checks: 7}, summary)
+	})
+	t.Run("not found", func(t *testing.T) {
+		conn.ExpectQuery(query).WithArgs(uid).
+			WillReturnRows(pgxmock.NewRows([]string{"users", "habits", "checks"}).AddRow(int64(0), int64(0), int64(0)))
+		_, err := repo.CountErasure(ctx, uid)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+	t.Run("db error", func(t *testing.T) {
+		conn.ExpectQuery(query).WithArgs(uid).WillReturnError(errors.New("db error"))
+		_, err := repo.CountErasure(ctx, uid)
+		assert.Error(t, err)
+	})
+}
+
+func TestEraseUserIntegrational(t *testing.T) {
+	cfg := setupUsersTestDB(t)
+	repo := repository.NewUsersRepo(cfg)
+	ctx := context.Background()
+	err := repo.Create(ctx, &entity.User{Name: "test_user", PasswordHash: "some_test_hash"})
+	assert.NoError(t, err)
+	user, err := repo.FindByName(ctx, "test_user")
+	assert.NoError(t, err)
+	conn, err := sql.Open("postgres", cfg.ConnString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, title := range []string{"first", "second"} {
+		var habitID uuid.UUID
+		err = conn.QueryRow(`INSERT INTO habits (user_id, title, start_date) VALUES ($1, $2, CURRENT_DATE) RETURNING id;`, user.ID, title).Scan(&habitID)
+		assert.NoError(t, err)
+		_, err = conn.Exec(`INSERT INTO habit_checks (habit_id, check_date) VALUES ($1, CURRENT_DATE), ($1, CURRENT_DATE - 1);`, habitID)
+		assert.NoError(t, err)
+	}
+	t.Run("previewed", func(t *testing.T) {
+		summary, err := repo.CountErasure(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.ErasureSummary{Users: 1, Habits: 2, Checks: 4}, summary)
+	})
+	t.Run("erased with summary", func(t *testing.T) {
+		summary, err := repo.Erase(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.ErasureSummary{Users: 1, Habits: 2, Checks: 4}, summary)
+	})
+	t.Run("no orphans left", func(t *testing.T) {
+		var habits, checks int
+		err := conn.QueryRow(`SELECT COUNT(*) FROM habits WHERE user_id = $1;`, user.ID).Scan(&habits)
+		assert.NoError(t, err)
+		err = conn.QueryRow(`SELECT COUNT(*) FROM habit_checks hc LEFT JOIN habits h ON h.id = hc.habit_id WHERE h.id IS NULL;`).Scan(&checks)
+		assert.NoError(t, err)
+		assert.Zero(t, habits)
+		assert.Zero(t, checks)
+	})
+	t.Run("unexist user", func(t *testing.T) {
+		_, err := repo.Erase(ctx, user.ID)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+}
+
 func TestUsersIntegrational(t *testing.T) {
 	cfg := setupUsersTestDB(t)
 	repo := repository.NewUsersRepo(cfg)
 	user := entity.User{
 		Name:         "test_user",
 		PasswordHash: "some_test_hash",
+		Timezone:     "UTC",
 	}
 	ctx := context.Background()
 	t.Run("successfully created user", func(t *testing.T) {
@@ -231,6 +595,7 @@ func TestUsersIntegrational(t *testing.T) {
 		ID:           user.ID,
 		Name:         "new_test_user",
 		PasswordHash: "other_test_hash",
+		Timezone:     "UTC",
 	}
 	t.Run("user updated", func(t *testing.T) {
 		err := repo.Update(ctx, &newUserCredentials)
@@ -239,6 +604,13 @@ func TestUsersIntegrational(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, newUserCredentials, *res)
 	})
+	t.Run("user timezone updated", func(t *testing.T) {
+		err := repo.UpdateTimezone(ctx, newUserCredentials.ID, "Asia/Tokyo")
+		assert.NoError(t, err)
+		res, err := repo.FindByID(ctx, newUserCredentials.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Tokyo", res.Timezone)
+	})
 	t.Run("user for update not found", func(t *testing.T) {
 		err := repo.Update(ctx, &entity.User{
 			ID: uuid.New(),
@@ -1,12 +1,17 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
 	"github.com/limbo/discipline/internal/repository"
 	"github.com/limbo/discipline/pkg/entity"
@@ -15,101 +20,863 @@ import (
 type HabitChecksService struct {
 	habitsRepo repository.HabitsRepositoryI
 	checksRepo repository.HabitChecksRepositoryI
+	// Source of users' timezones. Nil if days are counted in UTC for everyone
+	usersRepo repository.UsersRepositoryI
+	// Runs marks writes along with stats update. Nil if stats aren't materialized
+	tx repository.TxRunner
 }
 
 func NewHabitChecksService(habitsRepo repository.HabitsRepositoryI, checksRepo repository.HabitChecksRepositoryI) *HabitChecksService {
+	return NewHabitChecksServiceWithUsers(habitsRepo, checksRepo, nil)
+}
+
+// Creates service resolving "today" in timezone of user, taken from usersRepo.
+// If usersRepo is nil, days are counted in UTC.
+func NewHabitChecksServiceWithUsers(habitsRepo repository.HabitsRepositoryI, checksRepo repository.HabitChecksRepositoryI, usersRepo repository.UsersRepositoryI) *HabitChecksService {
 	if habitsRepo == nil || checksRepo == nil {
 		log.Fatal("on habit checks service provided nil repos")
 	}
 	return &HabitChecksService{
 		habitsRepo: habitsRepo,
 		checksRepo: checksRepo,
+		usersRepo:  usersRepo,
 	}
 }
 
-func (serv *HabitChecksService) CheckHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time) error {
-	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
+// Makes habits stats materialized: they are recalculated in same transaction with every
+// change of marks or pauses and read from repository until day changes. Must be called before service is used.
+func (serv *HabitChecksService) SetTxRunner(tx repository.TxRunner) {
+	serv.tx = tx
+}
+
+// Returns calendar day of t in loc as UTC midnight, the way check dates are stored.
+func CalendarDay(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func (serv *HabitChecksService) Today(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	loc, err := serv.userLocation(ctx, userID)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrHabitNotFound) {
+		return time.Time{}, err
+	}
+	return CalendarDay(time.Now(), loc), nil
+}
+
+// Returns location of user's timezone, UTC if it's unknown.
+func (serv *HabitChecksService) userLocation(ctx context.Context, userID uuid.UUID) (*time.Location, error) {
+	if serv.usersRepo == nil {
+		return time.UTC, nil
+	}
+	user, err := serv.usersRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, wrapError(err, "repository error", errorvalues.ErrUserNotFound)
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		// Timezone was valid when set, so it's likely missing in host's tzdata
+		slog.Warn("loading user's timezone error, falling back to UTC", slog.String("uid", userID.String()),
+			slog.String("timezone", user.Timezone), slog.String("error", err.Error()))
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+// Ensures habit with habitID is owned by user with userID and may be marked on date:
+// it's neither in the future nor before habit start.
+func (serv *HabitChecksService) checkMarkable(ctx context.Context, habitID, userID uuid.UUID, date time.Time) (*entity.Habit, error) {
+	habit, err := serv.checkOwner(ctx, habitID, userID)
+	if err != nil {
+		return nil, err
+	}
+	today, err := serv.Today(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	day := CalendarDay(date, time.UTC)
+	if day.After(today) || (!habit.StartDate.IsZero() && day.Before(habit.StartDate)) {
+		return nil, errorvalues.ErrCheckDateNotAllowed
+	}
+	return habit, nil
+}
+
+// Runs write of habit's marks or pauses. If stats are materialized, write is done in transaction
+// with habit locked, and stats are recalculated within it, so they never disagree with marks.
+func (serv *HabitChecksService) writeMarks(ctx context.Context, habit *entity.Habit, write func(checksRepo repository.HabitChecksRepositoryI) error) error {
+	if serv.tx == nil {
+		return write(serv.checksRepo)
+	}
+	loc, err := serv.userLocation(ctx, habit.UserID)
+	if err != nil {
+		return err
+	}
+	return serv.tx.WithTx(ctx, func(tx pgx.Tx) error {
+		checksRepo := serv.checksRepo.WithTx(tx)
+		if err := checksRepo.LockHabit(ctx, habit.ID); err != nil {
+			return wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
+		}
+		if err := write(checksRepo); err != nil {
 			return err
 		}
-		return errors.New("repository error: " + err.Error())
+		_, err := serv.refreshStats(ctx, checksRepo, habit, loc)
+		return err
+	})
+}
+
+// Same as writeMarks, but for marks of several habits owned by user with userID in one transaction.
+// Habits are locked in order of their ids, so concurrent batches don't deadlock.
+func (serv *HabitChecksService) writeManyMarks(ctx context.Context, userID uuid.UUID, habits []*entity.Habit, write func(checksRepo repository.HabitChecksRepositoryI) error) error {
+	if serv.tx == nil {
+		return write(serv.checksRepo)
 	}
-	if habit.UserID != userID {
-		return errorvalues.ErrWrongOwner
+	loc, err := serv.userLocation(ctx, userID)
+	if err != nil {
+		return err
+	}
+	habits = slices.Clone(habits)
+	slices.SortFunc(habits, func(a, b *entity.Habit) int { return bytes.Compare(a.ID[:], b.ID[:]) })
+	return serv.tx.WithTx(ctx, func(tx pgx.Tx) error {
+		checksRepo := serv.checksRepo.WithTx(tx)
+		for _, habit := range habits {
+			if err := checksRepo.LockHabit(ctx, habit.ID); err != nil {
+				return wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
+			}
+		}
+		if err := write(checksRepo); err != nil {
+			return err
+		}
+		for _, habit := range habits {
+			if _, err := serv.refreshStats(ctx, checksRepo, habit, loc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (serv *HabitChecksService) CheckHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time, note string) error {
+	habit, err := serv.checkMarkable(ctx, habitID, userID, date)
+	if err != nil {
+		return err
+	}
+	return serv.writeMarks(ctx, habit, func(checksRepo repository.HabitChecksRepositoryI) error {
+		if err := checkFree(ctx, checksRepo, habit, date); err != nil {
+			return err
+		}
+		if habit.AllowMultiplePerDay {
+			err = checksRepo.CreateRepeated(ctx, habitID, date, note)
+		} else {
+			err = checksRepo.Create(ctx, habitID, date, note)
+		}
+		if err != nil {
+			return fmt.Errorf("repository error: %w", err)
+		}
+		return nil
+	})
+}
+
+func (serv *HabitChecksService) CheckMany(ctx context.Context, userID uuid.UUID, reqs []CheckRequest) ([]BatchError, error) {
+	ids := make([]uuid.UUID, 0, len(reqs))
+	for _, req := range reqs {
+		if !slices.Contains(ids, req.HabitID) {
+			ids = append(ids, req.HabitID)
+		}
+	}
+	habits, err := serv.habitsRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	today, err := serv.Today(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	failures := make([]BatchError, 0)
+	valid := make([]int, 0, len(reqs))
+	marked := make([]*entity.Habit, 0, len(habits))
+	for i, req := range reqs {
+		habit, ok := habits[req.HabitID]
+		day := CalendarDay(req.Date, time.UTC)
+		switch {
+		case !ok:
+			failures = append(failures, BatchError{Index: i, Err: errorvalues.ErrHabitNotFound})
+		case habit.UserID != userID:
+			failures = append(failures, BatchError{Index: i, Err: errorvalues.ErrWrongOwner})
+		case day.After(today) || (!habit.StartDate.IsZero() && day.Before(habit.StartDate)):
+			failures = append(failures, BatchError{Index: i, Err: errorvalues.ErrCheckDateNotAllowed})
+		default:
+			valid = append(valid, i)
+			if !slices.Contains(marked, habit) {
+				marked = append(marked, habit)
+			}
+		}
+	}
+	if len(valid) == 0 {
+		return failures, nil
+	}
+	err = serv.writeManyMarks(ctx, userID, marked, func(checksRepo repository.HabitChecksRepositoryI) error {
+		for _, i := range valid {
+			habit := habits[reqs[i].HabitID]
+			// Repeated items of one day conflict with each other as well
+			err := checkFree(ctx, checksRepo, habit, reqs[i].Date)
+			if errors.Is(err, errorvalues.ErrCheckExist) {
+				failures = append(failures, BatchError{Index: i, Err: err})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if habit.AllowMultiplePerDay {
+				err = checksRepo.CreateRepeated(ctx, habit.ID, reqs[i].Date, "")
+			} else {
+				err = checksRepo.Create(ctx, habit.ID, reqs[i].Date, "")
+			}
+			if err != nil {
+				return fmt.Errorf("repository error: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(failures, func(a, b BatchError) int { return a.Index - b.Index })
+	return failures, nil
+}
+
+// Reasons CanCheck reports check is rejected with, same as codes of errors CheckHabit fails with
+const (
+	CheckRejectedDateNotAllowed = "check_date_not_allowed"
+	CheckRejectedExists         = "check_exists"
+)
+
+func (serv *HabitChecksService) CanCheck(ctx context.Context, habitID, userID uuid.UUID, date time.Time) (bool, string, error) {
+	habit, err := serv.checkMarkable(ctx, habitID, userID, date)
+	if err != nil {
+		if errors.Is(err, errorvalues.ErrCheckDateNotAllowed) {
+			return false, CheckRejectedDateNotAllowed, nil
+		}
+		return false, "", err
+	}
+	err = checkFree(ctx, serv.checksRepo, habit, date)
+	if err != nil {
+		if errors.Is(err, errorvalues.ErrCheckExist) {
+			return false, CheckRejectedExists, nil
+		}
+		return false, "", err
 	}
-	if date.After(time.Now()) {
-		return errorvalues.ErrCheckDateNotAllowed
+	return true, "", nil
+}
+
+// Returns status of habit's mark on date, empty if date isn't marked.
+func dayStatus(ctx context.Context, checksRepo repository.HabitChecksRepositoryI, habitID uuid.UUID, date time.Time) (entity.CheckStatus, error) {
+	marks, err := checksRepo.GetCheckedDates(ctx, habitID, date, date)
+	if err != nil {
+		return "", fmt.Errorf("repository error: %w", err)
+	}
+	return marks[CalendarDay(date, time.UTC).Format(time.DateOnly)], nil
+}
+
+// Ensures habit can get check on date: it isn't marked yet, or, for habits allowing
+// multiple checks per day, isn't skipped. Otherwise returns errorvalues.ErrCheckExist
+func checkFree(ctx context.Context, checksRepo repository.HabitChecksRepositoryI, habit *entity.Habit, date time.Time) error {
+	if habit.AllowMultiplePerDay {
+		status, err := dayStatus(ctx, checksRepo, habit.ID, date)
+		if err != nil {
+			return err
+		}
+		if status == entity.CheckStatusSkipped {
+			return errorvalues.ErrCheckExist
+		}
+		return nil
 	}
-	exist, err := serv.checksRepo.Exists(ctx, habitID, date)
+	exist, err := checksRepo.Exists(ctx, habit.ID, date)
 	if err != nil {
-		return errors.New("repository error: " + err.Error())
+		return fmt.Errorf("repository error: %w", err)
 	}
 	if exist {
 		return errorvalues.ErrCheckExist
 	}
-	err = serv.checksRepo.Create(ctx, habitID, date)
+	return nil
+}
+
+func (serv *HabitChecksService) UpsertCheck(ctx context.Context, habitID, userID uuid.UUID, date time.Time, note string) (bool, error) {
+	habit, err := serv.checkMarkable(ctx, habitID, userID, date)
 	if err != nil {
-		return errors.New("repository error: " + err.Error())
+		return false, err
 	}
-	return nil
+	var created bool
+	err = serv.writeMarks(ctx, habit, func(checksRepo repository.HabitChecksRepositoryI) (err error) {
+		if habit.AllowMultiplePerDay {
+			// Repeated checks aren't covered by upsert, they just get note replaced
+			status, err := dayStatus(ctx, checksRepo, habitID, date)
+			if err != nil {
+				return err
+			}
+			if status == entity.CheckStatusChecked {
+				if err = checksRepo.UpdateNote(ctx, habitID, date, note); err != nil {
+					return fmt.Errorf("repository error: %w", err)
+				}
+				return nil
+			}
+		}
+		created, err = checksRepo.Upsert(ctx, habitID, date, note)
+		if err != nil {
+			// Habit could be purged since it was loaded
+			return wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+func (serv *HabitChecksService) SkipHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time) error {
+	habit, err := serv.checkMarkable(ctx, habitID, userID, date)
+	if err != nil {
+		return err
+	}
+	return serv.writeMarks(ctx, habit, func(checksRepo repository.HabitChecksRepositoryI) error {
+		exist, err := checksRepo.Exists(ctx, habitID, date)
+		if err != nil {
+			return fmt.Errorf("repository error: %w", err)
+		}
+		if exist {
+			return errorvalues.ErrCheckExist
+		}
+		err = checksRepo.CreateSkip(ctx, habitID, date)
+		if err != nil {
+			return fmt.Errorf("repository error: %w", err)
+		}
+		return nil
+	})
 }
 
 func (serv *HabitChecksService) UncheckHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time) error {
-	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
+	habit, err := serv.checkOwner(ctx, habitID, userID)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrHabitNotFound) {
-			return err
+		return err
+	}
+	return serv.writeMarks(ctx, habit, func(checksRepo repository.HabitChecksRepositoryI) error {
+		exist, err := checksRepo.Exists(ctx, habitID, date)
+		if err != nil {
+			return fmt.Errorf("repository error: %w", err)
+		}
+		if !exist {
+			return errorvalues.ErrCheckNotFound
+		}
+		// Checks of habit allowing multiple ones per day are taken back one by one
+		if habit.AllowMultiplePerDay {
+			err = checksRepo.DeleteLatest(ctx, habitID, date)
+		} else {
+			err = checksRepo.Delete(ctx, habitID, date)
+		}
+		if err != nil {
+			return fmt.Errorf("repository error: %w", err)
 		}
-		return errors.New("repository error: " + err.Error())
+		return nil
+	})
+}
+
+func (serv *HabitChecksService) GetHabitChecks(ctx context.Context, habitID, userID uuid.UUID, from, to time.Time, order entity.CheckOrder, status entity.CheckStatus) ([]entity.HabitCheck, error) {
+	if err := validateVar(string(status), "omitempty,oneof=checked skipped"); err != nil {
+		return nil, err
+	}
+	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
+	if err != nil {
+		return nil, wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
 	}
 	if habit.UserID != userID {
-		return errorvalues.ErrWrongOwner
+		return nil, errorvalues.ErrWrongOwner
+	}
+	checks, err := serv.checksRepo.GetByHabitAndDateRange(ctx, habitID, from, to, order, status)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	return checks, nil
+}
+
+func (serv *HabitChecksService) GetMonthlyChecks(ctx context.Context, habitID, userID uuid.UUID, year int) (map[time.Month][]int, error) {
+	_, err := serv.checkOwner(ctx, habitID, userID)
+	if err != nil {
+		return nil, err
 	}
-	exist, err := serv.checksRepo.Exists(ctx, habitID, date)
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	marks, err := serv.checksRepo.GetCheckedDates(ctx, habitID, from, from.AddDate(1, 0, -1))
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	months := make(map[time.Month][]int)
+	for date, status := range marks {
+		if status != entity.CheckStatusChecked {
+			continue
+		}
+		day, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			return nil, fmt.Errorf("parsing check date error: %w", err)
+		}
+		months[day.Month()] = append(months[day.Month()], day.Day())
+	}
+	for _, days := range months {
+		slices.Sort(days)
+	}
+	return months, nil
+}
+
+func (serv *HabitChecksService) GetAtRiskHabits(ctx context.Context, userID uuid.UUID) ([]*entity.Habit, error) {
+	today, err := serv.Today(ctx, userID)
 	if err != nil {
-		return errors.New("repository error: " + err.Error())
+		return nil, err
 	}
-	if !exist {
-		return errorvalues.ErrCheckNotFound
+	habits, err := serv.checksRepo.HabitsNotCheckedToday(ctx, userID, today)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
 	}
-	err = serv.checksRepo.Delete(ctx, habitID, date)
+	return habits, nil
+}
+
+func (serv *HabitChecksService) ExportChecks(ctx context.Context, userID uuid.UUID, fn func(check *entity.HabitCheck) error) error {
+	var fnErr error
+	err := serv.checksRepo.StreamByUserID(ctx, userID, func(check *entity.HabitCheck) error {
+		fnErr = fn(check)
+		return fnErr
+	})
 	if err != nil {
-		return errors.New("repository error: " + err.Error())
+		if fnErr != nil {
+			return fnErr
+		}
+		return fmt.Errorf("repository error: %w", err)
 	}
 	return nil
 }
 
-func (serv *HabitChecksService) GetHabitChecks(ctx context.Context, habitID, userID uuid.UUID, from, to time.Time) ([]entity.HabitCheck, error) {
-	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
+func (serv *HabitChecksService) CheckedToday(ctx context.Context, userID uuid.UUID, habitIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	checked := make(map[uuid.UUID]bool, len(habitIDs))
+	if len(habitIDs) == 0 {
+		return checked, nil
+	}
+	today, err := serv.Today(ctx, userID)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrHabitNotFound) {
+		return nil, err
+	}
+	ids, err := serv.checksRepo.GetHabitIDsByDate(ctx, habitIDs, today)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	for _, id := range ids {
+		checked[id] = true
+	}
+	return checked, nil
+}
+
+func (serv *HabitChecksService) GetUserChecksOn(ctx context.Context, userID uuid.UUID, date time.Time) ([]entity.UserCheck, error) {
+	if date.IsZero() {
+		today, err := serv.Today(ctx, userID)
+		if err != nil {
 			return nil, err
 		}
-		return nil, errors.New("repository error: " + err.Error())
+		date = today
+	}
+	checks, err := serv.checksRepo.GetByUserAndDate(ctx, userID, CalendarDay(date, time.UTC))
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	return checks, nil
+}
+
+func (serv *HabitChecksService) CountHabitChecks(ctx context.Context, habitID, userID uuid.UUID, from, to time.Time) (int, error) {
+	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
+	if err != nil {
+		return 0, wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
+	}
+	if habit.UserID != userID {
+		return 0, errorvalues.ErrWrongOwner
+	}
+	count, err := serv.checksRepo.CountByHabitAndDateRange(ctx, habitID, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("repository error: %w", err)
+	}
+	return count, nil
+}
+
+func (serv *HabitChecksService) RecentAdherence(ctx context.Context, habitID, userID uuid.UUID, days int) (int, int, error) {
+	if err := validateVar(days, "min=1,max=365"); err != nil {
+		return 0, 0, err
+	}
+	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
+	if err != nil {
+		return 0, 0, wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
+	}
+	if habit.UserID != userID {
+		return 0, 0, errorvalues.ErrWrongOwner
+	}
+	today, err := serv.Today(ctx, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	// Window of days ending today, both bounds included
+	from := today.AddDate(0, 0, 1-days)
+	checked, err := serv.checksRepo.CountByHabitAndDateRange(ctx, habitID, from, today)
+	if err != nil {
+		return 0, 0, fmt.Errorf("repository error: %w", err)
+	}
+	pauses, err := serv.checksRepo.GetPauses(ctx, []uuid.UUID{habitID})
+	if err != nil {
+		return 0, 0, fmt.Errorf("repository error: %w", err)
+	}
+	// Paused days aren't expected to be checked
+	return checked, days - pausedDays(pauses[habitID], from, today, today), nil
+}
+
+func (serv *HabitChecksService) GetLastCheck(ctx context.Context, habitID, userID uuid.UUID) (*time.Time, error) {
+	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
+	if err != nil {
+		return nil, wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
 	}
 	if habit.UserID != userID {
 		return nil, errorvalues.ErrWrongOwner
 	}
-	checks, err := serv.checksRepo.GetByHabitAndDateRange(ctx, habitID, from, to)
+	date, err := serv.checksRepo.GetLastCheckDate(ctx, habitID)
 	if err != nil {
-		return nil, errors.New("repository error: " + err.Error())
+		return nil, fmt.Errorf("repository error: %w", err)
 	}
-	return checks, nil
+	return date, nil
 }
 
 func (serv *HabitChecksService) GetHabitStats(ctx context.Context, habitID, userID uuid.UUID) (*entity.HabitStats, error) {
+	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
+	if err != nil {
+		return nil, wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
+	}
+	if habit.UserID != userID {
+		return nil, errorvalues.ErrWrongOwner
+	}
+	return serv.GetStatsForHabit(ctx, habit)
+}
+
+func (serv *HabitChecksService) GetStatsForHabit(ctx context.Context, habit *entity.Habit) (*entity.HabitStats, error) {
+	loc, err := serv.userLocation(ctx, habit.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if serv.tx == nil {
+		return serv.countStats(ctx, serv.checksRepo, habit, loc)
+	}
+	stored, err := serv.checksRepo.GetStats(ctx, habit.ID)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	if stored != nil && stored.AsOf.Equal(CalendarDay(time.Now(), loc)) && stored.Since.Equal(trackedSince(habit, loc)) {
+		return &stored.Stats, nil
+	}
+	// Outdated or never saved stats are recalculated under lock, so concurrent write isn't overwritten with them
+	var stats *entity.HabitStats
+	err = serv.tx.WithTx(ctx, func(tx pgx.Tx) error {
+		checksRepo := serv.checksRepo.WithTx(tx)
+		if err := checksRepo.LockHabit(ctx, habit.ID); err != nil {
+			return wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
+		}
+		stats, err = serv.refreshStats(ctx, checksRepo, habit, loc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Counts stats of habit and saves them as materialized ones.
+func (serv *HabitChecksService) refreshStats(ctx context.Context, checksRepo repository.HabitChecksRepositoryI, habit *entity.Habit, loc *time.Location) (*entity.HabitStats, error) {
+	stats, err := serv.countStats(ctx, checksRepo, habit, loc)
+	if err != nil {
+		return nil, err
+	}
+	err = checksRepo.SaveStats(ctx, &repository.StoredStats{
+		Stats: *stats,
+		AsOf:  CalendarDay(time.Now(), loc),
+		Since: trackedSince(habit, loc),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	return stats, nil
+}
+
+// Counts stats of habit from its marks and pauses, today is resolved in loc.
+func (serv *HabitChecksService) countStats(ctx context.Context, checksRepo repository.HabitChecksRepositoryI, habit *entity.Habit, loc *time.Location) (*entity.HabitStats, error) {
+	today := CalendarDay(time.Now(), loc)
+	marks, err := checksRepo.GetCheckedDates(ctx, habit.ID, time.Time{}, today)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	pauses, err := checksRepo.GetPauses(ctx, []uuid.UUID{habit.ID})
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	stats := &entity.HabitStats{ID: habit.ID, Paused: isPaused(pauses[habit.ID])}
+	checkedDays := 0
+	for key, status := range marks {
+		date, err := time.Parse(time.DateOnly, key)
+		if err != nil || status != entity.CheckStatusChecked {
+			continue
+		}
+		checkedDays++
+		if date.After(stats.LastCheck) {
+			stats.LastCheck = date
+		}
+	}
+	stats.TotalChecks = checkedDays
+	// Day may have several checks, all of them are counted
+	if habit.AllowMultiplePerDay && checkedDays != 0 {
+		stats.TotalChecks, err = checksRepo.CountByHabitAndDateRange(ctx, habit.ID, time.Time{}, today)
+		if err != nil {
+			return nil, fmt.Errorf("repository error: %w", err)
+		}
+	}
+	if marks == nil {
+		marks = make(map[string]entity.CheckStatus)
+	}
+	markPausedDays(marks, pauses[habit.ID], today)
+	stats.CurrentStreak, stats.MaxStreak = countStreaks(marks, today)
+	since := trackedSince(habit, loc)
+	// Today isn't counted in completion rate days, see completionRate
+	paused := pausedDays(pauses[habit.ID], since, today.AddDate(0, 0, -1), today)
+	stats.CompletionRate = completionRate(checkedDays, since.AddDate(0, 0, paused), today)
+	return stats, nil
+}
+
+func (serv *HabitChecksService) GetWeekdayDistribution(ctx context.Context, habitID, userID uuid.UUID) ([7]int, error) {
 	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
 	if err != nil {
 		if errors.Is(err, errorvalues.ErrHabitNotFound) {
-			return nil, err
+			return [7]int{}, err
 		}
-		return nil, errors.New("repository error: " + err.Error())
+		return [7]int{}, fmt.Errorf("repository error: %w", err)
+	}
+	if habit.UserID != userID {
+		return [7]int{}, errorvalues.ErrWrongOwner
+	}
+	counts, err := serv.checksRepo.CountByWeekday(ctx, habitID)
+	if err != nil {
+		return [7]int{}, fmt.Errorf("repository error: %w", err)
+	}
+	return counts, nil
+}
+
+// Size of pages in which user's habits are loaded for summary
+const summaryPageSize = 100
+
+func (serv *HabitChecksService) GetUserSummary(ctx context.Context, userID uuid.UUID) (*entity.UserSummary, error) {
+	habitIDs := make([]uuid.UUID, 0)
+	multiplePerDay := make(map[uuid.UUID]bool)
+	for offset := 0; ; offset += summaryPageSize {
+		habits, err := serv.habitsRepo.GetByUserID(ctx, userID, entity.HabitSortCreatedAt, summaryPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("repository error: %w", err)
+		}
+		for _, habit := range habits {
+			habitIDs = append(habitIDs, habit.ID)
+			if habit.AllowMultiplePerDay {
+				multiplePerDay[habit.ID] = true
+			}
+		}
+		if len(habits) < summaryPageSize {
+			break
+		}
+	}
+	summary := &entity.UserSummary{Habits: len(habitIDs)}
+	if len(habitIDs) == 0 {
+		return summary, nil
+	}
+	today, err := serv.Today(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Checks of all habits in one query instead of one per habit
+	marksByHabit, err := serv.checksRepo.GetCheckedDatesByHabits(ctx, habitIDs, time.Time{}, today)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	pauses, err := serv.checksRepo.GetPauses(ctx, habitIDs)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %w", err)
+	}
+	for habitID, marks := range marksByHabit {
+		checkedDays := 0
+		for _, status := range marks {
+			if status == entity.CheckStatusChecked {
+				checkedDays++
+			}
+		}
+		// Day may have several checks, all of them are counted as in habit's stats
+		if multiplePerDay[habitID] && checkedDays != 0 {
+			checkedDays, err = serv.checksRepo.CountByHabitAndDateRange(ctx, habitID, time.Time{}, today)
+			if err != nil {
+				return nil, fmt.Errorf("repository error: %w", err)
+			}
+		}
+		summary.TotalChecks += checkedDays
+		markPausedDays(marks, pauses[habitID], today)
+		_, longest := countStreaks(marks, today)
+		summary.LongestStreak = max(summary.LongestStreak, longest)
+	}
+	return summary, nil
+}
+
+func (serv *HabitChecksService) PauseHabit(ctx context.Context, habitID, userID uuid.UUID) error {
+	habit, err := serv.checkOwner(ctx, habitID, userID)
+	if err != nil {
+		return err
+	}
+	today, err := serv.Today(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return serv.writeMarks(ctx, habit, func(checksRepo repository.HabitChecksRepositoryI) error {
+		err := checksRepo.Pause(ctx, habitID, today)
+		if err != nil {
+			return wrapError(err, "repository error", errorvalues.ErrHabitPaused, errorvalues.ErrHabitNotFound)
+		}
+		return nil
+	})
+}
+
+func (serv *HabitChecksService) ResumeHabit(ctx context.Context, habitID, userID uuid.UUID) error {
+	habit, err := serv.checkOwner(ctx, habitID, userID)
+	if err != nil {
+		return err
+	}
+	today, err := serv.Today(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return serv.writeMarks(ctx, habit, func(checksRepo repository.HabitChecksRepositoryI) error {
+		err := checksRepo.Resume(ctx, habitID, today)
+		if err != nil {
+			return wrapError(err, "repository error", errorvalues.ErrHabitNotPaused)
+		}
+		return nil
+	})
+}
+
+// Returns habit with habitID if it exists and is owned by user with userID.
+func (serv *HabitChecksService) checkOwner(ctx context.Context, habitID, userID uuid.UUID) (*entity.Habit, error) {
+	habit, err := serv.habitsRepo.GetByID(ctx, habitID)
+	if err != nil {
+		return nil, wrapError(err, "repository error", errorvalues.ErrHabitNotFound)
 	}
 	if habit.UserID != userID {
 		return nil, errorvalues.ErrWrongOwner
 	}
+	return habit, nil
+}
+
+// Reports if habit with given pauses (ordered by start) is paused now.
+func isPaused(pauses []entity.HabitPause) bool {
+	return len(pauses) != 0 && pauses[len(pauses)-1].ResumedAt == nil
+}
+
+// Returns last paused day of pause: day before resume, or today if pause is ongoing.
+func pauseEnd(pause entity.HabitPause, today time.Time) time.Time {
+	if pause.ResumedAt == nil {
+		return today
+	}
+	return CalendarDay(*pause.ResumedAt, time.UTC).AddDate(0, 0, -1)
+}
+
+// Marks paused days up to today as skipped unless they're marked already,
+// so gaps within pauses don't break streaks.
+func markPausedDays(marks map[string]entity.CheckStatus, pauses []entity.HabitPause, today time.Time) {
+	for _, pause := range pauses {
+		end := pauseEnd(pause, today)
+		for day := CalendarDay(pause.PausedAt, time.UTC); !day.After(end); day = day.AddDate(0, 0, 1) {
+			key := day.Format(time.DateOnly)
+			if _, ok := marks[key]; !ok {
+				marks[key] = entity.CheckStatusSkipped
+			}
+		}
+	}
+}
+
+// Counts paused days within period from..to, both included.
+func pausedDays(pauses []entity.HabitPause, from, to, today time.Time) int {
+	count := 0
+	for _, pause := range pauses {
+		start := CalendarDay(pause.PausedAt, time.UTC)
+		if start.Before(from) {
+			start = from
+		}
+		end := pauseEnd(pause, today)
+		if end.After(to) {
+			end = to
+		}
+		if !end.Before(start) {
+			count += int(end.Sub(start).Hours()/24) + 1
+		}
+	}
+	return count
+}
+
+// Returns calendar day since which habit is tracked: its start date, or creation day in loc if start isn't set.
+func trackedSince(habit *entity.Habit, loc *time.Location) time.Time {
+	if !habit.StartDate.IsZero() {
+		return CalendarDay(habit.StartDate, time.UTC)
+	}
+	return CalendarDay(habit.CreatedAt, loc)
+}
 
-	// TO-DO: get back after making streak counting
-	return nil, nil
+// Returns share of days since habit start that were checked, capped by 1.
+// Habit started today counts as one day old, so it's either 0 or 1 depending on today's check.
+func completionRate(total int, start, today time.Time) float64 {
+	days := int(today.Sub(start).Hours() / 24)
+	if days < 1 {
+		days = 1
+	}
+	return min(float64(total)/float64(days), 1)
+}
+
+// Counts current and max streaks over marks keyed by date (time.DateOnly).
+// Streak is a run of consecutive marked days, where only checked ones are counted,
+// so skipped day bridges checks around it. Current streak survives if today (calendar day) isn't marked yet.
+func countStreaks(marks map[string]entity.CheckStatus, today time.Time) (current, longest int) {
+	cursor := today
+	if _, ok := marks[cursor.Format(time.DateOnly)]; !ok {
+		cursor = cursor.AddDate(0, 0, -1)
+	}
+	for {
+		status, ok := marks[cursor.Format(time.DateOnly)]
+		if !ok {
+			break
+		}
+		if status == entity.CheckStatusChecked {
+			current++
+		}
+		cursor = cursor.AddDate(0, 0, -1)
+	}
+	for key := range marks {
+		date, err := time.Parse(time.DateOnly, key)
+		if err != nil {
+			continue
+		}
+		// Counting only from first day of run
+		if _, ok := marks[date.AddDate(0, 0, -1).Format(time.DateOnly)]; ok {
+			continue
+		}
+		run := 0
+		for {
+			status, ok := marks[date.Format(time.DateOnly)]
+			if !ok {
+				break
+			}
+			if status == entity.CheckStatusChecked {
+				run++
+			}
+			date = date.AddDate(0, 0, 1)
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	return current, longest
 }
@@ -2,16 +2,20 @@ package service_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
+	"github.com/limbo/discipline/internal/repository"
 	"github.com/limbo/discipline/internal/repository/mocks"
 	"github.com/limbo/discipline/internal/service"
 	"github.com/limbo/discipline/pkg/entity"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCheckHabit(t *testing.T) {
@@ -46,7 +50,7 @@ func TestCheckHabit(t *testing.T) {
 					Description: "test_desc",
 				}, nil)
 				checksRepo.EXPECT().Exists(gomock.Any(), habitID, checkDate).Return(false, nil)
-				checksRepo.EXPECT().Create(gomock.Any(), habitID, checkDate).Return(nil)
+				checksRepo.EXPECT().Create(gomock.Any(), habitID, checkDate, "felt great").Return(nil)
 			},
 		},
 		{
@@ -79,6 +83,22 @@ func TestCheckHabit(t *testing.T) {
 				}, nil)
 			},
 		},
+		{
+			Desc:      "error check before start date",
+			Error:     errorvalues.ErrCheckDateNotAllowed,
+			HabitID:   habitID,
+			UserID:    userID,
+			CheckDate: checkDate.AddDate(0, 0, -3),
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{
+					ID:          habitID,
+					UserID:      userID,
+					Title:       "test_habit",
+					Description: "test_desc",
+					StartDate:   service.CalendarDay(checkDate, time.UTC).AddDate(0, 0, -1),
+				}, nil)
+			},
+		},
 		{
 			Desc:      "error creating existed check",
 			Error:     errorvalues.ErrCheckExist,
@@ -110,8 +130,145 @@ func TestCheckHabit(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.Desc, func(t *testing.T) {
 			tc.MockPrepFunc()
-			err := serv.CheckHabit(ctx, tc.HabitID, tc.UserID, tc.CheckDate)
+			err := serv.CheckHabit(ctx, tc.HabitID, tc.UserID, tc.CheckDate, "felt great")
+			assert.ErrorIs(t, err, tc.Error)
+		})
+	}
+}
+
+func TestCheckMany(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	userID := uuid.New()
+	ownID, otherID, unknownID := uuid.New(), uuid.New(), uuid.New()
+	ctx := context.Background()
+	today := service.CalendarDay(time.Now(), time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	habits := map[uuid.UUID]*entity.Habit{
+		ownID:   {ID: ownID, UserID: userID},
+		otherID: {ID: otherID, UserID: uuid.New()},
+	}
+	t.Run("mixed items", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByIDs(gomock.Any(), []uuid.UUID{ownID, otherID, unknownID}).Return(habits, nil)
+		checksRepo.EXPECT().Exists(gomock.Any(), ownID, today).Return(false, nil)
+		checksRepo.EXPECT().Create(gomock.Any(), ownID, today, "").Return(nil)
+		checksRepo.EXPECT().Exists(gomock.Any(), ownID, yesterday).Return(true, nil)
+		failures, err := serv.CheckMany(ctx, userID, []service.CheckRequest{
+			{HabitID: ownID, Date: today},
+			{HabitID: otherID, Date: today},
+			{HabitID: ownID, Date: today.AddDate(0, 0, 3)},
+			{HabitID: unknownID, Date: today},
+			{HabitID: ownID, Date: yesterday},
+		})
+		require.NoError(t, err)
+		require.Len(t, failures, 4)
+		assert.Equal(t, 1, failures[0].Index)
+		assert.ErrorIs(t, failures[0].Err, errorvalues.ErrWrongOwner)
+		assert.Equal(t, 2, failures[1].Index)
+		assert.ErrorIs(t, failures[1].Err, errorvalues.ErrCheckDateNotAllowed)
+		assert.Equal(t, 3, failures[2].Index)
+		assert.ErrorIs(t, failures[2].Err, errorvalues.ErrHabitNotFound)
+		assert.Equal(t, 4, failures[3].Index)
+		assert.ErrorIs(t, failures[3].Err, errorvalues.ErrCheckExist)
+	})
+	t.Run("nothing valid", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByIDs(gomock.Any(), []uuid.UUID{otherID}).Return(habits, nil)
+		failures, err := serv.CheckMany(ctx, userID, []service.CheckRequest{{HabitID: otherID, Date: today}})
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.ErrorIs(t, failures[0].Err, errorvalues.ErrWrongOwner)
+	})
+	t.Run("repository error", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByIDs(gomock.Any(), []uuid.UUID{ownID}).Return(nil, errors.New("db down"))
+		_, err := serv.CheckMany(ctx, userID, []service.CheckRequest{{HabitID: ownID, Date: today}})
+		assert.Error(t, err)
+	})
+}
+
+func TestCanCheck(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	habit := &entity.Habit{ID: habitID, UserID: userID, StartDate: today.AddDate(0, 0, -7)}
+	testCases := []struct {
+		Desc         string
+		Date         time.Time
+		Allowed      bool
+		Reason       string
+		Error        error
+		MockPrepFunc func()
+	}{
+		{
+			Desc:    "allowed",
+			Date:    today,
+			Allowed: true,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+				checksRepo.EXPECT().Exists(gomock.Any(), habitID, today).Return(false, nil)
+			},
+		},
+		{
+			Desc:   "already checked",
+			Date:   today,
+			Reason: service.CheckRejectedExists,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+				checksRepo.EXPECT().Exists(gomock.Any(), habitID, today).Return(true, nil)
+			},
+		},
+		{
+			Desc:   "future date",
+			Date:   today.AddDate(0, 0, 1),
+			Reason: service.CheckRejectedDateNotAllowed,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+			},
+		},
+		{
+			Desc:   "before habit start",
+			Date:   today.AddDate(0, 0, -8),
+			Reason: service.CheckRejectedDateNotAllowed,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+			},
+		},
+		{
+			Desc:  "error wrong owner",
+			Date:  today,
+			Error: errorvalues.ErrWrongOwner,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: uuid.New()}, nil)
+			},
+		},
+		{
+			Desc:  "error habit not found",
+			Date:  today,
+			Error: errorvalues.ErrHabitNotFound,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(nil, errorvalues.ErrHabitNotFound)
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			// Nothing is written whatever the outcome: no Create expectations are set
+			allowed, reason, err := serv.CanCheck(ctx, habitID, userID, tc.Date)
 			assert.ErrorIs(t, err, tc.Error)
+			assert.Equal(t, tc.Allowed, allowed)
+			assert.Equal(t, tc.Reason, reason)
 		})
 	}
 }
@@ -258,7 +415,7 @@ func TestGetHabitChecks(t *testing.T) {
 					Description: "test_desc",
 				}, nil)
 				checksRepo.EXPECT().
-					GetByHabitAndDateRange(gomock.Any(), habitID, from, now).
+					GetByHabitAndDateRange(gomock.Any(), habitID, from, now, entity.CheckOrderDesc, entity.CheckStatus("")).
 					Return(returnedChecks, nil)
 			},
 		},
@@ -306,9 +463,929 @@ func TestGetHabitChecks(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.Desc, func(t *testing.T) {
 			tc.MockPrepFunc()
-			result, err := serv.GetHabitChecks(ctx, tc.HabitID, tc.UserID, tc.DateRange.From, tc.DateRange.To)
+			result, err := serv.GetHabitChecks(ctx, tc.HabitID, tc.UserID, tc.DateRange.From, tc.DateRange.To, entity.CheckOrderDesc, "")
+			assert.ErrorIs(t, err, tc.Error)
+			assert.Equal(t, tc.Result, result)
+		})
+	}
+	t.Run("filtered by status", func(t *testing.T) {
+		skips := []entity.HabitCheck{{ID: 6, HabitID: habitID, CheckDate: now, Status: entity.CheckStatusSkipped}}
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().
+			GetByHabitAndDateRange(gomock.Any(), habitID, from, now, entity.CheckOrderAsc, entity.CheckStatusSkipped).
+			Return(skips, nil)
+		result, err := serv.GetHabitChecks(ctx, habitID, userID, from, now, entity.CheckOrderAsc, entity.CheckStatusSkipped)
+		assert.NoError(t, err)
+		assert.Equal(t, skips, result)
+	})
+	t.Run("filtered by status of other user's habit", func(t *testing.T) {
+		// Filter doesn't let anyone but owner see habit's marks
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: uuid.New()}, nil)
+		_, err := serv.GetHabitChecks(ctx, habitID, userID, from, now, entity.CheckOrderAsc, entity.CheckStatusChecked)
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+	})
+	t.Run("unknown status", func(t *testing.T) {
+		_, err := serv.GetHabitChecks(ctx, habitID, userID, from, now, entity.CheckOrderAsc, "multiple")
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
+	})
+}
+
+func TestSkipHabit(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	date := time.Now()
+	habit := &entity.Habit{
+		ID:     habitID,
+		UserID: userID,
+		Title:  "test_habit",
+	}
+	testCases := []struct {
+		Desc         string
+		Error        error
+		Date         time.Time
+		MockPrepFunc func()
+	}{
+		{
+			Desc:  "success",
+			Error: nil,
+			Date:  date,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+				checksRepo.EXPECT().Exists(gomock.Any(), habitID, date).Return(false, nil)
+				checksRepo.EXPECT().CreateSkip(gomock.Any(), habitID, date).Return(nil)
+			},
+		},
+		{
+			Desc:  "error future date",
+			Error: errorvalues.ErrCheckDateNotAllowed,
+			Date:  date.Add(time.Hour * 72),
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+			},
+		},
+		{
+			Desc:  "error date already marked",
+			Error: errorvalues.ErrCheckExist,
+			Date:  date,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+				checksRepo.EXPECT().Exists(gomock.Any(), habitID, date).Return(true, nil)
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			err := serv.SkipHabit(ctx, habitID, userID, tc.Date)
 			assert.ErrorIs(t, err, tc.Error)
+		})
+	}
+}
+
+func TestUpsertCheck(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	date := time.Now()
+	note := "felt even better"
+	habit := &entity.Habit{
+		ID:     habitID,
+		UserID: userID,
+		Title:  "test_habit",
+	}
+	testCases := []struct {
+		Desc         string
+		Created      bool
+		Error        error
+		MockPrepFunc func()
+	}{
+		{
+			Desc:    "inserted",
+			Created: true,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+				checksRepo.EXPECT().Upsert(gomock.Any(), habitID, date, note).Return(true, nil)
+			},
+		},
+		{
+			Desc:    "note updated",
+			Created: false,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+				checksRepo.EXPECT().Upsert(gomock.Any(), habitID, date, note).Return(false, nil)
+			},
+		},
+		{
+			Desc:  "error habit not found",
+			Error: errorvalues.ErrHabitNotFound,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(nil, errorvalues.ErrHabitNotFound)
+			},
+		},
+		{
+			Desc:  "error habit purged before upsert",
+			Error: errorvalues.ErrHabitNotFound,
+			MockPrepFunc: func() {
+				habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+				checksRepo.EXPECT().Upsert(gomock.Any(), habitID, date, note).Return(false, errorvalues.ErrHabitNotFound)
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			tc.MockPrepFunc()
+			created, err := serv.UpsertCheck(ctx, habitID, userID, date, note)
+			if tc.Error != nil {
+				// Sentinel isn't wrapped, so it's reported as is
+				assert.Equal(t, tc.Error, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Created, created)
+		})
+	}
+}
+
+func TestGetHabitStats(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) time.Time {
+		return today.AddDate(0, 0, -n)
+	}
+	day := func(n int) string {
+		return daysAgo(n).Format(time.DateOnly)
+	}
+	resumed := daysAgo(2)
+	testCases := []struct {
+		Desc   string
+		Checks map[string]entity.CheckStatus
+		Pauses []entity.HabitPause
+		Result *entity.HabitStats
+	}{
+		{
+			Desc: "skip bridges two check runs",
+			Checks: map[string]entity.CheckStatus{
+				day(4): entity.CheckStatusChecked,
+				day(3): entity.CheckStatusChecked,
+				day(2): entity.CheckStatusSkipped,
+				day(1): entity.CheckStatusChecked,
+				day(0): entity.CheckStatusChecked,
+			},
+			Result: &entity.HabitStats{
+				ID:             habitID,
+				TotalChecks:    4,
+				CurrentStreak:  4,
+				MaxStreak:      4,
+				LastCheck:      daysAgo(0),
+				CompletionRate: 0.5,
+			},
+		},
+		{
+			Desc: "gap breaks streak",
+			Checks: map[string]entity.CheckStatus{
+				day(6): entity.CheckStatusChecked,
+				day(5): entity.CheckStatusSkipped,
+				day(4): entity.CheckStatusChecked,
+				day(3): entity.CheckStatusChecked,
+				day(1): entity.CheckStatusChecked,
+			},
+			Result: &entity.HabitStats{
+				ID:             habitID,
+				TotalChecks:    4,
+				CurrentStreak:  1,
+				MaxStreak:      3,
+				LastCheck:      daysAgo(1),
+				CompletionRate: 0.5,
+			},
+		},
+		{
+			Desc: "pause doesn't break streak",
+			Checks: map[string]entity.CheckStatus{
+				day(6): entity.CheckStatusChecked,
+				day(5): entity.CheckStatusChecked,
+				day(2): entity.CheckStatusChecked,
+				day(1): entity.CheckStatusChecked,
+				day(0): entity.CheckStatusChecked,
+			},
+			Pauses: []entity.HabitPause{{PausedAt: daysAgo(4), ResumedAt: &resumed}},
+			Result: &entity.HabitStats{
+				ID:             habitID,
+				TotalChecks:    5,
+				CurrentStreak:  5,
+				MaxStreak:      5,
+				LastCheck:      daysAgo(0),
+				CompletionRate: 5.0 / 6,
+			},
+		},
+		{
+			Desc: "ongoing pause keeps current streak",
+			Checks: map[string]entity.CheckStatus{
+				day(3): entity.CheckStatusChecked,
+				day(2): entity.CheckStatusChecked,
+			},
+			Pauses: []entity.HabitPause{{PausedAt: daysAgo(1)}},
+			Result: &entity.HabitStats{
+				ID:             habitID,
+				TotalChecks:    2,
+				CurrentStreak:  2,
+				MaxStreak:      2,
+				LastCheck:      daysAgo(2),
+				CompletionRate: 2.0 / 7,
+				Paused:         true,
+			},
+		},
+		{
+			Desc: "only skips don't extend streak",
+			Checks: map[string]entity.CheckStatus{
+				day(1): entity.CheckStatusSkipped,
+				day(0): entity.CheckStatusSkipped,
+			},
+			Result: &entity.HabitStats{
+				ID: habitID,
+			},
+		},
+	}
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{
+				ID:        habitID,
+				UserID:    userID,
+				CreatedAt: daysAgo(8),
+			}, nil)
+			checksRepo.EXPECT().GetCheckedDates(gomock.Any(), habitID, time.Time{}, gomock.Any()).Return(tc.Checks, nil)
+			checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{habitID}).
+				Return(map[uuid.UUID][]entity.HabitPause{habitID: tc.Pauses}, nil)
+			result, err := serv.GetHabitStats(ctx, habitID, userID)
+			assert.NoError(t, err)
 			assert.Equal(t, tc.Result, result)
 		})
 	}
 }
+
+// Runs fn right away, repositories are mocked, so there is nothing to commit
+type instantTx struct{}
+
+func (instantTx) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	return fn(nil)
+}
+
+func TestMaterializedStatsFollowChecks(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	serv.SetTxRunner(instantTx{})
+	// Counts stats from the same marks without storing them
+	direct := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) time.Time {
+		return today.AddDate(0, 0, -n)
+	}
+	habit := &entity.Habit{ID: habitID, UserID: userID, CreatedAt: daysAgo(9)}
+
+	marks := map[string]entity.CheckStatus{}
+	var stored *repository.StoredStats
+	counted := 0
+	habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil).AnyTimes()
+	checksRepo.EXPECT().WithTx(gomock.Any()).Return(checksRepo).AnyTimes()
+	checksRepo.EXPECT().LockHabit(gomock.Any(), habitID).Return(nil).AnyTimes()
+	checksRepo.EXPECT().Exists(gomock.Any(), habitID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, date time.Time) (bool, error) {
+			_, ok := marks[date.Format(time.DateOnly)]
+			return ok, nil
+		}).AnyTimes()
+	checksRepo.EXPECT().Create(gomock.Any(), habitID, gomock.Any(), "").DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, date time.Time, _ string) error {
+			marks[date.Format(time.DateOnly)] = entity.CheckStatusChecked
+			return nil
+		}).AnyTimes()
+	checksRepo.EXPECT().Delete(gomock.Any(), habitID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, date time.Time) error {
+			delete(marks, date.Format(time.DateOnly))
+			return nil
+		}).AnyTimes()
+	checksRepo.EXPECT().GetCheckedDates(gomock.Any(), habitID, time.Time{}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, _, _ time.Time) (map[string]entity.CheckStatus, error) {
+			counted++
+			result := make(map[string]entity.CheckStatus, len(marks))
+			for date, status := range marks {
+				result[date] = status
+			}
+			return result, nil
+		}).AnyTimes()
+	checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{habitID}).Return(nil, nil).AnyTimes()
+	checksRepo.EXPECT().SaveStats(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, stats *repository.StoredStats) error {
+			stored = stats
+			return nil
+		}).AnyTimes()
+	checksRepo.EXPECT().GetStats(gomock.Any(), habitID).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID) (*repository.StoredStats, error) {
+			return stored, nil
+		}).AnyTimes()
+
+	ctx := context.Background()
+	t.Run("never saved stats are counted and stored", func(t *testing.T) {
+		result, err := serv.GetHabitStats(ctx, habitID, userID)
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		assert.Equal(t, &entity.HabitStats{ID: habitID}, result)
+		assert.Equal(t, today, stored.AsOf)
+		assert.Equal(t, daysAgo(9), stored.Since)
+	})
+	steps := []struct {
+		Desc  string
+		Check bool
+		Day   int
+	}{
+		{Desc: "check 2 days ago", Check: true, Day: 2},
+		{Desc: "check yesterday", Check: true, Day: 1},
+		{Desc: "check today", Check: true, Day: 0},
+		{Desc: "uncheck yesterday", Check: false, Day: 1},
+		{Desc: "check yesterday again", Check: true, Day: 1},
+		{Desc: "uncheck today", Check: false, Day: 0},
+	}
+	for _, step := range steps {
+		t.Run(step.Desc, func(t *testing.T) {
+			var err error
+			if step.Check {
+				err = serv.CheckHabit(ctx, habitID, userID, daysAgo(step.Day), "")
+			} else {
+				err = serv.UncheckHabit(ctx, habitID, userID, daysAgo(step.Day))
+			}
+			require.NoError(t, err)
+			expected, err := direct.GetHabitStats(ctx, habitID, userID)
+			require.NoError(t, err)
+			before := counted
+			result, err := serv.GetHabitStats(ctx, habitID, userID)
+			require.NoError(t, err)
+			assert.Equal(t, expected, result)
+			assert.Equal(t, before, counted, "fresh stats must be read, not counted")
+		})
+	}
+	t.Run("failed write keeps stats", func(t *testing.T) {
+		saved := stored
+		err := serv.CheckHabit(ctx, habitID, userID, daysAgo(2), "")
+		assert.ErrorIs(t, err, errorvalues.ErrCheckExist)
+		assert.Same(t, saved, stored)
+	})
+	t.Run("outdated stats are recounted", func(t *testing.T) {
+		stored.AsOf = daysAgo(1)
+		stored.Stats.TotalChecks = 100
+		result, err := serv.GetHabitStats(ctx, habitID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, &entity.HabitStats{
+			ID:             habitID,
+			TotalChecks:    2,
+			CurrentStreak:  2,
+			MaxStreak:      2,
+			LastCheck:      daysAgo(1),
+			CompletionRate: 2.0 / 9,
+		}, result)
+		assert.Equal(t, today, stored.AsOf)
+	})
+}
+
+func TestChecksPerDay(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	userID := uuid.New()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := today.Format(time.DateOnly)
+	single := &entity.Habit{ID: uuid.New(), UserID: userID, CreatedAt: today.AddDate(0, 0, -3)}
+	multiple := &entity.Habit{ID: uuid.New(), UserID: userID, CreatedAt: today.AddDate(0, 0, -3), AllowMultiplePerDay: true}
+	ctx := context.Background()
+	t.Run("single per day", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), single.ID).Return(single, nil).AnyTimes()
+		t.Run("first check created", func(t *testing.T) {
+			checksRepo.EXPECT().Exists(gomock.Any(), single.ID, today).Return(false, nil)
+			checksRepo.EXPECT().Create(gomock.Any(), single.ID, today, "").Return(nil)
+			assert.NoError(t, serv.CheckHabit(ctx, single.ID, userID, today, ""))
+		})
+		t.Run("second check rejected", func(t *testing.T) {
+			checksRepo.EXPECT().Exists(gomock.Any(), single.ID, today).Return(true, nil)
+			assert.ErrorIs(t, serv.CheckHabit(ctx, single.ID, userID, today, ""), errorvalues.ErrCheckExist)
+		})
+		t.Run("uncheck deletes day", func(t *testing.T) {
+			checksRepo.EXPECT().Exists(gomock.Any(), single.ID, today).Return(true, nil)
+			checksRepo.EXPECT().Delete(gomock.Any(), single.ID, today).Return(nil)
+			assert.NoError(t, serv.UncheckHabit(ctx, single.ID, userID, today))
+		})
+		t.Run("stats count days", func(t *testing.T) {
+			checksRepo.EXPECT().GetCheckedDates(gomock.Any(), single.ID, time.Time{}, today).
+				Return(map[string]entity.CheckStatus{day: entity.CheckStatusChecked}, nil)
+			checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{single.ID}).Return(nil, nil)
+			stats, err := serv.GetHabitStats(ctx, single.ID, userID)
+			require.NoError(t, err)
+			assert.Equal(t, 1, stats.TotalChecks)
+		})
+	})
+	t.Run("multiple per day", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), multiple.ID).Return(multiple, nil).AnyTimes()
+		t.Run("check added to checked day", func(t *testing.T) {
+			checksRepo.EXPECT().GetCheckedDates(gomock.Any(), multiple.ID, today, today).
+				Return(map[string]entity.CheckStatus{day: entity.CheckStatusChecked}, nil)
+			checksRepo.EXPECT().CreateRepeated(gomock.Any(), multiple.ID, today, "glass of water").Return(nil)
+			assert.NoError(t, serv.CheckHabit(ctx, multiple.ID, userID, today, "glass of water"))
+		})
+		t.Run("skipped day rejected", func(t *testing.T) {
+			checksRepo.EXPECT().GetCheckedDates(gomock.Any(), multiple.ID, today, today).
+				Return(map[string]entity.CheckStatus{day: entity.CheckStatusSkipped}, nil)
+			assert.ErrorIs(t, serv.CheckHabit(ctx, multiple.ID, userID, today, ""), errorvalues.ErrCheckExist)
+		})
+		t.Run("upsert keeps count", func(t *testing.T) {
+			checksRepo.EXPECT().GetCheckedDates(gomock.Any(), multiple.ID, today, today).
+				Return(map[string]entity.CheckStatus{day: entity.CheckStatusChecked}, nil)
+			checksRepo.EXPECT().UpdateNote(gomock.Any(), multiple.ID, today, "new note").Return(nil)
+			created, err := serv.UpsertCheck(ctx, multiple.ID, userID, today, "new note")
+			assert.NoError(t, err)
+			assert.False(t, created)
+		})
+		t.Run("uncheck deletes latest check", func(t *testing.T) {
+			checksRepo.EXPECT().Exists(gomock.Any(), multiple.ID, today).Return(true, nil)
+			checksRepo.EXPECT().DeleteLatest(gomock.Any(), multiple.ID, today).Return(nil)
+			assert.NoError(t, serv.UncheckHabit(ctx, multiple.ID, userID, today))
+		})
+		t.Run("stats count checks", func(t *testing.T) {
+			yesterday := today.AddDate(0, 0, -1)
+			checksRepo.EXPECT().GetCheckedDates(gomock.Any(), multiple.ID, time.Time{}, today).
+				Return(map[string]entity.CheckStatus{
+					yesterday.Format(time.DateOnly): entity.CheckStatusChecked,
+					day:                             entity.CheckStatusChecked,
+				}, nil)
+			checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{multiple.ID}).Return(nil, nil)
+			checksRepo.EXPECT().CountByHabitAndDateRange(gomock.Any(), multiple.ID, time.Time{}, today).Return(5, nil)
+			stats, err := serv.GetHabitStats(ctx, multiple.ID, userID)
+			require.NoError(t, err)
+			assert.Equal(t, &entity.HabitStats{
+				ID:            multiple.ID,
+				TotalChecks:   5,
+				CurrentStreak: 2,
+				MaxStreak:     2,
+				LastCheck:     today,
+				// Days are what's completed, not checks
+				CompletionRate: 2.0 / 3,
+			}, stats)
+		})
+	})
+}
+
+func TestGetHabitStatsCompletionRate(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	checked := func(days ...int) map[string]entity.CheckStatus {
+		marks := make(map[string]entity.CheckStatus, len(days))
+		for _, n := range days {
+			marks[today.AddDate(0, 0, -n).Format(time.DateOnly)] = entity.CheckStatusChecked
+		}
+		return marks
+	}
+	testCases := []struct {
+		Desc      string
+		CreatedAt time.Time
+		StartDate time.Time
+		Checks    map[string]entity.CheckStatus
+		Rate      float64
+	}{
+		{
+			Desc:      "created 10 days ago with 5 checks",
+			CreatedAt: today.AddDate(0, 0, -10).Add(15 * time.Hour),
+			Checks:    checked(9, 7, 5, 3, 1),
+			Rate:      0.5,
+		},
+		{
+			Desc:      "started 10 days before creation with 5 checks",
+			CreatedAt: now,
+			StartDate: today.AddDate(0, 0, -10),
+			Checks:    checked(9, 7, 5, 3, 1),
+			Rate:      0.5,
+		},
+		{
+			Desc:      "start date prevails over creation time",
+			CreatedAt: today.AddDate(0, 0, -10),
+			StartDate: today.AddDate(0, 0, -4),
+			Checks:    checked(3, 1),
+			Rate:      0.5,
+		},
+		{
+			Desc:      "created today and checked",
+			CreatedAt: now,
+			Checks:    checked(0),
+			Rate:      1,
+		},
+		{
+			Desc:      "created today and not checked",
+			CreatedAt: now,
+			Rate:      0,
+		},
+	}
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{
+				ID:        habitID,
+				UserID:    userID,
+				CreatedAt: tc.CreatedAt,
+				StartDate: tc.StartDate,
+			}, nil)
+			checksRepo.EXPECT().GetCheckedDates(gomock.Any(), habitID, time.Time{}, gomock.Any()).Return(tc.Checks, nil)
+			checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{habitID}).Return(nil, nil)
+			result, err := serv.GetHabitStats(ctx, habitID, userID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Rate, result.CompletionRate)
+		})
+	}
+}
+
+func TestGetUserSummary(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	userID := uuid.New()
+	first, second := uuid.New(), uuid.New()
+	today := service.CalendarDay(time.Now(), time.UTC)
+	day := func(n int) string {
+		return today.AddDate(0, 0, -n).Format(time.DateOnly)
+	}
+	ctx := context.Background()
+	t.Run("longest streak among habits", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByUserID(gomock.Any(), userID, entity.HabitSortCreatedAt, gomock.Any(), 0).Return([]*entity.Habit{
+			{ID: first, UserID: userID},
+			{ID: second, UserID: userID},
+		}, nil)
+		checksRepo.EXPECT().GetCheckedDatesByHabits(gomock.Any(), []uuid.UUID{first, second}, time.Time{}, today).
+			Return(map[uuid.UUID]map[string]entity.CheckStatus{
+				// Two runs of 2
+				first: {
+					day(5): entity.CheckStatusChecked,
+					day(4): entity.CheckStatusChecked,
+					day(1): entity.CheckStatusChecked,
+					day(0): entity.CheckStatusChecked,
+				},
+				// Run of 3 bridged by skip
+				second: {
+					day(9): entity.CheckStatusChecked,
+					day(8): entity.CheckStatusSkipped,
+					day(7): entity.CheckStatusChecked,
+					day(6): entity.CheckStatusChecked,
+				},
+			}, nil)
+		checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{first, second}).Return(nil, nil)
+		summary, err := serv.GetUserSummary(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.UserSummary{Habits: 2, TotalChecks: 7, LongestStreak: 3}, summary)
+	})
+	t.Run("several checks a day", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByUserID(gomock.Any(), userID, entity.HabitSortCreatedAt, gomock.Any(), 0).Return([]*entity.Habit{
+			{ID: first, UserID: userID, AllowMultiplePerDay: true},
+			{ID: second, UserID: userID},
+		}, nil)
+		checksRepo.EXPECT().GetCheckedDatesByHabits(gomock.Any(), []uuid.UUID{first, second}, time.Time{}, today).
+			Return(map[uuid.UUID]map[string]entity.CheckStatus{
+				first: {
+					day(1): entity.CheckStatusChecked,
+					day(0): entity.CheckStatusChecked,
+				},
+				second: {
+					day(0): entity.CheckStatusChecked,
+				},
+			}, nil)
+		checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{first, second}).Return(nil, nil)
+		// Two checks each day
+		checksRepo.EXPECT().CountByHabitAndDateRange(gomock.Any(), first, time.Time{}, today).Return(4, nil)
+		summary, err := serv.GetUserSummary(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.UserSummary{Habits: 2, TotalChecks: 5, LongestStreak: 2}, summary)
+	})
+	t.Run("no habits", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByUserID(gomock.Any(), userID, entity.HabitSortCreatedAt, gomock.Any(), 0).Return([]*entity.Habit{}, nil)
+		summary, err := serv.GetUserSummary(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.UserSummary{}, summary)
+	})
+}
+
+func TestCalendarDay(t *testing.T) {
+	t.Parallel()
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	require.NoError(t, err)
+	baker, err := time.LoadLocation("Etc/GMT+12")
+	require.NoError(t, err)
+	// 2025-01-01 11:00 UTC is already 2025-01-02 in UTC+14 and still 2024-12-31 in UTC-12
+	instant := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), service.CalendarDay(instant, time.UTC))
+	assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), service.CalendarDay(instant, kiritimati))
+	assert.Equal(t, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), service.CalendarDay(instant, baker))
+}
+
+func TestCheckHabitInUserTimezone(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+	usersRepo := mocks.NewMockUsersRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksServiceWithUsers(habitsRepo, checksRepo, usersRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	habit := &entity.Habit{ID: habitID, UserID: userID, Title: "test_habit"}
+	ctx := context.Background()
+	for _, tz := range []string{"Pacific/Kiritimati", "Etc/GMT+12"} {
+		loc, err := time.LoadLocation(tz)
+		require.NoError(t, err)
+		userToday := service.CalendarDay(time.Now(), loc)
+		t.Run("today accepted in "+tz, func(t *testing.T) {
+			habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+			usersRepo.EXPECT().FindByID(gomock.Any(), userID).Return(&entity.User{ID: userID, Timezone: tz}, nil)
+			checksRepo.EXPECT().Exists(gomock.Any(), habitID, userToday).Return(false, nil)
+			checksRepo.EXPECT().Create(gomock.Any(), habitID, userToday, "").Return(nil)
+			err := serv.CheckHabit(ctx, habitID, userID, userToday, "")
+			assert.NoError(t, err)
+		})
+		t.Run("tomorrow rejected in "+tz, func(t *testing.T) {
+			habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(habit, nil)
+			usersRepo.EXPECT().FindByID(gomock.Any(), userID).Return(&entity.User{ID: userID, Timezone: tz}, nil)
+			err := serv.CheckHabit(ctx, habitID, userID, userToday.AddDate(0, 0, 1), "")
+			assert.ErrorIs(t, err, errorvalues.ErrCheckDateNotAllowed)
+		})
+		t.Run("today resolved in "+tz, func(t *testing.T) {
+			usersRepo.EXPECT().FindByID(gomock.Any(), userID).Return(&entity.User{ID: userID, Timezone: tz}, nil)
+			today, err := serv.Today(ctx, userID)
+			assert.NoError(t, err)
+			assert.Equal(t, userToday, today)
+		})
+	}
+}
+
+func TestGetUserChecksOn(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+	usersRepo := mocks.NewMockUsersRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksServiceWithUsers(habitsRepo, checksRepo, usersRepo)
+	userID := uuid.New()
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	checks := []entity.UserCheck{{
+		HabitCheck: entity.HabitCheck{ID: 1, HabitID: uuid.New(), CheckDate: day, Status: entity.CheckStatusChecked},
+		HabitTitle: "test_habit",
+	}}
+	ctx := context.Background()
+	t.Run("filtered by calendar day", func(t *testing.T) {
+		// Time of day doesn't matter, only date is
+		checksRepo.EXPECT().GetByUserAndDate(gomock.Any(), userID, day).Return(checks, nil)
+		result, err := serv.GetUserChecksOn(ctx, userID, day.Add(15*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, checks, result)
+	})
+	t.Run("zero date is user's today", func(t *testing.T) {
+		tz := "Pacific/Kiritimati"
+		loc, err := time.LoadLocation(tz)
+		require.NoError(t, err)
+		usersRepo.EXPECT().FindByID(gomock.Any(), userID).Return(&entity.User{ID: userID, Timezone: tz}, nil)
+		checksRepo.EXPECT().GetByUserAndDate(gomock.Any(), userID, service.CalendarDay(time.Now(), loc)).Return([]entity.UserCheck{}, nil)
+		result, err := serv.GetUserChecksOn(ctx, userID, time.Time{})
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+	t.Run("repository error", func(t *testing.T) {
+		checksRepo.EXPECT().GetByUserAndDate(gomock.Any(), userID, day).Return(nil, errors.New("db error"))
+		_, err := serv.GetUserChecksOn(ctx, userID, day)
+		assert.Error(t, err)
+	})
+}
+
+func TestCountHabitChecks(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().CountByHabitAndDateRange(gomock.Any(), habitID, from, to).Return(12, nil)
+		count, err := serv.CountHabitChecks(ctx, habitID, userID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, 12, count)
+	})
+	t.Run("error wrong owner", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: uuid.New()}, nil)
+		_, err := serv.CountHabitChecks(ctx, habitID, userID, from, to)
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+	})
+	t.Run("error habit not found", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(nil, errorvalues.ErrHabitNotFound)
+		_, err := serv.CountHabitChecks(ctx, habitID, userID, from, to)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+	})
+}
+
+func TestGetLastCheck(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		last := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().GetLastCheckDate(gomock.Any(), habitID).Return(&last, nil)
+		date, err := serv.GetLastCheck(ctx, habitID, userID)
+		assert.NoError(t, err)
+		require.NotNil(t, date)
+		assert.Equal(t, last, *date)
+	})
+	t.Run("success no checks", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().GetLastCheckDate(gomock.Any(), habitID).Return(nil, nil)
+		date, err := serv.GetLastCheck(ctx, habitID, userID)
+		assert.NoError(t, err)
+		assert.Nil(t, date)
+	})
+	t.Run("error wrong owner", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: uuid.New()}, nil)
+		_, err := serv.GetLastCheck(ctx, habitID, userID)
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+	})
+}
+
+func TestRecentAdherence(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	today := service.CalendarDay(time.Now(), time.UTC)
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().CountByHabitAndDateRange(gomock.Any(), habitID, today.AddDate(0, 0, -6), today).Return(5, nil)
+		checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{habitID}).Return(nil, nil)
+		checked, total, err := serv.RecentAdherence(ctx, habitID, userID, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, checked)
+		assert.Equal(t, 7, total)
+	})
+	t.Run("paused days excluded", func(t *testing.T) {
+		resumed := today.AddDate(0, 0, -4)
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().CountByHabitAndDateRange(gomock.Any(), habitID, today.AddDate(0, 0, -6), today).Return(3, nil)
+		checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{habitID}).Return(map[uuid.UUID][]entity.HabitPause{
+			// Started before window, only 2 days of it fall into window
+			habitID: {{PausedAt: today.AddDate(0, 0, -10), ResumedAt: &resumed}},
+		}, nil)
+		checked, total, err := serv.RecentAdherence(ctx, habitID, userID, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, checked)
+		assert.Equal(t, 5, total)
+	})
+	t.Run("only today", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().CountByHabitAndDateRange(gomock.Any(), habitID, today, today).Return(1, nil)
+		checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{habitID}).Return(nil, nil)
+		checked, total, err := serv.RecentAdherence(ctx, habitID, userID, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, checked)
+		assert.Equal(t, 1, total)
+	})
+	t.Run("error days out of range", func(t *testing.T) {
+		for _, days := range []int{0, -1, 366} {
+			_, _, err := serv.RecentAdherence(ctx, habitID, userID, days)
+			assert.ErrorIs(t, err, errorvalues.ErrValidation)
+		}
+	})
+	t.Run("error wrong owner", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: uuid.New()}, nil)
+		_, _, err := serv.RecentAdherence(ctx, habitID, userID, 30)
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+	})
+}
+
+func TestGetMonthlyChecks(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	ctx := context.Background()
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)
+	t.Run("grouped by month", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().GetCheckedDates(gomock.Any(), habitID, from, to).Return(map[string]entity.CheckStatus{
+			"2024-01-31": entity.CheckStatusChecked,
+			"2024-01-05": entity.CheckStatusChecked,
+			"2024-01-06": entity.CheckStatusSkipped,
+			"2024-02-01": entity.CheckStatusChecked,
+			"2024-02-29": entity.CheckStatusChecked,
+		}, nil)
+		months, err := serv.GetMonthlyChecks(ctx, habitID, userID, 2024)
+		assert.NoError(t, err)
+		assert.Equal(t, map[time.Month][]int{
+			time.January:  {5, 31},
+			time.February: {1, 29},
+		}, months)
+	})
+	t.Run("no checks", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().GetCheckedDates(gomock.Any(), habitID, from, to).Return(map[string]entity.CheckStatus{}, nil)
+		months, err := serv.GetMonthlyChecks(ctx, habitID, userID, 2024)
+		assert.NoError(t, err)
+		assert.Empty(t, months)
+	})
+	t.Run("error wrong owner", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: uuid.New()}, nil)
+		_, err := serv.GetMonthlyChecks(ctx, habitID, userID, 2024)
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+	})
+	t.Run("error habit not found", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(nil, errorvalues.ErrHabitNotFound)
+		_, err := serv.GetMonthlyChecks(ctx, habitID, userID, 2024)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+	})
+}
+
+func TestGetWeekdayDistribution(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	habitsRepo := mocks.NewMockHabitsRepositoryI(ctrl)
+
+	serv := service.NewHabitChecksService(habitsRepo, checksRepo)
+	habitID := uuid.New()
+	userID := uuid.New()
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		counts := [7]int{0, 4, 2, 0, 1, 0, 0}
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID}, nil)
+		checksRepo.EXPECT().CountByWeekday(gomock.Any(), habitID).Return(counts, nil)
+		result, err := serv.GetWeekdayDistribution(ctx, habitID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, counts, result)
+	})
+	t.Run("error wrong owner", func(t *testing.T) {
+		habitsRepo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: uuid.New()}, nil)
+		_, err := serv.GetWeekdayDistribution(ctx, habitID, userID)
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+	})
+}
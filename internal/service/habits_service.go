@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
@@ -11,26 +14,84 @@ import (
 	"github.com/limbo/discipline/pkg/entity"
 )
 
+// Default period, during which deleted habit can be restored
+const DefaultRestoreWindow = 7 * 24 * time.Hour
+
 type HabitsService struct {
 	repo repository.HabitsRepositoryI
+	// Deleted habits older than that can't be restored
+	restoreWindow time.Duration
+	// Stats source for habit details, nil if not set
+	checks HabitChecksServiceI
+	groups GroupMembersResolver
+}
+
+// Resolves members of habit groups, used to authorize group views.
+type GroupMembersResolver interface {
+	// Returns ids of group members. If there is no such group, returns errorvalues.ErrGroupNotFound
+	Members(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// Stub resolver until groups are stored: each user has own group with id equal to user's one
+// and no other members.
+type SoloGroups struct{}
+
+func (SoloGroups) Members(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	return []uuid.UUID{groupID}, nil
 }
 
 func NewHabitsService(habitsRepo repository.HabitsRepositoryI) *HabitsService {
+	return NewHabitsServiceWithRestoreWindow(habitsRepo, DefaultRestoreWindow)
+}
+
+// Creates service allowing to restore habits within window after deletion.
+// Non-positive window is replaced with DefaultRestoreWindow.
+func NewHabitsServiceWithRestoreWindow(habitsRepo repository.HabitsRepositoryI, window time.Duration) *HabitsService {
 	if habitsRepo == nil {
 		log.Fatal("provided nil habitsRepo")
 	}
+	if window <= 0 {
+		window = DefaultRestoreWindow
+	}
 	return &HabitsService{
-		repo: habitsRepo,
+		repo:          habitsRepo,
+		restoreWindow: window,
+		groups:        SoloGroups{},
 	}
 }
 
+// Sets service providing checks stats for GetHabitWithStats. Must be called before service is used.
+func (hs *HabitsService) SetChecksService(checks HabitChecksServiceI) {
+	hs.checks = checks
+}
+
+// Replaces resolver of group members (SoloGroups by default). Must be called before service is used.
+func (hs *HabitsService) SetGroups(groups GroupMembersResolver) {
+	hs.groups = groups
+}
+
 func (hs *HabitsService) CreateHabit(ctx context.Context, uid uuid.UUID, req CreateHabitRequest) (*entity.Habit, error) {
+	if err := validateStruct(req); err != nil {
+		return nil, err
+	}
 	h := entity.Habit{
-		UserID:      uid,
-		Title:       req.Title,
-		Description: req.Description,
+		UserID:              uid,
+		Title:               req.Title,
+		Description:         req.Description,
+		Color:               req.Color,
+		Icon:                req.Icon,
+		StartDate:           parseStartDate(req.StartDate),
+		AllowMultiplePerDay: req.AllowMultiplePerDay,
+		Tags:                req.Tags,
+	}
+	if h.StartDate.IsZero() {
+		today, err := hs.today(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		h.StartDate = today
 	}
-	id, err := hs.repo.Create(ctx, &h)
+	_, err := hs.repo.Create(ctx, &h)
 	if err != nil {
 		switch {
 		case errors.Is(err, errorvalues.ErrOwnerNotFound):
@@ -38,43 +99,242 @@ func (hs *HabitsService) CreateHabit(ctx context.Context, uid uuid.UUID, req Cre
 		case errors.Is(err, errorvalues.ErrUserHasHabit):
 			return nil, errorvalues.ErrUserHasHabit
 		}
-		return nil, errors.New("habits repository error: " + err.Error())
+		return nil, fmt.Errorf("habits repository error: %w", err)
 	}
-	habit, err := hs.repo.GetByID(ctx, id)
-	if err != nil {
-		if errors.Is(err, errorvalues.ErrHabitNotFound) {
-			return nil, err
+	return &h, nil
+}
+
+func (hs *HabitsService) CreateHabits(ctx context.Context, uid uuid.UUID, reqs []CreateHabitRequest) ([]*entity.Habit, []BatchError, error) {
+	validationErrs := make([]error, len(reqs))
+	habits := make([]*entity.Habit, 0, len(reqs))
+	for i, req := range reqs {
+		if validationErrs[i] = validateStruct(req); validationErrs[i] != nil {
+			continue
 		}
-		return nil, errors.New("habits repository error: " + err.Error())
+		habits = append(habits, &entity.Habit{
+			UserID:              uid,
+			Title:               req.Title,
+			Description:         req.Description,
+			Color:               req.Color,
+			Icon:                req.Icon,
+			StartDate:           parseStartDate(req.StartDate),
+			AllowMultiplePerDay: req.AllowMultiplePerDay,
+			Tags:                req.Tags,
+		})
 	}
-	return habit, nil
+	// Today is resolved once and only if some habit needs it
+	var today time.Time
+	for _, habit := range habits {
+		if !habit.StartDate.IsZero() {
+			continue
+		}
+		if today.IsZero() {
+			var err error
+			if today, err = hs.today(ctx, uid); err != nil {
+				return nil, nil, err
+			}
+		}
+		habit.StartDate = today
+	}
+	created := []bool{}
+	if len(habits) != 0 {
+		var err error
+		created, err = hs.repo.CreateMany(ctx, habits)
+		if err != nil {
+			if errors.Is(err, errorvalues.ErrOwnerNotFound) {
+				return nil, nil, errorvalues.ErrUserNotFound
+			}
+			return nil, nil, fmt.Errorf("habits repository error: %w", err)
+		}
+	}
+	result := make([]*entity.Habit, 0, len(habits))
+	var failures []BatchError
+	next := 0
+	for i := range reqs {
+		if validationErrs[i] != nil {
+			failures = append(failures, BatchError{Index: i, Err: validationErrs[i]})
+			continue
+		}
+		if created[next] {
+			result = append(result, habits[next])
+		} else {
+			failures = append(failures, BatchError{Index: i, Err: errorvalues.ErrUserHasHabit})
+		}
+		next++
+	}
+	return result, failures, nil
 }
 
-func (hs *HabitsService) GetUserHabits(ctx context.Context, uid uuid.UUID, pagination PaginationOpts) ([]*entity.Habit, error) {
-	habits, err := hs.repo.GetByUserID(ctx, uid, pagination.Limit, pagination.Offset)
+func (hs *HabitsService) GetUserHabits(ctx context.Context, uid uuid.UUID, sort entity.HabitSort, pagination PaginationOpts) ([]*entity.Habit, error) {
+	if err := validateVar(string(sort), "omitempty,oneof=created_at position"); err != nil {
+		return nil, err
+	}
+	if sort == "" {
+		sort = entity.HabitSortCreatedAt
+	}
+	habits, err := hs.repo.GetByUserID(ctx, uid, sort, pagination.Limit, pagination.Offset)
 	if err != nil {
-		return nil, errors.New("habits repository error: " + err.Error())
+		return nil, fmt.Errorf("habits repository error: %w", err)
 	}
 	return habits, nil
 }
 
+func (hs *HabitsService) GetUserHabitsAfter(ctx context.Context, uid uuid.UUID, cursor *entity.HabitCursor, limit int) ([]*entity.Habit, error) {
+	habits, err := hs.repo.GetByUserIDAfter(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("habits repository error: %w", err)
+	}
+	return habits, nil
+}
+
+func (hs *HabitsService) CountUserHabits(ctx context.Context, uid uuid.UUID) (int, error) {
+	count, err := hs.repo.CountByUserID(ctx, uid)
+	if err != nil {
+		return 0, fmt.Errorf("habits repository error: %w", err)
+	}
+	return count, nil
+}
+
+func (hs *HabitsService) ExportHabits(ctx context.Context, uid uuid.UUID, fn func(habit *entity.Habit) error) error {
+	var fnErr error
+	err := hs.repo.StreamByUserID(ctx, uid, func(habit *entity.Habit) error {
+		fnErr = fn(habit)
+		return fnErr
+	})
+	if err != nil {
+		if fnErr != nil {
+			return fnErr
+		}
+		return fmt.Errorf("habits repository error: %w", err)
+	}
+	return nil
+}
+
+func (hs *HabitsService) GetGroupHabits(ctx context.Context, groupID, userID uuid.UUID, pagination PaginationOpts) ([]*entity.Habit, int, error) {
+	members, err := hs.groups.Members(ctx, groupID)
+	if err != nil {
+		return nil, 0, wrapError(err, "groups error", errorvalues.ErrGroupNotFound)
+	}
+	// Group is hidden from those who aren't in it
+	if !slices.Contains(members, userID) {
+		return nil, 0, errorvalues.ErrGroupNotFound
+	}
+	habits, err := hs.repo.GetByUserIDs(ctx, members, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("habits repository error: %w", err)
+	}
+	total, err := hs.repo.CountByUserIDs(ctx, members)
+	if err != nil {
+		return nil, 0, fmt.Errorf("habits repository error: %w", err)
+	}
+	return habits, total, nil
+}
+
+func (hs *HabitsService) ListTags(ctx context.Context, uid uuid.UUID) ([]string, error) {
+	tags, err := hs.repo.ListTags(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("habits repository error: %w", err)
+	}
+	return tags, nil
+}
+
+func (hs *HabitsService) LastModified(ctx context.Context, uid uuid.UUID) (time.Time, error) {
+	lastModified, err := hs.repo.MaxUpdatedAt(ctx, uid)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("habits repository error: %w", err)
+	}
+	return lastModified, nil
+}
+
 func (hs *HabitsService) DeleteHabit(ctx context.Context, habitID, userID uuid.UUID) error {
 	habit, err := hs.repo.GetByID(ctx, habitID)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrHabitNotFound) {
-			return err
-		}
-		return errors.New("habits repository error: " + err.Error())
+		return wrapError(err, "habits repository error", errorvalues.ErrHabitNotFound)
 	}
 	if habit.UserID != userID {
 		return errorvalues.ErrWrongOwner
 	}
 	err = hs.repo.Delete(ctx, habitID)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrHabitNotFound) {
-			return err
+		return wrapError(err, "habits repository error", errorvalues.ErrHabitNotFound)
+	}
+	return nil
+}
+
+func (hs *HabitsService) RestoreHabit(ctx context.Context, habitID, userID uuid.UUID) error {
+	habit, err := hs.repo.GetDeletedByID(ctx, habitID)
+	if err != nil {
+		return wrapError(err, "habits repository error", errorvalues.ErrHabitNotFound)
+	}
+	if habit.UserID != userID {
+		return errorvalues.ErrWrongOwner
+	}
+	// Habit is waiting for purge already
+	if habit.DeletedAt == nil || time.Since(*habit.DeletedAt) > hs.restoreWindow {
+		return errorvalues.ErrHabitNotFound
+	}
+	err = hs.repo.Restore(ctx, habitID)
+	if err != nil {
+		return wrapError(err, "habits repository error", errorvalues.ErrHabitNotFound, errorvalues.ErrUserHasHabit)
+	}
+	return nil
+}
+
+func (hs *HabitsService) PurgeDeleted(ctx context.Context, olderThan time.Duration) (int64, error) {
+	purged, err := hs.repo.PurgeDeleted(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("habits repository error: %w", err)
+	}
+	return purged, nil
+}
+
+var errMergeIntoItself = errors.Join(errorvalues.ErrValidation, errors.New("habit can't be merged into itself"))
+
+func (hs *HabitsService) MergeHabits(ctx context.Context, sourceID, targetID, userID uuid.UUID) error {
+	if sourceID == targetID {
+		return errMergeIntoItself
+	}
+	// Ownership is checked by repository under lock, so habits can't change owner in between
+	_, err := hs.repo.Merge(ctx, sourceID, targetID, userID)
+	if err != nil {
+		return wrapError(err, "habits repository error", errorvalues.ErrHabitNotFound, errorvalues.ErrWrongOwner)
+	}
+	return nil
+}
+
+var (
+	errEmptyOrder      = errors.Join(errorvalues.ErrValidation, errors.New("no habits to order"))
+	errDuplicatedOrder = errors.Join(errorvalues.ErrValidation, errors.New("habit is listed more than once"))
+)
+
+func (hs *HabitsService) ReorderHabits(ctx context.Context, userID uuid.UUID, orderedIDs []uuid.UUID) error {
+	if len(orderedIDs) == 0 {
+		return errEmptyOrder
+	}
+	seen := make(map[uuid.UUID]struct{}, len(orderedIDs))
+	for _, id := range orderedIDs {
+		if _, ok := seen[id]; ok {
+			return errDuplicatedOrder
+		}
+		seen[id] = struct{}{}
+	}
+	habits, err := hs.repo.GetByIDs(ctx, orderedIDs)
+	if err != nil {
+		return fmt.Errorf("habits repository error: %w", err)
+	}
+	for _, id := range orderedIDs {
+		habit, ok := habits[id]
+		if !ok {
+			return errorvalues.ErrHabitNotFound
+		}
+		if habit.UserID != userID {
+			return errorvalues.ErrWrongOwner
 		}
-		return errors.New("habits repository error: " + err.Error())
+	}
+	// Repository checks ownership again, habit could be deleted in between
+	err = hs.repo.Reorder(ctx, userID, orderedIDs)
+	if err != nil {
+		return wrapError(err, "habits repository error", errorvalues.ErrHabitNotFound)
 	}
 	return nil
 }
@@ -82,13 +342,94 @@ func (hs *HabitsService) DeleteHabit(ctx context.Context, habitID, userID uuid.U
 func (hs *HabitsService) GetHabit(ctx context.Context, habitID, userID uuid.UUID) (*entity.Habit, error) {
 	habit, err := hs.repo.GetByID(ctx, habitID)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrHabitNotFound) {
-			return nil, err
-		}
-		return nil, errors.New("habits repository error: " + err.Error())
+		return nil, wrapError(err, "habits repository error", errorvalues.ErrHabitNotFound)
 	}
 	if habit.UserID != userID {
 		return nil, errorvalues.ErrWrongOwner
 	}
 	return habit, nil
 }
+
+func (hs *HabitsService) GetHabitWithStats(ctx context.Context, habitID, userID uuid.UUID) (*HabitDetail, error) {
+	if hs.checks == nil {
+		return nil, errors.New("checks service is not set")
+	}
+	habit, err := hs.GetHabit(ctx, habitID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Ownership is checked already
+	stats, err := hs.checks.GetStatsForHabit(ctx, habit)
+	if err != nil {
+		return nil, fmt.Errorf("checks service error: %w", err)
+	}
+	return &HabitDetail{Habit: habit, Stats: stats}, nil
+}
+
+func (hs *HabitsService) UpdateHabit(ctx context.Context, habitID, userID uuid.UUID, req UpdateHabitRequest) (*entity.Habit, error) {
+	if err := validateStruct(req); err != nil {
+		return nil, err
+	}
+	habit, err := hs.GetHabit(ctx, habitID, userID)
+	if err != nil {
+		return nil, err
+	}
+	metaChanged := req.Color != nil || req.Icon != nil || req.StartDate != nil || req.Tags != nil
+	switch {
+	case req.Title != nil && req.Description == nil && !metaChanged:
+		err = hs.repo.UpdateTitle(ctx, habitID, *req.Title)
+	case req.Description != nil && req.Title == nil && !metaChanged:
+		err = hs.repo.UpdateDescription(ctx, habitID, *req.Description)
+	case req.Title != nil || req.Description != nil || metaChanged:
+		updated := *habit
+		if req.Title != nil {
+			updated.Title = *req.Title
+		}
+		if req.Description != nil {
+			updated.Description = *req.Description
+		}
+		if req.Color != nil {
+			updated.Color = *req.Color
+		}
+		if req.Icon != nil {
+			updated.Icon = *req.Icon
+		}
+		if req.StartDate != nil {
+			updated.StartDate = parseStartDate(*req.StartDate)
+		}
+		if req.Tags != nil {
+			updated.Tags = *req.Tags
+		}
+		err = hs.repo.Update(ctx, &updated)
+	default:
+		// Nothing to update
+		return habit, nil
+	}
+	if err != nil {
+		return nil, wrapError(err, "habits repository error", errorvalues.ErrHabitNotFound, errorvalues.ErrUserHasHabit)
+	}
+	habit, err = hs.repo.GetByID(ctx, habitID)
+	if err != nil {
+		return nil, wrapError(err, "habits repository error", errorvalues.ErrHabitNotFound)
+	}
+	return habit, nil
+}
+
+// Returns current calendar day of user with uid, default start date of new habits.
+// Falls back to UTC day if checks service isn't set.
+func (hs *HabitsService) today(ctx context.Context, uid uuid.UUID) (time.Time, error) {
+	if hs.checks == nil {
+		return CalendarDay(time.Now(), time.UTC), nil
+	}
+	today, err := hs.checks.Today(ctx, uid)
+	if err != nil {
+		return time.Time{}, wrapError(err, "habit checks service error", errorvalues.ErrUserNotFound)
+	}
+	return today, nil
+}
+
+// Parses validated start date (YYYY-MM-DD), empty one gives zero time.
+func parseStartDate(value string) time.Time {
+	date, _ := time.Parse(time.DateOnly, value)
+	return date
+}
@@ -8,13 +8,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
 	"github.com/limbo/discipline/internal/repository"
+	"github.com/limbo/discipline/internal/repository/mocks"
 	"github.com/limbo/discipline/internal/service"
+	servicemocks "github.com/limbo/discipline/internal/service/mocks"
 	"github.com/limbo/discipline/pkg/entity"
 	"github.com/pressly/goose"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -60,10 +65,27 @@ func (hrmock *habitRepoMock) Create(ctx context.Context, habit *entity.Habit) (u
 	case stateDBError:
 		return uuid.UUID{}, errors.New("db error")
 	default:
+		habit.ID = habitID
+		habit.CreatedAt = testHabit.CreatedAt
+		habit.UpdatedAt = testHabit.UpdatedAt
 		return habitID, nil
 	}
 }
 
+func (hrmock *habitRepoMock) CreateMany(ctx context.Context, habits []*entity.Habit) ([]bool, error) {
+	switch hrmock.state {
+	case stateUserNotFoundError:
+		return nil, errorvalues.ErrOwnerNotFound
+	case stateDBError:
+		return nil, errors.New("db error")
+	default:
+		created := make([]bool, len(habits))
+		for i := range created {
+			created[i] = true
+		}
+		return created, nil
+	}
+}
 func (hrmock *habitRepoMock) GetByID(ctx context.Context, id uuid.UUID) (*entity.Habit, error) {
 	switch hrmock.state {
 	case stateHabitNotFoundError:
@@ -84,7 +106,7 @@ func (hrmock *habitRepoMock) GetByID(ctx context.Context, id uuid.UUID) (*entity
 	}
 }
 
-func (hrmock *habitRepoMock) GetByUserID(ctx context.Context, uid uuid.UUID, limit, offset int) ([]*entity.Habit, error) {
+func (hrmock *habitRepoMock) GetByUserID(ctx context.Context, uid uuid.UUID, sort entity.HabitSort, limit, offset int) ([]*entity.Habit, error) {
 	switch hrmock.state {
 	case stateUserNotFoundError:
 		return []*entity.Habit{}, nil
@@ -96,6 +118,24 @@ func (hrmock *habitRepoMock) GetByUserID(ctx context.Context, uid uuid.UUID, lim
 		}, nil
 	}
 }
+func (hrmock *habitRepoMock) GetByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.Habit, error) {
+	return nil, errors.New("not implemented")
+}
+func (hrmock *habitRepoMock) CountByUserID(ctx context.Context, uid uuid.UUID) (int, error) {
+	return 0, errors.New("not implemented")
+}
+func (hrmock *habitRepoMock) StreamByUserID(ctx context.Context, uid uuid.UUID, fn func(habit *entity.Habit) error) error {
+	return errors.New("not implemented")
+}
+func (hrmock *habitRepoMock) Merge(ctx context.Context, sourceID, targetID, userID uuid.UUID) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+func (hrmock *habitRepoMock) Reorder(ctx context.Context, uid uuid.UUID, orderedIDs []uuid.UUID) error {
+	return errors.New("not implemented")
+}
+func (hrmock *habitRepoMock) GetByUserIDAfter(ctx context.Context, uid uuid.UUID, cursor *entity.HabitCursor, limit int) ([]*entity.Habit, error) {
+	return hrmock.GetByUserID(ctx, uid, entity.HabitSortCreatedAt, limit, 0)
+}
 func (hrmock *habitRepoMock) Update(ctx context.Context, habit *entity.Habit) error {
 	switch hrmock.state {
 	case stateDBError:
@@ -106,6 +146,51 @@ func (hrmock *habitRepoMock) Update(ctx context.Context, habit *entity.Habit) er
 		return nil
 	}
 }
+func (hrmock *habitRepoMock) UpdateTitle(ctx context.Context, id uuid.UUID, title string) error {
+	return hrmock.Update(ctx, &entity.Habit{ID: id, Title: title})
+}
+func (hrmock *habitRepoMock) UpdateDescription(ctx context.Context, id uuid.UUID, description string) error {
+	return hrmock.Update(ctx, &entity.Habit{ID: id, Description: description})
+}
+func (hrmock *habitRepoMock) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entity.Habit, error) {
+	return hrmock.GetByID(ctx, id)
+}
+func (hrmock *habitRepoMock) Restore(ctx context.Context, id uuid.UUID) error {
+	return hrmock.Delete(ctx, id)
+}
+func (hrmock *habitRepoMock) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	if hrmock.state == stateDBError {
+		return 0, errors.New("db error")
+	}
+	return 0, nil
+}
+func (hrmock *habitRepoMock) GetByUserIDs(ctx context.Context, uids []uuid.UUID, limit, offset int) ([]*entity.Habit, error) {
+	if hrmock.state == stateDBError {
+		return nil, errors.New("db error")
+	}
+	return []*entity.Habit{&testHabit}, nil
+}
+func (hrmock *habitRepoMock) CountByUserIDs(ctx context.Context, uids []uuid.UUID) (int, error) {
+	if hrmock.state == stateDBError {
+		return 0, errors.New("db error")
+	}
+	return 1, nil
+}
+func (hrmock *habitRepoMock) ListTags(ctx context.Context, uid uuid.UUID) ([]string, error) {
+	if hrmock.state == stateDBError {
+		return nil, errors.New("db error")
+	}
+	return []string{}, nil
+}
+func (hrmock *habitRepoMock) MaxUpdatedAt(ctx context.Context, uid uuid.UUID) (time.Time, error) {
+	if hrmock.state == stateDBError {
+		return time.Time{}, errors.New("db error")
+	}
+	return time.Time{}, nil
+}
+func (hrmock *habitRepoMock) WithTx(tx pgx.Tx) repository.HabitsRepositoryI {
+	return hrmock
+}
 func (hrmock *habitRepoMock) Delete(ctx context.Context, id uuid.UUID) error {
 	switch hrmock.state {
 	case stateDBError:
@@ -127,7 +212,10 @@ func TestCreateHabit(t *testing.T) {
 			Description: testHabit.Description,
 		})
 		assert.NoError(t, err)
-		assert.Equal(t, testHabit, *h)
+		// Habit is returned as created, without reading it back
+		expected := testHabit
+		expected.StartDate = h.StartDate
+		assert.Equal(t, expected, *h)
 	})
 	t.Run("db error", func(t *testing.T) {
 		mock.state = stateDBError
@@ -155,6 +243,54 @@ func TestCreateHabit(t *testing.T) {
 	})
 }
 
+func TestCreateHabitDefaultStartDate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockHabitsRepositoryI(ctrl)
+	checks := servicemocks.NewMockHabitChecksServiceI(ctrl)
+	s := service.NewHabitsService(repo)
+	s.SetChecksService(checks)
+	ctx := context.Background()
+	// Day in user's timezone, may differ from server's one
+	today := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	t.Run("today in user's timezone", func(t *testing.T) {
+		checks.EXPECT().Today(gomock.Any(), userID).Return(today, nil)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, h *entity.Habit) (uuid.UUID, error) {
+			assert.Equal(t, today, h.StartDate)
+			return habitID, nil
+		})
+		_, err := s.CreateHabit(ctx, userID, service.CreateHabitRequest{Title: testHabit.Title})
+		assert.NoError(t, err)
+	})
+	t.Run("explicit date kept", func(t *testing.T) {
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, h *entity.Habit) (uuid.UUID, error) {
+			assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), h.StartDate)
+			return habitID, nil
+		})
+		_, err := s.CreateHabit(ctx, userID, service.CreateHabitRequest{Title: testHabit.Title, StartDate: "2025-01-01"})
+		assert.NoError(t, err)
+	})
+	t.Run("batch resolves today once", func(t *testing.T) {
+		checks.EXPECT().Today(gomock.Any(), userID).Return(today, nil).Times(1)
+		repo.EXPECT().CreateMany(gomock.Any(), gomock.Len(3)).DoAndReturn(func(_ context.Context, habits []*entity.Habit) ([]bool, error) {
+			assert.Equal(t, today, habits[0].StartDate)
+			assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), habits[1].StartDate)
+			assert.Equal(t, today, habits[2].StartDate)
+			return []bool{true, true, true}, nil
+		})
+		_, _, err := s.CreateHabits(ctx, userID, []service.CreateHabitRequest{
+			{Title: "first"},
+			{Title: "second", StartDate: "2025-01-01"},
+			{Title: "third"},
+		})
+		assert.NoError(t, err)
+	})
+	t.Run("unexist user", func(t *testing.T) {
+		checks.EXPECT().Today(gomock.Any(), userID).Return(time.Time{}, errorvalues.ErrUserNotFound)
+		_, err := s.CreateHabit(ctx, userID, service.CreateHabitRequest{Title: testHabit.Title})
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+}
+
 func TestGetUserHabits(t *testing.T) {
 	mock := &habitRepoMock{state: stateSuccess}
 	s := service.NewHabitsService(mock)
@@ -163,6 +299,7 @@ func TestGetUserHabits(t *testing.T) {
 		habits, err := s.GetUserHabits(
 			ctx,
 			userID,
+			"",
 			service.PaginationOpts{
 				Limit:  10,
 				Offset: 0,
@@ -177,6 +314,7 @@ func TestGetUserHabits(t *testing.T) {
 		_, err := s.GetUserHabits(
 			ctx,
 			userID,
+			"",
 			service.PaginationOpts{
 				Limit:  10,
 				Offset: 0,
@@ -184,6 +322,10 @@ func TestGetUserHabits(t *testing.T) {
 		)
 		assert.Error(t, err)
 	})
+	t.Run("unknown sort", func(t *testing.T) {
+		_, err := s.GetUserHabits(ctx, userID, "title", service.PaginationOpts{Limit: 10})
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
+	})
 }
 
 func TestGetHabitByID(t *testing.T) {
@@ -237,6 +379,320 @@ func TestDeleteHabit(t *testing.T) {
 	})
 }
 
+func TestRestoreHabit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockHabitsRepositoryI(ctrl)
+	s := service.NewHabitsServiceWithRestoreWindow(repo, 7*24*time.Hour)
+	ctx := context.Background()
+	deletedHabit := func(ago time.Duration) *entity.Habit {
+		deletedAt := time.Now().Add(-ago)
+		return &entity.Habit{ID: habitID, UserID: userID, Title: testHabit.Title, DeletedAt: &deletedAt}
+	}
+	t.Run("restored within window", func(t *testing.T) {
+		repo.EXPECT().GetDeletedByID(gomock.Any(), habitID).Return(deletedHabit(2*24*time.Hour), nil)
+		repo.EXPECT().Restore(gomock.Any(), habitID).Return(nil)
+		assert.NoError(t, s.RestoreHabit(ctx, habitID, userID))
+	})
+	t.Run("not found after window", func(t *testing.T) {
+		repo.EXPECT().GetDeletedByID(gomock.Any(), habitID).Return(deletedHabit(8*24*time.Hour), nil)
+		assert.ErrorIs(t, s.RestoreHabit(ctx, habitID, userID), errorvalues.ErrHabitNotFound)
+	})
+	t.Run("wrong owner", func(t *testing.T) {
+		repo.EXPECT().GetDeletedByID(gomock.Any(), habitID).Return(deletedHabit(time.Hour), nil)
+		assert.ErrorIs(t, s.RestoreHabit(ctx, habitID, uuid.New()), errorvalues.ErrWrongOwner)
+	})
+	t.Run("title taken since deletion", func(t *testing.T) {
+		repo.EXPECT().GetDeletedByID(gomock.Any(), habitID).Return(deletedHabit(time.Hour), nil)
+		repo.EXPECT().Restore(gomock.Any(), habitID).Return(errorvalues.ErrUserHasHabit)
+		assert.ErrorIs(t, s.RestoreHabit(ctx, habitID, userID), errorvalues.ErrUserHasHabit)
+	})
+}
+
+func TestMergeHabits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockHabitsRepositoryI(ctrl)
+	s := service.NewHabitsService(repo)
+	ctx := context.Background()
+	sourceID, targetID := uuid.New(), uuid.New()
+	t.Run("merged", func(t *testing.T) {
+		repo.EXPECT().Merge(gomock.Any(), sourceID, targetID, userID).Return(int64(3), nil)
+		assert.NoError(t, s.MergeHabits(ctx, sourceID, targetID, userID))
+	})
+	t.Run("source not found", func(t *testing.T) {
+		repo.EXPECT().Merge(gomock.Any(), sourceID, targetID, userID).Return(int64(0), errorvalues.ErrHabitNotFound)
+		assert.ErrorIs(t, s.MergeHabits(ctx, sourceID, targetID, userID), errorvalues.ErrHabitNotFound)
+	})
+	t.Run("target of other user", func(t *testing.T) {
+		repo.EXPECT().Merge(gomock.Any(), sourceID, targetID, userID).Return(int64(0), errorvalues.ErrWrongOwner)
+		assert.ErrorIs(t, s.MergeHabits(ctx, sourceID, targetID, userID), errorvalues.ErrWrongOwner)
+	})
+	t.Run("into itself", func(t *testing.T) {
+		assert.ErrorIs(t, s.MergeHabits(ctx, sourceID, sourceID, userID), errorvalues.ErrValidation)
+	})
+	t.Run("repo error", func(t *testing.T) {
+		repo.EXPECT().Merge(gomock.Any(), sourceID, targetID, userID).Return(int64(0), errors.New("db error"))
+		err := s.MergeHabits(ctx, sourceID, targetID, userID)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, errorvalues.ErrHabitNotFound)
+	})
+}
+
+func TestReorderHabits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockHabitsRepositoryI(ctrl)
+	s := service.NewHabitsService(repo)
+	ctx := context.Background()
+	firstID, secondID := uuid.New(), uuid.New()
+	ids := []uuid.UUID{secondID, firstID}
+	t.Run("reordered", func(t *testing.T) {
+		repo.EXPECT().GetByIDs(gomock.Any(), ids).Return(map[uuid.UUID]*entity.Habit{
+			firstID:  {ID: firstID, UserID: userID},
+			secondID: {ID: secondID, UserID: userID},
+		}, nil)
+		repo.EXPECT().Reorder(gomock.Any(), userID, ids).Return(nil)
+		assert.NoError(t, s.ReorderHabits(ctx, userID, ids))
+	})
+	t.Run("not found", func(t *testing.T) {
+		repo.EXPECT().GetByIDs(gomock.Any(), ids).Return(map[uuid.UUID]*entity.Habit{
+			firstID: {ID: firstID, UserID: userID},
+		}, nil)
+		assert.ErrorIs(t, s.ReorderHabits(ctx, userID, ids), errorvalues.ErrHabitNotFound)
+	})
+	t.Run("habit of other user", func(t *testing.T) {
+		repo.EXPECT().GetByIDs(gomock.Any(), ids).Return(map[uuid.UUID]*entity.Habit{
+			firstID:  {ID: firstID, UserID: userID},
+			secondID: {ID: secondID, UserID: uuid.New()},
+		}, nil)
+		assert.ErrorIs(t, s.ReorderHabits(ctx, userID, ids), errorvalues.ErrWrongOwner)
+	})
+	t.Run("duplicates", func(t *testing.T) {
+		err := s.ReorderHabits(ctx, userID, []uuid.UUID{firstID, secondID, firstID})
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
+	})
+	t.Run("empty", func(t *testing.T) {
+		assert.ErrorIs(t, s.ReorderHabits(ctx, userID, nil), errorvalues.ErrValidation)
+	})
+	t.Run("deleted concurrently", func(t *testing.T) {
+		repo.EXPECT().GetByIDs(gomock.Any(), ids).Return(map[uuid.UUID]*entity.Habit{
+			firstID:  {ID: firstID, UserID: userID},
+			secondID: {ID: secondID, UserID: userID},
+		}, nil)
+		repo.EXPECT().Reorder(gomock.Any(), userID, ids).Return(errorvalues.ErrHabitNotFound)
+		assert.ErrorIs(t, s.ReorderHabits(ctx, userID, ids), errorvalues.ErrHabitNotFound)
+	})
+}
+
+func TestGetHabitWithStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockHabitsRepositoryI(ctrl)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	s := service.NewHabitsService(repo)
+	s.SetChecksService(service.NewHabitChecksService(repo, checksRepo))
+	ctx := context.Background()
+	t.Run("success", func(t *testing.T) {
+		today := service.CalendarDay(time.Now(), time.UTC)
+		// Habit is loaded and its owner checked only once
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil).Times(1)
+		checksRepo.EXPECT().GetCheckedDates(gomock.Any(), habitID, time.Time{}, today).Return(map[string]entity.CheckStatus{
+			today.Format(time.DateOnly):                   entity.CheckStatusChecked,
+			today.AddDate(0, 0, -1).Format(time.DateOnly): entity.CheckStatusChecked,
+		}, nil)
+		checksRepo.EXPECT().GetPauses(gomock.Any(), []uuid.UUID{habitID}).Return(nil, nil)
+		detail, err := s.GetHabitWithStats(ctx, habitID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, testHabit.Title, detail.Habit.Title)
+		require.NotNil(t, detail.Stats)
+		assert.Equal(t, habitID, detail.Stats.ID)
+		assert.Equal(t, 2, detail.Stats.TotalChecks)
+		assert.Equal(t, 2, detail.Stats.CurrentStreak)
+	})
+	t.Run("error wrong owner", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil).Times(1)
+		_, err := s.GetHabitWithStats(ctx, habitID, uuid.New())
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+	})
+	t.Run("error no checks service", func(t *testing.T) {
+		_, err := service.NewHabitsService(repo).GetHabitWithStats(ctx, habitID, userID)
+		assert.Error(t, err)
+	})
+}
+
+func TestSentinelsPropagate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockHabitsRepositoryI(ctrl)
+	checksRepo := mocks.NewMockHabitChecksRepositoryI(ctrl)
+	s := service.NewHabitsService(repo)
+	s.SetChecksService(service.NewHabitChecksService(repo, checksRepo))
+	ctx := context.Background()
+	t.Run("sentinel wrapped by repository", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(nil, fmt.Errorf("tx error: %w", errorvalues.ErrHabitNotFound))
+		_, err := s.GetHabit(ctx, habitID, userID)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+	})
+	t.Run("sentinel isn't prefixed", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(nil, errorvalues.ErrHabitNotFound)
+		_, err := s.GetHabit(ctx, habitID, userID)
+		assert.Equal(t, errorvalues.ErrHabitNotFound, err)
+	})
+	t.Run("through checks service", func(t *testing.T) {
+		// Habit is deleted between loading and counting its stats
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil)
+		checksRepo.EXPECT().GetCheckedDates(gomock.Any(), habitID, gomock.Any(), gomock.Any()).Return(nil, errorvalues.ErrHabitNotFound)
+		_, err := s.GetHabitWithStats(ctx, habitID, userID)
+		assert.ErrorIs(t, err, errorvalues.ErrHabitNotFound)
+	})
+	t.Run("cancellation", func(t *testing.T) {
+		repo.EXPECT().GetByUserID(gomock.Any(), userID, entity.HabitSortCreatedAt, 10, 0).Return(nil, fmt.Errorf("getting habits error: %w", context.Canceled))
+		_, err := s.GetUserHabits(ctx, userID, "", service.PaginationOpts{Limit: 10})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestUpdateHabit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockHabitsRepositoryI(ctrl)
+	s := service.NewHabitsService(repo)
+	ctx := context.Background()
+	title, desc := "new_title", "new_desc"
+	t.Run("title only", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil)
+		repo.EXPECT().UpdateTitle(gomock.Any(), habitID, title).Return(nil)
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID, Title: title}, nil)
+		h, err := s.UpdateHabit(ctx, habitID, userID, service.UpdateHabitRequest{Title: &title})
+		assert.NoError(t, err)
+		assert.Equal(t, title, h.Title)
+	})
+	t.Run("description only", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil)
+		repo.EXPECT().UpdateDescription(gomock.Any(), habitID, desc).Return(nil)
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID, Description: desc}, nil)
+		h, err := s.UpdateHabit(ctx, habitID, userID, service.UpdateHabitRequest{Description: &desc})
+		assert.NoError(t, err)
+		assert.Equal(t, desc, h.Description)
+	})
+	t.Run("both fields", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, h *entity.Habit) error {
+			assert.Equal(t, title, h.Title)
+			assert.Equal(t, desc, h.Description)
+			return nil
+		})
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID, Title: title, Description: desc}, nil)
+		_, err := s.UpdateHabit(ctx, habitID, userID, service.UpdateHabitRequest{Title: &title, Description: &desc})
+		assert.NoError(t, err)
+	})
+	t.Run("color keeps other fields", func(t *testing.T) {
+		color := "#00ff00"
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, h *entity.Habit) error {
+			assert.Equal(t, testHabit.Title, h.Title)
+			assert.Equal(t, testHabit.Description, h.Description)
+			assert.Equal(t, color, h.Color)
+			return nil
+		})
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID, Color: color}, nil)
+		h, err := s.UpdateHabit(ctx, habitID, userID, service.UpdateHabitRequest{Color: &color})
+		assert.NoError(t, err)
+		assert.Equal(t, color, h.Color)
+	})
+	t.Run("tags replaced", func(t *testing.T) {
+		tags := []string{"health", "evening"}
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, h *entity.Habit) error {
+			assert.Equal(t, testHabit.Title, h.Title)
+			assert.Equal(t, tags, h.Tags)
+			return nil
+		})
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&entity.Habit{ID: habitID, UserID: userID, Tags: tags}, nil)
+		h, err := s.UpdateHabit(ctx, habitID, userID, service.UpdateHabitRequest{Tags: &tags})
+		assert.NoError(t, err)
+		assert.Equal(t, tags, h.Tags)
+	})
+	t.Run("empty tag", func(t *testing.T) {
+		tags := []string{"health", ""}
+		_, err := s.UpdateHabit(ctx, habitID, userID, service.UpdateHabitRequest{Tags: &tags})
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
+	})
+	t.Run("invalid color", func(t *testing.T) {
+		color := "red"
+		_, err := s.UpdateHabit(ctx, habitID, userID, service.UpdateHabitRequest{Color: &color})
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
+	})
+	t.Run("wrong owner", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil)
+		_, err := s.UpdateHabit(ctx, habitID, uuid.New(), service.UpdateHabitRequest{Title: &title})
+		assert.ErrorIs(t, err, errorvalues.ErrWrongOwner)
+	})
+	t.Run("title taken", func(t *testing.T) {
+		repo.EXPECT().GetByID(gomock.Any(), habitID).Return(&testHabit, nil)
+		repo.EXPECT().UpdateTitle(gomock.Any(), habitID, title).Return(errorvalues.ErrUserHasHabit)
+		_, err := s.UpdateHabit(ctx, habitID, userID, service.UpdateHabitRequest{Title: &title})
+		assert.ErrorIs(t, err, errorvalues.ErrUserHasHabit)
+	})
+}
+
+func TestCreateHabits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockHabitsRepositoryI(ctrl)
+	s := service.NewHabitsService(repo)
+	ctx := context.Background()
+	reqs := []service.CreateHabitRequest{
+		{Title: "first"},
+		{Title: "duplicate"},
+		{Title: "third"},
+	}
+	t.Run("one duplicate", func(t *testing.T) {
+		repo.EXPECT().CreateMany(gomock.Any(), gomock.Len(3)).DoAndReturn(func(_ context.Context, habits []*entity.Habit) ([]bool, error) {
+			for _, h := range habits {
+				h.ID = uuid.New()
+			}
+			return []bool{true, false, true}, nil
+		})
+		habits, failures, err := s.CreateHabits(ctx, userID, reqs)
+		assert.NoError(t, err)
+		require.Len(t, habits, 2)
+		assert.Equal(t, "first", habits[0].Title)
+		assert.Equal(t, "third", habits[1].Title)
+		require.Len(t, failures, 1)
+		assert.Equal(t, 1, failures[0].Index)
+		assert.ErrorIs(t, failures[0].Err, errorvalues.ErrUserHasHabit)
+	})
+	t.Run("invalid item not sent to repo", func(t *testing.T) {
+		repo.EXPECT().CreateMany(gomock.Any(), gomock.Len(2)).Return([]bool{true, true}, nil)
+		_, failures, err := s.CreateHabits(ctx, userID, []service.CreateHabitRequest{
+			{Title: "first"},
+			{Title: "second", Color: "red"},
+			{Title: "third"},
+		})
+		assert.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.Equal(t, 1, failures[0].Index)
+		assert.ErrorIs(t, failures[0].Err, errorvalues.ErrValidation)
+	})
+	t.Run("unexist user", func(t *testing.T) {
+		repo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).Return(nil, errorvalues.ErrOwnerNotFound)
+		_, _, err := s.CreateHabits(ctx, userID, reqs)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+}
+
+func TestCreateHabitColorValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockHabitsRepositoryI(ctrl)
+	s := service.NewHabitsService(repo)
+	ctx := context.Background()
+	t.Run("name of color rejected", func(t *testing.T) {
+		_, err := s.CreateHabit(ctx, userID, service.CreateHabitRequest{Title: "test_habit", Color: "red"})
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
+	})
+	t.Run("hex code accepted", func(t *testing.T) {
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(habitID, nil)
+		h, err := s.CreateHabit(ctx, userID, service.CreateHabitRequest{Title: "test_habit", Color: "#00ff00"})
+		assert.NoError(t, err)
+		assert.Equal(t, "#00ff00", h.Color)
+	})
+}
+
 func TestHabitsServiceIntegrational(t *testing.T) {
 	cfg := setupHabitsTestDB(t)
 	repo := repository.NewHabitsRepo(cfg)
@@ -279,7 +735,7 @@ func TestHabitsServiceIntegrational(t *testing.T) {
 	})
 	t.Run("get user's habits", func(t *testing.T) {
 		t.Run("got all", func(t *testing.T) {
-			result, err := s.GetUserHabits(ctx, userID, service.PaginationOpts{Limit: 5, Offset: 0})
+			result, err := s.GetUserHabits(ctx, userID, "", service.PaginationOpts{Limit: 5, Offset: 0})
 			assert.NoError(t, err)
 			assert.Equal(t, 5, len(result))
 			for i := range result {
@@ -288,7 +744,7 @@ func TestHabitsServiceIntegrational(t *testing.T) {
 		})
 		t.Run("got some", func(t *testing.T) {
 			limit, offset := 2, 2
-			result, err := s.GetUserHabits(ctx, userID, service.PaginationOpts{Limit: limit, Offset: offset})
+			result, err := s.GetUserHabits(ctx, userID, "", service.PaginationOpts{Limit: limit, Offset: offset})
 			assert.NoError(t, err)
 			assert.Equal(t, limit, len(result))
 			for i := range limit {
@@ -9,17 +9,21 @@ import (
 )
 
 type RegisterRequest struct {
-	Name     string `validate:"required,alphanum_underscore,min=3,max=100"`
-	Password string `validate:"required,min=8,max=72"`
+	// Validated by ValidateUsername
+	Name     string
+	Password string `validate:"required,min=8,max=72,strong_password"`
 }
 
 type UserServiceI interface {
 	// Validates user's credentials, creates new row in database. Returns user's data with ID.
-	// If user with such name already exists, returns errorvalues.ErrUserExists
+	// If credentials are invalid or name is reserved, returns error wrapping errorvalues.ErrValidation.
+	// If user with such name already exists, returns errorvalues.ErrNameTaken (which is errorvalues.ErrUserExists too)
 	Register(ctx context.Context, req *RegisterRequest) (*entity.User, error)
-	// Compares given credentials to stored ones. If ok, give back user's data with ID.
+	// Compares given credentials to stored ones. If ok, give back user's data with ID
+	// and updates last login time (returned data keeps previous one).
 	// If user not found, returns errorvalues.ErrUserNotFound.
-	// If credentials are wrong, returns errorvalues.ErrWrongCredentials
+	// If credentials are wrong, returns errorvalues.ErrWrongCredentials.
+	// If account is disabled, returns errorvalues.ErrAccountDisabled
 	Login(ctx context.Context, name, password string) (*entity.User, error)
 	// Searchs for user's metadata by given id.
 	// If user not found, returns errorvalues.ErrUserNotFound
@@ -27,15 +31,79 @@ type UserServiceI interface {
 	// Searchs for user's metadata by name.
 	// If user not found, returns errorvalues.ErrUserNotFound
 	GetByName(ctx context.Context, name string) (*entity.User, error)
+	// Validates new name as on registration and renames user with id.
+	// If name is invalid, returns error wrapping errorvalues.ErrValidation.
+	// If name is already taken, returns errorvalues.ErrNameTaken.
+	// If user not found, returns errorvalues.ErrUserNotFound
+	ChangeUsername(ctx context.Context, id uuid.UUID, newName string) error
+	// Sets timezone (IANA name, e.g. Asia/Tokyo) in which user's calendar days are counted.
+	// If timezone is unknown, returns error wrapping errorvalues.ErrValidation.
+	// If user not found, returns errorvalues.ErrUserNotFound
+	SetTimezone(ctx context.Context, id uuid.UUID, timezone string) error
+	// Activates or suspends user's account. Suspended user can't log in and their tokens are rejected.
+	// If user not found, returns errorvalues.ErrUserNotFound
+	SetActive(ctx context.Context, id uuid.UUID, active bool) error
+	// Revokes all user's tokens issued so far (see entity.User.TokenVersion), user has to log in again.
+	// If user not found, returns errorvalues.ErrUserNotFound
+	RevokeSessions(ctx context.Context, id uuid.UUID) error
 	// Deletes user by id, needs password for security matters.
 	// If user not found, returns errorvalues.ErrUserNotFound.
-	// If password is wrong, returns errorvalues.ErrUserNotFound
+	// If password is wrong, returns errorvalues.ErrWrongCredentials
 	DeleteAccount(ctx context.Context, id uuid.UUID, password string) error
+	// Deletes user by id with all habits and checks in single transaction, needs password as DeleteAccount.
+	// Returns count of removed rows.
+	// If user not found, returns errorvalues.ErrUserNotFound.
+	// If password is wrong, returns errorvalues.ErrWrongCredentials
+	EraseAccount(ctx context.Context, id uuid.UUID, password string) (*entity.ErasureSummary, error)
+	// Returns count of rows account deletion would remove (including deleted habits and their checks),
+	// nothing is removed. If user not found, returns errorvalues.ErrUserNotFound
+	PreviewDeletion(ctx context.Context, id uuid.UUID) (*entity.ErasureSummary, error)
+	// Lists users whose name contains query (case-insensitive) in given order (by name if empty)
+	// along with total count of matching ones. Password hashes aren't provided.
+	// If sort is unknown, returns error wrapping errorvalues.ErrValidation
+	ListUsers(ctx context.Context, query string, sort entity.UserSort, pagination PaginationOpts) ([]*entity.User, int, error)
 }
 
 type CreateHabitRequest struct {
 	Title       string
 	Description string
+	Color       string `validate:"omitempty,hex_color"`
+	Icon        string `validate:"max=64"`
+	// YYYY-MM-DD, creation day in user's timezone if empty
+	StartDate string `validate:"omitempty,datetime=2006-01-02"`
+	// Can't be changed once habit is created
+	AllowMultiplePerDay bool
+	Tags                []string `validate:"max=16,dive,min=1,max=32"`
+}
+
+// Fields to update in habit, nil ones stay untouched.
+type UpdateHabitRequest struct {
+	Title       *string
+	Description *string
+	Color       *string `validate:"omitempty,hex_color"`
+	Icon        *string `validate:"omitempty,max=64"`
+	StartDate   *string `validate:"omitempty,datetime=2006-01-02"`
+	// Replaces all habit's tags, empty list removes them
+	Tags *[]string `validate:"omitempty,max=16,dive,min=1,max=32"`
+}
+
+// Failure of single item in batch operation
+type BatchError struct {
+	// Position of failed item in request
+	Index int
+	Err   error
+}
+
+// Check of one habit in batch spanning several habits
+type CheckRequest struct {
+	HabitID uuid.UUID
+	Date    time.Time
+}
+
+// Habit with stats of its checks, for habit detail view
+type HabitDetail struct {
+	Habit *entity.Habit
+	Stats *entity.HabitStats
 }
 
 type PaginationOpts struct {
@@ -45,34 +113,150 @@ type PaginationOpts struct {
 
 type HabitsServiceI interface {
 	// Creates habit owned by user with uid. On success returns Habit data.
+	// Empty start date defaults to current day in user's timezone.
+	// If color or icon are invalid, returns error wrapping errorvalues.ErrValidation.
 	// If there is no such owner (user), returns errorvalues.ErrUserNotFound
 	CreateHabit(ctx context.Context, uid uuid.UUID, req CreateHabitRequest) (*entity.Habit, error)
-	// Returns list of user's habits. Requires pagination options.
+	// Creates several habits owned by user with uid in single transaction.
+	// Returns created habits in request order and failures of the rest items:
+	// duplicated titles are reported with errorvalues.ErrUserHasHabit, invalid items with errorvalues.ErrValidation.
+	// If there is no such owner (user), returns errorvalues.ErrUserNotFound
+	CreateHabits(ctx context.Context, uid uuid.UUID, reqs []CreateHabitRequest) ([]*entity.Habit, []BatchError, error)
+	// Returns list of user's habits in given order (by creation if sort is empty). Requires pagination options.
+	// If sort is unknown, returns errorvalues.ErrValidation.
 	// If there is no such user, returns empty list TO-DO: should check user for existion and return error, if doesn't exist
-	GetUserHabits(ctx context.Context, uid uuid.UUID, pagination PaginationOpts) ([]*entity.Habit, error)
-	// Deletes habit by habitID if userID is truly its owner.
+	GetUserHabits(ctx context.Context, uid uuid.UUID, sort entity.HabitSort, pagination PaginationOpts) ([]*entity.Habit, error)
+	// Same as GetUserHabits, but pages by cursor: returns up to limit habits ordered by creation time,
+	// starting right after cursor (from the first one if cursor is nil).
+	GetUserHabitsAfter(ctx context.Context, uid uuid.UUID, cursor *entity.HabitCursor, limit int) ([]*entity.Habit, error)
+	// Returns count of user's habits on all pages of GetUserHabits.
+	CountUserHabits(ctx context.Context, uid uuid.UUID) (int, error)
+	// Calls fn for each user's habit in order of creation without loading them all at once, for export.
+	// Iteration stops on first fn's error, which is returned as is.
+	ExportHabits(ctx context.Context, uid uuid.UUID, fn func(habit *entity.Habit) error) error
+	// Returns page of habits of all members of group with groupID (see GroupMembersResolver) and their total count.
+	// Requires pagination options. If there is no such group or user with userID isn't its member, returns errorvalues.ErrGroupNotFound
+	GetGroupHabits(ctx context.Context, groupID, userID uuid.UUID, pagination PaginationOpts) ([]*entity.Habit, int, error)
+	// Returns sorted distinct tags of user's habits, empty list if there are none.
+	ListTags(ctx context.Context, uid uuid.UUID) ([]string, error)
+	// Returns time user's habits list last changed, zero time if user never had habits.
+	LastModified(ctx context.Context, uid uuid.UUID) (time.Time, error)
+	// Deletes habit by habitID if userID is truly its owner. Habit can be restored within restore window.
 	// If there is no habit with such ID, returns errorvalues.ErrHabitNotFound
 	DeleteHabit(ctx context.Context, habitID, userID uuid.UUID) error
+	// Brings back habit deleted within restore window if userID is truly its owner.
+	// If there is no such deleted habit or window has passed, returns errorvalues.ErrHabitNotFound.
+	// If its title has been used by another habit since, returns errorvalues.ErrUserHasHabit
+	RestoreHabit(ctx context.Context, habitID, userID uuid.UUID) error
+	// Permanently deletes habits deleted more than olderThan ago. Returns count of purged habits.
+	// Safe to be called periodically and concurrently.
+	PurgeDeleted(ctx context.Context, olderThan time.Duration) (int64, error)
+	// Combines duplicate habits: checks and skips of source are moved to target, then source is deleted
+	// permanently (it can't be restored). On days both habits are marked target's marks are kept.
+	// If target doesn't allow several marks a day, repeated source's marks of a day are collapsed into one.
+	// If any of habits doesn't exist, returns errorvalues.ErrHabitNotFound.
+	// If userID doesn't own both, returns errorvalues.ErrWrongOwner.
+	// If sourceID equals targetID, returns errorvalues.ErrValidation
+	MergeHabits(ctx context.Context, sourceID, targetID, userID uuid.UUID) error
+	// Arranges user's habits in order of orderedIDs (see entity.HabitSortPosition), all at once.
+	// Habits not listed go after listed ones, keeping their relative order.
+	// If any of habits doesn't exist, returns errorvalues.ErrHabitNotFound.
+	// If userID doesn't own all of them, returns errorvalues.ErrWrongOwner.
+	// If orderedIDs is empty or has duplicates, returns errorvalues.ErrValidation
+	ReorderHabits(ctx context.Context, userID uuid.UUID, orderedIDs []uuid.UUID) error
 	// Returns habit metadata if userID is truly its owner.
 	// If there is no habit with such ID, returns errorvalues.ErrHabitNotFound
 	GetHabit(ctx context.Context, habitID, userID uuid.UUID) (*entity.Habit, error)
+	// Same as GetHabit, but also provides habit's checks stats (see HabitChecksServiceI.GetHabitStats).
+	// Fails if service was created without checks service (SetChecksService)
+	GetHabitWithStats(ctx context.Context, habitID, userID uuid.UUID) (*HabitDetail, error)
+	// Updates only provided (non-nil) fields of habit if userID is truly its owner. Returns updated habit.
+	// If there is no habit with such ID, returns errorvalues.ErrHabitNotFound.
+	// If new color or icon are invalid, returns error wrapping errorvalues.ErrValidation.
+	// If user already has habit with new title, returns errorvalues.ErrUserHasHabit
+	UpdateHabit(ctx context.Context, habitID, userID uuid.UUID, req UpdateHabitRequest) (*entity.Habit, error)
 }
 
 type HabitChecksServiceI interface {
-	// Adds check to habit (habitID).
+	// Returns current calendar day (as UTC midnight) in timezone of user with userID.
+	// If user not found, returns errorvalues.ErrUserNotFound
+	Today(ctx context.Context, userID uuid.UUID) (time.Time, error)
+	// Adds check with optional note to habit (habitID).
+	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
+	// If there is attempt to create check to the future date (in user's timezone) or before habit start date, returns errorvalues.ErrCheckDateNotAllowed.
+	// If there was check on this date already, returns errorvalues.ErrCheckExist.
+	// Habits allowing multiple checks per day get one more check, only skipped date is rejected with errorvalues.ErrCheckExist
+	CheckHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time, note string) error
+	// Runs same validations as CheckHabit without writing. If check would be rejected, reports false and
+	// reason: CheckRejectedDateNotAllowed or CheckRejectedExists. Unexist or others' habits are reported with errors as by CheckHabit
+	CanCheck(ctx context.Context, habitID, userID uuid.UUID, date time.Time) (bool, string, error)
+	// Same as CheckHabit, but if date is checked or skipped already, check is kept (or skip turned into check) with note replaced.
+	// Reports if check was created rather than updated.
+	UpsertCheck(ctx context.Context, habitID, userID uuid.UUID, date time.Time, note string) (bool, error)
+	// Marks date as skipped for habit (habitID): skip day neither breaks nor extends streak.
 	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
-	// If there is attempt to create check to the future date, returns errorvalues.ErrCheckDateNotAllowed.
-	// If there was check on this date already, returns errorvalues.ErrCheckExist
-	CheckHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time) error
-	// Unchecks habit (deletes check by date).
+	// If there is attempt to skip future date or one before habit start date, returns errorvalues.ErrCheckDateNotAllowed.
+	// If date is checked or skipped already, returns errorvalues.ErrCheckExist
+	SkipHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time) error
+	// Unchecks habit (deletes check by date), habits allowing multiple checks per day lose only latest one.
 	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
 	// If there is no check on given date, returns errorvalues.ErrCheckNotFound
 	UncheckHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time) error
-	// Provides list of checks bound to given date interval.
+	// Provides list of checks bound to given date interval, sorted by date in order (ascending by default).
+	// Only checks or only skips are provided if status is set, all marks if it's empty; unknown status
+	// is reported with errorvalues.ErrValidation.
+	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
+	GetHabitChecks(ctx context.Context, habitID, userID uuid.UUID, from, to time.Time, order entity.CheckOrder, status entity.CheckStatus) ([]entity.HabitCheck, error)
+	// Provides days of year habit was checked on, grouped by month, days are sorted. Skips are ignored,
+	// months without checks are absent. Compares userID with owner of habit with habitID,
+	// if they don't match, returns errovalues.ErrWrongOwner.
+	GetMonthlyChecks(ctx context.Context, habitID, userID uuid.UUID, year int) (map[time.Month][]int, error)
+	// Checks several habits of user with userID at once, items are validated as by CheckHabit.
+	// Valid items are created in single transaction, failures of the rest are reported in request order:
+	// others' habits with errorvalues.ErrWrongOwner, unexist ones with errorvalues.ErrHabitNotFound,
+	// disallowed dates with errorvalues.ErrCheckDateNotAllowed and already checked days with errorvalues.ErrCheckExist.
+	CheckMany(ctx context.Context, userID uuid.UUID, reqs []CheckRequest) ([]BatchError, error)
+	// Provides user's habits whose streak breaks unless they are checked (or skipped) today, today is counted
+	// in user's timezone. Paused habits are never at risk. If there are no such habits, returns empty list.
+	GetAtRiskHabits(ctx context.Context, userID uuid.UUID) ([]*entity.Habit, error)
+	// Calls fn for each check and skip of user's habits, grouped by habit and ordered by date,
+	// without loading them all at once, for export. Iteration stops on first fn's error, which is returned as is.
+	ExportChecks(ctx context.Context, userID uuid.UUID, fn func(check *entity.HabitCheck) error) error
+	// Reports which of habitIDs are checked today in user's timezone, skips don't count as checks.
+	// Habits aren't looked up, so ownership of habitIDs must be known already (e.g. they are from user's list).
+	CheckedToday(ctx context.Context, userID uuid.UUID, habitIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	// Provides checks and skips of all user's habits on date, along with habits titles.
+	// Zero date means today in user's timezone.
+	GetUserChecksOn(ctx context.Context, userID uuid.UUID, date time.Time) ([]entity.UserCheck, error)
+	// Returns count of checks (skips excluded) on habit within period, bounds included.
 	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
-	GetHabitChecks(ctx context.Context, habitID, userID uuid.UUID, from, to time.Time) ([]entity.HabitCheck, error)
+	CountHabitChecks(ctx context.Context, habitID, userID uuid.UUID, from, to time.Time) (int, error)
+	// Returns count of checked days among last days ones (today included) and total count of days in window.
+	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
+	// If days is not in 1..365, returns errorvalues.ErrValidation
+	RecentAdherence(ctx context.Context, habitID, userID uuid.UUID, days int) (checked int, total int, err error)
+	// Returns date of last check on habit (skips excluded), nil if habit has no checks.
+	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
+	GetLastCheck(ctx context.Context, habitID, userID uuid.UUID) (*time.Time, error)
+	// Pauses habit since today: until it's resumed, days without checks break neither streak nor completion rate.
+	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
+	// If habit is paused already, returns errorvalues.ErrHabitPaused
+	PauseHabit(ctx context.Context, habitID, userID uuid.UUID) error
+	// Ends pause of habit, today is tracked again.
+	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
+	// If habit isn't paused, returns errorvalues.ErrHabitNotPaused
+	ResumeHabit(ctx context.Context, habitID, userID uuid.UUID) error
 	// Returns checks stat on habit.
 	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
-	// Returns summ count of checks, streaks and last check date.
+	// Returns summ count of checks, streaks and last check date. Skipped days are not counted as checks,
+	// but keep streak going.
 	GetHabitStats(ctx context.Context, habitID, userID uuid.UUID) (*entity.HabitStats, error)
+	// Same as GetHabitStats, but for habit already loaded by caller, who is responsible for ownership check.
+	GetStatsForHabit(ctx context.Context, habit *entity.Habit) (*entity.HabitStats, error)
+	// Returns count of checks on habit by day of week, indexed as time.Weekday (Sunday is 0).
+	// Compares userID with owner of habit with habitID, if they don't match, returns errovalues.ErrWrongOwner.
+	GetWeekdayDistribution(ctx context.Context, habitID, userID uuid.UUID) ([7]int, error)
+	// Returns stats aggregated over all user's habits: count of habits and checks, and the longest max streak among them.
+	// If user has no habits, summary is zero.
+	GetUserSummary(ctx context.Context, userID uuid.UUID) (*entity.UserSummary, error)
 }
@@ -38,6 +38,20 @@ func (m *MockUserServiceI) EXPECT() *MockUserServiceIMockRecorder {
 	return m.recorder
 }
 
+// ChangeUsername mocks base method.
+func (m *MockUserServiceI) ChangeUsername(ctx context.Context, id uuid.UUID, newName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUsername", ctx, id, newName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeUsername indicates an expected call of ChangeUsername.
+func (mr *MockUserServiceIMockRecorder) ChangeUsername(ctx, id, newName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUsername", reflect.TypeOf((*MockUserServiceI)(nil).ChangeUsername), ctx, id, newName)
+}
+
 // DeleteAccount mocks base method.
 func (m *MockUserServiceI) DeleteAccount(ctx context.Context, id uuid.UUID, password string) error {
 	m.ctrl.T.Helper()
@@ -52,6 +66,21 @@ func (mr *MockUserServiceIMockRecorder) DeleteAccount(ctx, id, password interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockUserServiceI)(nil).DeleteAccount), ctx, id, password)
 }
 
+// EraseAccount mocks base method.
+func (m *MockUserServiceI) EraseAccount(ctx context.Context, id uuid.UUID, password string) (*entity.ErasureSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseAccount", ctx, id, password)
+	ret0, _ := ret[0].(*entity.ErasureSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EraseAccount indicates an expected call of EraseAccount.
+func (mr *MockUserServiceIMockRecorder) EraseAccount(ctx, id, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseAccount", reflect.TypeOf((*MockUserServiceI)(nil).EraseAccount), ctx, id, password)
+}
+
 // GetByID mocks base method.
 func (m *MockUserServiceI) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
 	m.ctrl.T.Helper()
@@ -82,6 +111,22 @@ func (mr *MockUserServiceIMockRecorder) GetByName(ctx, name interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockUserServiceI)(nil).GetByName), ctx, name)
 }
 
+// ListUsers mocks base method.
+func (m *MockUserServiceI) ListUsers(ctx context.Context, query string, sort entity.UserSort, pagination service.PaginationOpts) ([]*entity.User, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, query, sort, pagination)
+	ret0, _ := ret[0].([]*entity.User)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserServiceIMockRecorder) ListUsers(ctx, query, sort, pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserServiceI)(nil).ListUsers), ctx, query, sort, pagination)
+}
+
 // Login mocks base method.
 func (m *MockUserServiceI) Login(ctx context.Context, name, password string) (*entity.User, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +142,21 @@ func (mr *MockUserServiceIMockRecorder) Login(ctx, name, password interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserServiceI)(nil).Login), ctx, name, password)
 }
 
+// PreviewDeletion mocks base method.
+func (m *MockUserServiceI) PreviewDeletion(ctx context.Context, id uuid.UUID) (*entity.ErasureSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewDeletion", ctx, id)
+	ret0, _ := ret[0].(*entity.ErasureSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewDeletion indicates an expected call of PreviewDeletion.
+func (mr *MockUserServiceIMockRecorder) PreviewDeletion(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewDeletion", reflect.TypeOf((*MockUserServiceI)(nil).PreviewDeletion), ctx, id)
+}
+
 // Register mocks base method.
 func (m *MockUserServiceI) Register(ctx context.Context, req *service.RegisterRequest) (*entity.User, error) {
 	m.ctrl.T.Helper()
@@ -112,6 +172,48 @@ func (mr *MockUserServiceIMockRecorder) Register(ctx, req interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserServiceI)(nil).Register), ctx, req)
 }
 
+// RevokeSessions mocks base method.
+func (m *MockUserServiceI) RevokeSessions(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockUserServiceIMockRecorder) RevokeSessions(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockUserServiceI)(nil).RevokeSessions), ctx, id)
+}
+
+// SetActive mocks base method.
+func (m *MockUserServiceI) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActive", ctx, id, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetActive indicates an expected call of SetActive.
+func (mr *MockUserServiceIMockRecorder) SetActive(ctx, id, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActive", reflect.TypeOf((*MockUserServiceI)(nil).SetActive), ctx, id, active)
+}
+
+// SetTimezone mocks base method.
+func (m *MockUserServiceI) SetTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTimezone", ctx, id, timezone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTimezone indicates an expected call of SetTimezone.
+func (mr *MockUserServiceIMockRecorder) SetTimezone(ctx, id, timezone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTimezone", reflect.TypeOf((*MockUserServiceI)(nil).SetTimezone), ctx, id, timezone)
+}
+
 // MockHabitsServiceI is a mock of HabitsServiceI interface.
 type MockHabitsServiceI struct {
 	ctrl     *gomock.Controller
@@ -135,6 +237,21 @@ func (m *MockHabitsServiceI) EXPECT() *MockHabitsServiceIMockRecorder {
 	return m.recorder
 }
 
+// CountUserHabits mocks base method.
+func (m *MockHabitsServiceI) CountUserHabits(ctx context.Context, uid uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserHabits", ctx, uid)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserHabits indicates an expected call of CountUserHabits.
+func (mr *MockHabitsServiceIMockRecorder) CountUserHabits(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserHabits", reflect.TypeOf((*MockHabitsServiceI)(nil).CountUserHabits), ctx, uid)
+}
+
 // CreateHabit mocks base method.
 func (m *MockHabitsServiceI) CreateHabit(ctx context.Context, uid uuid.UUID, req service.CreateHabitRequest) (*entity.Habit, error) {
 	m.ctrl.T.Helper()
@@ -150,6 +267,22 @@ func (mr *MockHabitsServiceIMockRecorder) CreateHabit(ctx, uid, req interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHabit", reflect.TypeOf((*MockHabitsServiceI)(nil).CreateHabit), ctx, uid, req)
 }
 
+// CreateHabits mocks base method.
+func (m *MockHabitsServiceI) CreateHabits(ctx context.Context, uid uuid.UUID, reqs []service.CreateHabitRequest) ([]*entity.Habit, []service.BatchError, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHabits", ctx, uid, reqs)
+	ret0, _ := ret[0].([]*entity.Habit)
+	ret1, _ := ret[1].([]service.BatchError)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateHabits indicates an expected call of CreateHabits.
+func (mr *MockHabitsServiceIMockRecorder) CreateHabits(ctx, uid, reqs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHabits", reflect.TypeOf((*MockHabitsServiceI)(nil).CreateHabits), ctx, uid, reqs)
+}
+
 // DeleteHabit mocks base method.
 func (m *MockHabitsServiceI) DeleteHabit(ctx context.Context, habitID, userID uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -164,6 +297,36 @@ func (mr *MockHabitsServiceIMockRecorder) DeleteHabit(ctx, habitID, userID inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHabit", reflect.TypeOf((*MockHabitsServiceI)(nil).DeleteHabit), ctx, habitID, userID)
 }
 
+// ExportHabits mocks base method.
+func (m *MockHabitsServiceI) ExportHabits(ctx context.Context, uid uuid.UUID, fn func(*entity.Habit) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportHabits", ctx, uid, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportHabits indicates an expected call of ExportHabits.
+func (mr *MockHabitsServiceIMockRecorder) ExportHabits(ctx, uid, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportHabits", reflect.TypeOf((*MockHabitsServiceI)(nil).ExportHabits), ctx, uid, fn)
+}
+
+// GetGroupHabits mocks base method.
+func (m *MockHabitsServiceI) GetGroupHabits(ctx context.Context, groupID, userID uuid.UUID, pagination service.PaginationOpts) ([]*entity.Habit, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupHabits", ctx, groupID, userID, pagination)
+	ret0, _ := ret[0].([]*entity.Habit)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetGroupHabits indicates an expected call of GetGroupHabits.
+func (mr *MockHabitsServiceIMockRecorder) GetGroupHabits(ctx, groupID, userID, pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupHabits", reflect.TypeOf((*MockHabitsServiceI)(nil).GetGroupHabits), ctx, groupID, userID, pagination)
+}
+
 // GetHabit mocks base method.
 func (m *MockHabitsServiceI) GetHabit(ctx context.Context, habitID, userID uuid.UUID) (*entity.Habit, error) {
 	m.ctrl.T.Helper()
@@ -179,19 +342,151 @@ func (mr *MockHabitsServiceIMockRecorder) GetHabit(ctx, habitID, userID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabit", reflect.TypeOf((*MockHabitsServiceI)(nil).GetHabit), ctx, habitID, userID)
 }
 
+// GetHabitWithStats mocks base method.
+func (m *MockHabitsServiceI) GetHabitWithStats(ctx context.Context, habitID, userID uuid.UUID) (*service.HabitDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHabitWithStats", ctx, habitID, userID)
+	ret0, _ := ret[0].(*service.HabitDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHabitWithStats indicates an expected call of GetHabitWithStats.
+func (mr *MockHabitsServiceIMockRecorder) GetHabitWithStats(ctx, habitID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitWithStats", reflect.TypeOf((*MockHabitsServiceI)(nil).GetHabitWithStats), ctx, habitID, userID)
+}
+
 // GetUserHabits mocks base method.
-func (m *MockHabitsServiceI) GetUserHabits(ctx context.Context, uid uuid.UUID, pagination service.PaginationOpts) ([]*entity.Habit, error) {
+func (m *MockHabitsServiceI) GetUserHabits(ctx context.Context, uid uuid.UUID, sort entity.HabitSort, pagination service.PaginationOpts) ([]*entity.Habit, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserHabits", ctx, uid, pagination)
+	ret := m.ctrl.Call(m, "GetUserHabits", ctx, uid, sort, pagination)
 	ret0, _ := ret[0].([]*entity.Habit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserHabits indicates an expected call of GetUserHabits.
-func (mr *MockHabitsServiceIMockRecorder) GetUserHabits(ctx, uid, pagination interface{}) *gomock.Call {
+func (mr *MockHabitsServiceIMockRecorder) GetUserHabits(ctx, uid, sort, pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserHabits", reflect.TypeOf((*MockHabitsServiceI)(nil).GetUserHabits), ctx, uid, sort, pagination)
+}
+
+// GetUserHabitsAfter mocks base method.
+func (m *MockHabitsServiceI) GetUserHabitsAfter(ctx context.Context, uid uuid.UUID, cursor *entity.HabitCursor, limit int) ([]*entity.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserHabitsAfter", ctx, uid, cursor, limit)
+	ret0, _ := ret[0].([]*entity.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserHabitsAfter indicates an expected call of GetUserHabitsAfter.
+func (mr *MockHabitsServiceIMockRecorder) GetUserHabitsAfter(ctx, uid, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserHabitsAfter", reflect.TypeOf((*MockHabitsServiceI)(nil).GetUserHabitsAfter), ctx, uid, cursor, limit)
+}
+
+// LastModified mocks base method.
+func (m *MockHabitsServiceI) LastModified(ctx context.Context, uid uuid.UUID) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastModified", ctx, uid)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastModified indicates an expected call of LastModified.
+func (mr *MockHabitsServiceIMockRecorder) LastModified(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastModified", reflect.TypeOf((*MockHabitsServiceI)(nil).LastModified), ctx, uid)
+}
+
+// ListTags mocks base method.
+func (m *MockHabitsServiceI) ListTags(ctx context.Context, uid uuid.UUID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", ctx, uid)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockHabitsServiceIMockRecorder) ListTags(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockHabitsServiceI)(nil).ListTags), ctx, uid)
+}
+
+// MergeHabits mocks base method.
+func (m *MockHabitsServiceI) MergeHabits(ctx context.Context, sourceID, targetID, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeHabits", ctx, sourceID, targetID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeHabits indicates an expected call of MergeHabits.
+func (mr *MockHabitsServiceIMockRecorder) MergeHabits(ctx, sourceID, targetID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeHabits", reflect.TypeOf((*MockHabitsServiceI)(nil).MergeHabits), ctx, sourceID, targetID, userID)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockHabitsServiceI) PurgeDeleted(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockHabitsServiceIMockRecorder) PurgeDeleted(ctx, olderThan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockHabitsServiceI)(nil).PurgeDeleted), ctx, olderThan)
+}
+
+// ReorderHabits mocks base method.
+func (m *MockHabitsServiceI) ReorderHabits(ctx context.Context, userID uuid.UUID, orderedIDs []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderHabits", ctx, userID, orderedIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderHabits indicates an expected call of ReorderHabits.
+func (mr *MockHabitsServiceIMockRecorder) ReorderHabits(ctx, userID, orderedIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderHabits", reflect.TypeOf((*MockHabitsServiceI)(nil).ReorderHabits), ctx, userID, orderedIDs)
+}
+
+// RestoreHabit mocks base method.
+func (m *MockHabitsServiceI) RestoreHabit(ctx context.Context, habitID, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreHabit", ctx, habitID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreHabit indicates an expected call of RestoreHabit.
+func (mr *MockHabitsServiceIMockRecorder) RestoreHabit(ctx, habitID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreHabit", reflect.TypeOf((*MockHabitsServiceI)(nil).RestoreHabit), ctx, habitID, userID)
+}
+
+// UpdateHabit mocks base method.
+func (m *MockHabitsServiceI) UpdateHabit(ctx context.Context, habitID, userID uuid.UUID, req service.UpdateHabitRequest) (*entity.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHabit", ctx, habitID, userID, req)
+	ret0, _ := ret[0].(*entity.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateHabit indicates an expected call of UpdateHabit.
+func (mr *MockHabitsServiceIMockRecorder) UpdateHabit(ctx, habitID, userID, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserHabits", reflect.TypeOf((*MockHabitsServiceI)(nil).GetUserHabits), ctx, uid, pagination)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHabit", reflect.TypeOf((*MockHabitsServiceI)(nil).UpdateHabit), ctx, habitID, userID, req)
 }
 
 // MockHabitChecksServiceI is a mock of HabitChecksServiceI interface.
@@ -217,33 +512,123 @@ func (m *MockHabitChecksServiceI) EXPECT() *MockHabitChecksServiceIMockRecorder
 	return m.recorder
 }
 
+// CanCheck mocks base method.
+func (m *MockHabitChecksServiceI) CanCheck(ctx context.Context, habitID, userID uuid.UUID, date time.Time) (bool, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanCheck", ctx, habitID, userID, date)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CanCheck indicates an expected call of CanCheck.
+func (mr *MockHabitChecksServiceIMockRecorder) CanCheck(ctx, habitID, userID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanCheck", reflect.TypeOf((*MockHabitChecksServiceI)(nil).CanCheck), ctx, habitID, userID, date)
+}
+
 // CheckHabit mocks base method.
-func (m *MockHabitChecksServiceI) CheckHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time) error {
+func (m *MockHabitChecksServiceI) CheckHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time, note string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckHabit", ctx, habitID, userID, date)
+	ret := m.ctrl.Call(m, "CheckHabit", ctx, habitID, userID, date, note)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CheckHabit indicates an expected call of CheckHabit.
-func (mr *MockHabitChecksServiceIMockRecorder) CheckHabit(ctx, habitID, userID, date interface{}) *gomock.Call {
+func (mr *MockHabitChecksServiceIMockRecorder) CheckHabit(ctx, habitID, userID, date, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckHabit", reflect.TypeOf((*MockHabitChecksServiceI)(nil).CheckHabit), ctx, habitID, userID, date, note)
+}
+
+// CheckMany mocks base method.
+func (m *MockHabitChecksServiceI) CheckMany(ctx context.Context, userID uuid.UUID, reqs []service.CheckRequest) ([]service.BatchError, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckMany", ctx, userID, reqs)
+	ret0, _ := ret[0].([]service.BatchError)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckMany indicates an expected call of CheckMany.
+func (mr *MockHabitChecksServiceIMockRecorder) CheckMany(ctx, userID, reqs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckMany", reflect.TypeOf((*MockHabitChecksServiceI)(nil).CheckMany), ctx, userID, reqs)
+}
+
+// CheckedToday mocks base method.
+func (m *MockHabitChecksServiceI) CheckedToday(ctx context.Context, userID uuid.UUID, habitIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckedToday", ctx, userID, habitIDs)
+	ret0, _ := ret[0].(map[uuid.UUID]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckedToday indicates an expected call of CheckedToday.
+func (mr *MockHabitChecksServiceIMockRecorder) CheckedToday(ctx, userID, habitIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckedToday", reflect.TypeOf((*MockHabitChecksServiceI)(nil).CheckedToday), ctx, userID, habitIDs)
+}
+
+// CountHabitChecks mocks base method.
+func (m *MockHabitChecksServiceI) CountHabitChecks(ctx context.Context, habitID, userID uuid.UUID, from, to time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountHabitChecks", ctx, habitID, userID, from, to)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountHabitChecks indicates an expected call of CountHabitChecks.
+func (mr *MockHabitChecksServiceIMockRecorder) CountHabitChecks(ctx, habitID, userID, from, to interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckHabit", reflect.TypeOf((*MockHabitChecksServiceI)(nil).CheckHabit), ctx, habitID, userID, date)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountHabitChecks", reflect.TypeOf((*MockHabitChecksServiceI)(nil).CountHabitChecks), ctx, habitID, userID, from, to)
+}
+
+// ExportChecks mocks base method.
+func (m *MockHabitChecksServiceI) ExportChecks(ctx context.Context, userID uuid.UUID, fn func(*entity.HabitCheck) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportChecks", ctx, userID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportChecks indicates an expected call of ExportChecks.
+func (mr *MockHabitChecksServiceIMockRecorder) ExportChecks(ctx, userID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportChecks", reflect.TypeOf((*MockHabitChecksServiceI)(nil).ExportChecks), ctx, userID, fn)
+}
+
+// GetAtRiskHabits mocks base method.
+func (m *MockHabitChecksServiceI) GetAtRiskHabits(ctx context.Context, userID uuid.UUID) ([]*entity.Habit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAtRiskHabits", ctx, userID)
+	ret0, _ := ret[0].([]*entity.Habit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAtRiskHabits indicates an expected call of GetAtRiskHabits.
+func (mr *MockHabitChecksServiceIMockRecorder) GetAtRiskHabits(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAtRiskHabits", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetAtRiskHabits), ctx, userID)
 }
 
 // GetHabitChecks mocks base method.
-func (m *MockHabitChecksServiceI) GetHabitChecks(ctx context.Context, habitID, userID uuid.UUID, from, to time.Time) ([]entity.HabitCheck, error) {
+func (m *MockHabitChecksServiceI) GetHabitChecks(ctx context.Context, habitID, userID uuid.UUID, from, to time.Time, order entity.CheckOrder, status entity.CheckStatus) ([]entity.HabitCheck, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetHabitChecks", ctx, habitID, userID, from, to)
+	ret := m.ctrl.Call(m, "GetHabitChecks", ctx, habitID, userID, from, to, order, status)
 	ret0, _ := ret[0].([]entity.HabitCheck)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetHabitChecks indicates an expected call of GetHabitChecks.
-func (mr *MockHabitChecksServiceIMockRecorder) GetHabitChecks(ctx, habitID, userID, from, to interface{}) *gomock.Call {
+func (mr *MockHabitChecksServiceIMockRecorder) GetHabitChecks(ctx, habitID, userID, from, to, order, status interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitChecks", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetHabitChecks), ctx, habitID, userID, from, to)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitChecks", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetHabitChecks), ctx, habitID, userID, from, to, order, status)
 }
 
 // GetHabitStats mocks base method.
@@ -261,6 +646,169 @@ func (mr *MockHabitChecksServiceIMockRecorder) GetHabitStats(ctx, habitID, userI
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHabitStats", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetHabitStats), ctx, habitID, userID)
 }
 
+// GetLastCheck mocks base method.
+func (m *MockHabitChecksServiceI) GetLastCheck(ctx context.Context, habitID, userID uuid.UUID) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastCheck", ctx, habitID, userID)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastCheck indicates an expected call of GetLastCheck.
+func (mr *MockHabitChecksServiceIMockRecorder) GetLastCheck(ctx, habitID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastCheck", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetLastCheck), ctx, habitID, userID)
+}
+
+// GetMonthlyChecks mocks base method.
+func (m *MockHabitChecksServiceI) GetMonthlyChecks(ctx context.Context, habitID, userID uuid.UUID, year int) (map[time.Month][]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMonthlyChecks", ctx, habitID, userID, year)
+	ret0, _ := ret[0].(map[time.Month][]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMonthlyChecks indicates an expected call of GetMonthlyChecks.
+func (mr *MockHabitChecksServiceIMockRecorder) GetMonthlyChecks(ctx, habitID, userID, year interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMonthlyChecks", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetMonthlyChecks), ctx, habitID, userID, year)
+}
+
+// GetStatsForHabit mocks base method.
+func (m *MockHabitChecksServiceI) GetStatsForHabit(ctx context.Context, habit *entity.Habit) (*entity.HabitStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatsForHabit", ctx, habit)
+	ret0, _ := ret[0].(*entity.HabitStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatsForHabit indicates an expected call of GetStatsForHabit.
+func (mr *MockHabitChecksServiceIMockRecorder) GetStatsForHabit(ctx, habit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatsForHabit", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetStatsForHabit), ctx, habit)
+}
+
+// GetUserChecksOn mocks base method.
+func (m *MockHabitChecksServiceI) GetUserChecksOn(ctx context.Context, userID uuid.UUID, date time.Time) ([]entity.UserCheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserChecksOn", ctx, userID, date)
+	ret0, _ := ret[0].([]entity.UserCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserChecksOn indicates an expected call of GetUserChecksOn.
+func (mr *MockHabitChecksServiceIMockRecorder) GetUserChecksOn(ctx, userID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserChecksOn", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetUserChecksOn), ctx, userID, date)
+}
+
+// GetUserSummary mocks base method.
+func (m *MockHabitChecksServiceI) GetUserSummary(ctx context.Context, userID uuid.UUID) (*entity.UserSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSummary", ctx, userID)
+	ret0, _ := ret[0].(*entity.UserSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSummary indicates an expected call of GetUserSummary.
+func (mr *MockHabitChecksServiceIMockRecorder) GetUserSummary(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSummary", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetUserSummary), ctx, userID)
+}
+
+// GetWeekdayDistribution mocks base method.
+func (m *MockHabitChecksServiceI) GetWeekdayDistribution(ctx context.Context, habitID, userID uuid.UUID) ([7]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWeekdayDistribution", ctx, habitID, userID)
+	ret0, _ := ret[0].([7]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWeekdayDistribution indicates an expected call of GetWeekdayDistribution.
+func (mr *MockHabitChecksServiceIMockRecorder) GetWeekdayDistribution(ctx, habitID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWeekdayDistribution", reflect.TypeOf((*MockHabitChecksServiceI)(nil).GetWeekdayDistribution), ctx, habitID, userID)
+}
+
+// PauseHabit mocks base method.
+func (m *MockHabitChecksServiceI) PauseHabit(ctx context.Context, habitID, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseHabit", ctx, habitID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PauseHabit indicates an expected call of PauseHabit.
+func (mr *MockHabitChecksServiceIMockRecorder) PauseHabit(ctx, habitID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseHabit", reflect.TypeOf((*MockHabitChecksServiceI)(nil).PauseHabit), ctx, habitID, userID)
+}
+
+// RecentAdherence mocks base method.
+func (m *MockHabitChecksServiceI) RecentAdherence(ctx context.Context, habitID, userID uuid.UUID, days int) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecentAdherence", ctx, habitID, userID, days)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RecentAdherence indicates an expected call of RecentAdherence.
+func (mr *MockHabitChecksServiceIMockRecorder) RecentAdherence(ctx, habitID, userID, days interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecentAdherence", reflect.TypeOf((*MockHabitChecksServiceI)(nil).RecentAdherence), ctx, habitID, userID, days)
+}
+
+// ResumeHabit mocks base method.
+func (m *MockHabitChecksServiceI) ResumeHabit(ctx context.Context, habitID, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeHabit", ctx, habitID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeHabit indicates an expected call of ResumeHabit.
+func (mr *MockHabitChecksServiceIMockRecorder) ResumeHabit(ctx, habitID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeHabit", reflect.TypeOf((*MockHabitChecksServiceI)(nil).ResumeHabit), ctx, habitID, userID)
+}
+
+// SkipHabit mocks base method.
+func (m *MockHabitChecksServiceI) SkipHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SkipHabit", ctx, habitID, userID, date)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SkipHabit indicates an expected call of SkipHabit.
+func (mr *MockHabitChecksServiceIMockRecorder) SkipHabit(ctx, habitID, userID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SkipHabit", reflect.TypeOf((*MockHabitChecksServiceI)(nil).SkipHabit), ctx, habitID, userID, date)
+}
+
+// Today mocks base method.
+func (m *MockHabitChecksServiceI) Today(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Today", ctx, userID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Today indicates an expected call of Today.
+func (mr *MockHabitChecksServiceIMockRecorder) Today(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Today", reflect.TypeOf((*MockHabitChecksServiceI)(nil).Today), ctx, userID)
+}
+
 // UncheckHabit mocks base method.
 func (m *MockHabitChecksServiceI) UncheckHabit(ctx context.Context, habitID, userID uuid.UUID, date time.Time) error {
 	m.ctrl.T.Helper()
@@ -274,3 +822,18 @@ func (mr *MockHabitChecksServiceIMockRecorder) UncheckHabit(ctx, habitID, userID
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UncheckHabit", reflect.TypeOf((*MockHabitChecksServiceI)(nil).UncheckHabit), ctx, habitID, userID, date)
 }
+
+// UpsertCheck mocks base method.
+func (m *MockHabitChecksServiceI) UpsertCheck(ctx context.Context, habitID, userID uuid.UUID, date time.Time, note string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCheck", ctx, habitID, userID, date, note)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertCheck indicates an expected call of UpsertCheck.
+func (mr *MockHabitChecksServiceIMockRecorder) UpsertCheck(ctx, habitID, userID, date, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCheck", reflect.TypeOf((*MockHabitChecksServiceI)(nil).UpsertCheck), ctx, habitID, userID, date, note)
+}
@@ -3,58 +3,110 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"strconv"
+	"strings"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	errorvalues "github.com/limbo/discipline/internal/error_values"
 	"github.com/limbo/discipline/internal/repository"
+	"github.com/limbo/discipline/pkg/cache"
 	"github.com/limbo/discipline/pkg/entity"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService struct {
 	repo repository.UsersRepositoryI
+	// Users by id, looked up on every authorized request. Nil if caching is off
+	cache cache.Cache[uuid.UUID, entity.User]
+	// Lowercased names users can't take
+	reserved map[string]struct{}
+	// Bcrypt cost of new hashes, weaker stored ones are upgraded on login
+	hashCost int
 }
 
+// Names colliding with routes or implying privilege, reserved unless SetReservedNames is called
+var DefaultReservedNames = []string{"admin", "administrator", "api", "auth", "me", "root", "support", "system"}
+
 func NewUserService(usersRepo repository.UsersRepositoryI) *UserService {
+	return NewUserServiceWithCache(usersRepo, nil)
+}
+
+// Creates service with GetByID results cached in userCache.
+// If userCache is nil, every lookup goes to repository.
+func NewUserServiceWithCache(usersRepo repository.UsersRepositoryI, userCache cache.Cache[uuid.UUID, entity.User]) *UserService {
 	if usersRepo == nil {
 		log.Fatal("provided nil usersRepo")
 	}
-	return &UserService{
-		repo: usersRepo,
+	us := &UserService{
+		repo:     usersRepo,
+		cache:    userCache,
+		hashCost: bcrypt.DefaultCost,
 	}
+	us.SetReservedNames(DefaultReservedNames...)
+	return us
 }
 
-func (us *UserService) Register(ctx context.Context, req *RegisterRequest) (*entity.User, error) {
-	err := validate.Struct(*req)
-	if err != nil {
-		if validationError, ok := err.(validator.ValidationErrors); ok {
-			err = errors.New("validation error: ")
-			for _, fieldErr := range validationError {
-				err = errors.Join(err, fieldErr)
-			}
-			return nil, err
+// Replaces set of names users can't register or change name to. Names are compared case-insensitively.
+// Must be called before service is used.
+func (us *UserService) SetReservedNames(names ...string) {
+	us.reserved = make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			us.reserved[strings.ToLower(name)] = struct{}{}
 		}
-		return nil, errors.New("validation unexpected error: " + err.Error())
 	}
-	passwordHash, err := Hash(req.Password)
+}
+
+// Replaces bcrypt cost of password hashes (bcrypt.DefaultCost by default), values out of
+// bcrypt.MinCost..bcrypt.MaxCost are ignored. Must be called before service is used.
+func (us *UserService) SetHashCost(cost int) {
+	if cost >= bcrypt.MinCost && cost <= bcrypt.MaxCost {
+		us.hashCost = cost
+	}
+}
+
+// Reports if name is reserved, case-insensitively.
+func (us *UserService) IsReservedName(name string) bool {
+	_, ok := us.reserved[strings.ToLower(name)]
+	return ok
+}
+
+// Returns errorvalues.ErrValidation if name is reserved.
+func (us *UserService) validateNotReserved(name string) error {
+	if us.IsReservedName(name) {
+		return errors.Join(errorvalues.ErrValidation, errors.New("name "+strconv.Quote(name)+" is reserved"))
+	}
+	return nil
+}
+
+func (us *UserService) Register(ctx context.Context, req *RegisterRequest) (*entity.User, error) {
+	err := ValidateUsername(req.Name)
 	if err != nil {
-		return nil, errors.New("hashing password error: " + err.Error())
+		return nil, err
+	}
+	if err = validateStruct(*req); err != nil {
+		return nil, err
+	}
+	if err = us.validateNotReserved(req.Name); err != nil {
+		return nil, err
+	}
+	passwordHash, err := HashWithCost(req.Password, us.hashCost)
+	if err != nil {
+		return nil, fmt.Errorf("hashing password error: %w", err)
 	}
 	err = us.repo.Create(ctx, &entity.User{
 		Name:         req.Name,
 		PasswordHash: passwordHash,
 	})
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrUserExists) {
-			return nil, errorvalues.ErrUserExists
-		}
-		return nil, errors.New("repository creating error: " + err.Error())
+		return nil, wrapError(err, "repository creating error", errorvalues.ErrUserExists, errorvalues.ErrValidation)
 	}
 	user, err := us.repo.FindByName(ctx, req.Name)
 	if err != nil {
-		return nil, errors.New("repository searching error: " + err.Error())
+		return nil, fmt.Errorf("repository searching error: %w", err)
 	}
 	return user, nil
 }
@@ -62,57 +114,184 @@ func (us *UserService) Register(ctx context.Context, req *RegisterRequest) (*ent
 func (us *UserService) Login(ctx context.Context, name, password string) (*entity.User, error) {
 	user, err := us.repo.FindByName(ctx, name)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrUserNotFound) {
-			return nil, errorvalues.ErrUserNotFound
-		}
-		return nil, errors.New("repository searching error: " + err.Error())
+		return nil, wrapError(err, "repository searching error", errorvalues.ErrUserNotFound)
 	}
 	if err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
 		return nil, errorvalues.ErrWrongCredentials
 	}
+	// Checked after password, so suspension isn't revealed to ones who don't know it
+	if user.Disabled {
+		return nil, errorvalues.ErrAccountDisabled
+	}
+	us.upgradeHash(ctx, user, password)
+	// Login shouldn't fail because of activity tracking
+	if err = us.repo.TouchLastLogin(ctx, user.ID); err != nil {
+		slog.Warn("updating last login time error", slog.String("uid", user.ID.String()), slog.String("error", err.Error()))
+	}
+	us.invalidate(user.ID)
 	return user, nil
 }
 
+// Re-hashes password of user if stored hash is weaker than configured cost.
+// Must be called with password already compared to hash. Login shouldn't fail because of upgrade,
+// so errors are only logged and user keeps old hash.
+func (us *UserService) upgradeHash(ctx context.Context, user *entity.User, password string) {
+	cost, err := bcrypt.Cost([]byte(user.PasswordHash))
+	if err != nil || cost >= us.hashCost {
+		return
+	}
+	hash, err := HashWithCost(password, us.hashCost)
+	if err != nil {
+		slog.Warn("upgrading password hash error", slog.String("uid", user.ID.String()), slog.String("error", err.Error()))
+		return
+	}
+	// Only hash is written, so concurrent rename isn't reverted with stale name
+	if err = us.repo.UpdatePasswordHash(ctx, user.ID, hash); err != nil {
+		slog.Warn("upgrading password hash error", slog.String("uid", user.ID.String()), slog.String("error", err.Error()))
+		return
+	}
+	user.PasswordHash = hash
+}
+
 func (us *UserService) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	if us.cache != nil {
+		// Copy is kept in cache, so callers can't modify it
+		if user, ok := us.cache.Get(id); ok {
+			return &user, nil
+		}
+	}
 	user, err := us.repo.FindByID(ctx, id)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrUserNotFound) {
-			return nil, errorvalues.ErrUserNotFound
-		}
-		return nil, errors.New("repository searching error: " + err.Error())
+		return nil, wrapError(err, "repository searching error", errorvalues.ErrUserNotFound)
+	}
+	if us.cache != nil {
+		us.cache.Set(id, *user)
 	}
 	return user, nil
 }
 
+// Drops cached user data, must be called after any change of user
+func (us *UserService) invalidate(id uuid.UUID) {
+	if us.cache != nil {
+		us.cache.Delete(id)
+	}
+}
+
 func (us *UserService) GetByName(ctx context.Context, name string) (*entity.User, error) {
 	user, err := us.repo.FindByName(ctx, name)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrUserNotFound) {
-			return nil, errorvalues.ErrUserNotFound
-		}
-		return nil, errors.New("repository searching error: " + err.Error())
+		return nil, wrapError(err, "repository searching error", errorvalues.ErrUserNotFound)
 	}
 	return user, nil
 }
 
+func (us *UserService) ChangeUsername(ctx context.Context, id uuid.UUID, newName string) error {
+	if err := ValidateUsername(newName); err != nil {
+		return err
+	}
+	if err := us.validateNotReserved(newName); err != nil {
+		return err
+	}
+	err := us.repo.UpdateName(ctx, id, newName)
+	us.invalidate(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, errorvalues.ErrUserExists), errors.Is(err, errorvalues.ErrUserNotFound),
+			errors.Is(err, errorvalues.ErrValidation):
+			return err
+		}
+		return fmt.Errorf("repository updating error: %w", err)
+	}
+	return nil
+}
+
+func (us *UserService) SetTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	if err := validateVar(timezone, "required,timezone"); err != nil {
+		return err
+	}
+	err := us.repo.UpdateTimezone(ctx, id, timezone)
+	us.invalidate(id)
+	if err != nil {
+		return wrapError(err, "repository updating error", errorvalues.ErrUserNotFound)
+	}
+	return nil
+}
+
+func (us *UserService) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	err := us.repo.SetActive(ctx, id, active)
+	us.invalidate(id)
+	if err != nil {
+		return wrapError(err, "repository updating error", errorvalues.ErrUserNotFound)
+	}
+	return nil
+}
+
+func (us *UserService) RevokeSessions(ctx context.Context, id uuid.UUID) error {
+	_, err := us.repo.BumpTokenVersion(ctx, id)
+	// Cached user carries old version, revoked tokens would pass until it expires
+	us.invalidate(id)
+	if err != nil {
+		return wrapError(err, "repository updating error", errorvalues.ErrUserNotFound)
+	}
+	return nil
+}
+
 func (us *UserService) DeleteAccount(ctx context.Context, id uuid.UUID, password string) error {
 	user, err := us.repo.FindByID(ctx, id)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrUserNotFound) {
-			return errorvalues.ErrUserNotFound
-		}
-		return errors.New("repository searching error: " + err.Error())
+		return wrapError(err, "repository searching error", errorvalues.ErrUserNotFound)
 	}
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		return errors.New("deletion failed: wrong password")
+		return errorvalues.ErrWrongCredentials
 	}
 	err = us.repo.Delete(ctx, user.ID)
+	us.invalidate(user.ID)
 	if err != nil {
-		if errors.Is(err, errorvalues.ErrUserNotFound) {
-			return errorvalues.ErrUserNotFound
-		}
-		return errors.New("repository deletion error: " + err.Error())
+		return wrapError(err, "repository deletion error", errorvalues.ErrUserNotFound)
 	}
 	return nil
 }
+
+func (us *UserService) EraseAccount(ctx context.Context, id uuid.UUID, password string) (*entity.ErasureSummary, error) {
+	user, err := us.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, wrapError(err, "repository searching error", errorvalues.ErrUserNotFound)
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		return nil, errorvalues.ErrWrongCredentials
+	}
+	summary, err := us.repo.Erase(ctx, user.ID)
+	us.invalidate(user.ID)
+	if err != nil {
+		return nil, wrapError(err, "repository erasing error", errorvalues.ErrUserNotFound)
+	}
+	return summary, nil
+}
+
+func (us *UserService) PreviewDeletion(ctx context.Context, id uuid.UUID) (*entity.ErasureSummary, error) {
+	summary, err := us.repo.CountErasure(ctx, id)
+	if err != nil {
+		return nil, wrapError(err, "repository counting error", errorvalues.ErrUserNotFound)
+	}
+	return summary, nil
+}
+
+func (us *UserService) ListUsers(ctx context.Context, query string, sort entity.UserSort, pagination PaginationOpts) ([]*entity.User, int, error) {
+	if err := validateVar(string(sort), "omitempty,oneof=name -name created_at -created_at"); err != nil {
+		return nil, 0, err
+	}
+	if sort == "" {
+		sort = entity.UserSortName
+	}
+	users, err := us.repo.List(ctx, query, sort, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository listing error: %w", err)
+	}
+	total, err := us.repo.Count(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("repository counting error: %w", err)
+	}
+	return users, total, nil
+}
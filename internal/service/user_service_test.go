@@ -3,16 +3,24 @@ package service_test
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
+	errorvalues "github.com/limbo/discipline/internal/error_values"
 	"github.com/limbo/discipline/internal/repository"
+	"github.com/limbo/discipline/internal/repository/mocks"
 	"github.com/limbo/discipline/internal/service"
+	"github.com/limbo/discipline/pkg/cache"
 	"github.com/limbo/discipline/pkg/entity"
 	"github.com/pressly/goose"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -25,7 +33,7 @@ func TestUserServiceIntegrational(t *testing.T) {
 	us := service.NewUserService(repo)
 	ctx := context.Background()
 	username := "test_user"
-	password := "test_password"
+	password := "test_password1"
 	var user *entity.User
 	var err error
 	t.Run("registered user", func(t *testing.T) {
@@ -56,6 +64,9 @@ func TestUserServiceIntegrational(t *testing.T) {
 	t.Run("found by name", func(t *testing.T) {
 		res, err := us.GetByName(ctx, username)
 		assert.NoError(t, err)
+		// Login has been made before
+		assert.NotNil(t, res.LastLoginAt)
+		user.LastLoginAt = res.LastLoginAt
 		assert.Equal(t, *user, *res)
 	})
 	t.Run("not found by name", func(t *testing.T) {
@@ -85,6 +96,338 @@ func TestUserServiceIntegrational(t *testing.T) {
 	})
 }
 
+func TestLoginTouchesLastLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	// Hash below is of actual cost, so it isn't upgraded
+	us.SetHashCost(bcrypt.MinCost)
+	password := "test_password1"
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &entity.User{
+		ID:           uuid.New(),
+		Name:         "test_user",
+		PasswordHash: string(hash),
+	}
+	ctx := context.Background()
+	t.Run("touched", func(t *testing.T) {
+		repo.EXPECT().FindByName(gomock.Any(), user.Name).Return(user, nil)
+		repo.EXPECT().TouchLastLogin(gomock.Any(), user.ID).Return(nil)
+		res, err := us.Login(ctx, user.Name, password)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, res.ID)
+	})
+	t.Run("touch error doesn't fail login", func(t *testing.T) {
+		repo.EXPECT().FindByName(gomock.Any(), user.Name).Return(user, nil)
+		repo.EXPECT().TouchLastLogin(gomock.Any(), user.ID).Return(errors.New("db error"))
+		res, err := us.Login(ctx, user.Name, password)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, res.ID)
+	})
+	t.Run("not touched on wrong password", func(t *testing.T) {
+		repo.EXPECT().FindByName(gomock.Any(), user.Name).Return(user, nil)
+		_, err := us.Login(ctx, user.Name, "wrong_password")
+		assert.ErrorIs(t, err, errorvalues.ErrWrongCredentials)
+	})
+}
+
+func TestLoginDisabledAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	us.SetHashCost(bcrypt.MinCost)
+	password := "test_password1"
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &entity.User{
+		ID:           uuid.New(),
+		Name:         "test_user",
+		PasswordHash: string(hash),
+		Disabled:     true,
+	}
+	ctx := context.Background()
+	t.Run("rejected", func(t *testing.T) {
+		// Last login isn't touched as well
+		repo.EXPECT().FindByName(gomock.Any(), user.Name).Return(user, nil)
+		_, err := us.Login(ctx, user.Name, password)
+		assert.ErrorIs(t, err, errorvalues.ErrAccountDisabled)
+	})
+	t.Run("wrong password isn't told disabled", func(t *testing.T) {
+		repo.EXPECT().FindByName(gomock.Any(), user.Name).Return(user, nil)
+		_, err := us.Login(ctx, user.Name, "wrong_password")
+		assert.ErrorIs(t, err, errorvalues.ErrWrongCredentials)
+	})
+}
+
+func TestSetActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	id := uuid.New()
+	ctx := context.Background()
+	t.Run("disabled", func(t *testing.T) {
+		repo.EXPECT().SetActive(gomock.Any(), id, false).Return(nil)
+		assert.NoError(t, us.SetActive(ctx, id, false))
+	})
+	t.Run("not found", func(t *testing.T) {
+		repo.EXPECT().SetActive(gomock.Any(), id, false).Return(errorvalues.ErrUserNotFound)
+		assert.ErrorIs(t, us.SetActive(ctx, id, false), errorvalues.ErrUserNotFound)
+	})
+}
+
+func TestRevokeSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	id := uuid.New()
+	ctx := context.Background()
+	t.Run("revoked", func(t *testing.T) {
+		repo.EXPECT().BumpTokenVersion(gomock.Any(), id).Return(1, nil)
+		assert.NoError(t, us.RevokeSessions(ctx, id))
+	})
+	t.Run("not found", func(t *testing.T) {
+		repo.EXPECT().BumpTokenVersion(gomock.Any(), id).Return(0, errorvalues.ErrUserNotFound)
+		assert.ErrorIs(t, us.RevokeSessions(ctx, id), errorvalues.ErrUserNotFound)
+	})
+}
+
+func TestLoginUpgradesHashCost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	us.SetHashCost(bcrypt.MinCost + 1)
+	password := "test_password1"
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	newUser := func() *entity.User {
+		return &entity.User{ID: uuid.New(), Name: "test_user", PasswordHash: string(hash)}
+	}
+	ctx := context.Background()
+	t.Run("upgraded", func(t *testing.T) {
+		user := newUser()
+		repo.EXPECT().FindByName(gomock.Any(), user.Name).Return(user, nil)
+		repo.EXPECT().UpdatePasswordHash(gomock.Any(), user.ID, gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, upgraded string) error {
+			cost, err := bcrypt.Cost([]byte(upgraded))
+			require.NoError(t, err)
+			assert.Equal(t, bcrypt.MinCost+1, cost)
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(upgraded), []byte(password)))
+			return nil
+		})
+		repo.EXPECT().TouchLastLogin(gomock.Any(), user.ID).Return(nil)
+		res, err := us.Login(ctx, user.Name, password)
+		assert.NoError(t, err)
+		assert.NotEqual(t, string(hash), res.PasswordHash)
+	})
+	t.Run("update error doesn't fail login", func(t *testing.T) {
+		user := newUser()
+		repo.EXPECT().FindByName(gomock.Any(), user.Name).Return(user, nil)
+		repo.EXPECT().UpdatePasswordHash(gomock.Any(), user.ID, gomock.Any()).Return(errors.New("db error"))
+		repo.EXPECT().TouchLastLogin(gomock.Any(), user.ID).Return(nil)
+		res, err := us.Login(ctx, user.Name, password)
+		assert.NoError(t, err)
+		assert.Equal(t, string(hash), res.PasswordHash)
+	})
+	t.Run("not upgraded on wrong password", func(t *testing.T) {
+		user := newUser()
+		repo.EXPECT().FindByName(gomock.Any(), user.Name).Return(user, nil)
+		_, err := us.Login(ctx, user.Name, "wrong_password")
+		assert.ErrorIs(t, err, errorvalues.ErrWrongCredentials)
+	})
+}
+
+func TestRegisterPasswordPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	ctx := context.Background()
+	t.Run("all digits", func(t *testing.T) {
+		_, err := us.Register(ctx, &service.RegisterRequest{Name: "test_user", Password: "1234567890"})
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
+		assert.ErrorContains(t, err, "at least one letter")
+	})
+	t.Run("all letters", func(t *testing.T) {
+		_, err := us.Register(ctx, &service.RegisterRequest{Name: "test_user", Password: "password"})
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
+		assert.ErrorContains(t, err, "at least one digit")
+	})
+	t.Run("compliant", func(t *testing.T) {
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().FindByName(gomock.Any(), "test_user").Return(&entity.User{ID: uuid.New(), Name: "test_user"}, nil)
+		_, err := us.Register(ctx, &service.RegisterRequest{Name: "test_user", Password: "passw0rd"})
+		assert.NoError(t, err)
+	})
+}
+
+func TestRegisterReservedName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	ctx := context.Background()
+	t.Run("reserved rejected", func(t *testing.T) {
+		for _, name := range []string{"admin", "Admin"} {
+			_, err := us.Register(ctx, &service.RegisterRequest{Name: name, Password: "passw0rd"})
+			assert.ErrorIs(t, err, errorvalues.ErrValidation)
+			assert.ErrorContains(t, err, "is reserved")
+		}
+	})
+	t.Run("similar allowed", func(t *testing.T) {
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().FindByName(gomock.Any(), "admin2").Return(&entity.User{ID: uuid.New(), Name: "admin2"}, nil)
+		_, err := us.Register(ctx, &service.RegisterRequest{Name: "admin2", Password: "passw0rd"})
+		assert.NoError(t, err)
+	})
+	t.Run("custom list", func(t *testing.T) {
+		custom := service.NewUserService(repo)
+		custom.SetReservedNames("moderator")
+		assert.True(t, custom.IsReservedName("MODERATOR"))
+		assert.False(t, custom.IsReservedName("admin"))
+	})
+}
+
+func TestUsernameRulesShared(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	ctx := context.Background()
+	uid := uuid.New()
+	invalid := map[string]string{
+		"too short":              "ab",
+		"too long":               strings.Repeat("a", service.MaxUsernameLength+1),
+		"starts with digit":      "1user",
+		"forbidden symbols":      "user-name",
+		"starts with underscore": "_user",
+	}
+	for desc, name := range invalid {
+		t.Run(desc, func(t *testing.T) {
+			assert.ErrorIs(t, service.ValidateUsername(name), errorvalues.ErrValidation)
+			_, err := us.Register(ctx, &service.RegisterRequest{Name: name, Password: "passw0rd"})
+			assert.ErrorIs(t, err, errorvalues.ErrValidation)
+			assert.ErrorIs(t, us.ChangeUsername(ctx, uid, name), errorvalues.ErrValidation)
+		})
+	}
+	t.Run("longest allowed on both paths", func(t *testing.T) {
+		name := strings.Repeat("a", service.MaxUsernameLength)
+		assert.NoError(t, service.ValidateUsername(name))
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().FindByName(gomock.Any(), name).Return(&entity.User{ID: uid, Name: name}, nil)
+		_, err := us.Register(ctx, &service.RegisterRequest{Name: name, Password: "passw0rd"})
+		assert.NoError(t, err)
+		repo.EXPECT().UpdateName(gomock.Any(), uid, name).Return(nil)
+		assert.NoError(t, us.ChangeUsername(ctx, uid, name))
+	})
+}
+
+func TestSetTimezone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	ctx := context.Background()
+	uid := uuid.New()
+	t.Run("set", func(t *testing.T) {
+		repo.EXPECT().UpdateTimezone(gomock.Any(), uid, "Asia/Vladivostok").Return(nil)
+		assert.NoError(t, us.SetTimezone(ctx, uid, "Asia/Vladivostok"))
+	})
+	t.Run("unknown timezone", func(t *testing.T) {
+		err := us.SetTimezone(ctx, uid, "Mars/Olympus_Mons")
+		assert.ErrorIs(t, err, errorvalues.ErrValidation)
+	})
+}
+
+func TestPreviewDeletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	ctx := context.Background()
+	id := uuid.New()
+	t.Run("counted", func(t *testing.T) {
+		// User with 3 habits checked 4, 4 and 2 times
+		repo.EXPECT().CountErasure(gomock.Any(), id).Return(&entity.ErasureSummary{Users: 1, Habits: 3, Checks: 10}, nil)
+		res, err := us.PreviewDeletion(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), res.Habits)
+		assert.Equal(t, int64(10), res.Checks)
+	})
+	t.Run("not found", func(t *testing.T) {
+		repo.EXPECT().CountErasure(gomock.Any(), id).Return(nil, errorvalues.ErrUserNotFound)
+		_, err := us.PreviewDeletion(ctx, id)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+	t.Run("repo error", func(t *testing.T) {
+		repo.EXPECT().CountErasure(gomock.Any(), id).Return(nil, errors.New("db error"))
+		_, err := us.PreviewDeletion(ctx, id)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+}
+
+func TestEraseAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	ctx := context.Background()
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("test_password1"), bcrypt.MinCost)
+	user := &entity.User{ID: uuid.New(), Name: "test_user", PasswordHash: string(passwordHash)}
+	t.Run("erased", func(t *testing.T) {
+		summary := &entity.ErasureSummary{Users: 1, Habits: 3, Checks: 10}
+		repo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		repo.EXPECT().Erase(gomock.Any(), user.ID).Return(summary, nil)
+		res, err := us.EraseAccount(ctx, user.ID, "test_password1")
+		assert.NoError(t, err)
+		assert.Equal(t, summary, res)
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		_, err := us.EraseAccount(ctx, user.ID, "wrong_password1")
+		assert.ErrorIs(t, err, errorvalues.ErrWrongCredentials)
+	})
+}
+
+func TestUserSentinelsPropagate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserService(repo)
+	id := uuid.New()
+	repo.EXPECT().FindByID(gomock.Any(), id).Return(nil, fmt.Errorf("tx error: %w", errorvalues.ErrUserNotFound))
+	_, err := us.GetByID(context.Background(), id)
+	assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+}
+
+func TestGetByIDCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockUsersRepositoryI(ctrl)
+	us := service.NewUserServiceWithCache(repo, cache.NewMemory[uuid.UUID, entity.User](time.Minute))
+	ctx := context.Background()
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("test_password1"), bcrypt.MinCost)
+	user := &entity.User{ID: uuid.New(), Name: "test_user", PasswordHash: string(passwordHash)}
+	t.Run("second lookup within ttl cached", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		for range 2 {
+			res, err := us.GetByID(ctx, user.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, user.Name, res.Name)
+		}
+	})
+	t.Run("revoking sessions busts cache", func(t *testing.T) {
+		repo.EXPECT().BumpTokenVersion(gomock.Any(), user.ID).Return(1, nil)
+		require.NoError(t, us.RevokeSessions(ctx, user.ID))
+		revoked := *user
+		revoked.TokenVersion = 1
+		repo.EXPECT().FindByID(gomock.Any(), user.ID).Return(&revoked, nil)
+		res, err := us.GetByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, res.TokenVersion)
+	})
+	t.Run("deletion busts cache", func(t *testing.T) {
+		repo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		repo.EXPECT().Delete(gomock.Any(), user.ID).Return(nil)
+		require.NoError(t, us.DeleteAccount(ctx, user.ID, "test_password1"))
+		repo.EXPECT().FindByID(gomock.Any(), user.ID).Return(nil, errorvalues.ErrUserNotFound)
+		_, err := us.GetByID(ctx, user.ID)
+		assert.ErrorIs(t, err, errorvalues.ErrUserNotFound)
+	})
+}
+
 func TestMain(m *testing.M) {
 	service.InitValidator()
 	m.Run()
@@ -1,11 +1,31 @@
 package service
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 func Hash(value string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(value), bcrypt.DefaultCost)
+	return HashWithCost(value, bcrypt.DefaultCost)
+}
+
+func HashWithCost(value string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(value), cost)
 	if err != nil {
 		return "", err
 	}
 	return string(hash), nil
 }
+
+// Returns err as is if it is one of expected errors (errorvalues sentinels callers respond with),
+// so it isn't prefixed with internal context, otherwise wraps it with msg keeping it unwrappable.
+func wrapError(err error, msg string, expected ...error) error {
+	for _, target := range expected {
+		if errors.Is(err, target) {
+			return err
+		}
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
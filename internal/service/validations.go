@@ -1,18 +1,79 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
 	"sync"
 	"unicode"
 
 	"github.com/go-playground/validator/v10"
+	errorvalues "github.com/limbo/discipline/internal/error_values"
 )
 
 // Package for custom validations
 var (
 	validate *validator.Validate
 	once     sync.Once
+
+	hexColorRegexp = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+	passwordPolicy = PasswordPolicy{RequireDigit: true, RequireLetter: true}
+
+	usernameMaxLength = MaxUsernameLength
 )
 
+// Longest name users table can store (users.name is VARCHAR(100))
+const MaxUsernameLength = 100
+
+// Lowers maximum username length, values out of 3..MaxUsernameLength are ignored.
+// Must be called before service is used.
+func SetUsernameMaxLength(n int) {
+	if n >= 3 && n <= MaxUsernameLength {
+		usernameMaxLength = n
+	}
+}
+
+// Checks name against rules shared by registration and renaming: 3 to max length
+// letters, digits or underscores, not starting with digit or underscore.
+// If some rule is unmet, returns errorvalues.ErrValidation
+func ValidateUsername(name string) error {
+	return validateVar(name, "required,alphanum_underscore,min=3,max="+strconv.Itoa(usernameMaxLength))
+}
+
+// Requirements checked by strong_password validation
+type PasswordPolicy struct {
+	RequireDigit  bool
+	RequireLetter bool
+}
+
+// Replaces default password policy (digit and letter required).
+// Must be called before InitValidator.
+func SetPasswordPolicy(p PasswordPolicy) {
+	passwordPolicy = p
+}
+
+// Returns description of first requirement password doesn't meet, empty if ok
+func (p PasswordPolicy) unmet(password string) string {
+	var hasDigit, hasLetter bool
+	for _, char := range password {
+		switch {
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsLetter(char):
+			hasLetter = true
+		}
+	}
+	switch {
+	case p.RequireDigit && !hasDigit:
+		return "password must contain at least one digit"
+	case p.RequireLetter && !hasLetter:
+		return "password must contain at least one letter"
+	}
+	return ""
+}
+
 func InitValidator() {
 	once.Do(func() {
 		validate = validator.New()
@@ -30,5 +91,42 @@ func InitValidator() {
 			}
 			return true
 		})
+		validate.RegisterValidation("strong_password", func(fl validator.FieldLevel) bool {
+			return passwordPolicy.unmet(fl.Field().String()) == ""
+		})
+		// Color in #RRGGBB format
+		validate.RegisterValidation("hex_color", func(fl validator.FieldLevel) bool {
+			return hexColorRegexp.MatchString(fl.Field().String())
+		})
 	})
 }
+
+// Validates struct by its tags. If some rules are unmet, returns
+// errorvalues.ErrValidation joined with description of each failed field
+func validateStruct(s any) error {
+	return wrapValidationError(validate.Struct(s))
+}
+
+// Same as validateStruct, but for single value checked by tag
+func validateVar(field any, tag string) error {
+	return wrapValidationError(validate.Var(field, tag))
+}
+
+func wrapValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if validationError, ok := err.(validator.ValidationErrors); ok {
+		err = errorvalues.ErrValidation
+		for _, fieldErr := range validationError {
+			if fieldErr.Tag() == "strong_password" {
+				// Tag name alone doesn't tell what exactly is wrong
+				err = errors.Join(err, errors.New(passwordPolicy.unmet(fieldErr.Value().(string))))
+				continue
+			}
+			err = errors.Join(err, fieldErr)
+		}
+		return err
+	}
+	return fmt.Errorf("validation unexpected error: %w", err)
+}
@@ -0,0 +1,3 @@
+-- +goose Up
+ALTER TABLE habits ADD COLUMN IF NOT EXISTS color VARCHAR(7) NOT NULL DEFAULT '';
+ALTER TABLE habits ADD COLUMN IF NOT EXISTS icon VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- +goose Up
+ALTER TABLE habit_checks ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- +goose Up
+ALTER TABLE habit_checks ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'checked'
+    CHECK (status IN ('checked', 'skipped'));
@@ -0,0 +1,2 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE habits ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+-- Deleted habit must not block creating new one with the same title
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_user_id_title_key;
+CREATE UNIQUE INDEX IF NOT EXISTS habits_user_id_title_active_key ON habits (user_id, title) WHERE deleted_at IS NULL;
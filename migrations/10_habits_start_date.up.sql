@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE habits ADD COLUMN IF NOT EXISTS start_date DATE;
+-- Existing habits are tracked from the day they were created
+UPDATE habits SET start_date = created_at::date WHERE start_date IS NULL;
+ALTER TABLE habits ALTER COLUMN start_date SET DEFAULT CURRENT_DATE;
+ALTER TABLE habits ALTER COLUMN start_date SET NOT NULL;
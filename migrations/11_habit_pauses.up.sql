@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS habit_pauses (
+    id SERIAL PRIMARY KEY,
+    habit_id UUID NOT NULL REFERENCES habits(id) ON DELETE CASCADE,
+    paused_at DATE NOT NULL,
+    -- NULL while habit stays paused
+    resumed_at DATE,
+
+    CHECK (resumed_at IS NULL OR resumed_at >= paused_at)
+);
+
+-- Ongoing pause is habit's paused state, there can be only one
+CREATE UNIQUE INDEX IF NOT EXISTS habit_pauses_ongoing_key ON habit_pauses (habit_id) WHERE resumed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_habit_pauses_habit_id ON habit_pauses (habit_id);
@@ -0,0 +1,2 @@
+-- +goose Up
+ALTER TABLE users ALTER COLUMN name TYPE VARCHAR(100);
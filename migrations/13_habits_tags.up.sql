@@ -0,0 +1,2 @@
+-- +goose Up
+ALTER TABLE habits ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Materialized checks stats of habit, valid for as_of day and since tracking start only
+CREATE TABLE IF NOT EXISTS habit_stats (
+    habit_id UUID PRIMARY KEY REFERENCES habits(id) ON DELETE CASCADE,
+    total_checks INTEGER NOT NULL DEFAULT 0,
+    current_streak INTEGER NOT NULL DEFAULT 0,
+    max_streak INTEGER NOT NULL DEFAULT 0,
+    last_check DATE,
+    completion_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    as_of DATE NOT NULL,
+    since DATE NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE habits ADD COLUMN IF NOT EXISTS allow_multiple_per_day BOOLEAN NOT NULL DEFAULT FALSE;
+-- Checks of habits allowing several ones a day are marked repeatable and exempt from per-day uniqueness
+ALTER TABLE habit_checks ADD COLUMN IF NOT EXISTS repeatable BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE habit_checks DROP CONSTRAINT IF EXISTS habit_checks_habit_id_check_date_key;
+CREATE UNIQUE INDEX IF NOT EXISTS habit_checks_habit_id_check_date_single_key ON habit_checks (habit_id, check_date) WHERE NOT repeatable;
@@ -0,0 +1,3 @@
+-- +goose Up
+-- Backs cursor pagination of user's habits: WHERE (created_at, id) > cursor ORDER BY created_at, id
+CREATE INDEX IF NOT EXISTS idx_habits_user_id_created_at_id ON habits (user_id, created_at, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+-- +goose Up
+-- Description is scanned into string, so it must never be NULL
+UPDATE habits SET description = '' WHERE description IS NULL;
+ALTER TABLE habits ALTER COLUMN description SET DEFAULT '';
+ALTER TABLE habits ALTER COLUMN description SET NOT NULL;
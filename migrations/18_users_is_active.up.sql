@@ -0,0 +1,3 @@
+-- +goose Up
+-- Inactive users are suspended by admin, they can neither log in nor use issued tokens
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
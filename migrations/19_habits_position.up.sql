@@ -0,0 +1,9 @@
+-- +goose Up
+-- Position in user's manually arranged habits list, new habits go last
+ALTER TABLE habits ADD COLUMN IF NOT EXISTS position BIGSERIAL;
+-- Existing habits keep creation order
+UPDATE habits SET position = ordered.n
+FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY created_at, id) AS n FROM habits) ordered
+WHERE habits.id = ordered.id;
+SELECT setval(pg_get_serial_sequence('habits', 'position'), COALESCE(MAX(position), 0) + 1, false) FROM habits;
+CREATE INDEX IF NOT EXISTS idx_habits_user_id_position ON habits (user_id, position, id) WHERE deleted_at IS NULL;
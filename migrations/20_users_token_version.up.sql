@@ -0,0 +1,3 @@
+-- +goose Up
+-- Bumped to revoke all user's tokens, ones carrying other version are rejected
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- +goose Up
+-- Defense in depth for service's future dates rejection. It's done in user's timezone,
+-- which may be up to a day ahead of server's one, hence a day of margin
+ALTER TABLE habit_checks ADD CONSTRAINT habit_checks_check_date_not_future
+    CHECK (check_date <= CURRENT_DATE + 1);
@@ -0,0 +1,3 @@
+-- +goose Up
+-- Default start date is today in user's timezone, which only service knows
+ALTER TABLE habits ALTER COLUMN start_date DROP DEFAULT;
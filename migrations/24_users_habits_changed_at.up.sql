@@ -0,0 +1,3 @@
+-- +goose Up
+-- Last time habit left user's list without trace in habits table (hard deletion or merge)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS habits_changed_at TIMESTAMPTZ;
@@ -0,0 +1,8 @@
+// Package migrations keeps SQL migrations embedded into binary,
+// so it doesn't depend on files layout on the host
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,9 @@
+package buildinfo
+
+// Values are injected on build, e.g.:
+// go build -ldflags "-X github.com/limbo/discipline/pkg/buildinfo.Version=v1.0.0" ...
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
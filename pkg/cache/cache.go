@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Key-value storage with entries expiring after some time.
+// Implementations must be safe for concurrent use.
+type Cache[K comparable, V any] interface {
+	// Returns value by key, false if there is no such key or it's expired
+	Get(key K) (V, bool)
+	Set(key K, value V)
+	Delete(key K)
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// In-memory cache, expired entries are dropped on access and by periodic sweeps on Set.
+type Memory[K comparable, V any] struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[K]entry[V]
+	lastSweep time.Time
+}
+
+func NewMemory[K comparable, V any](ttl time.Duration) *Memory[K, V] {
+	return &Memory[K, V]{
+		ttl:       ttl,
+		entries:   make(map[K]entry[V]),
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *Memory[K, V]) Get(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(m.entries, key)
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (m *Memory[K, V]) Set(key K, value V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	// Keys never requested again would stay forever otherwise
+	if now.Sub(m.lastSweep) > m.ttl {
+		for k, e := range m.entries {
+			if now.After(e.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	m.entries[key] = entry[V]{value: value, expiresAt: now.Add(m.ttl)}
+}
+
+func (m *Memory[K, V]) Delete(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
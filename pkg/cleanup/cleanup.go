@@ -1,6 +1,10 @@
 package cleanup
 
-import "log"
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
 
 type Job struct {
 	Name string
@@ -15,14 +19,25 @@ func Register(j *Job) {
 	jobs = append(jobs, j)
 }
 
-func CleanUp() {
+// Runs all registered jobs in order of registration, failed job doesn't stop others.
+// Returns errors of failed jobs joined, nil if all of them succeeded.
+func CleanUp(logger *slog.Logger) error {
+	start := time.Now()
+	var errs []error
 	for _, j := range jobs {
-		log.Printf("Cleanup job %s started...", j.Name)
+		jobStart := time.Now()
 		err := j.F()
 		if err != nil {
-			log.Printf("Job finished with error: %v", err)
-		} else {
-			log.Println("Cleaned")
+			logger.Error("cleanup job failed", slog.String("job", j.Name), slog.Duration("duration", time.Since(jobStart)), slog.String("error", err.Error()))
+			errs = append(errs, errors.New(j.Name+": "+err.Error()))
+			continue
 		}
+		logger.Info("cleanup job done", slog.String("job", j.Name), slog.Duration("duration", time.Since(jobStart)))
 	}
+	logger.Info("cleanup finished",
+		slog.Int("jobs", len(jobs)),
+		slog.Int("failed", len(errs)),
+		slog.Duration("duration", time.Since(start)),
+	)
+	return errors.Join(errs...)
 }
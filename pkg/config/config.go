@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"io/fs"
 	"log"
 	"os"
 	"sync"
@@ -13,20 +15,49 @@ var (
 	instance *Config
 )
 
+// Used if ENV_FILE isn't set
+const DefaultEnvFile = "./configs/.env"
+
+// Variables service can't start without. If all of them are set in environment,
+// env file is optional, so deployments may rely on real env vars only.
+var RequiredKeys = []string{"API_ADDRESS", "JWT_SECRET", "POSTGRES_DB_ADDRESS", "POSTGRES_USER", "POSTGRES_PASSWORD", "POSTGRES_DB"}
+
 type Config struct {
 }
 
+// Loads envs from file at ENV_FILE path (DefaultEnvFile if unset) once, any error is fatal.
 func New() *Config {
 	once.Do(func() {
-		err := godotenv.Load("./configs/.env")
+		path := os.Getenv("ENV_FILE")
+		if path == "" {
+			path = DefaultEnvFile
+		}
+		var err error
+		instance, err = Load(path, RequiredKeys)
 		if err != nil {
 			log.Fatal("loading envs error: ", err)
 		}
-		instance = &Config{}
 	})
 	return instance
 }
 
+// Loads envs from file at path, already set variables are not overridden.
+// Missing file is not an error if all required variables are set.
+func Load(path string, required []string) (*Config, error) {
+	err := godotenv.Load(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, key := range required {
+			if _, ok := os.LookupEnv(key); !ok {
+				return nil, errors.New("env file " + path + " not found and " + key + " is not set")
+			}
+		}
+	}
+	return &Config{}, nil
+}
+
 func (c *Config) GetString(key string) string {
 	return os.Getenv(key)
 }
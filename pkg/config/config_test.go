@@ -0,0 +1,33 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/limbo/discipline/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("override path", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.env")
+		require.NoError(t, os.WriteFile(path, []byte("CONFIG_TEST_VALUE=from_file\n"), 0o600))
+		t.Setenv("CONFIG_TEST_VALUE", "")
+		os.Unsetenv("CONFIG_TEST_VALUE")
+		cfg, err := config.Load(path, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "from_file", cfg.GetString("CONFIG_TEST_VALUE"))
+	})
+	t.Run("file missing but env set", func(t *testing.T) {
+		t.Setenv("CONFIG_TEST_REQUIRED", "from_env")
+		cfg, err := config.Load(filepath.Join(t.TempDir(), "missing.env"), []string{"CONFIG_TEST_REQUIRED"})
+		require.NoError(t, err)
+		assert.Equal(t, "from_env", cfg.GetString("CONFIG_TEST_REQUIRED"))
+	})
+	t.Run("file missing and env unset", func(t *testing.T) {
+		_, err := config.Load(filepath.Join(t.TempDir(), "missing.env"), []string{"CONFIG_TEST_UNSET"})
+		assert.ErrorContains(t, err, "CONFIG_TEST_UNSET")
+	})
+}
@@ -10,6 +10,40 @@ type User struct {
 	ID           uuid.UUID
 	Name         string
 	PasswordHash string
+	// Nil if user has never logged in
+	LastLoginAt *time.Time
+	// IANA name (e.g. Europe/Moscow), user's calendar days are counted in it
+	Timezone string
+	// Set if account is suspended by admin (users.is_active is false), zero value is active one
+	Disabled bool
+	// Issued tokens carry it, ones with outdated version are revoked
+	TokenVersion int
+}
+
+// Order of users list, "-" prefix means descending one
+type UserSort string
+
+const (
+	UserSortName          UserSort = "name"
+	UserSortNameDesc      UserSort = "-name"
+	UserSortCreatedAt     UserSort = "created_at"
+	UserSortCreatedAtDesc UserSort = "-created_at"
+)
+
+// Order of user's habits list
+type HabitSort string
+
+const (
+	HabitSortCreatedAt HabitSort = "created_at"
+	// Order arranged by user (see HabitsRepositoryI.Reorder), new habits go last
+	HabitSortPosition HabitSort = "position"
+)
+
+// Count of rows removed on user's data erasure
+type ErasureSummary struct {
+	Users  int64 `json:"users"`
+	Habits int64 `json:"habits"`
+	Checks int64 `json:"checks"`
 }
 
 type Habit struct {
@@ -17,21 +51,90 @@ type Habit struct {
 	UserID      uuid.UUID `json:"uid"`
 	Title       string    `json:"title"`
 	Description string    `json:"desc"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	// Hex code as #RRGGBB, empty if not set
+	Color string `json:"color"`
+	Icon  string `json:"icon"`
+	// Calendar day (UTC midnight) since which habit is tracked, creation day by default
+	StartDate time.Time `json:"start_date"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Habit may be checked several times a day, then its checks are counted, not just days
+	AllowMultiplePerDay bool `json:"allow_multiple_per_day"`
+	// Labels user groups habits by, empty if there are none
+	Tags []string `json:"tags"`
+	// Set when habit is deleted, it can be restored until purged. Nil for active habits
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Position in habits list ordered by creation time, next page starts right after it.
+// ID breaks ties between habits created at the same time.
+type HabitCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Habit with mark if it was checked on requested day
+type HabitWithStatus struct {
+	Habit
+	CheckedToday bool `json:"checked_today"`
 }
 
+// Kind of mark on habit's day
+type CheckStatus string
+
+const (
+	// Habit was done
+	CheckStatusChecked CheckStatus = "checked"
+	// Day was skipped on purpose (e.g. rest day), it neither breaks streak nor extends it
+	CheckStatusSkipped CheckStatus = "skipped"
+)
+
+// Order of habit checks by check date
+type CheckOrder string
+
+const (
+	CheckOrderAsc  CheckOrder = "asc"
+	CheckOrderDesc CheckOrder = "desc"
+)
+
 type HabitCheck struct {
 	ID        int
 	HabitID   uuid.UUID
 	CheckDate time.Time
+	Status    CheckStatus
+	// User's annotation, empty if not set
+	Note      string
 	CreatedAt time.Time
 }
 
+// Check (or skip) along with title of its habit
+type UserCheck struct {
+	HabitCheck
+	HabitTitle string
+}
+
+// Interval when habit wasn't tracked: from PausedAt up to ResumedAt, which is tracked again.
+// Gaps in checks within pause don't count against user.
+type HabitPause struct {
+	PausedAt time.Time `json:"paused_at" example:"2025-01-01T00:00:00Z"`
+	// Nil while habit stays paused
+	ResumedAt *time.Time `json:"resumed_at,omitempty" example:"2025-01-08T00:00:00Z"`
+}
+
 type HabitStats struct {
-	ID            uuid.UUID `json:"habit_id"`
-	TotalChecks   int       `json:"total_checks"`
-	CurrentStreak int       `json:"current_streak"`
-	MaxStreak     int       `json:"max_streak"`
-	LastCheck     time.Time `json:"last_check,omitempty"`
+	ID             uuid.UUID `json:"habit_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TotalChecks    int       `json:"total_checks" example:"42"`
+	CurrentStreak  int       `json:"current_streak" example:"5"`
+	MaxStreak      int       `json:"max_streak" example:"12"`
+	LastCheck      time.Time `json:"last_check,omitempty" example:"2025-01-01T00:00:00Z"`
+	CompletionRate float64   `json:"completion_rate" example:"0.75"`
+	Paused         bool      `json:"paused" example:"false"`
+}
+
+// Aggregated stats over all user's habits
+type UserSummary struct {
+	Habits      int `json:"habits"`
+	TotalChecks int `json:"total_checks"`
+	// The longest max streak among user's habits
+	LongestStreak int `json:"longest_streak"`
 }
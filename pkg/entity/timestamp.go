@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Time serialized in JSON as RFC3339 in UTC without fractional seconds (e.g. 2025-01-01T12:00:00Z),
+// so clients get the same format whatever precision and zone it's stored with.
+// Decoding accepts any RFC3339 time, with or without fractional seconds.
+type Timestamp time.Time
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + time.Time(t).UTC().Format(time.RFC3339) + `"`), nil
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	return (*time.Time)(t).UnmarshalJSON(data)
+}
+
+// Converts optional time to Timestamp, nil stays nil.
+func OptionalTimestamp(t *time.Time) *Timestamp {
+	if t == nil {
+		return nil
+	}
+	ts := Timestamp(*t)
+	return &ts
+}
+
+// Same fields as Habit without its MarshalJSON, timestamps are overridden in HabitJSON
+type habitFields Habit
+
+// Habit as it's serialized in JSON. Structs embedding Habit marshal it
+// through Habit.JSON, otherwise promoted Habit.MarshalJSON would drop their own fields.
+type HabitJSON struct {
+	habitFields
+	StartDate Timestamp  `json:"start_date"`
+	CreatedAt Timestamp  `json:"created_at"`
+	UpdatedAt Timestamp  `json:"updated_at"`
+	DeletedAt *Timestamp `json:"deleted_at,omitempty"`
+}
+
+func (h Habit) JSON() HabitJSON {
+	return HabitJSON{
+		habitFields: habitFields(h),
+		StartDate:   Timestamp(h.StartDate),
+		CreatedAt:   Timestamp(h.CreatedAt),
+		UpdatedAt:   Timestamp(h.UpdatedAt),
+		DeletedAt:   OptionalTimestamp(h.DeletedAt),
+	}
+}
+
+func (h Habit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.JSON())
+}
+
+func (h HabitWithStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		HabitJSON
+		CheckedToday bool `json:"checked_today"`
+	}{h.Habit.JSON(), h.CheckedToday})
+}
+
+type habitCheckFields HabitCheck
+
+type habitCheckJSON struct {
+	habitCheckFields
+	CheckDate Timestamp
+	CreatedAt Timestamp
+}
+
+func (c HabitCheck) jsonView() habitCheckJSON {
+	return habitCheckJSON{
+		habitCheckFields: habitCheckFields(c),
+		CheckDate:        Timestamp(c.CheckDate),
+		CreatedAt:        Timestamp(c.CreatedAt),
+	}
+}
+
+func (c HabitCheck) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.jsonView())
+}
+
+func (c UserCheck) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		habitCheckJSON
+		HabitTitle string
+	}{c.HabitCheck.jsonView(), c.HabitTitle})
+}
+
+type habitStatsFields HabitStats
+
+func (s HabitStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		habitStatsFields
+		LastCheck Timestamp `json:"last_check,omitempty"`
+	}{habitStatsFields(s), Timestamp(s.LastCheck)})
+}
@@ -0,0 +1,78 @@
+package entity_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/limbo/discipline/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampFormat(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	createdAt := time.Date(2025, 1, 2, 15, 4, 5, 123456789, moscow)
+	deletedAt := createdAt.Add(time.Hour)
+	habit := entity.Habit{
+		ID:        uuid.New(),
+		Title:     "habit",
+		StartDate: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		DeletedAt: &deletedAt,
+	}
+	t.Run("habit", func(t *testing.T) {
+		data, err := json.Marshal(habit)
+		require.NoError(t, err)
+		var raw map[string]any
+		require.NoError(t, json.Unmarshal(data, &raw))
+		assert.Equal(t, "2025-01-02T00:00:00Z", raw["start_date"])
+		assert.Equal(t, "2025-01-02T12:04:05Z", raw["created_at"])
+		assert.Equal(t, "2025-01-02T12:04:05Z", raw["updated_at"])
+		assert.Equal(t, "2025-01-02T13:04:05Z", raw["deleted_at"])
+		assert.Equal(t, "habit", raw["title"])
+	})
+	t.Run("habit with status keeps its fields", func(t *testing.T) {
+		data, err := json.Marshal(entity.HabitWithStatus{Habit: habit, CheckedToday: true})
+		require.NoError(t, err)
+		var raw map[string]any
+		require.NoError(t, json.Unmarshal(data, &raw))
+		assert.Equal(t, "2025-01-02T12:04:05Z", raw["created_at"])
+		assert.Equal(t, true, raw["checked_today"])
+	})
+	t.Run("check", func(t *testing.T) {
+		data, err := json.Marshal(entity.UserCheck{
+			HabitCheck: entity.HabitCheck{CheckDate: habit.StartDate, CreatedAt: createdAt},
+			HabitTitle: "habit",
+		})
+		require.NoError(t, err)
+		var raw map[string]any
+		require.NoError(t, json.Unmarshal(data, &raw))
+		assert.Equal(t, "2025-01-02T00:00:00Z", raw["CheckDate"])
+		assert.Equal(t, "2025-01-02T12:04:05Z", raw["CreatedAt"])
+		assert.Equal(t, "habit", raw["HabitTitle"])
+	})
+	t.Run("stats", func(t *testing.T) {
+		data, err := json.Marshal(entity.HabitStats{TotalChecks: 3, LastCheck: createdAt})
+		require.NoError(t, err)
+		var raw map[string]any
+		require.NoError(t, json.Unmarshal(data, &raw))
+		assert.Equal(t, "2025-01-02T12:04:05Z", raw["last_check"])
+		assert.EqualValues(t, 3, raw["total_checks"])
+	})
+	t.Run("decoded back", func(t *testing.T) {
+		data, err := json.Marshal(habit)
+		require.NoError(t, err)
+		var decoded entity.Habit
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.True(t, decoded.CreatedAt.Equal(createdAt.Truncate(time.Second)))
+		require.NotNil(t, decoded.DeletedAt)
+		assert.True(t, decoded.DeletedAt.Equal(deletedAt.Truncate(time.Second)))
+		// Input with fractional seconds is accepted as well
+		var ts entity.Timestamp
+		require.NoError(t, json.Unmarshal([]byte(`"2025-01-02T12:04:05.123Z"`), &ts))
+		assert.Equal(t, 123000000, time.Time(ts).Nanosecond())
+	})
+}
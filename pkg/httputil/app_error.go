@@ -0,0 +1,112 @@
+package httputil
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error with HTTP status and machine-readable code it's responded with
+type AppError struct {
+	// Error shown to client, its text becomes response message
+	Err    error
+	Status int
+	Code   string
+	// Full text of matched error is provided in details. Only for errors telling client
+	// what's wrong with its request, others may carry internals in their text
+	ShowDetails bool
+}
+
+func (e *AppError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+type registeredError struct {
+	sentinel error
+	appErr   AppError
+}
+
+// Known sentinels in registration order, first matching one wins
+var registry []registeredError
+
+// Makes errors matching sentinel (by errors.Is) responded with appErr by WriteAppError.
+// If appErr.Err is nil, sentinel itself is shown to client.
+// Must be called on initialization, before any response is written.
+func RegisterError(sentinel error, appErr AppError) {
+	if appErr.Err == nil {
+		appErr.Err = sentinel
+	}
+	registry = append(registry, registeredError{sentinel: sentinel, appErr: appErr})
+}
+
+// Returns AppErrors of registered sentinels in registration order, one per code:
+// if several sentinels share code, the first registered one is kept.
+func RegisteredErrors() []AppError {
+	seen := make(map[string]struct{}, len(registry))
+	result := make([]AppError, 0, len(registry))
+	for _, known := range registry {
+		if _, ok := seen[known.appErr.Code]; ok {
+			continue
+		}
+		seen[known.appErr.Code] = struct{}{}
+		result = append(result, known.appErr)
+	}
+	return result
+}
+
+// Finds AppError err is responded with: err itself if it wraps *AppError,
+// otherwise one registered for sentinel err matches.
+func LookupAppError(err error) (*AppError, bool) {
+	appErr, _, ok := lookup(err, false)
+	return appErr, ok
+}
+
+// Same as LookupAppError, also returns details of err: its full text if err carries
+// more than sentinel itself and either sentinel shows details unmasked or debug is set.
+func lookup(err error, debug bool) (appErr *AppError, details string, ok bool) {
+	if errors.As(err, &appErr) {
+		return appErr, "", true
+	}
+	for _, known := range registry {
+		if !errors.Is(err, known.sentinel) {
+			continue
+		}
+		result := known.appErr
+		showDetails := debug || (result.ShowDetails && result.Err == known.sentinel)
+		if showDetails && err.Error() != known.sentinel.Error() {
+			details = err.Error()
+		}
+		return &result, details, true
+	}
+	return nil, "", false
+}
+
+// Writes error response with status and code of AppError found by LookupAppError.
+// Details are provided only for sentinels registered with ShowDetails,
+// unknown errors are responded with 500 without details.
+func WriteAppError(w http.ResponseWriter, err error) {
+	writeAppError(w, err, false)
+}
+
+// Same as WriteAppError, but full text of err is provided in details whatever sentinel it matches.
+// Meant for debugging only, as error text may carry internals.
+func WriteAppErrorWithDetails(w http.ResponseWriter, err error) {
+	writeAppError(w, err, true)
+}
+
+func writeAppError(w http.ResponseWriter, err error, debug bool) {
+	appErr, details, ok := lookup(err, debug)
+	if !ok {
+		WriteErrorResponse(w, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+	writeErrorResponse(w, appErr.Status, ErrorResponse{
+		Code:      appErr.Status,
+		ErrorCode: appErr.Code,
+		Message:   appErr.Error(),
+		Details:   details,
+	})
+}
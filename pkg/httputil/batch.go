@@ -0,0 +1,47 @@
+package httputil
+
+import "net/http"
+
+// Outcome of single item in batch, status is HTTP code it would get being sent alone.
+// Responses carrying item's data embed it.
+type BatchItemResult struct {
+	Index  int `json:"index" example:"0"`
+	Status int `json:"status" example:"201"`
+	// Machine-readable code of item's error, see AppError. Absent for succeeded items and internal errors
+	ErrorCode string `json:"error_code,omitempty" example:"habit_exists"`
+	Error     string `json:"error,omitempty" example:"habit already exists"`
+}
+
+func (res BatchItemResult) BatchResult() BatchItemResult {
+	return res
+}
+
+func (res BatchItemResult) Failed() bool {
+	return res.Status >= http.StatusBadRequest
+}
+
+// Result of batch item, BatchItemResult itself or type embedding it
+type BatchItem interface {
+	BatchResult() BatchItemResult
+}
+
+// Body of batch responses, results are in request order
+type BatchResponse[T any] struct {
+	Results []T `json:"results"`
+}
+
+// Writes results of batch: with successStatus (e.g. 201) if all items succeeded,
+// with 207 Multi-Status if any failed, so clients know to inspect items.
+func WriteBatchResponse[T BatchItem](w http.ResponseWriter, successStatus int, results []T) {
+	status := successStatus
+	for _, res := range results {
+		if res.BatchResult().Failed() {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+	if results == nil {
+		results = make([]T, 0)
+	}
+	WriteJSONResponse(w, status, BatchResponse[T]{Results: results})
+}
@@ -0,0 +1,36 @@
+package httputil_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/limbo/discipline/pkg/httputil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteBatchResponse(t *testing.T) {
+	created := httputil.BatchItemResult{Index: 0, Status: http.StatusCreated}
+	failed := httputil.BatchItemResult{Index: 1, Status: http.StatusConflict, ErrorCode: "habit_exists", Error: "habit already exists"}
+	testCases := []struct {
+		Desc         string
+		Results      []httputil.BatchItemResult
+		ExpectedCode int
+	}{
+		{Desc: "all succeeded", Results: []httputil.BatchItemResult{created, created}, ExpectedCode: http.StatusCreated},
+		{Desc: "mixed", Results: []httputil.BatchItemResult{created, failed}, ExpectedCode: http.StatusMultiStatus},
+		{Desc: "all failed", Results: []httputil.BatchItemResult{failed}, ExpectedCode: http.StatusMultiStatus},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			httputil.WriteBatchResponse(rr, http.StatusCreated, tc.Results)
+			assert.Equal(t, tc.ExpectedCode, rr.Code)
+		})
+	}
+	t.Run("items shape", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		httputil.WriteBatchResponse(rr, http.StatusOK, []httputil.BatchItemResult{created, failed})
+		assert.JSONEq(t, `{"results":[{"index":0,"status":201},{"index":1,"status":409,"error_code":"habit_exists","error":"habit already exists"}]}`, rr.Body.String())
+	})
+}
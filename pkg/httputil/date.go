@@ -0,0 +1,21 @@
+package httputil
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Error ParseDate fails with, its text is meant to be responded to client
+var ErrInvalidDate = errors.New("date must be YYYY-MM-DD")
+
+// Parses date given in request. Only date-only format (YYYY-MM-DD) is accepted:
+// full timestamps are rejected, so time components never get to stored check dates.
+// Result is midnight in UTC.
+func ParseDate(raw string) (time.Time, error) {
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, errors.Join(ErrInvalidDate, errors.New("got "+strconv.Quote(raw)))
+	}
+	return date, nil
+}
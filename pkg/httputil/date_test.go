@@ -0,0 +1,39 @@
+package httputil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/limbo/discipline/pkg/httputil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDate(t *testing.T) {
+	testCases := []struct {
+		Desc     string
+		Raw      string
+		Expected time.Time
+		Valid    bool
+	}{
+		{Desc: "date only", Raw: "2025-01-31", Expected: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), Valid: true},
+		{Desc: "leap day", Raw: "2024-02-29", Expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), Valid: true},
+		{Desc: "timestamp", Raw: "2025-01-31T10:00:00Z"},
+		{Desc: "timestamp with space", Raw: "2025-01-31 10:00:00"},
+		{Desc: "non-existent day", Raw: "2025-02-30"},
+		{Desc: "no zero padding", Raw: "2025-1-5"},
+		{Desc: "other order", Raw: "31.01.2025"},
+		{Desc: "garbage", Raw: "yesterday"},
+		{Desc: "empty", Raw: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Desc, func(t *testing.T) {
+			date, err := httputil.ParseDate(tc.Raw)
+			if !tc.Valid {
+				assert.ErrorIs(t, err, httputil.ErrInvalidDate)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, date)
+		})
+	}
+}
@@ -6,16 +6,18 @@ import (
 	"github.com/bytedance/sonic"
 )
 
+// Body of every error response
 type ErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	// Duplicates HTTP status code
+	Code int `json:"code" example:"404"`
+	// Machine-readable error code, see AppError
+	ErrorCode string `json:"error_code,omitempty" example:"habit_not_found"`
+	Message   string `json:"message" example:"habit doesn't exist"`
+	// Underlying error, provided if debug errors are on or it clarifies client's error (e.g. unmet validation rules)
+	Details string `json:"details,omitempty" example:"habit not found"`
 }
 
 func WriteErrorResponse(w http.ResponseWriter, statusCode int, message string, details error) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
 	resp := ErrorResponse{
 		Code:    statusCode,
 		Message: message,
@@ -25,6 +27,12 @@ func WriteErrorResponse(w http.ResponseWriter, statusCode int, message string, d
 		resp.Details = details.Error()
 	}
 
+	writeErrorResponse(w, statusCode, resp)
+}
+
+func writeErrorResponse(w http.ResponseWriter, statusCode int, resp ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
 	sonic.ConfigFastest.NewEncoder(w).Encode(resp)
 }
 
@@ -0,0 +1,36 @@
+package httputil
+
+// Page of list, embedded into responses of paginated endpoints so they share the same shape
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// Absent in cursor pages
+	Page  int `json:"page,omitempty" example:"1"`
+	Limit int `json:"limit" example:"10"`
+	// Count of items on all pages, absent in cursor pages since they aren't counted
+	Total      *int `json:"total,omitempty" example:"42"`
+	TotalPages *int `json:"total_pages,omitempty" example:"5"`
+}
+
+// Makes page of items out of total ones. Nil items become empty list, so they are encoded as [].
+func NewPage[T any](items []T, page, limit, total int) Page[T] {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = (total + limit - 1) / limit
+	}
+	p := NewCursorPage(items, limit)
+	p.Page = page
+	p.Total = &total
+	p.TotalPages = &totalPages
+	return p
+}
+
+// Same as NewPage, but for page fetched by cursor: it has no number and total is unknown.
+func NewCursorPage[T any](items []T, limit int) Page[T] {
+	if items == nil {
+		items = make([]T, 0)
+	}
+	return Page[T]{
+		Items: items,
+		Limit: limit,
+	}
+}
@@ -0,0 +1,39 @@
+package httputil_test
+
+import (
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/limbo/discipline/pkg/httputil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageJSON(t *testing.T) {
+	t.Run("strings", func(t *testing.T) {
+		data, err := sonic.Marshal(httputil.NewPage([]string{"a", "b"}, 2, 2, 5))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"items":["a","b"],"page":2,"limit":2,"total":5,"total_pages":3}`, string(data))
+	})
+	t.Run("structs embedded in response", func(t *testing.T) {
+		type item struct {
+			Name string `json:"name"`
+		}
+		data, err := sonic.Marshal(struct {
+			httputil.Page[item]
+			Extra string `json:"extra"`
+		}{httputil.NewPage([]item{{Name: "x"}}, 1, 10, 1), "meta"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"items":[{"name":"x"}],"page":1,"limit":10,"total":1,"total_pages":1,"extra":"meta"}`, string(data))
+	})
+	t.Run("empty", func(t *testing.T) {
+		data, err := sonic.Marshal(httputil.NewPage[int](nil, 1, 10, 0))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"items":[],"page":1,"limit":10,"total":0,"total_pages":0}`, string(data))
+	})
+	t.Run("cursor page without totals", func(t *testing.T) {
+		data, err := sonic.Marshal(httputil.NewCursorPage([]string{"a"}, 10))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"items":["a"],"limit":10}`, string(data))
+	})
+}
@@ -13,23 +13,44 @@ import (
 
 var (
 	tokenTTL = time.Hour
+	// HS256 accepts empty key, but tokens signed with it can be forged by anyone
+	ErrEmptySecret = errors.New("jwt secret is empty")
 )
 
 type JWTService struct {
+	// Signs new tokens and verifies them
 	secret []byte
+	// Secrets of previous rotations, tokens signed with them are still accepted
+	previous [][]byte
 }
 
 func New(secret string) *JWTService {
-	return &JWTService{
+	return NewWithRotation(secret, nil)
+}
+
+// Creates service signing tokens with secret and accepting ones signed with any of previous secrets,
+// so secret can be rotated without invalidating live tokens. Empty previous secrets are ignored.
+func NewWithRotation(secret string, previous []string) *JWTService {
+	s := &JWTService{
 		secret: []byte(secret),
 	}
+	for _, prev := range previous {
+		if prev != "" {
+			s.previous = append(s.previous, []byte(prev))
+		}
+	}
+	return s
 }
 
 func (s *JWTService) GenerateToken(user *entity.User) (string, error) {
+	if len(s.secret) == 0 {
+		return "", ErrEmptySecret
+	}
 	expTime := time.Now().Add(tokenTTL)
 	claims := &api.JWTClaims{
-		UserID:   user.ID.String(),
-		Username: user.Name,
+		UserID:       user.ID.String(),
+		Username:     user.Name,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -41,14 +62,27 @@ func (s *JWTService) GenerateToken(user *entity.User) (string, error) {
 }
 
 func (s *JWTService) ParseToken(tokenString string) (*api.JWTClaims, error) {
+	// Otherwise token signed with empty key would be verified
+	if len(s.secret) == 0 {
+		return nil, ErrEmptySecret
+	}
 	token, err := jwt.ParseWithClaims(tokenString, &api.JWTClaims{}, func(t *jwt.Token) (any, error) {
 		if t.Method != jwt.SigningMethodHS256 {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return s.secret, nil
+		if len(s.previous) == 0 {
+			return s.secret, nil
+		}
+		// Keys are tried in order until one verifies signature
+		keys := jwt.VerificationKeySet{Keys: []jwt.VerificationKey{s.secret}}
+		for _, prev := range s.previous {
+			keys.Keys = append(keys.Keys, prev)
+		}
+		return keys, nil
 	})
 	if err != nil {
-		return nil, errors.New("token parsing error: " + err.Error())
+		// Malformed, expired or forged token is client's fault, not internal error
+		return nil, errors.Join(errorvalues.ErrInvalidToken, errors.New("token parsing error: "+err.Error()))
 	}
 	claims, ok := token.Claims.(*api.JWTClaims)
 	if !ok || !token.Valid {
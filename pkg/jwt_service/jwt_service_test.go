@@ -0,0 +1,59 @@
+package jwtservice_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/limbo/discipline/internal/api"
+	"github.com/limbo/discipline/pkg/entity"
+	jwtservice "github.com/limbo/discipline/pkg/jwt_service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretRotation(t *testing.T) {
+	user := &entity.User{ID: uuid.New(), Name: "test_name"}
+	rotated := jwtservice.NewWithRotation("new_secret", []string{"old_secret"})
+	t.Run("signed with primary", func(t *testing.T) {
+		token, err := rotated.GenerateToken(user)
+		require.NoError(t, err)
+		claims, err := jwtservice.New("new_secret").ParseToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID.String(), claims.UserID)
+	})
+	t.Run("old secret still accepted", func(t *testing.T) {
+		token, err := jwtservice.New("old_secret").GenerateToken(user)
+		require.NoError(t, err)
+		claims, err := rotated.ParseToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID.String(), claims.UserID)
+	})
+	t.Run("retired secret rejected", func(t *testing.T) {
+		token, err := jwtservice.New("retired_secret").GenerateToken(user)
+		require.NoError(t, err)
+		_, err = rotated.ParseToken(token)
+		assert.Error(t, err)
+	})
+}
+
+func TestEmptySecret(t *testing.T) {
+	_, err := jwtservice.New("").GenerateToken(&entity.User{ID: uuid.New(), Name: "test_name"})
+	assert.ErrorIs(t, err, jwtservice.ErrEmptySecret)
+	t.Run("token signed with empty key rejected", func(t *testing.T) {
+		claims := &api.JWTClaims{
+			UserID:   uuid.New().String(),
+			Username: "test_name",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+		forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(""))
+		require.NoError(t, err)
+		_, err = jwtservice.New("").ParseToken(forged)
+		assert.ErrorIs(t, err, jwtservice.ErrEmptySecret)
+		_, err = jwtservice.NewWithRotation("", []string{"old_secret"}).ParseToken(forged)
+		assert.ErrorIs(t, err, jwtservice.ErrEmptySecret)
+	})
+}
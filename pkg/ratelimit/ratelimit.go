@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Token buckets keyed by string (e.g. user id). Each bucket holds up to burst tokens
+// and is refilled with rate tokens per second. Safe for concurrent use.
+type Keyed struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func NewKeyed(rate float64, burst int) *Keyed {
+	return &Keyed{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Takes token from key's bucket, reports false if it's empty.
+func (k *Keyed) Allow(key string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := time.Now()
+	// Time for empty bucket to get full, such buckets are the same as absent ones
+	refill := time.Duration(k.burst / k.rate * float64(time.Second))
+	if now.Sub(k.lastSweep) > refill {
+		for key, b := range k.buckets {
+			if now.Sub(b.updatedAt) > refill {
+				delete(k.buckets, key)
+			}
+		}
+		k.lastSweep = now
+	}
+	b, ok := k.buckets[key]
+	if !ok {
+		b = &bucket{tokens: k.burst, updatedAt: now}
+		k.buckets[key] = b
+	}
+	b.tokens = min(k.burst, b.tokens+now.Sub(b.updatedAt).Seconds()*k.rate)
+	b.updatedAt = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Returns time needed for single token to be refilled.
+func (k *Keyed) Interval() time.Duration {
+	return time.Duration(float64(time.Second) / k.rate)
+}
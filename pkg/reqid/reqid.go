@@ -0,0 +1,74 @@
+package reqid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Generates request ids, implementations are safe for concurrent use
+type Generator interface {
+	NewID() string
+}
+
+// Returns generator of ids in given format: "uuid" (also for empty one), "ulid" or "counter".
+func New(format string) (Generator, error) {
+	switch format {
+	case "", "uuid":
+		return UUID{}, nil
+	case "ulid":
+		return ULID{}, nil
+	case "counter":
+		return &Counter{}, nil
+	}
+	return nil, fmt.Errorf("unknown request id format %q", format)
+}
+
+// Generates random (v4) UUIDs
+type UUID struct{}
+
+func (UUID) NewID() string {
+	return uuid.NewString()
+}
+
+// Generates ULIDs: millisecond timestamp followed by 80 random bits in Crockford's base32,
+// so ids of different milliseconds sort in order of generation.
+type ULID struct{}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func (ULID) NewID() string {
+	var b [16]byte
+	// Timestamp takes 48 bits, so two highest bytes of uint64 are dropped
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	rand.Read(b[6:])
+	var out [26]byte
+	// 128 bits are encoded as 130 ones with two leading zeros, 5 bits per char
+	for i := range out {
+		var char byte
+		for j := range 5 {
+			bit := i*5 + j - 2
+			char <<= 1
+			if bit >= 0 && b[bit/8]&(0x80>>(bit%8)) != 0 {
+				char |= 1
+			}
+		}
+		out[i] = crockfordAlphabet[char]
+	}
+	return string(out[:])
+}
+
+// Generates increasing decimal numbers starting from 1. Ids are unique only within process,
+// they repeat after restart. Zero value is ready to use.
+type Counter struct {
+	last atomic.Uint64
+}
+
+func (c *Counter) NewID() string {
+	return strconv.FormatUint(c.last.Add(1), 10)
+}
@@ -0,0 +1,83 @@
+package reqid_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/limbo/discipline/pkg/reqid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		Format   string
+		Expected reqid.Generator
+		Error    bool
+	}{
+		{Format: "", Expected: reqid.UUID{}},
+		{Format: "uuid", Expected: reqid.UUID{}},
+		{Format: "ulid", Expected: reqid.ULID{}},
+		{Format: "counter", Expected: &reqid.Counter{}},
+		{Format: "snowflake", Error: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Format, func(t *testing.T) {
+			gen, err := reqid.New(tc.Format)
+			if tc.Error {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tc.Expected, gen)
+		})
+	}
+}
+
+func TestUUID(t *testing.T) {
+	id := reqid.UUID{}.NewID()
+	_, err := uuid.Parse(id)
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, reqid.UUID{}.NewID())
+}
+
+func TestULID(t *testing.T) {
+	gen := reqid.ULID{}
+	first := gen.NewID()
+	assert.Len(t, first, 26)
+	for _, char := range first {
+		assert.Contains(t, "0123456789ABCDEFGHJKMNPQRSTVWXYZ", string(char))
+	}
+	// Highest char holds only 3 bits
+	assert.LessOrEqual(t, first[0], byte('7'))
+	time.Sleep(2 * time.Millisecond)
+	second := gen.NewID()
+	assert.Less(t, first, second)
+	// Random part follows 10 chars of timestamp
+	assert.NotEqual(t, first[10:], second[10:])
+}
+
+func TestCounter(t *testing.T) {
+	var c reqid.Counter
+	assert.Equal(t, "1", c.NewID())
+	assert.Equal(t, "2", c.NewID())
+	ids := make(chan string, 100)
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- c.NewID()
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	unique := make(map[string]struct{})
+	for id := range ids {
+		unique[id] = struct{}{}
+	}
+	assert.Len(t, unique, 100)
+	assert.Equal(t, "103", c.NewID())
+}
@@ -0,0 +1,60 @@
+package reqstats
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// In-memory counters of served requests, lightweight alternative to Prometheus metrics.
+// Safe for concurrent use, zero value is ready to count.
+type Counters struct {
+	total   atomic.Int64
+	latency atomic.Int64
+	// Requests by status class, index is first digit of status (1xx-5xx), unexpected statuses go to 0
+	classes [6]atomic.Int64
+}
+
+// Counted requests since server start
+type Snapshot struct {
+	Total int64
+	// Requests by status class ("2xx", "4xx" etc.), classes without requests are absent
+	ByStatusClass map[string]int64
+	// Zero if nothing was counted yet
+	AverageLatency time.Duration
+}
+
+// Counts request responded with status after taking d.
+func (c *Counters) Observe(status int, d time.Duration) {
+	class := status / 100
+	if class < 1 || class >= len(c.classes) {
+		class = 0
+	}
+	c.classes[class].Add(1)
+	c.latency.Add(int64(d))
+	c.total.Add(1)
+}
+
+// Returns current values of counters. Counters aren't read at once,
+// so snapshot taken while requests are observed may be off by these requests.
+func (c *Counters) Snapshot() Snapshot {
+	snap := Snapshot{
+		Total:         c.total.Load(),
+		ByStatusClass: make(map[string]int64, len(c.classes)),
+	}
+	for class := range c.classes {
+		count := c.classes[class].Load()
+		if count == 0 {
+			continue
+		}
+		name := "other"
+		if class != 0 {
+			name = strconv.Itoa(class) + "xx"
+		}
+		snap.ByStatusClass[name] = count
+	}
+	if snap.Total > 0 {
+		snap.AverageLatency = time.Duration(c.latency.Load() / snap.Total)
+	}
+	return snap
+}
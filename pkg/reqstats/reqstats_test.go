@@ -0,0 +1,32 @@
+package reqstats_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/limbo/discipline/pkg/reqstats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounters(t *testing.T) {
+	var c reqstats.Counters
+	assert.Equal(t, reqstats.Snapshot{ByStatusClass: map[string]int64{}}, c.Snapshot())
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Observe(http.StatusOK, 10*time.Millisecond)
+		}()
+	}
+	wg.Wait()
+	c.Observe(http.StatusNotFound, 20*time.Millisecond)
+	c.Observe(http.StatusInternalServerError, 30*time.Millisecond)
+	c.Observe(0, 0)
+	snap := c.Snapshot()
+	assert.EqualValues(t, 103, snap.Total)
+	assert.Equal(t, map[string]int64{"2xx": 100, "4xx": 1, "5xx": 1, "other": 1}, snap.ByStatusClass)
+	assert.Equal(t, 1050*time.Millisecond/103, snap.AverageLatency)
+}